@@ -0,0 +1,129 @@
+package raml
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AnnotationType is the declaration of an annotation in `annotationTypes`,
+// in an API definition, a library or a `#%RAML 1.0 AnnotationTypeDeclaration`
+// fragment, e.g.:
+//
+//	annotationTypes:
+//	  owner:
+//	    type: string
+//	    allowedTargets: [ Resource, Method ]
+type AnnotationType struct {
+	// name of the annotation type, without parentheses
+	Name string
+
+	// type of the values of the annotation, `string` if not declared.
+	// The annotation type could be declared by its type expression only, e.g. `tags: string[]`.
+	Type Type
+
+	// the kinds of nodes the annotation could be applied to, e.g. `Method`,
+	// any kind of node if empty
+	AllowedTargets []string
+}
+
+// annotationTypeDeclaration is the declaration of an annotation type,
+// its keys are checked for unknown keys
+type annotationTypeDeclaration struct {
+	typeDeclaration `yaml:",inline"`
+	AllowedTargets  interface{} `yaml:"allowedTargets"`
+}
+
+// UnmarshalYAML decodes the type of the annotation and its allowed targets,
+// a target or a list of targets
+func (a *AnnotationType) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&a.Type); err != nil {
+		return err
+	}
+	delete(a.Type.FacetValues, "allowedTargets")
+	if len(a.Type.FacetValues) == 0 {
+		a.Type.FacetValues = nil
+	}
+	var decl struct {
+		AllowedTargets interface{} `yaml:"allowedTargets"`
+	}
+	if err := unmarshal(&decl); err != nil {
+		// declared by its type expression
+		return nil
+	}
+	switch targets := decl.AllowedTargets.(type) {
+	case []interface{}:
+		for _, target := range targets {
+			a.AllowedTargets = append(a.AllowedTargets, fmt.Sprint(target))
+		}
+	case nil:
+	default:
+		a.AllowedTargets = []string{fmt.Sprint(targets)}
+	}
+	return nil
+}
+
+// TypeString returns the type of the values of the annotation, `string` if not declared
+func (a AnnotationType) TypeString() string {
+	if t := a.Type.TypeString(); t != "" {
+		return t
+	}
+	if len(a.Type.Properties) > 0 {
+		return "object"
+	}
+	return "string"
+}
+
+// AllowsTarget returns true if the annotation could be applied to the kind of node, e.g. `Method`
+func (a AnnotationType) AllowsTarget(target string) bool {
+	if len(a.AllowedTargets) == 0 {
+		return true
+	}
+	for _, t := range a.AllowedTargets {
+		if strings.EqualFold(t, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// nameAnnotationTypes sets the names of the declared annotation types
+func nameAnnotationTypes(annotationTypes map[string]AnnotationType) {
+	for name, a := range annotationTypes {
+		a.Name = name
+		a.Type.Name = name
+		annotationTypes[name] = a
+	}
+}
+
+// AllAnnotationTypes returns the annotation types of the API definition and of its libraries,
+// the ones of the libraries are qualified by the library name, e.g. `lib.owner`
+func (apiDef *APIDefinition) AllAnnotationTypes() map[string]AnnotationType {
+	all := map[string]AnnotationType{}
+	for name, a := range apiDef.AnnotationTypes {
+		all[name] = a
+	}
+	addLibraryAnnotationTypes(all, apiDef.Libraries)
+	return all
+}
+
+// addLibraryAnnotationTypes adds the annotation types of the libraries
+// and of their libraries, qualified by the library name
+func addLibraryAnnotationTypes(all map[string]AnnotationType, libraries map[string]*Library) {
+	for libName, l := range libraries {
+		for name, a := range l.AnnotationTypes {
+			all[libName+"."+name] = a
+		}
+		if l.Libraries != nil {
+			addLibraryAnnotationTypes(all, l.Libraries)
+		}
+	}
+}
+
+// AnnotationType returns the annotation type of an annotation, declared by the API definition
+// or one of its libraries. The name could be given with or without the parentheses,
+// e.g. `(lib.owner)` or `lib.owner`.
+func (apiDef *APIDefinition) AnnotationType(name string) (AnnotationType, bool) {
+	name = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(name), "("), ")")
+	a, ok := apiDef.AllAnnotationTypes()[name]
+	return a, ok
+}
@@ -0,0 +1,64 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAnnotationTypes(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/annotation_types/api.raml", apiDef, WithUnknownKeyErrors())
+	Convey("annotation types", t, func() {
+		So(err, ShouldBeNil)
+
+		Convey("declared by the API definition", func() {
+			So(apiDef.AnnotationTypes, ShouldHaveLength, 2)
+			owner := apiDef.AnnotationTypes["owner"]
+			So(owner.Name, ShouldEqual, "owner")
+			So(owner.Type.Description, ShouldEqual, "the team owning the resource")
+			So(owner.TypeString(), ShouldEqual, "string")
+			So(owner.AllowedTargets, ShouldResemble, []string{"Resource", "Method"})
+			So(owner.AllowsTarget("method"), ShouldBeTrue)
+			So(owner.AllowsTarget("Response"), ShouldBeFalse)
+
+			tags := apiDef.AnnotationTypes["tags"]
+			So(tags.TypeString(), ShouldEqual, "string[]")
+			So(tags.AllowsTarget("Response"), ShouldBeTrue)
+		})
+
+		Convey("declared by the libraries, qualified by the library name", func() {
+			all := apiDef.AllAnnotationTypes()
+			So(sortedKeys(all), ShouldResemble, []string{"audit.audited", "audit.retention", "owner", "tags"})
+
+			audited, ok := apiDef.AnnotationType("(audit.audited)")
+			So(ok, ShouldBeTrue)
+			So(audited.Name, ShouldEqual, "audited")
+			So(audited.TypeString(), ShouldEqual, "object")
+			So(audited.Type.Properties, ShouldContainKey, "level")
+			So(audited.AllowedTargets, ShouldResemble, []string{"Method"})
+
+			_, ok = apiDef.AnnotationType("audit.owner")
+			So(ok, ShouldBeFalse)
+
+			for name := range apiDef.Resources["/users"].Get.Annotations {
+				_, ok := apiDef.AnnotationType(name)
+				So(ok, ShouldBeTrue)
+			}
+		})
+
+		Convey("AnnotationTypeDeclaration fragment", func() {
+			owner, err := ParseAnnotationType("./samples/annotation_types/owner.raml")
+			So(err, ShouldBeNil)
+			So(owner.Name, ShouldEqual, "owner")
+			So(owner.AllowedTargets, ShouldResemble, []string{"Resource", "Method"})
+		})
+
+		Convey("the unknown keys of the annotation types are checked", func() {
+			doc := []byte("#%RAML 1.0\ntitle: x\nannotationTypes:\n  owner:\n    allowedTarget: Method\n")
+			err := ParseBytes(doc, new(APIDefinition), WithUnknownKeyErrors())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "allowedTarget")
+		})
+	})
+}
@@ -0,0 +1,30 @@
+package raml
+
+import (
+	"strings"
+)
+
+// annotation returns the value of an annotation,
+// the name could be given with or without the parentheses
+func annotation(annotations map[string]interface{}, name string) (interface{}, bool) {
+	if !strings.HasPrefix(name, "(") {
+		name = "(" + name + ")"
+	}
+	v, ok := annotations[name]
+	return v, ok
+}
+
+// inheritAnnotations inherits the annotations which are not
+// annotated by the child from the parent annotations
+func inheritAnnotations(childs, parents map[string]interface{}) map[string]interface{} {
+	for name, v := range parents {
+		if _, ok := childs[name]; ok {
+			continue
+		}
+		if childs == nil {
+			childs = map[string]interface{}{}
+		}
+		childs[name] = v
+	}
+	return childs
+}
@@ -0,0 +1,301 @@
+package raml
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeSeverity is the impact of a change of an API definition on its clients,
+// in the terms of semantic versioning
+type ChangeSeverity int
+
+// severities of the changes, from the least to the most severe
+const (
+	// the documentation changed, e.g. a description
+	PatchChange ChangeSeverity = iota + 1
+
+	// a backward compatible change, e.g. a new resource or an optional property
+	MinorChange
+
+	// a breaking change, e.g. a removed method or a new required parameter
+	MajorChange
+)
+
+func (s ChangeSeverity) String() string {
+	switch s {
+	case PatchChange:
+		return "patch"
+	case MinorChange:
+		return "minor"
+	case MajorChange:
+		return "major"
+	}
+	return "none"
+}
+
+// APIChange is a change between two versions of an API definition
+type APIChange struct {
+	Severity ChangeSeverity
+
+	// where the change is, e.g. `GET /users` or `type User`
+	Location string
+
+	// what changed, e.g. `query parameter page added, required`
+	Description string
+}
+
+func (c APIChange) String() string {
+	return fmt.Sprintf("%v: %v (%v)", c.Location, c.Description, c.Severity)
+}
+
+// MaxSeverity returns the severity of the most severe change, 0 if there is none
+func MaxSeverity(changes []APIChange) ChangeSeverity {
+	var max ChangeSeverity
+	for _, c := range changes {
+		if c.Severity > max {
+			max = c.Severity
+		}
+	}
+	return max
+}
+
+// apiDiff collects the changes between two API definitions
+type apiDiff struct {
+	changes []APIChange
+}
+
+func (d *apiDiff) add(severity ChangeSeverity, location, format string, args ...interface{}) {
+	d.changes = append(d.changes, APIChange{
+		Severity:    severity,
+		Location:    location,
+		Description: fmt.Sprintf(format, args...),
+	})
+}
+
+// text adds a patch change if a documentation text changed
+func (d *apiDiff) text(location, name, old, new string) {
+	if strings.TrimSpace(old) != strings.TrimSpace(new) {
+		d.add(PatchChange, location, "%v changed", name)
+	}
+}
+
+// CompareAPIs returns the changes from the old to the new version of an API
+// definition, sorted by location, with their severity for the clients of the API:
+//   - removed resources, methods, responses, media types, types and properties,
+//     new required parameters and properties, properties which become optional
+//     and changed types are major changes
+//   - added resources, methods, responses, media types, types, optional
+//     parameters and properties are minor changes
+//   - changed titles, display names and descriptions are patch changes
+//
+// The types declared by the libraries are compared through the types
+// of the bodies and properties which reference them.
+func CompareAPIs(old, new *APIDefinition) []APIChange {
+	d := &apiDiff{}
+	d.text("API", "title", old.Title, new.Title)
+	if old.BaseURI != new.BaseURI {
+		d.add(MajorChange, "API", "baseUri changed from %v to %v", old.BaseURI, new.BaseURI)
+	}
+
+	d.compareTypes(old.Types, new.Types)
+
+	oldResources, newResources := resourcesByURI(old), resourcesByURI(new)
+	for _, uri := range sortedKeys(oldResources) {
+		r, ok := newResources[uri]
+		if !ok {
+			d.add(MajorChange, uri, "resource removed")
+			continue
+		}
+		d.compareResources(oldResources[uri], r)
+	}
+	for _, uri := range sortedKeys(newResources) {
+		if _, ok := oldResources[uri]; !ok {
+			d.add(MinorChange, uri, "resource added")
+		}
+	}
+
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		return d.changes[i].Location < d.changes[j].Location
+	})
+	return d.changes
+}
+
+// resourcesByURI returns the resources of an API definition by full URI
+func resourcesByURI(apiDef *APIDefinition) map[string]*Resource {
+	resources := map[string]*Resource{}
+	apiDef.walkResources(func(r *Resource) {
+		resources[r.FullURI()] = r
+	})
+	return resources
+}
+
+func (d *apiDiff) compareResources(old, new *Resource) {
+	uri := new.FullURI()
+	d.text(uri, "displayName", old.DisplayName, new.DisplayName)
+	d.text(uri, "description", old.Description, new.Description)
+
+	oldMethods, newMethods := map[string]*Method{}, map[string]*Method{}
+	for _, m := range old.methods() {
+		oldMethods[m.Name] = m
+	}
+	for _, m := range new.methods() {
+		newMethods[m.Name] = m
+	}
+	for _, name := range sortedKeys(oldMethods) {
+		m, ok := newMethods[name]
+		if !ok {
+			d.add(MajorChange, name+" "+uri, "method removed")
+			continue
+		}
+		d.compareMethods(name+" "+uri, oldMethods[name], m)
+	}
+	for _, name := range sortedKeys(newMethods) {
+		if _, ok := oldMethods[name]; !ok {
+			d.add(MinorChange, name+" "+uri, "method added")
+		}
+	}
+}
+
+func (d *apiDiff) compareMethods(location string, old, new *Method) {
+	d.text(location, "displayName", old.DisplayName, new.DisplayName)
+	d.text(location, "description", old.Description, new.Description)
+	d.compareParameters(location, "query parameter", old.QueryParameters, new.QueryParameters)
+	d.compareParameters(location, "header", headerParameters(old.Headers), headerParameters(new.Headers))
+	d.compareBodies(location, "request body", old.Bodies, new.Bodies)
+
+	for _, code := range sortedKeys(old.Responses) {
+		resp, ok := new.Responses[code]
+		if !ok {
+			d.add(MajorChange, location, "response %v removed", code)
+			continue
+		}
+		d.text(location, fmt.Sprintf("response %v description", code), old.Responses[code].Description, resp.Description)
+		d.compareBodies(location, fmt.Sprintf("response %v body", code), old.Responses[code].Bodies, resp.Bodies)
+	}
+	for _, code := range sortedKeys(new.Responses) {
+		if _, ok := old.Responses[code]; !ok {
+			d.add(MinorChange, location, "response %v added", code)
+		}
+	}
+}
+
+// headerParameters returns the headers as named parameters
+func headerParameters(headers map[HTTPHeader]Header) map[string]NamedParameter {
+	params := map[string]NamedParameter{}
+	for name, h := range headers {
+		params[string(name)] = NamedParameter(h)
+	}
+	return params
+}
+
+func (d *apiDiff) compareParameters(location, kind string, old, new map[string]NamedParameter) {
+	for _, name := range sortedKeys(old) {
+		p, ok := new[name]
+		switch {
+		case !ok:
+			d.add(MajorChange, location, "%v %v removed", kind, name)
+		case p.Type != old[name].Type:
+			d.add(MajorChange, location, "%v %v type changed from %v to %v", kind, name, old[name].Type, p.Type)
+		case p.Required && !old[name].Required:
+			d.add(MajorChange, location, "%v %v is now required", kind, name)
+		case !p.Required && old[name].Required:
+			d.add(MinorChange, location, "%v %v is now optional", kind, name)
+		}
+	}
+	for _, name := range sortedKeys(new) {
+		if _, ok := old[name]; ok {
+			continue
+		}
+		if new[name].Required {
+			d.add(MajorChange, location, "%v %v added, required", kind, name)
+		} else {
+			d.add(MinorChange, location, "%v %v added", kind, name)
+		}
+	}
+}
+
+func (d *apiDiff) compareBodies(location, kind string, old, new Bodies) {
+	oldBodies, newBodies := bodiesByMediaType(old), bodiesByMediaType(new)
+	for _, mediaType := range sortedKeys(oldBodies) {
+		b, ok := newBodies[mediaType]
+		switch {
+		case !ok:
+			d.add(MajorChange, location, "%v %v removed", kind, mediaType)
+		case b.TypeString() != oldBodies[mediaType].TypeString():
+			d.add(MajorChange, location, "%v %v type changed from %v to %v",
+				kind, mediaType, oldBodies[mediaType].TypeString(), b.TypeString())
+		}
+	}
+	for _, mediaType := range sortedKeys(newBodies) {
+		if _, ok := oldBodies[mediaType]; !ok {
+			d.add(MinorChange, location, "%v %v added", kind, mediaType)
+		}
+	}
+}
+
+// bodiesByMediaType returns the bodies by media type,
+// the body declared without media type is keyed by `default`
+func bodiesByMediaType(bodies Bodies) map[string]Body {
+	m := map[string]Body{}
+	if bodies.Default != nil {
+		m["default"] = *bodies.Default
+	}
+	for mediaType, b := range bodies.ForMIMEType {
+		m[mediaType] = b
+	}
+	return m
+}
+
+func (d *apiDiff) compareTypes(old, new map[string]Type) {
+	for _, name := range sortedKeys(old) {
+		t, ok := new[name]
+		location := "type " + name
+		if !ok {
+			d.add(MajorChange, location, "type removed")
+			continue
+		}
+		o := old[name]
+		if !t.IsJSONType() && t.TypeString() != o.TypeString() {
+			d.add(MajorChange, location, "type changed from %v to %v", o.TypeString(), t.TypeString())
+		}
+		d.text(location, "description", o.Description, t.Description)
+		d.compareProperties(location, &o, &t)
+	}
+	for _, name := range sortedKeys(new) {
+		if _, ok := old[name]; !ok {
+			d.add(MinorChange, "type "+name, "type added")
+		}
+	}
+}
+
+func (d *apiDiff) compareProperties(location string, old, new *Type) {
+	for _, name := range sortedKeys(old.Properties) {
+		p, ok := new.Properties[name]
+		o := old.Properties[name]
+		if !ok {
+			d.add(MajorChange, location, "property %v removed", name)
+			continue
+		}
+		o._type, p._type = old, new
+		switch {
+		case p.TypeString() != o.TypeString():
+			d.add(MajorChange, location, "property %v type changed from %v to %v", name, o.TypeString(), p.TypeString())
+		case p.Required && !o.Required:
+			d.add(MajorChange, location, "property %v is now required", name)
+		case !p.Required && o.Required:
+			d.add(MajorChange, location, "property %v is now optional", name)
+		}
+		d.text(location, "property "+name+" description", o.Description, p.Description)
+	}
+	for _, name := range sortedKeys(new.Properties) {
+		if _, ok := old.Properties[name]; ok {
+			continue
+		}
+		if new.Properties[name].Required {
+			d.add(MajorChange, location, "property %v added, required", name)
+		} else {
+			d.add(MinorChange, location, "property %v added", name)
+		}
+	}
+}
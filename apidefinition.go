@@ -1,20 +1,33 @@
 package raml
 
 import (
+	"bytes"
 	"fmt"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/gigforks/yaml"
 )
 
 // APIDefinition describes the basic information of an API, such as its
 // title and base URI, and describes how to define common schema references.
 type APIDefinition struct {
+	// version of RAML from the header of the document, e.g. `1.0`
 	RAMLVersion string `yaml:"-"`
 
+	// kind of the document from its header, empty for an API definition,
+	// OverlayDocument or ExtensionDocument for an overlay or an extension
+	FragmentKind string `yaml:"-"`
+
 	// A short, plain-text label for the API.
 	Title string `yaml:"title" validate:"nonzero"`
 
+	// A substantial, human-friendly description of the API.
+	// Its value is a string and MAY be formatted using markdown.
+	Description string `yaml:"description"`
+
 	// The version of the API, for example "v1"
 	Version string `yaml:"version"`
 
@@ -61,7 +74,7 @@ type APIDefinition struct {
 	// Deprecated - API definitions should use the "types" property
 	// because a future RAML version might remove the "schemas" alias for that property name.
 	// The "types" property supports XML and JSON schemas.
-	Schemas []map[string]string
+	Schemas SchemaDeclarations
 
 	// Declarations of (data) types for use within the API.
 	Types map[string]Type `yaml:"types"`
@@ -72,7 +85,9 @@ type APIDefinition struct {
 	// Declarations of resource types for use within the API.
 	ResourceTypes map[string]ResourceType `yaml:"resourceTypes"`
 
-	// TODO : annontation types
+	// Declarations of annotation types for use within the API,
+	// see AllAnnotationTypes for the ones of the libraries.
+	AnnotationTypes map[string]AnnotationType `yaml:"annotationTypes"`
 
 	// Declarations of security schemes for use within the API.
 	SecuritySchemes map[string]SecurityScheme `yaml:"securitySchemes"`
@@ -83,6 +98,10 @@ type APIDefinition struct {
 	// Imported external libraries for use within the API.
 	Uses map[string]string `yaml:"uses"`
 
+	// Annotations of the API, keyed by the annotation name
+	// in parentheses as written in the document, e.g. `(specVersion)`.
+	Annotations map[string]interface{} `yaml:",regexp:\\(.*\\)"`
+
 	// The resources of the API, identified as relative URIs that begin with a slash (/).
 	// A resource property is one that begins with the slash and is either
 	// at the root of the API definition or a child of a resource property. For example, /users and /{groupId}.
@@ -90,7 +109,71 @@ type APIDefinition struct {
 
 	Libraries map[string]*Library `yaml:"-"`
 
+	// Extensions are the unknown root keys of the document, e.g. `x-generation-settings`,
+	// with their values. They are only kept with WithRootExtensions.
+	Extensions map[string]interface{} `yaml:"-"`
+
 	Filename string
+
+	// KeepRaw needs to be set before parsing to keep
+	// the Raw and RawTree fields.
+	KeepRaw bool `yaml:"-"`
+
+	// TraitText needs to be set before parsing to control how the display
+	// name and description of the traits are merged into the methods,
+	// by default the text of the methods is kept.
+	TraitText TraitTextMerge `yaml:"-"`
+
+	// TraitOrder needs to be set before parsing to give precedence to the traits
+	// of the resources over the traits of the methods, see Method.AppliedTraits.
+	TraitOrder TraitOrder `yaml:"-"`
+
+	// CascadeAnnotations needs to be set before parsing to cascade the annotations
+	// of the resources to their nested resources and methods, unless they are
+	// annotated with the same annotation.
+	CascadeAnnotations bool `yaml:"-"`
+
+	// TypeNaming and TypeCollision need to be set before parsing to control the
+	// names of the types synthesized from the inline type declarations,
+	// see RegisterType. By default the names are concatenated and a declared
+	// type with the same name is used instead of the synthesized type.
+	TypeNaming    TypeNamingStrategy  `yaml:"-"`
+	TypeCollision TypeCollisionPolicy `yaml:"-"`
+
+	// Raw is the API definition exactly as decoded from the document,
+	// before any inheritance, trait application and parameters substitution.
+	// It is only available when KeepRaw is true.
+	Raw *APIDefinition `yaml:"-"`
+
+	// RawTree is the ordered YAML tree of the (preprocessed) document.
+	// It is only available when KeepRaw is true.
+	RawTree yaml.MapSlice `yaml:"-"`
+
+	// Comments of the (preprocessed) document, keyed by
+	// the JSON pointer of the node they are attached to.
+	// It is only available when KeepRaw is true.
+	Comments map[string]NodeComments `yaml:"-"`
+
+	// Warnings are the problems of the document, of its included files and
+	// of its libraries which don't fail the parsing, in the order they are found,
+	// e.g. an unknown trait or the deprecated `schemas`. See WithWarningHandler.
+	Warnings []Warning `yaml:"-"`
+
+	// source of the document, used to write it back
+	source *documentSource
+
+	// ordered YAML tree of the (preprocessed) document,
+	// for the declaration order of the maps
+	declTree yaml.MapSlice
+
+	// files included by the document
+	includes []IncludedFile
+
+	// locations of the nodes in the original files
+	sourceMap *sourceMap
+
+	// configuration of the parsing
+	cfg *parseConfig
 }
 
 // PostProcess doing additional processing
@@ -99,57 +182,207 @@ type APIDefinition struct {
 // - setting some additional values not exist in the .raml
 // - allocate map fields
 func (apiDef *APIDefinition) PostProcess(workDir, fileName string) error {
-	apiDef.Filename = path.Join(workDir, fileName)
+	if fileName != "" {
+		apiDef.Filename = path.Join(workDir, fileName)
+	}
 	// libraries
 	apiDef.Libraries = map[string]*Library{}
 
-	if !isURL(fileName) {
-		workDir = filepath.Join(workDir, filepath.Dir(fileName))
-	}
+	workDir = libraryDir(workDir, fileName)
 
-	for name, useFileName := range apiDef.Uses {
+	for _, name := range sortedKeys(apiDef.Uses) {
+		useFileName := apiDef.Uses[name]
 		lib := &Library{Filename: useFileName}
-		if _, err := ParseReadFile(workDir, useFileName, lib); err != nil {
+		if _, err := parseLibrary(workDir, useFileName, lib, apiDef.cfg); err != nil {
 			return fmt.Errorf("apiDef.PostProcess() failed to parse library	name=%v, path=%v\n\terr=%v",
 				name, useFileName, err)
 		}
 		apiDef.Libraries[name] = lib
 	}
+	if err := addFragmentLibraries(apiDef.Libraries, apiDef.Traits, apiDef.ResourceTypes,
+		apiDef.sourceMap, workDir, apiDef.cfg); err != nil {
+		return err
+	}
+	apiDef.warnShadowedDeclarations()
+	if len(apiDef.Schemas) > 0 {
+		apiDef.warn("schemas", "deprecated, use types")
+	}
+
+	// the declarations and resources are all processed even if some fail,
+	// so all the errors are reported at once
+	errs := new(Error)
+	errs.add(apiDef.cfg.process(AfterLibraries, apiDef))
+
+	nameAnnotationTypes(apiDef.AnnotationTypes)
+	nameSecuritySchemes(apiDef.SecuritySchemes)
 
 	// traits
-	for name, t := range apiDef.Traits {
-		t.postProcess(name)
+	for _, name := range sortedKeys(apiDef.Traits) {
+		t := apiDef.Traits[name]
+		errs.add(t.postProcess(name))
 		apiDef.Traits[name] = t
 	}
 
-	// resource types
-	for name, rt := range apiDef.ResourceTypes {
-		rt.postProcess(name, apiDef.Traits, apiDef)
+	// resource types, with the traits of the libraries, e.g. the libraries of the fragments
+	rtTraits := make(map[string]Trait, len(apiDef.Traits))
+	for name, t := range apiDef.Traits {
+		rtTraits[name] = t
+	}
+	rtTraits = apiDef.allTraits(rtTraits, apiDef.Libraries)
+	for _, name := range sortedKeys(apiDef.ResourceTypes) {
+		rt := apiDef.ResourceTypes[name]
+		errs.add(rt.postProcess(name, rtTraits, apiDef))
 		apiDef.ResourceTypes[name] = rt
 	}
 
-	// types
-	for name, t := range apiDef.Types {
-		err := t.postProcess(name, apiDef)
-		if err != nil {
+	// types, the types of the inline properties are added while processing
+	processed := map[string]bool{}
+	for len(processed) < len(apiDef.Types) {
+		for _, name := range sortedKeys(apiDef.Types) {
+			if processed[name] {
+				continue
+			}
+			processed[name] = true
+			t := apiDef.Types[name]
+			errs.add(t.postProcess(name, apiDef))
+			apiDef.Types[name] = t
+		}
+	}
+
+	var registry SchemaRegistry
+	var pulled map[string]bool
+	if apiDef.cfg != nil && apiDef.cfg.schemaRegistry != nil {
+		registry = apiDef.cfg.schemaRegistry
+		var err error
+		if pulled, err = apiDef.pullTypes(apiDef.cfg.context(), registry); err != nil {
+			errs.add(err)
+			return errs
+		}
+	}
+
+	// examples, discriminators and facets, need all types to be processed
+	for _, name := range sortedKeys(apiDef.Types) {
+		errs.add(apiDef.Types[name].validateExamples(apiDef))
+	}
+	errs.add(apiDef.validateDiscriminators())
+	errs.add(apiDef.validateFacets())
+	if registry != nil && len(errs.Errors) == 0 {
+		if err := apiDef.pushTypes(apiDef.cfg.context(), registry, pulled); err != nil {
 			return err
 		}
-		apiDef.Types[name] = t
 	}
 
 	// resources
-	for k := range apiDef.Resources {
+	rts := apiDef.allResourceTypes(apiDef.ResourceTypes, apiDef.Libraries)
+	trts := apiDef.allTraits(apiDef.Traits, apiDef.Libraries)
+	for _, k := range sortedKeys(apiDef.Resources) {
 		r := apiDef.Resources[k]
-		rts := apiDef.allResourceTypes(apiDef.ResourceTypes, apiDef.Libraries)
-		trts := apiDef.allTraits(apiDef.Traits, apiDef.Libraries)
-		if err := r.postProcess(k, nil, rts, trts, apiDef); err != nil {
-			return err
-		}
+		errs.add(r.postProcess(k, nil, rts, trts, apiDef))
 		apiDef.Resources[k] = r
 	}
+
+	// default response headers
+	if apiDef.cfg != nil && apiDef.cfg.responseHeadersTrait != "" {
+		t, ok := trts[apiDef.cfg.responseHeadersTrait]
+		if !ok {
+			errs.add(fmt.Errorf("can't find trait of the default response headers named :%v",
+				apiDef.cfg.responseHeadersTrait))
+		} else {
+			apiDef.addResponseHeaders(t.Headers)
+		}
+	}
+
+	// the bodies declared by name, once the traits and resource types are applied
+	errs.add(apiDef.resolveBodyTypes())
+	errs.add(apiDef.validateDefaults())
+	apiDef.warnUnusedURIParameters()
+	return errs.errOrNil()
+}
+
+// addResponseHeaders adds the headers to every response of every method,
+// unless the response declares them
+func (apiDef *APIDefinition) addResponseHeaders(headers map[HTTPHeader]Header) {
+	apiDef.walkResources(func(r *Resource) {
+		for _, m := range r.methods() {
+			for code, resp := range m.Responses {
+				resp.Headers = inheritHeaders(resp.Headers, headers, map[string]interface{}{})
+				m.Responses[code] = resp
+			}
+		}
+	})
+}
+
+// keepRaw decodes the preprocessed document again, without post processing,
+// so tools could work with exactly what the author wrote.
+// original is the content of the document before preprocessing.
+func (apiDef *APIDefinition) keepRaw(contents, original []byte) error {
+	raw := new(APIDefinition)
+	if err := unmarshalYAML(contents, raw); err != nil {
+		return err
+	}
+
+	var tree, pristine yaml.MapSlice
+	if err := unmarshalYAML(contents, &tree); err != nil {
+		return err
+	}
+	if err := unmarshalYAML(contents, &pristine); err != nil {
+		return err
+	}
+
+	comments, src := scanSource(contents)
+	header := string(bytes.SplitN(original, []byte("\n"), 2)[0])
+	src.header = strings.TrimSuffix(header, "\r")
+	src.lineEnding = "\n"
+	if strings.HasSuffix(header, "\r") {
+		src.lineEnding = "\r\n"
+	}
+	src.finalNewline = bytes.HasSuffix(original, []byte("\n"))
+	src.tree = pristine
+
+	apiDef.Raw = raw
+	apiDef.RawTree = tree
+	apiDef.Comments = comments
+	apiDef.source = src
 	return nil
 }
 
+// walkResources calls fn for every resource and nested resource,
+// sorted by their URI
+func (apiDef *APIDefinition) walkResources(fn func(r *Resource)) {
+	var walk func(resources map[string]*Resource)
+	walk = func(resources map[string]*Resource) {
+		for _, uri := range sortedResourceKeys(resources) {
+			r := resources[uri]
+			fn(r)
+			walk(r.Nested)
+		}
+	}
+
+	roots := make(map[string]*Resource, len(apiDef.Resources))
+	for uri := range apiDef.Resources {
+		r := apiDef.Resources[uri]
+		roots[uri] = &r
+	}
+	walk(roots)
+}
+
+// Annotation returns the value of an annotation of the API,
+// the name could be given with or without the parentheses, e.g. `specVersion`
+func (apiDef *APIDefinition) Annotation(name string) (interface{}, bool) {
+	return annotation(apiDef.Annotations, name)
+}
+
+func sortedResourceKeys(resources map[string]*Resource) []string {
+	keys := make([]string, 0, len(resources))
+	for k, r := range resources {
+		if r != nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // FindLibFile find lbrary dir and file by it's name
 // we also search from included library
 func (apiDef *APIDefinition) FindLibFile(name string) (string, string) {
@@ -191,6 +424,59 @@ func (apiDef *APIDefinition) GetSecurityScheme(name string) (SecurityScheme, boo
 	return ss, ok
 }
 
+// TypeByName gets type by it's name, it is the canonical type lookup.
+// The type could be:
+//   - declared in this document, including the types synthesized from inline declarations
+//   - declared in a library, qualified by the library name, e.g. `lib.Type` or `lib.nested.Type`
+//   - declared in a library and used unqualified, it is only found if
+//     exactly one of the libraries declares it
+//
+// The returned type is a copy, its LibraryChain tells the libraries it is resolved through.
+func (apiDef *APIDefinition) TypeByName(name string) (*Type, bool) {
+	name = strings.TrimSpace(name)
+	if t, ok := apiDef.Types[name]; ok {
+		return &t, true
+	}
+
+	// qualified by library name
+	if strings.Contains(name, ".") {
+		lib, chain, typeName, ok := apiDef.resolveLibrary(name)
+		if !ok {
+			return nil, false
+		}
+		t, ok := libraryType(lib, typeName)
+		if ok {
+			t.LibraryChain = chain
+		}
+		return t, ok
+	}
+
+	// unqualified library type
+	var found *Type
+	for libName, lib := range apiDef.Libraries {
+		if t, ok := libraryType(lib, name); ok {
+			if found != nil {
+				// ambiguous
+				return nil, false
+			}
+			t.LibraryChain = LibraryChain{{Name: libName, Filename: lib.Filename}}
+			found = t
+		}
+	}
+	return found, found != nil
+}
+
+func libraryType(lib *Library, name string) (*Type, bool) {
+	t, ok := lib.Types[name]
+	if !ok {
+		return nil, false
+	}
+	if t.Name == "" {
+		t.Name = name
+	}
+	return &t, true
+}
+
 // AllResourceTypes gets all resource type that defined in this api definition.
 // resource types could be from:
 // - this document itself
@@ -230,32 +516,3 @@ func (apiDef *APIDefinition) allTraits(trts map[string]Trait, libraries map[stri
 	}
 	return trts
 }
-
-// create new type
-func (apiDef *APIDefinition) createType(name string, tip interface{},
-	inputProps map[interface{}]interface{}) bool {
-
-	// check that there is no type with this name
-	if _, exist := apiDef.Types[name]; exist {
-		return false
-	}
-
-	// convert the inputProps to properties
-	props := make(map[string]interface{})
-
-	for k, p := range inputProps {
-		name, ok := k.(string)
-		if !ok {
-			panic(fmt.Errorf("property key:%v need to be a string", k))
-		}
-		props[name] = p
-	}
-
-	t := Type{
-		Name:       name,
-		Type:       tip,
-		Properties: props,
-	}
-	apiDef.Types[name] = t
-	return true
-}
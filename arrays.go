@@ -0,0 +1,99 @@
+package raml
+
+import (
+	"strings"
+)
+
+// arrayDimensions returns the number of dimensions of an array type expression
+// and the type of its elements, e.g. 3 and `string` for `string[][][]`. The parenthesized
+// element types are unwrapped, e.g. `(string | nil)[][]` is an array of `string | nil`.
+// It returns 0 and the expression itself if it is not an array.
+func arrayDimensions(expr string) (int, string) {
+	depth := 0
+	elem := strings.TrimSpace(expr)
+	for strings.HasSuffix(elem, "[]") {
+		depth++
+		elem = strings.TrimSpace(strings.TrimSuffix(elem, "[]"))
+		if isParenthesized(elem) {
+			elem = strings.TrimSpace(elem[1 : len(elem)-1])
+		}
+	}
+	return depth, elem
+}
+
+// isParenthesized checks if a type expression is wrapped in a pair of parentheses,
+// e.g. `(string | nil)` but not `(Cat) | (Dog)`
+func isParenthesized(expr string) bool {
+	if !strings.HasPrefix(expr, "(") || !strings.HasSuffix(expr, ")") {
+		return false
+	}
+	level := 0
+	for i, r := range expr {
+		switch r {
+		case '(':
+			level++
+		case ')':
+			level--
+			if level == 0 && i < len(expr)-1 {
+				return false
+			}
+		}
+	}
+	return level == 0
+}
+
+// ArrayDepth returns the number of dimensions of this array type, e.g. 3 for
+// `string[][][]` or for an `array` whose items are a `string[][]`, 0 if it is not an array
+func (t Type) ArrayDepth() int {
+	depth, _ := t.arrayDimensions()
+	return depth
+}
+
+// ElementType returns the type of the elements of this array type, whatever its
+// number of dimensions, e.g. `string` for `string[][][]`. It is the type of this
+// type if it is not an array, empty for an `array` without items.
+func (t Type) ElementType() string {
+	_, elem := t.arrayDimensions()
+	return elem
+}
+
+func (t Type) arrayDimensions() (int, string) {
+	if t.IsJSONType() {
+		return 0, t.TypeString()
+	}
+	if t.TypeString() != arrayType {
+		return arrayDimensions(t.TypeString())
+	}
+	items := t.ItemsDeclaration()
+	if items == nil {
+		return 1, ""
+	}
+	depth, elem := items.arrayDimensions()
+	return depth + 1, elem
+}
+
+// ArrayDepth returns the number of dimensions of the array type of this property,
+// e.g. 3 for `string[][][]`, 0 if it is not an array
+func (p Property) ArrayDepth() int {
+	depth, _ := p.arrayDimensions()
+	return depth
+}
+
+// ElementType returns the type of the elements of the array type of this property,
+// whatever its number of dimensions, e.g. `string` for `string[][][]`.
+// It is the type of the property if it is not an array.
+func (p Property) ElementType() string {
+	_, elem := p.arrayDimensions()
+	return elem
+}
+
+func (p Property) arrayDimensions() (int, string) {
+	if p.TypeString() != arrayType {
+		return arrayDimensions(p.TypeString())
+	}
+	if p.Items.Declaration == nil {
+		return 1, p.Items.Type
+	}
+	depth, elem := p.Items.Declaration.arrayDimensions()
+	return depth + 1, elem
+}
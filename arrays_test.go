@@ -0,0 +1,58 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestArrayDimensions(t *testing.T) {
+	Convey("arrays of any number of dimensions", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/arrays.raml", apiDef, WithUnknownKeyErrors()), ShouldBeNil)
+
+		Convey("types", func() {
+			So(apiDef.Types["Matrix"].ArrayDepth(), ShouldEqual, 2)
+			So(apiDef.Types["Matrix"].ElementType(), ShouldEqual, "number")
+			So(apiDef.Types["Cube"].ArrayDepth(), ShouldEqual, 3)
+			So(apiDef.Types["Cube"].ElementType(), ShouldEqual, "number")
+			So(apiDef.Types["Cube"].IsBidimensiArray(), ShouldBeTrue)
+			So(apiDef.Types["Grid"].ArrayDepth(), ShouldEqual, 2)
+			So(apiDef.Types["Grid"].ElementType(), ShouldEqual, "integer")
+			So(apiDef.Types["Labels"].ArrayDepth(), ShouldEqual, 2)
+			So(apiDef.Types["Labels"].ElementType(), ShouldEqual, "string | nil")
+			So(apiDef.Types["Scene"].ArrayDepth(), ShouldEqual, 0)
+			So(apiDef.Types["Scene"].ElementType(), ShouldEqual, "")
+		})
+
+		Convey("properties", func() {
+			scene := apiDef.Types["Scene"]
+			voxels := scene.GetProperty("voxels")
+			So(voxels.ArrayDepth(), ShouldEqual, 3)
+			So(voxels.ElementType(), ShouldEqual, "boolean")
+			rows := scene.GetProperty("rows")
+			So(rows.ArrayDepth(), ShouldEqual, 2)
+			So(rows.ElementType(), ShouldEqual, "string")
+			So(rows.IsBidimensiArray(), ShouldBeTrue)
+			cube := scene.GetProperty("cube")
+			So(cube.ArrayDepth(), ShouldEqual, 0)
+			So(cube.ElementType(), ShouldEqual, "Cube")
+		})
+
+		Convey("examples", func() {
+			So(validateValue([]interface{}{[]interface{}{"a", nil}}, "(string | nil)[][]", "", apiDef, 0), ShouldBeNil)
+			So(validateValue([]interface{}{[]interface{}{[]interface{}{"a"}}}, "Cube", "", apiDef, 0), ShouldNotBeNil)
+		})
+
+		Convey("JSON schemas", func() {
+			js := NewJSONSchema(apiDef.Types["Cube"], "Cube")
+			So(js.Items.Type, ShouldEqual, "array")
+			So(js.Items.Items.Type, ShouldEqual, "array")
+			So(js.Items.Items.Items.Type, ShouldEqual, "number")
+
+			js = NewJSONSchema(apiDef.Types["Scene"], "Scene")
+			So(js.Properties, ShouldContainKey, "voxels")
+			So(js.Properties["voxels"].Items.Items.Items.Type, ShouldEqual, "boolean")
+		})
+	})
+}
@@ -0,0 +1,205 @@
+package raml
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteBlueprint converts the API definition into API Blueprint (format 1A) markdown.
+//
+// Every resource with methods becomes a resource section, with its URI parameters,
+// and every method an action with its query parameters, an example request and
+// the declared responses. Values which are not known from the API definition,
+// e.g. credentials, are written as `<name>` placeholders.
+func (apiDef *APIDefinition) WriteBlueprint(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	base := exampleRequest{BaseURI: apiDef.BaseURI, BaseURIParams: apiDef.baseURIParams()}
+	fmt.Fprintf(bw, "FORMAT: 1A\n")
+	if apiDef.BaseURI != "" {
+		fmt.Fprintf(bw, "HOST: %v\n", strings.TrimSuffix(base.resolveBase(uriTemplateVar), "/"))
+	}
+	fmt.Fprintf(bw, "\n# %v\n", apiDef.Title)
+	for _, doc := range apiDef.Documentation {
+		fmt.Fprintf(bw, "\n**%v**\n\n%v\n", doc.Title, strings.TrimSpace(doc.Content))
+	}
+
+	apiDef.walkResources(func(r *Resource) {
+		methods := r.methods()
+		if len(methods) == 0 {
+			return
+		}
+		uri := r.FullURI()
+		fmt.Fprintf(bw, "\n## %v [%v]\n", orDefault(r.DisplayName, uri), uri)
+		writeBlueprintText(bw, r.Description)
+
+		var params []blueprintParam
+		for _, name := range uriTemplateParams(uri) {
+			params = append(params, blueprintParam{name, findURIParameter(r, name), true})
+		}
+		writeBlueprintParams(bw, params)
+
+		for _, m := range methods {
+			apiDef.writeBlueprintAction(bw, r, m)
+		}
+	})
+	return bw.Flush()
+}
+
+// blueprintParam is a parameter of a resource or action
+type blueprintParam struct {
+	name     string
+	np       NamedParameter
+	required bool
+}
+
+func (apiDef *APIDefinition) writeBlueprintAction(w io.Writer, r *Resource, m *Method) {
+	// query parameters are part of the action URI template
+	var params []blueprintParam
+	for _, name := range sortedParamNames(m.QueryParameters) {
+		np := m.QueryParameters[name]
+		params = append(params, blueprintParam{name, np, np.Required})
+	}
+	if len(params) > 0 {
+		var names []string
+		for _, p := range params {
+			names = append(names, p.name)
+		}
+		fmt.Fprintf(w, "\n### %v [%v %v{?%v}]\n", orDefault(m.DisplayName, m.Name), m.Name, r.FullURI(), strings.Join(names, ","))
+	} else {
+		fmt.Fprintf(w, "\n### %v [%v]\n", orDefault(m.DisplayName, m.Name), m.Name)
+	}
+	writeBlueprintText(w, m.Description)
+	writeBlueprintParams(w, params)
+
+	req := apiDef.exampleRequest(r, m)
+	var headers []string
+	switch req.Auth {
+	case authBasic:
+		headers = append(headers, "Authorization: Basic <credentials>")
+	case authDigest:
+		headers = append(headers, "Authorization: Digest <credentials>")
+	}
+	for _, h := range req.Headers {
+		headers = append(headers, h.Name+": "+h.Prefix+placeholderVar(h))
+	}
+	if len(headers) > 0 || req.Body != "" {
+		fmt.Fprintf(w, "\n+ Request%v\n", blueprintMediaType(req.ContentType))
+		if len(headers) > 0 {
+			fmt.Fprintf(w, "\n    + Headers\n\n")
+			writeIndented(w, strings.Join(headers, "\n"), 12)
+		}
+		if req.Body != "" {
+			fmt.Fprintf(w, "\n    + Body\n\n")
+			writeIndented(w, req.Body, 12)
+		}
+	}
+
+	var codes []string
+	for code := range m.Responses {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		resp := m.Responses[HTTPCode(code)]
+		contentType, body := apiDef.bodyExample(&resp.Bodies)
+		fmt.Fprintf(w, "\n+ Response %v%v\n", code, blueprintMediaType(contentType))
+		writeBlueprintText(w, indent(resp.Description, 4))
+		if body != "" {
+			fmt.Fprintf(w, "\n    + Body\n\n")
+			writeIndented(w, body, 12)
+		}
+	}
+}
+
+// writeBlueprintParams writes the parameters section
+func writeBlueprintParams(w io.Writer, params []blueprintParam) {
+	if len(params) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n+ Parameters\n\n")
+	for _, p := range params {
+		line := "    + " + p.name
+		if examples := p.np.AllExamples(); len(examples) > 0 {
+			line += fmt.Sprintf(": `%v`", examples[0].Value)
+		}
+		required := "optional"
+		if p.required {
+			required = "required"
+		}
+		line += fmt.Sprintf(" (%v, %v)", blueprintType(p.np.Type), required)
+		if p.np.Description != "" {
+			line += " - " + strings.Join(strings.Fields(p.np.Description), " ")
+		}
+		fmt.Fprintf(w, "%v\n", line)
+		if p.np.Default != nil {
+			fmt.Fprintf(w, "        + Default: `%v`\n", p.np.Default)
+		}
+	}
+}
+
+// blueprintType maps a RAML type to API Blueprint parameter type
+func blueprintType(t string) string {
+	switch t {
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+func blueprintMediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	return " (" + contentType + ")"
+}
+
+// writeBlueprintText writes a paragraph, if not empty
+func writeBlueprintText(w io.Writer, text string) {
+	if strings.TrimSpace(text) != "" {
+		fmt.Fprintf(w, "\n%v\n", strings.TrimRight(text, "\n "))
+	}
+}
+
+// writeIndented writes a text block indented by n spaces
+func writeIndented(w io.Writer, text string, n int) {
+	fmt.Fprintf(w, "%v\n", indent(strings.TrimRight(text, "\n"), n))
+}
+
+// indent indents all non empty lines of the text by n spaces
+func indent(text string, n int) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = strings.Repeat(" ", n) + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// uriTemplateVar returns URI template expression of the variable of the parameter
+func uriTemplateVar(p requestParam) string {
+	return "{" + p.Value + "}"
+}
+
+// placeholderVar returns the value of the parameter
+// or a `<name>` placeholder if the value is not known
+func placeholderVar(p requestParam) string {
+	if p.Variable {
+		return "<" + p.Value + ">"
+	}
+	return p.Value
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,83 @@
+package raml
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteBlueprint(t *testing.T) {
+	Convey("API Blueprint export", t, func() {
+		apiDef := new(APIDefinition)
+		err := ParseFile("./samples/commands.raml", apiDef)
+		So(err, ShouldBeNil)
+
+		var buf bytes.Buffer
+		err = apiDef.WriteBlueprint(&buf)
+		So(err, ShouldBeNil)
+		So(buf.String(), ShouldEqual, `FORMAT: 1A
+HOST: https://api.example.com/api/v1
+
+# Commands API
+
+## /users [/users]
+
+### GET [GET /users{?filter,page}]
+
++ Parameters
+
+    + filter (string, optional)
+    + page: `+"`"+`2`+"`"+` (number, required)
+
++ Request
+
+    + Headers
+
+            Authorization: Bearer <access_token>
+
+### POST [POST]
+
++ Request (application/json)
+
+    + Headers
+
+            Authorization: Bearer <access_token>
+
+    + Body
+
+            {
+              "age": 30,
+              "name": "John's"
+            }
+
+## /users/{userId} [/users/{userId}]
+
++ Parameters
+
+    + userId (string, required)
+
+### GET [GET]
+
++ Request
+
+    + Headers
+
+            Authorization: Basic <credentials>
+            X-Request-Id: abc 123
+
++ Response 200 (application/json)
+
+    + Body
+
+            {
+              "age": 30,
+              "name": "John's"
+            }
+
++ Response 404
+
+    user not found
+`)
+	})
+}
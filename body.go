@@ -0,0 +1,458 @@
+package raml
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Body is the request/response body
+// Some method verbs expect the resource to be sent as a request body.
+// For example, to create a resource, the request must include the details of
+// the resource to create.
+// Resources CAN have alternate representations. For example, an API might
+// support both JSON and XML representations.
+type Body struct {
+	// media type of the body, empty for the body declared
+	// without media type
+	mediaType string
+
+	// The structure of a request or response body MAY be further specified
+	// by the schema property under the appropriate media type.
+	// The schema key CANNOT be specified if a body's media type is
+	// application/x-www-form-urlencoded or multipart/form-data.
+	// All parsers of RAML MUST be able to interpret JSON Schema [JSON_SCHEMA]
+	// and XML Schema [XML_SCHEMA].
+	// Alternatively, the value of the schema field MAY be the name of a schema
+	// specified in the root-level schemas property
+	Schema string `yaml:"schema"`
+
+	// Brief description
+	Description string `yaml:"description"`
+
+	// Type of the body: a type name, a type expression,
+	// a JSON/XML schema or an inline type declaration
+	Type interface{} `yaml:"type"`
+
+	// Items of an array body
+	Items interface{} `yaml:"items"`
+
+	// The properties of the body, keyed by the property name without the `?` suffix.
+	// They are parsed from RawProperties.
+	Properties map[string]Property `yaml:"-"`
+
+	// The properties as written in the document.
+	// we use `interface{}` as property type to support syntactic sugar & shortcut
+	RawProperties map[string]interface{} `yaml:"properties"`
+
+	// Example attribute to generate example invocations, the text of RawExample:
+	// a string as is, the value of a structured example, other values JSON encoded
+	Example string `yaml:"-"`
+
+	// The example as written in the document,
+	// the value itself or a structured example
+	RawExample interface{} `yaml:"example"`
+
+	// Named examples of the body, keyed by the example name, e.g. included
+	// from a `#%RAML 1.0 NamedExample` fragment. An example could be the value
+	// itself or a structured example, use AllExamples to get them as Example.
+	Examples map[string]interface{} `yaml:"examples"`
+
+	// Media type of the binary file included as example, e.g. `image/png` for
+	// `example: !include logo.png`, whose content is then base64 encoded
+	// in Example. Empty if the example isn't a binary file.
+	ExampleMediaType string `yaml:"-"`
+
+	Headers map[HTTPHeader]Header `yaml:"headers"`
+
+	// The declared type or schema named by the schema or the type of the body,
+	// e.g. `schema: User`, nil if the body is not declared by name.
+	ResolvedType *Type `yaml:"-"`
+}
+
+// bodyDeclaration is decoded by Body.UnmarshalYAML
+type bodyDeclaration Body
+
+// UnmarshalYAML decodes the body, which could be declared
+// by its type only, e.g. `application/json: User`
+func (b *Body) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var typ string
+	if err := unmarshal(&typ); err == nil {
+		*b = Body{Type: typ}
+		return nil
+	}
+
+	var decl bodyDeclaration
+	if err := unmarshal(&decl); err != nil {
+		return err
+	}
+	*b = Body(decl)
+	b.Properties = parseProperties(b.RawProperties)
+	b.Example = exampleText(b.RawExample)
+	return nil
+}
+
+// AllExamples returns the `example` and the `examples` of this body.
+// Examples from `examples` are sorted by name.
+func (b Body) AllExamples() []Example {
+	return allExamples(b.RawExample, b.Examples)
+}
+
+// MediaType returns the media type of the body,
+// empty if the body is declared without media type
+func (b Body) MediaType() string {
+	return b.mediaType
+}
+
+// TypeString returns string representation of the type of the body
+func (b Body) TypeString() string {
+	return interfaceToString(b.Type)
+}
+
+// ItemsDeclaration returns the items of an array body as a type declaration,
+// with their facets, properties and examples, nil if the body declares no items
+func (b Body) ItemsDeclaration() *Type {
+	return itemsDeclaration(b.Items)
+}
+
+// GetProperty gets property with given name
+func (b Body) GetProperty(name string) Property {
+	return BodiesProperty{Properties: b.Properties, RawProperties: b.RawProperties}.GetProperty(name)
+}
+
+// - normalize inline array definition
+// - parse the properties
+func (b *Body) postProcess() {
+	b.Type, b.Items = normalizeArray(b.Type, b.Items)
+	b.Properties = parseProperties(b.RawProperties)
+}
+
+// registerInlineTypes registers the inline types of the properties and of the items of the body,
+// named after the owner and the path of the body, e.g. `/users`, `post` and `body`
+func (b *Body) registerInlineTypes(apiDef *APIDefinition, owner string, path ...string) error {
+	errs := new(Error)
+	if _, ok := b.Items.(map[interface{}]interface{}); ok {
+		decl, err := registerInlineTypes(map[interface{}]interface{}{"type": b.Type, "items": b.Items}, apiDef, owner, path...)
+		if err != nil {
+			errs.add(fmt.Errorf("items: %v", err))
+		} else {
+			b.Items = decl["items"]
+		}
+	}
+	if len(b.RawProperties) == 0 {
+		return errs.errOrNil()
+	}
+	raw := make(map[string]interface{}, len(b.RawProperties))
+	for name, p := range b.RawProperties {
+		raw[name] = p
+		propMap, ok := p.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		decl, err := registerInlineTypes(propMap, apiDef, owner, append(path, strings.TrimSuffix(name, "?"))...)
+		if err != nil {
+			errs.add(fmt.Errorf("property %v: %v", name, err))
+			continue
+		}
+		raw[name] = decl
+	}
+	b.RawProperties = raw
+	b.Properties = parseProperties(raw)
+	return errs.errOrNil()
+}
+
+// inherit inherits body properties from a parent body
+// parent object could be from trait or resource type
+// context is the qualified name of the parent, see APIDefinition.QualifyTypeName
+func (b *Body) inherit(parent Body, dicts map[string]interface{}, context string, apiDef *APIDefinition) {
+	b.Schema = substituteParams(b.Schema, parent.Schema, dicts)
+	b.Description = substituteParams(b.Description, parent.Description, dicts)
+	b.inheritExamples(parent, dicts)
+
+	if _, ok := parent.Type.(string); !ok && b.Type == nil {
+		// inline type declaration
+		b.Type = parent.Type
+	} else if typeStr, ok := b.Type.(string); ok || b.Type == nil {
+		typeStr = substituteParams(typeStr, parent.TypeString(), dicts)
+		if typeStr != "" {
+			// check if type name is in library
+			b.Type = apiDef.QualifyTypeName(typeStr, context)
+		}
+	}
+	if b.Items == nil {
+		b.Items = parent.Items
+		if items, ok := b.Items.(string); ok {
+			b.Items = apiDef.QualifyTypeName(substituteParams("", items, dicts), context)
+		}
+	}
+
+	if len(parent.RawProperties) > 0 && b.RawProperties == nil {
+		b.RawProperties = map[string]interface{}{}
+	}
+	for k, p := range parent.RawProperties {
+		if _, ok := b.RawProperties[k]; ok {
+			continue
+		}
+
+		// handle optional properties as described in
+		// https://github.com/raml-org/raml-spec/blob/raml-10/versions/raml-10/raml-10.md#optional-properties
+		switch {
+		case strings.HasSuffix(k, `\?`): // if ended with `\?` we make it optional property
+			k = k[:len(k)-2] + "?"
+		case strings.HasSuffix(k, "?"): // if only ended with `?`, we can ignore it
+			continue
+		}
+		k = substituteParams(k, k, dicts)
+		b.RawProperties[k] = qualifyRawProperty(p, dicts, context, apiDef)
+	}
+	b.Properties = parseProperties(b.RawProperties)
+
+	if len(parent.Headers) > 0 {
+		b.Headers = inheritHeaders(b.Headers, parent.Headers, dicts)
+	}
+}
+
+// inheritExamples inherits the example of the parent body if the body has none,
+// and the named examples the body doesn't have
+func (b *Body) inheritExamples(parent Body, dicts map[string]interface{}) {
+	text, isText := b.RawExample.(string)
+	if parentText, ok := parent.RawExample.(string); ok && (isText || b.RawExample == nil) {
+		if text = substituteParams(text, parentText, dicts); text != "" {
+			b.RawExample = text
+		}
+	} else if b.RawExample == nil {
+		b.RawExample = parent.RawExample
+	}
+	b.Example = exampleText(b.RawExample)
+
+	for name, ex := range parent.Examples {
+		if b.Examples == nil {
+			b.Examples = map[string]interface{}{}
+		}
+		if _, ok := b.Examples[name]; !ok {
+			b.Examples[name] = ex
+		}
+	}
+}
+
+// qualifyRawProperty substitutes the parameters of the type of an inherited
+// property and qualifies it, the other facets of the property are kept
+func qualifyRawProperty(p interface{}, dicts map[string]interface{}, context string, apiDef *APIDefinition) interface{} {
+	decl, ok := p.(map[interface{}]interface{})
+	if !ok {
+		typ := substituteParams("", toProperty("", p).TypeString(), dicts)
+		return apiDef.QualifyTypeName(typ, context)
+	}
+	typ, ok := decl["type"].(string)
+	if !ok {
+		return p
+	}
+	qualified := make(map[interface{}]interface{}, len(decl))
+	for k, v := range decl {
+		qualified[k] = v
+	}
+	qualified["type"] = apiDef.QualifyTypeName(substituteParams("", typ, dicts), context)
+	return qualified
+}
+
+// Bodies is the body of a method or response.
+//
+// Some RAML APIs don't use the media type part of the body, instead relying
+// on the mediaType property in the APIDefinition.
+// So, you might see:
+//
+//	responses:
+//	  200:
+//	    body:
+//	      type: User
+//
+// which is the Default body, and also:
+//
+//	responses:
+//	  200:
+//	    body:
+//	      application/json:
+//	        type: User
+//
+// which are the bodies ForMIMEType.
+type Bodies struct {
+	// The body declared without media type, it has the default
+	// media type of the API. Nil if the bodies are declared per media type.
+	Default *Body
+
+	// Resources CAN have alternate representations. For example, an API
+	// might support both JSON and XML representations. This is the map
+	// between MIME-type and the body definition related to it.
+	//
+	// TODO: For APIs without a priori knowledge of the response types for
+	// their responses, "*/*" MAY be used to indicate that responses that do
+	// not matching other defined data types MUST be accepted. Processing
+	// applications MUST match the most descriptive media type first if
+	// "*/*" is used.
+	ForMIMEType map[string]Body
+
+	// Schema of the Default body.
+	//
+	// Deprecated: use Default.
+	Schema string
+
+	// Description of the Default body.
+	//
+	// Deprecated: use Default.
+	Description string
+
+	// Example of the Default body.
+	//
+	// Deprecated: use Default.
+	Example string
+
+	// The `application/json` body.
+	//
+	// Deprecated: use ForMIMEType or Body.
+	ApplicationJSON *BodiesProperty
+
+	// Type of the Default body.
+	//
+	// Deprecated: use Default.
+	Type string
+
+	// true if the body is declared, even without any content
+	declared bool
+}
+
+// UnmarshalYAML decodes the bodies, with or without the media types
+func (b *Bodies) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	b.declared = true
+
+	var decl map[string]interface{}
+	if err := unmarshal(&decl); err == nil && len(decl) == 0 {
+		return nil
+	}
+
+	if hasMediaTypes(decl) {
+		var bodies map[string]Body
+		if err := unmarshal(&bodies); err != nil {
+			return err
+		}
+		for mediaType, body := range bodies {
+			body.mediaType = mediaType
+			bodies[mediaType] = body
+		}
+		b.ForMIMEType = bodies
+	} else {
+		var body Body
+		if err := unmarshal(&body); err != nil {
+			return err
+		}
+		b.Default = &body
+	}
+	b.updateDeprecated()
+	return nil
+}
+
+// hasMediaTypes returns true if the keys of a body declaration are media types
+func hasMediaTypes(decl map[string]interface{}) bool {
+	for key := range decl {
+		if strings.Contains(key, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty returns true if the body is empty
+func (b *Bodies) IsEmpty() bool {
+	return b.Default == nil && len(b.ForMIMEType) == 0
+}
+
+// Declared returns true if the body is declared, including a body declared
+// empty, e.g. `body: {}` or `body: ~`, to tell that a method has no body.
+// A body inherited from a trait or resource type is declared.
+func (b *Bodies) Declared() bool {
+	return b.declared
+}
+
+// MediaTypes returns the media types of the bodies, sorted
+func (b *Bodies) MediaTypes() []string {
+	var mediaTypes []string
+	for mediaType := range b.ForMIMEType {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
+// Body returns the body for the given media type.
+// The Default body is returned for any media type.
+func (b *Bodies) Body(mediaType string) (Body, bool) {
+	if body, ok := b.ForMIMEType[mediaType]; ok {
+		return body, true
+	}
+	if b.Default != nil {
+		return *b.Default, true
+	}
+	return Body{}, false
+}
+
+// contentTypes returns the media types of the bodies, sorted.
+// The Default body has the given default media type, if any.
+func (b *Bodies) contentTypes(defaultMediaType string) []string {
+	if b.Default != nil && defaultMediaType != "" {
+		return []string{defaultMediaType}
+	}
+	return b.MediaTypes()
+}
+
+// inherit inherits bodies from a parent bodies
+// parent object could be from trait or resource type
+func (b *Bodies) inherit(parent Bodies, dicts map[string]interface{}, context string, apiDef *APIDefinition) {
+	b.declared = b.declared || parent.declared
+	if parent.Default != nil {
+		if b.Default == nil {
+			b.Default = &Body{}
+		}
+		b.Default.inherit(*parent.Default, dicts, context, apiDef)
+	}
+
+	for mediaType, parentBody := range parent.ForMIMEType {
+		mediaType = substituteParams(mediaType, mediaType, dicts)
+		if b.ForMIMEType == nil {
+			b.ForMIMEType = map[string]Body{}
+		}
+		body := b.ForMIMEType[mediaType]
+		body.mediaType = mediaType
+		body.inherit(parentBody, dicts, context, apiDef)
+		b.ForMIMEType[mediaType] = body
+	}
+	b.updateDeprecated()
+}
+
+func (b *Bodies) postProcess() {
+	if b.Default != nil {
+		b.Default.postProcess()
+	}
+	for mediaType, body := range b.ForMIMEType {
+		body.postProcess()
+		b.ForMIMEType[mediaType] = body
+	}
+	b.updateDeprecated()
+}
+
+// updateDeprecated updates the deprecated fields from the Default
+// and `application/json` bodies
+func (b *Bodies) updateDeprecated() {
+	b.Schema, b.Description, b.Example, b.Type = "", "", "", ""
+	if d := b.Default; d != nil {
+		b.Schema, b.Description, b.Example, b.Type = d.Schema, d.Description, d.Example, d.TypeString()
+	}
+
+	b.ApplicationJSON = nil
+	if body, ok := b.ForMIMEType["application/json"]; ok {
+		b.ApplicationJSON = &BodiesProperty{
+			Properties:    body.Properties,
+			RawProperties: body.RawProperties,
+			Type:          body.Type,
+			Items:         body.Items,
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBodies(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/bodies.raml", apiDef)
+	Convey("bodies", t, func() {
+		So(err, ShouldBeNil)
+		r := apiDef.Resources["/users"]
+
+		Convey("body without media type", func() {
+			bodies := r.Get.Responses["200"].Bodies
+			So(bodies.ForMIMEType, ShouldBeEmpty)
+			So(bodies.Default, ShouldNotBeNil)
+			So(bodies.Default.MediaType(), ShouldEqual, "")
+			So(bodies.Default.TypeString(), ShouldEqual, "User[]")
+			So(bodies.Type, ShouldEqual, "User[]")
+			So(bodies.Description, ShouldEqual, "all users")
+
+			body, ok := bodies.Body("application/xml")
+			So(ok, ShouldBeTrue)
+			So(body.TypeString(), ShouldEqual, "User[]")
+		})
+
+		Convey("bodies per media type", func() {
+			bodies := r.Post.Bodies
+			So(bodies.Default, ShouldBeNil)
+			So(bodies.MediaTypes(), ShouldResemble, []string{"application/json", "application/xml"})
+
+			json, ok := bodies.Body("application/json")
+			So(ok, ShouldBeTrue)
+			So(json.MediaType(), ShouldEqual, "application/json")
+			So(json.TypeString(), ShouldEqual, "User")
+			So(bodies.ApplicationJSON.TypeString(), ShouldEqual, "User")
+
+			xml := bodies.ForMIMEType["application/xml"]
+			So(xml.TypeString(), ShouldEqual, "User")
+			So(xml.Example, ShouldEqual, "<users/>")
+
+			_, ok = bodies.Body("text/plain")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("bodies are inherited per media type", func() {
+			bodies := r.Post.Bodies
+			So(bodies.ForMIMEType["application/json"].GetProperty("page").Type, ShouldEqual, "integer")
+			So(bodies.ApplicationJSON.Properties, ShouldContainKey, "page")
+			So(bodies.ForMIMEType["application/xml"].Description, ShouldEqual, "paged users")
+		})
+
+		Convey("array body is normalized", func() {
+			body := r.Put.Bodies.ForMIMEType["application/json"]
+			So(body.TypeString(), ShouldEqual, "User[]")
+			So(body.Items, ShouldBeNil)
+		})
+
+		Convey("content types", func() {
+			So(r.Post.RequestContentTypes(), ShouldResemble, []string{"application/json", "application/xml"})
+			So(r.Put.RequestContentTypes(), ShouldResemble, []string{"application/json"})
+			So(r.Get.RequestContentTypes(), ShouldBeEmpty)
+
+			// default media type of the API
+			So(r.Get.ResponseContentTypes(200), ShouldResemble, []string{"application/json"})
+			So(r.Get.ResponseContentTypes(404), ShouldBeEmpty)
+		})
+
+		Convey("empty body", func() {
+			So(r.Get.Bodies.IsEmpty(), ShouldBeTrue)
+			So(r.Post.Bodies.IsEmpty(), ShouldBeFalse)
+		})
+	})
+}
+
+func TestMethodMediaType(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/media_types.raml", apiDef)
+	Convey("media type of a method", t, func() {
+		So(err, ShouldBeNil)
+		r := apiDef.Resources["/files"]
+
+		Convey("default media type of the API", func() {
+			So(r.Get.ResponseContentTypes(200), ShouldResemble, []string{"application/json"})
+		})
+
+		Convey("media type declared by the body", func() {
+			So(r.Post.RequestContentTypes(), ShouldResemble, []string{"application/octet-stream"})
+			So(r.Post.ResponseContentTypes(201), ShouldResemble, []string{"application/octet-stream"})
+		})
+
+		Convey("media type of a body from a trait", func() {
+			put := r.Nested["/{id}"].Put
+			So(put.RequestContentTypes(), ShouldResemble, []string{"application/octet-stream"})
+		})
+
+		Convey("mediaType is not a facet of the methods", func() {
+			doc := "#%RAML 1.0\ntitle: API\n/files:\n  post:\n    mediaType: application/octet-stream\n"
+			err := ParseBytes([]byte(doc), new(APIDefinition), WithUnknownKeyErrors())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "mediaType")
+		})
+
+		Convey("declared empty body", func() {
+			So(r.Delete.Bodies.Declared(), ShouldBeTrue)
+			So(r.Delete.Bodies.IsEmpty(), ShouldBeTrue)
+			So(r.Patch.Bodies.Declared(), ShouldBeTrue)
+			So(r.Patch.Bodies.IsEmpty(), ShouldBeTrue)
+
+			So(r.Get.Bodies.Declared(), ShouldBeFalse)
+			So(r.Post.Bodies.Declared(), ShouldBeTrue)
+		})
+	})
+}
+
+func TestBodyResolvedType(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/body_schemas.raml", apiDef)
+	Convey("bodies declared by name", t, func() {
+		So(err, ShouldBeNil)
+		So(apiDef.Schemas, ShouldHaveLength, 1)
+
+		Convey("schema of the root schemas", func() {
+			body := apiDef.Resources["/users"].Post.Bodies.ForMIMEType["application/json"]
+			So(body.ResolvedType, ShouldNotBeNil)
+			So(body.ResolvedType.Name, ShouldEqual, "User")
+			So(body.ResolvedType.Properties, ShouldContainKey, "name")
+		})
+
+		Convey("declared type", func() {
+			body := apiDef.Resources["/users"].Get.Responses["200"].Bodies.ForMIMEType["application/json"]
+			So(body.ResolvedType, ShouldNotBeNil)
+			So(body.ResolvedType.Name, ShouldEqual, "Book")
+			So(body.ResolvedType.Properties, ShouldContainKey, "title")
+		})
+
+		Convey("type expression", func() {
+			body := apiDef.Resources["/books"].Get.Responses["200"].Bodies.ForMIMEType["application/json"]
+			So(body.ResolvedType, ShouldBeNil)
+		})
+
+		Convey("undeclared schema", func() {
+			err := ParseBytes([]byte("#%RAML 1.0\ntitle: Missing\n/users:\n  post:\n    body:\n      schema: User\n"),
+				new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "POST /users: body: schema User is not declared")
+		})
+	})
+}
+
+func TestBodyExamples(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/named_examples/api.raml", apiDef, WithUnknownKeyErrors())
+	Convey("examples of the bodies", t, func() {
+		So(err, ShouldBeNil)
+		r := apiDef.Resources["/users"]
+
+		Convey("named examples included from a NamedExample fragment", func() {
+			examples := r.Post.Bodies.ForMIMEType["application/json"].AllExamples()
+			So(examples, ShouldHaveLength, 2)
+			So(examples[0].Name, ShouldEqual, "jane")
+			So(examples[0].DisplayName, ShouldEqual, "Jane")
+			So(examples[0].Description, ShouldEqual, "a user without age")
+			So(examples[0].Value, ShouldResemble, map[interface{}]interface{}{"name": "Jane"})
+			So(examples[1].Name, ShouldEqual, "john")
+			So(examples[1].Strict, ShouldBeTrue)
+
+			So(apiDef.Types["User"].AllExamples(), ShouldHaveLength, 2)
+		})
+
+		Convey("structured example", func() {
+			body := r.Get.Responses["200"].Bodies.Default
+			examples := body.AllExamples()
+			So(examples, ShouldHaveLength, 1)
+			So(examples[0].DisplayName, ShouldEqual, "Joe")
+			So(examples[0].Strict, ShouldBeFalse)
+			So(body.Example, ShouldEqual, "{\n  \"name\": 12\n}")
+		})
+
+		Convey("structured example inherited from a trait", func() {
+			body := r.Post.Responses["201"].Bodies.ForMIMEType["application/json"]
+			So(body.Example, ShouldEqual, "{\n  \"name\": \"Created\"\n}")
+			So(body.AllExamples()[0].Value, ShouldResemble, map[interface{}]interface{}{"name": "Created"})
+		})
+
+		Convey("the strict examples are validated against the type of the body", func() {
+			doc := []byte(`#%RAML 1.0
+title: Invalid example
+types:
+  User:
+    properties:
+      name: string
+/users:
+  post:
+    body:
+      application/json:
+        type: User
+        examples:
+          bad:
+            value:
+              name: 12
+`)
+			err := ParseBytes(doc, new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "POST /users: body application/json: invalid bad")
+		})
+	})
+}
@@ -0,0 +1,91 @@
+package raml
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// xmlEncodingRe matches the encoding of an XML declaration, e.g. `<?xml version="1.0" encoding="ISO-8859-1"?>`
+var xmlEncodingRe = regexp.MustCompile(`^<\?xml[^>]*\sencoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+// textExtensions are the extensions of the text files, besides the `text/*` media types
+var textExtensions = map[string]bool{
+	".raml": true, ".yaml": true, ".yml": true, ".json": true,
+	".xml": true, ".xsd": true, ".md": true, ".txt": true,
+}
+
+// WithIncludeCharset sets the charset of the included text files which are neither UTF-8
+// nor declare their charset, ISO-8859-1 by default, e.g. charmap.Windows1252 for legacy
+// schema files. The UTF-16 files with a byte order mark and the XML files declaring
+// their encoding are decoded with their charset. The other files which are not UTF-8,
+// e.g. images, are binary files.
+func WithIncludeCharset(e encoding.Encoding) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.includeCharset = e
+	}
+}
+
+// fallbackCharset returns the charset of the text files which don't declare it
+func (cfg *parseConfig) fallbackCharset() encoding.Encoding {
+	if cfg == nil || cfg.includeCharset == nil {
+		return charmap.ISO8859_1
+	}
+	return cfg.includeCharset
+}
+
+// decodeText returns the content of an included file as UTF-8 text,
+// false if it is a binary file or a text file of unknown charset
+func (cfg *parseConfig) decodeText(fileName string, contents []byte) ([]byte, bool) {
+	// byte order marks
+	switch {
+	case bytes.HasPrefix(contents, []byte{0xEF, 0xBB, 0xBF}):
+		contents = contents[3:]
+	case bytes.HasPrefix(contents, []byte{0xFF, 0xFE}), bytes.HasPrefix(contents, []byte{0xFE, 0xFF}):
+		decoded, err := unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder().Bytes(contents)
+		return decoded, err == nil
+	}
+	if utf8.Valid(contents) {
+		return contents, true
+	}
+	if !isTextFile(fileName) {
+		return nil, false
+	}
+
+	if m := xmlEncodingRe.FindSubmatch(contents); m != nil {
+		if e, err := ianaindex.IANA.Encoding(string(m[1])); err == nil && e != nil {
+			decoded, err := e.NewDecoder().Bytes(contents)
+			return decoded, err == nil
+		}
+	}
+	e := cfg.fallbackCharset()
+	decoded, err := e.NewDecoder().Bytes(contents)
+	if err != nil {
+		return nil, false
+	}
+	cfg.warn(fileName, "not an UTF-8 text file, decoded as %v", charsetName(e))
+	return decoded, true
+}
+
+// isTextFile returns true if the file is a text file, from its extension
+func isTextFile(fileName string) bool {
+	ext := strings.ToLower(path.Ext(fileName))
+	return textExtensions[ext] || strings.HasPrefix(mime.TypeByExtension(ext), "text/")
+}
+
+// charsetName returns the name of a charset, e.g. `ISO-8859-1`
+func charsetName(e encoding.Encoding) string {
+	if name, err := ianaindex.MIME.Name(e); err == nil {
+		return name
+	}
+	return fmt.Sprint(e)
+}
@@ -0,0 +1,371 @@
+package raml
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/gigforks/yaml"
+)
+
+// NodeComments are the YAML comments attached to a node of the document.
+type NodeComments struct {
+	// comment lines directly above the node
+	Head []string
+
+	// comment on the same line as the node
+	Line string
+
+	// comment lines after the last node of the document,
+	// only used by the document root
+	Foot []string
+}
+
+// commentFrame is an element of the path currently being scanned
+type commentFrame struct {
+	indent int
+	seq    bool
+	idx    int
+	key    string
+
+	pointer string
+	span    nodeSpan
+}
+
+// nodeSpan is the location of a node in the source document
+type nodeSpan struct {
+	// lines of the node, including the preceding
+	// blank lines and comments
+	start, end int
+
+	// column of the key or the sequence item dash
+	col int
+
+	// true if the node doesn't start at its own line,
+	// i.e. first key of a sequence item
+	inline bool
+}
+
+// commentScanner scans a YAML document line by line and
+// attaches comments to the path of the nodes.
+// It also records the lines spanned by every node so
+// the unmodified nodes could be written back verbatim.
+// It only understands block style collections, which is what
+// RAML documents are mostly written with, a flow collection is
+// considered as a single node.
+type commentScanner struct {
+	stack    []commentFrame
+	pending  []string
+	comments map[string]NodeComments
+	spans    map[string]nodeSpan
+
+	// current line number
+	lineNo int
+
+	// first line of the blank lines and comments
+	// preceding the next node, -1 if none
+	pendingStart int
+
+	// true if the next node is on the same line
+	// as a sequence item dash
+	inlineNext bool
+
+	// indentation of the node owning the block scalar
+	// we are currently in, -1 if not in block scalar.
+	blockIndent int
+}
+
+// documentSource is the source of a document
+// as recorded by the commentScanner
+type documentSource struct {
+	lines     []string
+	spans     map[string]nodeSpan
+	footStart int
+
+	// first line of the document, e.g. `#%RAML 1.0`
+	header string
+
+	// line ending of the original document
+	lineEnding string
+
+	// true if the original document ends with a line ending
+	finalNewline bool
+
+	// the tree as decoded from the document
+	tree yaml.MapSlice
+}
+
+// parseComments returns all comments of a YAML document,
+// keyed by the JSON pointer (RFC 6901) of the node
+// they are attached to.
+func parseComments(contents []byte) map[string]NodeComments {
+	comments, _ := scanSource(contents)
+	return comments
+}
+
+// scanSource scans a YAML document for its comments and nodes location
+func scanSource(contents []byte) (map[string]NodeComments, *documentSource) {
+	cs := &commentScanner{
+		comments:     map[string]NodeComments{},
+		spans:        map[string]nodeSpan{},
+		pendingStart: -1,
+		blockIndent:  -1,
+	}
+	src := &documentSource{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		src.lines = append(src.lines, scanner.Text())
+		cs.scanLine(scanner.Text())
+		cs.lineNo++
+	}
+
+	src.footStart = cs.nodeStart()
+	for len(cs.stack) > 0 {
+		cs.pop(src.footStart)
+	}
+	if len(cs.pending) > 0 {
+		root := cs.comments[""]
+		root.Foot = cs.pending
+		cs.comments[""] = root
+	}
+	src.spans = cs.spans
+	return cs.comments, src
+}
+
+func (cs *commentScanner) scanLine(line string) {
+	trimmed := strings.TrimSpace(line)
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+
+	if cs.blockIndent >= 0 {
+		if trimmed == "" || indent > cs.blockIndent {
+			return
+		}
+		cs.blockIndent = -1
+	}
+
+	switch {
+	case trimmed == "":
+		cs.markPending()
+		return
+	case strings.HasPrefix(trimmed, "#"):
+		cs.markPending()
+		cs.pending = append(cs.pending, trimmed)
+		return
+	}
+	cs.scanContent(line[indent:], indent)
+}
+
+// markPending marks current line as part of the
+// lines preceding the next node
+func (cs *commentScanner) markPending() {
+	if cs.pendingStart < 0 {
+		cs.pendingStart = cs.lineNo
+	}
+}
+
+// nodeStart returns first line of the next node
+func (cs *commentScanner) nodeStart() int {
+	if cs.pendingStart >= 0 {
+		return cs.pendingStart
+	}
+	return cs.lineNo
+}
+
+// scanContent scans the content of a line which starts at column col
+func (cs *commentScanner) scanContent(content string, col int) {
+	content, comment := splitComment(content)
+
+	// sequence item
+	if content == "-" || strings.HasPrefix(content, "- ") {
+		cs.popFrames(col, false)
+		if top := len(cs.stack) - 1; top >= 0 && cs.stack[top].seq && cs.stack[top].indent == col {
+			idx := cs.stack[top].idx + 1
+			cs.pop(cs.nodeStart())
+			cs.push(commentFrame{indent: col, seq: true, idx: idx})
+		} else {
+			cs.push(commentFrame{indent: col, seq: true})
+		}
+		rest := strings.TrimPrefix(strings.TrimPrefix(content, "-"), " ")
+		restTrimmed := strings.TrimLeft(rest, " ")
+		if restTrimmed == "" || isKeyLine(restTrimmed) || strings.HasPrefix(restTrimmed, "- ") {
+			if restTrimmed == "" {
+				cs.attach(comment)
+				return
+			}
+			cs.inlineNext = true
+			cs.scanContent(restTrimmed+commentSuffix(comment), col+2+len(rest)-len(restTrimmed))
+			return
+		}
+		cs.attach(comment)
+		cs.checkBlockScalar(restTrimmed, col)
+		return
+	}
+
+	key, value, ok := splitKey(content)
+	if !ok {
+		// continuation of a multi line scalar or flow collection
+		return
+	}
+	cs.popFrames(col, true)
+	cs.push(commentFrame{indent: col, key: key})
+	cs.attach(comment)
+	cs.checkBlockScalar(value, col)
+}
+
+// push pushes a new node to the stack
+func (cs *commentScanner) push(f commentFrame) {
+	f.span = nodeSpan{
+		start:  cs.nodeStart(),
+		col:    f.indent,
+		inline: cs.inlineNext,
+	}
+	cs.inlineNext = false
+	cs.stack = append(cs.stack, f)
+	cs.stack[len(cs.stack)-1].pointer = cs.path()
+}
+
+// pop removes the top of the stack, the node ends before line end
+func (cs *commentScanner) pop(end int) {
+	f := cs.stack[len(cs.stack)-1]
+	f.span.end = end
+	cs.spans[f.pointer] = f.span
+	cs.stack = cs.stack[:len(cs.stack)-1]
+}
+
+// popFrames removes all frames that are not parent of a node in column col.
+// A sequence item could be in the same column as its parent key.
+func (cs *commentScanner) popFrames(col int, isKey bool) {
+	for len(cs.stack) > 0 {
+		top := cs.stack[len(cs.stack)-1]
+		if top.indent < col || (top.indent == col && !isKey) {
+			return
+		}
+		cs.pop(cs.nodeStart())
+	}
+}
+
+// attach attaches pending comments and the line comment
+// to the node at the top of the stack
+func (cs *commentScanner) attach(lineComment string) {
+	cs.pendingStart = -1
+	if len(cs.pending) == 0 && lineComment == "" {
+		return
+	}
+	path := cs.path()
+	nc := cs.comments[path]
+	nc.Head = append(nc.Head, cs.pending...)
+	nc.Line = lineComment
+	cs.comments[path] = nc
+	cs.pending = nil
+}
+
+// checkBlockScalar checks if the value starts a block scalar
+func (cs *commentScanner) checkBlockScalar(value string, col int) {
+	if isBlockScalarIndicator(value) {
+		cs.blockIndent = col
+	}
+}
+
+// path returns JSON pointer of the node at the top of the stack
+func (cs *commentScanner) path() string {
+	var elems []string
+	for _, f := range cs.stack {
+		if f.seq {
+			elems = append(elems, strconv.Itoa(f.idx))
+		} else {
+			elems = append(elems, f.key)
+		}
+	}
+	return jsonPointer(elems)
+}
+
+// jsonPointer creates JSON pointer (RFC 6901) from the path elements
+func jsonPointer(elems []string) string {
+	var buf bytes.Buffer
+	for _, e := range elems {
+		buf.WriteByte('/')
+		e = strings.Replace(e, "~", "~0", -1)
+		buf.WriteString(strings.Replace(e, "/", "~1", -1))
+	}
+	return buf.String()
+}
+
+func commentSuffix(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	return " " + comment
+}
+
+// isBlockScalarIndicator returns true if the value is
+// a literal or folded block scalar indicator, e.g. `|`, `>-`, `|2`
+func isBlockScalarIndicator(value string) bool {
+	if value == "" || (value[0] != '|' && value[0] != '>') {
+		return false
+	}
+	return strings.Trim(value[1:], "+-0123456789") == ""
+}
+
+// isKeyLine returns true if the content is a `key: value` pair
+func isKeyLine(content string) bool {
+	_, _, ok := splitKey(content)
+	return ok
+}
+
+// splitKey splits `key: value` content into key and value
+func splitKey(content string) (string, string, bool) {
+	if content == "" || content[0] == '[' || content[0] == '{' {
+		return "", "", false
+	}
+	quote := byte(0)
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case i == 0 && (c == '"' || c == '\''):
+			quote = c
+		case c == ':' && (i == len(content)-1 || content[i+1] == ' '):
+			return unquoteKey(strings.TrimSpace(content[:i])), strings.TrimSpace(content[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+func unquoteKey(key string) string {
+	if len(key) < 2 {
+		return key
+	}
+	switch {
+	case key[0] == '"' && key[len(key)-1] == '"':
+		if k, err := strconv.Unquote(key); err == nil {
+			return k
+		}
+	case key[0] == '\'' && key[len(key)-1] == '\'':
+		return strings.Replace(key[1:len(key)-1], "''", "'", -1)
+	}
+	return key
+}
+
+// splitComment splits line content into the content and comment
+func splitComment(content string) (string, string) {
+	quote := byte(0)
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" [{,:-", content[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || content[i-1] == ' '):
+			return strings.TrimRight(content[:i], " "), content[i:]
+		}
+	}
+	return content, ""
+}
@@ -0,0 +1,145 @@
+package raml
+
+import (
+	"strings"
+)
+
+// separates the variables from the literal text of a shell word
+const shellVarMark = "\x00"
+
+// CommandExample is a ready-to-run command line invocation of an operation
+type CommandExample struct {
+	// full URI of the resource
+	URI string
+
+	// method name, e.g. GET
+	Method string
+
+	// curl command
+	Curl string
+
+	// HTTPie command
+	HTTPie string
+}
+
+// CommandExamples returns command line invocations of all operations of the API.
+// Values which are not known from the API definition, e.g. URI parameters without
+// example or the credentials, are taken from the environment variables
+// named after the parameter, e.g. `$USER_ID` for `userId`.
+func (apiDef *APIDefinition) CommandExamples() []CommandExample {
+	var examples []CommandExample
+	apiDef.walkResources(func(r *Resource) {
+		for _, m := range r.methods() {
+			examples = append(examples, CommandExample{
+				URI:    r.FullURI(),
+				Method: m.Name,
+				Curl:   apiDef.CurlCommand(r, m),
+				HTTPie: apiDef.HTTPieCommand(r, m),
+			})
+		}
+	})
+	return examples
+}
+
+// CurlCommand returns curl command that invokes the method of the resource
+func (apiDef *APIDefinition) CurlCommand(r *Resource, m *Method) string {
+	req := apiDef.exampleRequest(r, m)
+
+	args := []string{"curl", "-X", req.Method, shellQuote(req.resolveURL(shellVar))}
+	switch req.Auth {
+	case authBasic:
+		args = append(args, "-u", shellQuote(credentials()))
+	case authDigest:
+		args = append(args, "--digest", "-u", shellQuote(credentials()))
+	}
+	for _, h := range req.Headers {
+		args = append(args, "-H", shellQuote(h.Name+": "+h.Prefix+shellVar(h)))
+	}
+	if req.Body != "" {
+		args = append(args, "-H", shellQuote("Content-Type: "+req.ContentType))
+		args = append(args, "--data-raw", shellQuote(req.Body))
+	}
+	return joinCommand(args)
+}
+
+// HTTPieCommand returns HTTPie command that invokes the method of the resource
+func (apiDef *APIDefinition) HTTPieCommand(r *Resource, m *Method) string {
+	req := apiDef.exampleRequest(r, m)
+
+	args := []string{"http"}
+	switch req.Auth {
+	case authBasic:
+		args = append(args, "-a", shellQuote(credentials()))
+	case authDigest:
+		args = append(args, "-A", "digest", "-a", shellQuote(credentials()))
+	}
+	if req.Body != "" {
+		args = append(args, "--raw", shellQuote(req.Body))
+	}
+	args = append(args, req.Method, shellQuote(req.resolveURL(shellVar)))
+	for _, h := range req.Headers {
+		args = append(args, shellQuote(h.Name+":"+h.Prefix+shellVar(h)))
+	}
+	if req.Body != "" {
+		args = append(args, shellQuote("Content-Type:"+req.ContentType))
+	}
+	return joinCommand(args)
+}
+
+// credentials returns user name and password variables of the basic/digest authentication
+func credentials() string {
+	return shellVar(requestParam{Value: "username", Variable: true}) + ":" +
+		shellVar(requestParam{Value: "password", Variable: true})
+}
+
+// shellVar returns value of the parameter,
+// variables are marked to be expanded by the shell
+func shellVar(p requestParam) string {
+	if !p.Variable {
+		return p.Value
+	}
+	return shellVarMark + envName(p.Value) + shellVarMark
+}
+
+// shellQuote quotes a word for POSIX shells.
+// Words without variables are single quoted,
+// others are double quoted so the variables are expanded.
+func shellQuote(s string) string {
+	parts := strings.Split(s, shellVarMark)
+	if len(parts) == 1 {
+		if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@") == "" {
+			return s
+		}
+		return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+	}
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for i, part := range parts {
+		if i%2 == 1 {
+			buf.WriteString("${" + part + "}")
+		} else {
+			buf.WriteString(escaper.Replace(part))
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// joinCommand joins the command arguments, options
+// are written on their own lines
+func joinCommand(args []string) string {
+	var buf strings.Builder
+	for i, arg := range args {
+		switch {
+		case i == 0:
+		case strings.HasPrefix(arg, "-") && i > 1:
+			buf.WriteString(" \\\n  ")
+		default:
+			buf.WriteString(" ")
+		}
+		buf.WriteString(arg)
+	}
+	return buf.String()
+}
@@ -0,0 +1,46 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCommandExamples(t *testing.T) {
+	Convey("curl and HTTPie command examples", t, func() {
+		apiDef := new(APIDefinition)
+		err := ParseFile("./samples/commands.raml", apiDef)
+		So(err, ShouldBeNil)
+
+		examples := apiDef.CommandExamples()
+		So(len(examples), ShouldEqual, 3)
+
+		Convey("query parameters and bearer token", func() {
+			So(examples[0].URI, ShouldEqual, "/users")
+			So(examples[0].Method, ShouldEqual, "GET")
+			So(examples[0].Curl, ShouldEqual, `curl -X GET 'https://api.example.com/api/v1/users?page=2' \
+  -H "Authorization: Bearer ${ACCESS_TOKEN}"`)
+			So(examples[0].HTTPie, ShouldEqual,
+				`http GET 'https://api.example.com/api/v1/users?page=2' "Authorization:Bearer ${ACCESS_TOKEN}"`)
+		})
+
+		Convey("body from the type example", func() {
+			So(examples[1].Method, ShouldEqual, "POST")
+			So(examples[1].Curl, ShouldEqual, `curl -X POST https://api.example.com/api/v1/users \
+  -H "Authorization: Bearer ${ACCESS_TOKEN}" \
+  -H 'Content-Type: application/json' \
+  --data-raw '{
+  "age": 30,
+  "name": "John'\''s"
+}'`)
+		})
+
+		Convey("URI parameter variable and basic authentication", func() {
+			So(examples[2].URI, ShouldEqual, "/users/{userId}")
+			So(examples[2].Curl, ShouldEqual, `curl -X GET "https://api.example.com/api/v1/users/${USER_ID}" \
+  -u "${USERNAME}:${PASSWORD}" \
+  -H 'X-Request-Id: abc 123'`)
+			So(examples[2].HTTPie, ShouldEqual, `http -a "${USERNAME}:${PASSWORD}" GET "https://api.example.com/api/v1/users/${USER_ID}" 'X-Request-Id:abc 123'`)
+		})
+	})
+}
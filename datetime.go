@@ -0,0 +1,101 @@
+package raml
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// formats of the datetime type
+const (
+	// DateTimeRFC3339 is the default format, e.g. `2016-02-28T16:41:41.090Z`
+	DateTimeRFC3339 = "rfc3339"
+
+	// DateTimeRFC2616 is the format of the HTTP dates, e.g. `Sun, 28 Feb 2016 16:41:41 GMT`
+	DateTimeRFC2616 = "rfc2616"
+)
+
+// isDateTimeType returns true for the date and time builtin types
+func isDateTimeType(typ string) bool {
+	switch typ {
+	case "date-only", "time-only", "datetime-only", "datetime":
+		return true
+	}
+	return false
+}
+
+// isDateTimeFormat returns true if the format is a format of the datetime type
+func isDateTimeFormat(format string) bool {
+	return strings.EqualFold(format, DateTimeRFC3339) || strings.EqualFold(format, DateTimeRFC2616)
+}
+
+// dateTimeLayout returns the time layout of a date or time type,
+// format is the format of the datetime type, rfc3339 if empty.
+// The fractional seconds are written if not zero, and always accepted
+// when parsing.
+func dateTimeLayout(typ, format string) (string, error) {
+	switch typ {
+	case "date-only":
+		return "2006-01-02", nil
+	case "time-only":
+		return "15:04:05.999999999", nil
+	case "datetime-only":
+		return "2006-01-02T15:04:05.999999999", nil
+	case "datetime":
+		switch strings.ToLower(format) {
+		case "", DateTimeRFC3339:
+			return time.RFC3339Nano, nil
+		case DateTimeRFC2616:
+			return http.TimeFormat, nil
+		}
+		return "", fmt.Errorf("invalid datetime format %v", format)
+	}
+	return "", fmt.Errorf("%v is not a date or time type", typ)
+}
+
+// ParseDateTime parses a value of a date or time type: date-only, time-only,
+// datetime-only or datetime in the given format, rfc3339 if empty.
+// The values without time zone are in UTC.
+func ParseDateTime(typ, format, value string) (time.Time, error) {
+	layout, err := dateTimeLayout(typ, format)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(layout, strings.TrimSpace(value))
+	if err != nil {
+		if format == "" {
+			return time.Time{}, fmt.Errorf("%v is not a valid %v", value, typ)
+		}
+		return time.Time{}, fmt.Errorf("%v is not a valid %v in %v format", value, typ, format)
+	}
+	return t, nil
+}
+
+// FormatDateTime writes a time as a value of a date or time type: date-only,
+// time-only, datetime-only or datetime in the given format, rfc3339 if empty.
+// The rfc2616 datetimes are written in UTC.
+func FormatDateTime(typ, format string, t time.Time) (string, error) {
+	layout, err := dateTimeLayout(typ, format)
+	if err != nil {
+		return "", err
+	}
+	if layout == http.TimeFormat {
+		t = t.UTC()
+	}
+	return t.Format(layout), nil
+}
+
+// ParseTime parses a value of this type, which must be a date or time type
+func (t Type) ParseTime(value string) (time.Time, error) {
+	return ParseDateTime(t.TypeString(), t.Format, value)
+}
+
+// ParseTime parses a value of this property, which must be a date or time type
+func (p Property) ParseTime(value string) (time.Time, error) {
+	var format string
+	if p.Format != nil {
+		format = *p.Format
+	}
+	return ParseDateTime(p.TypeString(), format, value)
+}
@@ -0,0 +1,85 @@
+package raml
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateDefaults checks that the default values of the types, of their properties
+// and of the parameters of the resources are values of their declared type.
+// The parameters are checked once the traits and resource types are applied.
+func (apiDef *APIDefinition) validateDefaults() error {
+	errs := new(Error)
+	for _, name := range sortedKeys(apiDef.Types) {
+		t := apiDef.Types[name]
+		if t.IsJSONType() {
+			continue
+		}
+		if t.Default != nil {
+			if err := validateTypeValue(t.Default, t, apiDef, 0); err != nil {
+				errs.add(fmt.Errorf("type %v: invalid default: %v", name, err))
+			}
+		}
+		for _, pname := range sortedKeys(t.Properties) {
+			p := t.Properties[pname]
+			if p.Default == nil {
+				continue
+			}
+			format := ""
+			if p.Format != nil {
+				format = *p.Format
+			}
+			if err := validateValue(p.Default, p.TypeString(), format, apiDef, 0); err != nil {
+				errs.add(fmt.Errorf("type %v: property %v: invalid default: %v", name, pname, err))
+			}
+		}
+	}
+
+	errs.add(validateParameterDefaults("base URI", apiDef.BaseURIParameters, apiDef))
+	apiDef.walkResources(func(r *Resource) {
+		errs.add(validateParameterDefaults(r.FullURI()+": URI", r.URIParameters, apiDef))
+		for _, m := range r.methods() {
+			location := m.Name + " " + r.FullURI()
+			errs.add(validateParameterDefaults(location+": query", m.QueryParameters, apiDef))
+			errs.add(validateHeaderDefaults(location+":", m.Headers, apiDef))
+			for _, code := range sortedKeys(m.Responses) {
+				errs.add(validateHeaderDefaults(fmt.Sprintf("%v: response %v", location, code),
+					m.Responses[code].Headers, apiDef))
+			}
+		}
+	})
+	return errs.errOrNil()
+}
+
+// validateParameterDefaults checks the default values of named parameters
+func validateParameterDefaults(location string, params map[string]NamedParameter, apiDef *APIDefinition) error {
+	errs := new(Error)
+	for _, name := range sortedKeys(params) {
+		errs.add(params[name].validateDefault(location, name, apiDef))
+	}
+	return errs.errOrNil()
+}
+
+// validateHeaderDefaults checks the default values of headers
+func validateHeaderDefaults(location string, headers map[HTTPHeader]Header, apiDef *APIDefinition) error {
+	errs := new(Error)
+	for _, name := range sortedKeys(headers) {
+		errs.add(NamedParameter(headers[name]).validateDefault(location+" header", string(name), apiDef))
+	}
+	return errs.errOrNil()
+}
+
+// validateDefault checks the default value of a parameter, a value still
+// holding a trait or resource type parameter, e.g. `<<pageSize>>`, is not checked
+func (np NamedParameter) validateDefault(location, name string, apiDef *APIDefinition) error {
+	if np.Default == nil {
+		return nil
+	}
+	if s, ok := np.Default.(string); ok && strings.Contains(s, "<<") {
+		return nil
+	}
+	if err := validateValue(np.Default, np.Type, "", apiDef, 0); err != nil {
+		return fmt.Errorf("%v parameter %v: invalid default: %v", location, name, err)
+	}
+	return nil
+}
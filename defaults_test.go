@@ -0,0 +1,39 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDefaults(t *testing.T) {
+	Convey("default values", t, func() {
+		Convey("with their YAML types", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/defaults.raml", apiDef, WithUnknownKeyErrors()), ShouldBeNil)
+
+			So(apiDef.Types["PageSize"].Default, ShouldEqual, 20)
+			So(apiDef.BaseURIParameters["version"].Default, ShouldEqual, "v1")
+
+			settings := apiDef.Types["Settings"]
+			So(settings.GetProperty("theme").Default, ShouldEqual, "dark")
+			So(settings.GetProperty("retries").Default, ShouldEqual, 3)
+			So(settings.GetProperty("notify").Default, ShouldEqual, false)
+			So(settings.GetProperty("limits").Default, ShouldResemble, map[interface{}]interface{}{"daily": 10})
+
+			get := apiDef.Resources["/users"].Get
+			So(get.QueryParameters["active"].Default, ShouldEqual, true)
+			So(get.Headers["X-Page"].Default, ShouldEqual, 1)
+			So(get.QueryParameters["size"].Default, ShouldEqual, 50)
+		})
+
+		Convey("of their declared type", func() {
+			err := ParseFile("./samples/defaults/invalid.raml", new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "type PageSize: invalid default: twenty is not an integer")
+			So(err.Error(), ShouldContainSubstring, "type Settings: property retries: invalid default: three is not an integer")
+			So(err.Error(), ShouldContainSubstring, "GET /users: query parameter active: invalid default: yes please is not a boolean")
+			So(err.Error(), ShouldContainSubstring, "GET /users: response 200 header parameter X-Page: invalid default: 1.5 is not an integer")
+		})
+	})
+}
@@ -0,0 +1,190 @@
+package raml
+
+import "fmt"
+
+// deprecatedAnnotation is the annotation of the deprecated resources, methods, types and parameters
+const deprecatedAnnotation = "deprecated"
+
+// Kinds of the deprecated elements of the DeprecationReport
+const (
+	DeprecatedResource  = "resource"
+	DeprecatedMethod    = "method"
+	DeprecatedType      = "type"
+	DeprecatedParameter = "parameter"
+)
+
+// Deprecation is the deprecation of an element of the API, given by the `(deprecated)` annotation:
+//
+//	(deprecated): true
+//	(deprecated): use /v2/users
+//	(deprecated): {since: v1.4, replacement: /v2/users, message: removed in 2025}
+//
+// `(deprecated): false` means the element isn't deprecated.
+type Deprecation struct {
+	// version or date of the deprecation, empty if not given
+	Since string
+
+	// the element to use instead, empty if not given
+	Replacement string
+
+	// free text, e.g. the text of `(deprecated): use /v2/users`
+	Message string
+}
+
+func (d Deprecation) String() string {
+	s := "deprecated"
+	if d.Since != "" {
+		s += " since " + d.Since
+	}
+	if d.Replacement != "" {
+		s += ", use " + d.Replacement
+	}
+	if d.Message != "" {
+		s += ": " + d.Message
+	}
+	return s
+}
+
+// newDeprecation returns the deprecation of the `(deprecated)` annotation, nil if there is none
+func newDeprecation(annotations map[string]interface{}) *Deprecation {
+	v, ok := annotation(annotations, deprecatedAnnotation)
+	if !ok {
+		return nil
+	}
+	switch value := v.(type) {
+	case nil:
+		return &Deprecation{}
+	case bool:
+		if !value {
+			return nil
+		}
+		return &Deprecation{}
+	case map[interface{}]interface{}:
+		d := &Deprecation{}
+		for k, v := range value {
+			switch fmt.Sprint(k) {
+			case "since":
+				d.Since = fmt.Sprint(v)
+			case "replacement":
+				d.Replacement = fmt.Sprint(v)
+			case "message":
+				d.Message = fmt.Sprint(v)
+			}
+		}
+		return d
+	default:
+		return &Deprecation{Message: fmt.Sprint(value)}
+	}
+}
+
+// Deprecation returns the deprecation of this resource or of its parent resources, nil if it isn't deprecated
+func (r *Resource) Deprecation() *Deprecation {
+	for ; r != nil; r = r.Parent {
+		if _, ok := r.Annotation(deprecatedAnnotation); ok {
+			return newDeprecation(r.Annotations)
+		}
+	}
+	return nil
+}
+
+// IsDeprecated returns true if this resource or one of its parent resources is deprecated
+func (r *Resource) IsDeprecated() bool {
+	return r.Deprecation() != nil
+}
+
+// Deprecation returns the deprecation of this method or of its resource, nil if it isn't deprecated.
+// The parameters of the method could be deprecated on their own, see NamedParameter.Deprecation.
+func (m *Method) Deprecation() *Deprecation {
+	if _, ok := m.Annotation(deprecatedAnnotation); ok {
+		return newDeprecation(m.Annotations)
+	}
+	return m.resourceDeprecation
+}
+
+// IsDeprecated returns true if this method or its resource is deprecated
+func (m *Method) IsDeprecated() bool {
+	return m.Deprecation() != nil
+}
+
+// Deprecation returns the deprecation of this parameter, nil if it isn't deprecated.
+// A parameter inherits the annotations of the parameter of the traits and resource types.
+func (np NamedParameter) Deprecation() *Deprecation {
+	return newDeprecation(np.Annotations)
+}
+
+// IsDeprecated returns true if this parameter is deprecated
+func (np NamedParameter) IsDeprecated() bool {
+	return np.Deprecation() != nil
+}
+
+// Deprecation returns the deprecation of this type or of the first deprecated ancestor,
+// nil if it isn't deprecated. Only the type itself is looked at if apiDef is nil.
+func (t Type) Deprecation(apiDef *APIDefinition) *Deprecation {
+	if _, ok := t.Annotation(deprecatedAnnotation); ok || apiDef == nil {
+		return newDeprecation(t.Annotations)
+	}
+	for _, parent := range t.Ancestors(apiDef) {
+		if _, ok := parent.Annotation(deprecatedAnnotation); ok {
+			return newDeprecation(parent.Annotations)
+		}
+	}
+	return nil
+}
+
+// IsDeprecated returns true if this type or one of its ancestors is deprecated
+func (t Type) IsDeprecated(apiDef *APIDefinition) bool {
+	return t.Deprecation(apiDef) != nil
+}
+
+// DeprecatedElement is a deprecated element of the API
+type DeprecatedElement struct {
+	// one of the Deprecated* constants
+	Kind string
+
+	// name of the element, e.g. `/users`, `GET /users`, `User`
+	// or `GET /users: query parameter page`
+	Name string
+
+	Deprecation Deprecation
+}
+
+func (e DeprecatedElement) String() string {
+	return fmt.Sprintf("%v %v is %v", e.Kind, e.Name, e.Deprecation)
+}
+
+// DeprecationReport returns the deprecated elements of the API: the resources and their
+// methods in the order of the resource tree, each followed by its deprecated URI parameters,
+// query parameters and headers, then the types sorted by name.
+// The deprecation of a resource is inherited by its nested resources and methods,
+// the deprecation of a type by the types inheriting from it.
+func (apiDef *APIDefinition) DeprecationReport() []DeprecatedElement {
+	var report []DeprecatedElement
+	add := func(kind, name string, d *Deprecation) {
+		if d != nil {
+			report = append(report, DeprecatedElement{Kind: kind, Name: name, Deprecation: *d})
+		}
+	}
+	addParams := func(prefix, kind string, params map[string]NamedParameter) {
+		for _, name := range sortedKeys(params) {
+			add(DeprecatedParameter, fmt.Sprintf("%v: %v %v", prefix, kind, name), params[name].Deprecation())
+		}
+	}
+
+	apiDef.walkResources(func(r *Resource) {
+		uri := r.FullURI()
+		add(DeprecatedResource, uri, r.Deprecation())
+		addParams(uri, "URI parameter", r.URIParameters)
+		for _, m := range r.methods() {
+			name := m.Name + " " + uri
+			add(DeprecatedMethod, name, m.Deprecation())
+			addParams(name, "query parameter", m.QueryParameters)
+			for _, h := range sortedHeaderNames(m.Headers) {
+				add(DeprecatedParameter, fmt.Sprintf("%v: header %v", name, h), NamedParameter(m.Headers[h]).Deprecation())
+			}
+		}
+	})
+	for _, name := range sortedKeys(apiDef.Types) {
+		add(DeprecatedType, name, apiDef.Types[name].Deprecation(apiDef))
+	}
+	return report
+}
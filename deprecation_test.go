@@ -0,0 +1,65 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDeprecation(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/deprecation.raml", apiDef, WithUnknownKeyErrors())
+	Convey("deprecations", t, func() {
+		So(err, ShouldBeNil)
+		users := apiDef.Resources["/users"]
+
+		Convey("resources and their nested resources", func() {
+			So(users.IsDeprecated(), ShouldBeTrue)
+			So(*users.Deprecation(), ShouldResemble, Deprecation{Since: "v1.4", Replacement: "/accounts"})
+			So(users.Nested["/{userId}"].IsDeprecated(), ShouldBeTrue)
+			accounts := apiDef.Resources["/accounts"]
+			So(accounts.IsDeprecated(), ShouldBeFalse)
+		})
+
+		Convey("methods", func() {
+			So(users.Get.IsDeprecated(), ShouldBeTrue)
+			So(users.Nested["/{userId}"].Get.Deprecation().Replacement, ShouldEqual, "/accounts")
+			So(users.Nested["/{userId}"].Delete.IsDeprecated(), ShouldBeFalse)
+
+			accounts := apiDef.Resources["/accounts"]
+			So(accounts.Get.IsDeprecated(), ShouldBeTrue)
+			So(accounts.Post.IsDeprecated(), ShouldBeFalse)
+		})
+
+		Convey("parameters, inherited from the traits", func() {
+			offset := users.Get.QueryParameters["offset"]
+			So(offset.IsDeprecated(), ShouldBeTrue)
+			So(offset.Deprecation().Message, ShouldEqual, "use page")
+		})
+
+		Convey("types and the types inheriting from them", func() {
+			So(apiDef.Types["User"].IsDeprecated(apiDef), ShouldBeTrue)
+			So(apiDef.Types["Admin"].Deprecation(apiDef).Since, ShouldEqual, "v1.4")
+			So(apiDef.Types["Admin"].IsDeprecated(nil), ShouldBeFalse)
+			So(apiDef.Types["Account"].IsDeprecated(apiDef), ShouldBeFalse)
+		})
+
+		Convey("report", func() {
+			var lines []string
+			for _, e := range apiDef.DeprecationReport() {
+				lines = append(lines, e.String())
+			}
+			So(lines, ShouldResemble, []string{
+				"method GET /accounts is deprecated",
+				"parameter GET /accounts: header X-Legacy is deprecated: use X-Trace",
+				"resource /users is deprecated since v1.4, use /accounts",
+				"method GET /users is deprecated since v1.4, use /accounts",
+				"parameter GET /users: query parameter offset is deprecated: use page",
+				"resource /users/{userId} is deprecated since v1.4, use /accounts",
+				"method GET /users/{userId} is deprecated since v1.4, use /accounts",
+				"type Admin is deprecated since v1.4, use Account",
+				"type User is deprecated since v1.4, use Account",
+			})
+		})
+	})
+}
@@ -0,0 +1,131 @@
+package raml
+
+import (
+	"fmt"
+)
+
+// DiscriminatorOf returns the discriminator of this type, declared by the type
+// or inherited from its nearest ancestor declaring one, with the name of the type
+// declaring it. The discriminator is empty if there is none.
+func (t Type) DiscriminatorOf(apiDef *APIDefinition) (discriminator string, declaredBy string) {
+	if t.Discriminator != "" {
+		return t.Discriminator, t.Name
+	}
+	for _, parent := range t.Ancestors(apiDef) {
+		if parent.Discriminator != "" {
+			return parent.Discriminator, parent.Name
+		}
+	}
+	return "", ""
+}
+
+// DiscriminatorValueOf returns the value of the discriminator identifying
+// this type, its `discriminatorValue`, the name of the type by default
+func (t Type) DiscriminatorValueOf() string {
+	if t.DiscriminatorValue != "" {
+		return t.DiscriminatorValue
+	}
+	return t.Name
+}
+
+// Subtypes returns the types of the API definition inheriting from this type,
+// directly or through other types, sorted by name
+func (t Type) Subtypes(apiDef *APIDefinition) []Type {
+	var subtypes []Type
+	for _, name := range sortedKeys(apiDef.Types) {
+		if name == t.Name {
+			continue
+		}
+		sub := apiDef.Types[name]
+		for _, parent := range sub.Ancestors(apiDef) {
+			if parent.Name == t.Name {
+				subtypes = append(subtypes, sub)
+				break
+			}
+		}
+	}
+	return subtypes
+}
+
+// SelectSubtype returns the type of an instance of this type, e.g. a decoded JSON
+// payload: this type or the subtype whose discriminator value is the value of the
+// discriminator property of the instance. It returns false if the type has no
+// discriminator, the instance is not an object or no type has its discriminator value.
+func (t Type) SelectSubtype(apiDef *APIDefinition, instance interface{}) (Type, bool) {
+	discriminator, _ := t.DiscriminatorOf(apiDef)
+	if discriminator == "" {
+		return Type{}, false
+	}
+	var value interface{}
+	var ok bool
+	switch obj := instance.(type) {
+	case map[string]interface{}:
+		value, ok = obj[discriminator]
+	case map[interface{}]interface{}:
+		value, ok = obj[discriminator]
+	}
+	if !ok || value == nil {
+		return Type{}, false
+	}
+	for _, candidate := range append([]Type{t}, t.Subtypes(apiDef)...) {
+		if candidate.DiscriminatorValueOf() == fmt.Sprint(value) {
+			return candidate, true
+		}
+	}
+	return Type{}, false
+}
+
+// validateDiscriminators checks the discriminators of the types of an API definition:
+// a discriminator is a property of the type which is not an array, a discriminator value
+// needs a discriminator and identifies one type of the hierarchy of the discriminator
+func (apiDef *APIDefinition) validateDiscriminators() error {
+	errs := new(Error)
+
+	// types by value, by type declaring the discriminator
+	values := map[string]map[string]string{}
+	for _, name := range sortedKeys(apiDef.Types) {
+		t := apiDef.Types[name]
+		if t.IsJSONType() {
+			continue
+		}
+		discriminator, declaredBy := t.DiscriminatorOf(apiDef)
+		if discriminator == "" {
+			if t.DiscriminatorValue != "" {
+				errs.add(fmt.Errorf("type %v: discriminatorValue %v needs a discriminator", name, t.DiscriminatorValue))
+			}
+			continue
+		}
+		if t.Discriminator != "" {
+			if err := t.validateDiscriminatorProperty(apiDef); err != nil {
+				errs.add(fmt.Errorf("type %v: %v", name, err))
+				continue
+			}
+		}
+
+		value := t.DiscriminatorValueOf()
+		if values[declaredBy] == nil {
+			values[declaredBy] = map[string]string{}
+		}
+		if other, ok := values[declaredBy][value]; ok {
+			errs.add(fmt.Errorf("type %v: discriminatorValue %v is the one of type %v", name, value, other))
+			continue
+		}
+		values[declaredBy][value] = name
+	}
+	return errs.errOrNil()
+}
+
+// validateDiscriminatorProperty checks that the discriminator of a type
+// is one of its properties, not an array
+func (t Type) validateDiscriminatorProperty(apiDef *APIDefinition) error {
+	for _, p := range t.AllProperties(apiDef) {
+		if p.Name != t.Discriminator {
+			continue
+		}
+		if p.IsArray() || p.IsBidimensiArray() {
+			return fmt.Errorf("discriminator %v is an array property", t.Discriminator)
+		}
+		return nil
+	}
+	return fmt.Errorf("discriminator %v is not a property", t.Discriminator)
+}
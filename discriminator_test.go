@@ -0,0 +1,76 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiscriminator(t *testing.T) {
+	Convey("discriminators", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/discriminator.raml", apiDef, WithUnknownKeyErrors()), ShouldBeNil)
+		shape := apiDef.Types["Shape"]
+
+		Convey("declared and inherited", func() {
+			discriminator, declaredBy := apiDef.Types["RoundedSquare"].DiscriminatorOf(apiDef)
+			So(discriminator, ShouldEqual, "kind")
+			So(declaredBy, ShouldEqual, "Shape")
+			So(apiDef.Types["Circle"].DiscriminatorValueOf(), ShouldEqual, "circle")
+			So(apiDef.Types["Rectangle"].DiscriminatorValueOf(), ShouldEqual, "Rectangle")
+		})
+
+		Convey("subtypes", func() {
+			var names []string
+			for _, sub := range shape.Subtypes(apiDef) {
+				names = append(names, sub.Name)
+			}
+			So(names, ShouldResemble, []string{"Circle", "Rectangle", "RoundedSquare", "Square"})
+			So(apiDef.Types["Circle"].Subtypes(apiDef), ShouldBeEmpty)
+		})
+
+		Convey("the subtype of an instance", func() {
+			sub, ok := shape.SelectSubtype(apiDef, map[string]interface{}{"kind": "rounded-square", "side": 2})
+			So(ok, ShouldBeTrue)
+			So(sub.Name, ShouldEqual, "RoundedSquare")
+
+			sub, ok = shape.SelectSubtype(apiDef, map[interface{}]interface{}{"kind": "Rectangle"})
+			So(ok, ShouldBeTrue)
+			So(sub.Name, ShouldEqual, "Rectangle")
+
+			sub, ok = apiDef.Types["Square"].SelectSubtype(apiDef, map[string]interface{}{"kind": "square"})
+			So(ok, ShouldBeTrue)
+			So(sub.Name, ShouldEqual, "Square")
+
+			_, ok = apiDef.Types["Square"].SelectSubtype(apiDef, map[string]interface{}{"kind": "circle"})
+			So(ok, ShouldBeFalse)
+			_, ok = shape.SelectSubtype(apiDef, map[string]interface{}{"radius": 1})
+			So(ok, ShouldBeFalse)
+			_, ok = shape.SelectSubtype(apiDef, "circle")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("validation", func() {
+			parse := func(types string) error {
+				return ParseBytes([]byte("#%RAML 1.0\ntitle: API\ntypes:\n"+types), new(APIDefinition))
+			}
+			err := parse("  Shape:\n    discriminator: kind\n    properties:\n      name: string\n")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "type Shape: discriminator kind is not a property")
+
+			err = parse("  Shape:\n    discriminator: kinds\n    properties:\n      kinds: string[]\n")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "discriminator kinds is an array property")
+
+			err = parse("  Circle:\n    discriminatorValue: circle\n    properties:\n      radius: number\n")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "type Circle: discriminatorValue circle needs a discriminator")
+
+			err = parse("  Shape:\n    discriminator: kind\n    properties:\n      kind: string\n" +
+				"  Circle:\n    type: Shape\n    discriminatorValue: round\n" +
+				"  Disc:\n    type: Shape\n    discriminatorValue: round\n")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "type Disc: discriminatorValue round is the one of type Circle")
+		})
+	})
+}
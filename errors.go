@@ -30,6 +30,7 @@ package raml
 // This file contains all code related to YAML and RAML errors.
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -37,14 +38,60 @@ import (
 )
 
 // An Error is returned by the ParseFile function when RAML or YAML problems
-// are encountered when parsing the RAML document.
+// are encountered when parsing the RAML document. The problems of the
+// declarations and resources are all reported, not only the first one.
+// The errors are kept as is, e.g. errors.As(err, &limitErr) finds a *LimitError.
 type Error struct {
-	Errors []string
+	Errors []error
 }
 
 func (e *Error) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
 	return fmt.Sprintf("Error parsing RAML:\n  %s\n",
-		strings.Join(e.Errors, "\n  "))
+		strings.Join(msgs, "\n  "))
+}
+
+// As finds the first error that matches target, see errors.As
+func (e *Error) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is reports whether one of the errors matches target, see errors.Is
+func (e *Error) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// add appends an error, nil is ignored and the errors of an Error are appended one by one
+func (e *Error) add(err error) {
+	if err == nil {
+		return
+	}
+	if ramlError, ok := err.(*Error); ok {
+		e.Errors = append(e.Errors, ramlError.Errors...)
+		return
+	}
+	e.Errors = append(e.Errors, err)
+}
+
+// errOrNil returns the error if it has errors, nil otherwise
+func (e *Error) errOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
 }
 
 // Populate the RAML error value with converted YAML error strings (with
@@ -57,7 +104,7 @@ func populateRAMLError(ramlError *Error,
 
 		// Create the RAML errors
 		ramlError.Errors =
-			append(ramlError.Errors, convertYAMLError(currErr))
+			append(ramlError.Errors, errors.New(convertYAMLError(currErr)))
 	}
 }
 
@@ -70,14 +117,11 @@ func convertYAMLError(yamlError string) string {
 
 		if len(yamlErrorParts) >= 7 {
 
-			fmt.Println(yamlError)
-
 			var ok bool
 			var source string
 			var target string
 			var targetName string
-			line := yamlErrorParts[1]
-			line = line[:len(line)-1]
+			line := strings.TrimSuffix(yamlErrorParts[1], ":")
 
 			// TODO: support more complex types:
 			// map[string]raml.NamedParameter -->
@@ -97,9 +141,8 @@ func convertYAMLError(yamlError string) string {
 			if source, ok = yamlTypeToName[yamlErrorParts[4]]; !ok {
 				source = yamlErrorParts[4]
 			}
-			fmt.Println("source: ", source)
 
-			if source == "string" {
+			if source == "string" && len(yamlErrorParts) >= 8 {
 				source = fmt.Sprintf("string (got %s)", yamlErrorParts[5])
 				target = yamlErrorParts[7]
 			} else {
@@ -155,6 +198,8 @@ var ramlTypeNames = map[string]string{
 	"raml.Method":               "method",
 	"raml.Resource":             "resource",
 	"raml.APIDefinition":        "API definition",
+	"raml.typeDeclaration":      "type",
+	"raml.AnnotationType":       "annotation type",
 }
 
 var ramlTypes = map[string]string{
@@ -177,4 +222,6 @@ var ramlTypes = map[string]string{
 	"raml.Method":               "mapping",
 	"raml.Resource":             "mapping",
 	"raml.APIDefinition":        "mapping",
+	"raml.typeDeclaration":      "mapping",
+	"raml.AnnotationType":       "type or mapping",
 }
@@ -0,0 +1,300 @@
+package raml
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maximum depth of nested types checked by example validation,
+// to protect against recursive types
+const maxExampleDepth = 32
+
+// Example is an example of an instance of a type.
+// An example could be written as the value itself or
+// as structured example, which is a map with a `value` key.
+type Example struct {
+	// name of the example in the `examples` facet,
+	// empty for the `example` facet.
+	Name string
+
+	// An alternate, human-friendly name for the example.
+	DisplayName string
+
+	// A substantial, human-friendly description for an example.
+	Description string
+
+	// The actual example of a type instance.
+	Value interface{}
+
+	// Validates this example against its type at parse time.
+	// Default is true.
+	Strict bool
+}
+
+// newExample creates example from the value of
+// `example` facet or an element of `examples` facet
+func newExample(name string, v interface{}) Example {
+	ex := Example{
+		Name:   name,
+		Value:  v,
+		Strict: true,
+	}
+
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return ex
+	}
+	value, ok := m["value"]
+	if !ok {
+		return ex
+	}
+
+	// structured example
+	ex.Value = value
+	if s, ok := m["displayName"].(string); ok {
+		ex.DisplayName = s
+	}
+	if s, ok := m["description"].(string); ok {
+		ex.Description = s
+	}
+	if strict, ok := m["strict"].(bool); ok {
+		ex.Strict = strict
+	}
+	return ex
+}
+
+// AllExamples returns the examples of this type,
+// from both the `example` and `examples` facets.
+// Examples from `examples` are sorted by name.
+func (t Type) AllExamples() []Example {
+	return allExamples(t.Example, t.Examples)
+}
+
+// allExamples creates examples from the values of `example` and `examples` facets
+func allExamples(example interface{}, named map[string]interface{}) []Example {
+	var examples []Example
+	if example != nil {
+		examples = append(examples, newExample("", example))
+	}
+
+	var names []string
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		examples = append(examples, newExample(name, named[name]))
+	}
+	return examples
+}
+
+// exampleText returns the text of an example, the value of a structured example:
+// a string as is, other values JSON encoded
+func exampleText(v interface{}) string {
+	switch value := newExample("", v).Value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	default:
+		text, err := json.MarshalIndent(jsonValue(value), "", "  ")
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(text)
+	}
+}
+
+// validateExamples validates the strict examples of a body against its resolved type.
+// The examples written as text are validated for the JSON media types only,
+// e.g. an XML example isn't. mediaType is the media type of the body.
+func (b Body) validateExamples(mediaType string, apiDef *APIDefinition) error {
+	if b.ResolvedType == nil || b.ExampleMediaType != "" {
+		return nil
+	}
+	for _, ex := range b.AllExamples() {
+		if _, isText := ex.Value.(string); !ex.Strict || (isText && !strings.Contains(mediaType, "json")) {
+			continue
+		}
+		if err := validateTypeValue(ex.Value, *b.ResolvedType, apiDef, 0); err != nil {
+			name := ex.Name
+			if name == "" {
+				name = "example"
+			}
+			return fmt.Errorf("invalid %v: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// validateExamples validates all strict examples of the type
+func (t Type) validateExamples(apiDef *APIDefinition) error {
+	for _, ex := range t.AllExamples() {
+		if !ex.Strict {
+			continue
+		}
+		if err := validateTypeValue(ex.Value, t, apiDef, 0); err != nil {
+			name := ex.Name
+			if name == "" {
+				name = "example"
+			}
+			return fmt.Errorf("type %v: invalid %v: %v", t.Name, name, err)
+		}
+	}
+	return nil
+}
+
+// validateTypeValue validates a value against a type.
+// Only the built-in scalar types, arrays and object properties are checked,
+// other type expressions are accepted as is.
+func validateTypeValue(v interface{}, t Type, apiDef *APIDefinition, depth int) error {
+	tStr := strings.TrimSpace(t.TypeString())
+	if tStr == "" && len(t.Properties) > 0 {
+		tStr = "object"
+	}
+	if len(t.Properties) > 0 {
+		v = decodeJSONText(v)
+	}
+	if err := validateValue(v, tStr, t.Format, apiDef, depth); err != nil {
+		return err
+	}
+	if len(t.Properties) == 0 {
+		return nil
+	}
+	return validateProperties(v, &t, apiDef, depth)
+}
+
+// decodeJSONText decodes the example of an object or an array written as JSON text,
+// e.g. included from a .json file, other values are returned as is
+func decodeJSONText(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") && !strings.HasPrefix(s, "[") {
+		return v
+	}
+	var decoded interface{}
+	if err := unmarshalYAML([]byte(s), &decoded); err != nil {
+		return v
+	}
+	return decoded
+}
+
+// validateValue validates a value against a type expression,
+// format is the format of a datetime value
+func validateValue(v interface{}, tStr, format string, apiDef *APIDefinition, depth int) error {
+	if depth > maxExampleDepth {
+		return nil
+	}
+	if members, nullable := nonNilMembers(tStr); nullable {
+		if v == nil {
+			return nil
+		}
+		if len(members) == 0 {
+			return fmt.Errorf("%v is not nil", v)
+		}
+		tStr = strings.Join(members, " | ")
+	}
+
+	switch {
+	case tStr == "" || tStr == "any" || tStr == "file" || strings.Contains(tStr, "|"):
+		return nil
+	case strings.HasSuffix(tStr, "[]"):
+		items, ok := decodeJSONText(v).([]interface{})
+		if !ok {
+			return fmt.Errorf("%v is not an array", v)
+		}
+		elem := strings.TrimSpace(strings.TrimSuffix(tStr, "[]"))
+		if isParenthesized(elem) {
+			elem = elem[1 : len(elem)-1]
+		}
+		for _, item := range items {
+			if err := validateValue(item, elem, format, apiDef, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch tStr {
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%v is not a %v", v, tStr)
+		}
+	case "date-only", "time-only", "datetime-only", "datetime":
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%v is not a %v", v, tStr)
+		}
+		if _, err := ParseDateTime(tStr, format, s); err != nil {
+			return err
+		}
+	case "number", "float", "double":
+		switch v.(type) {
+		case int, int64, uint64, float64:
+		default:
+			return fmt.Errorf("%v is not a number", v)
+		}
+	case "integer", "int", "int8", "int16", "int32", "int64", "long":
+		switch n := v.(type) {
+		case int, int64, uint64:
+		case float64:
+			if n != float64(int64(n)) {
+				return fmt.Errorf("%v is not an integer", v)
+			}
+		default:
+			return fmt.Errorf("%v is not an integer", v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%v is not a boolean", v)
+		}
+	case "object", "array":
+		v = decodeJSONText(v)
+		if tStr == "object" {
+			if _, ok := v.(map[interface{}]interface{}); !ok {
+				return fmt.Errorf("%v is not an object", v)
+			}
+		} else if _, ok := v.([]interface{}); !ok {
+			return fmt.Errorf("%v is not an array", v)
+		}
+	default:
+		// user defined type
+		if apiDef == nil {
+			return nil
+		}
+		if ut, ok := apiDef.TypeByName(tStr); ok {
+			return validateTypeValue(v, *ut, apiDef, depth+1)
+		}
+	}
+	return nil
+}
+
+// validateProperties validates an object value against the type properties
+func validateProperties(v interface{}, t *Type, apiDef *APIDefinition, depth int) error {
+	obj, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return fmt.Errorf("%v is not an object", v)
+	}
+	for _, prop := range t.Properties {
+		pv, ok := obj[prop.Name]
+		if !ok {
+			if prop.Required {
+				return fmt.Errorf("missing required property %v", prop.Name)
+			}
+			continue
+		}
+		var format string
+		if prop.Format != nil {
+			format = *prop.Format
+		}
+		if err := validateValue(pv, prop.TypeString(), format, apiDef, depth+1); err != nil {
+			return fmt.Errorf("property %v: %v", prop.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package raml
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// typeKeys are the keys of a type declaration, the builtin facets
+var typeKeys, _, _ = structKeys(reflect.TypeOf(typeDeclaration{}))
+
+// propertyKeys are the keys of a property declaration which are not the ones of a type
+var propertyKeys = map[string]bool{
+	"required":  true,
+	"capnpType": true,
+}
+
+// facetValues returns the keys of a declaration which are the values of custom facets:
+// neither builtin facets nor annotations, nil if there is none
+func facetValues(keys map[string]interface{}, builtin map[string]reflect.Type) map[string]interface{} {
+	var values map[string]interface{}
+	for key, v := range keys {
+		if _, ok := builtin[key]; ok || propertyKeys[key] || isAnnotationKey(key) || strings.Contains(key, "<<") {
+			continue
+		}
+		if values == nil {
+			values = map[string]interface{}{}
+		}
+		values[key] = v
+	}
+	return values
+}
+
+// propertyFacetValues returns the values of the custom facets of a property declaration
+func propertyFacetValues(decl map[interface{}]interface{}) map[string]interface{} {
+	keys := make(map[string]interface{}, len(decl))
+	for k, v := range decl {
+		keys[fmt.Sprint(k)] = v
+	}
+	return facetValues(keys, typeKeys)
+}
+
+// AllFacets returns the facets declared by this type and by its ancestors,
+// a facet redeclared by a type overrides the inherited one
+func (t Type) AllFacets(apiDef *APIDefinition) map[string]Property {
+	facets := map[string]Property{}
+	ancestors := t.Ancestors(apiDef)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		for name, f := range ancestors[i].Facets {
+			facets[name] = f
+		}
+	}
+	for name, f := range t.Facets {
+		facets[name] = f
+	}
+	return facets
+}
+
+// FacetValue returns the value of a custom facet of this type,
+// given by the type or inherited from its nearest ancestor giving it
+func (t Type) FacetValue(apiDef *APIDefinition, name string) (interface{}, bool) {
+	if v, ok := t.FacetValues[name]; ok {
+		return v, true
+	}
+	for _, parent := range t.Ancestors(apiDef) {
+		if v, ok := parent.FacetValues[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// inheritedFacets returns the facets declared by the ancestors of a type,
+// with the name of the type declaring them
+func (t Type) inheritedFacets(apiDef *APIDefinition) (map[string]Property, map[string]string) {
+	facets := map[string]Property{}
+	declaredBy := map[string]string{}
+	for _, parent := range t.Ancestors(apiDef) {
+		for name, f := range parent.Facets {
+			if _, ok := facets[name]; !ok {
+				facets[name] = f
+				declaredBy[name] = parent.Name
+			}
+		}
+	}
+	return facets, declaredBy
+}
+
+// validateFacets checks the custom facets of the types of an API definition:
+// a facet is not a builtin facet nor declared by an ancestor, the values given by
+// a type or its properties are the ones of facets declared by its ancestors and
+// are valid values of these facets, and the required facets are given.
+// The values of undeclared facets, e.g. misspelled builtin facets, are only
+// reported with WithUnknownKeyErrors, they are ignored otherwise.
+func (apiDef *APIDefinition) validateFacets() error {
+	unknownKeys := apiDef.cfg != nil && apiDef.cfg.unknownKeys
+	errs := new(Error)
+	for _, name := range sortedKeys(apiDef.Types) {
+		t := apiDef.Types[name]
+		inherited, declaredBy := t.inheritedFacets(apiDef)
+
+		for _, facet := range sortedKeys(t.Facets) {
+			if _, ok := typeKeys[facet]; ok {
+				errs.add(fmt.Errorf("type %v: facet %v is a builtin facet", name, facet))
+			} else if owner, ok := declaredBy[facet]; ok {
+				errs.add(fmt.Errorf("type %v: facet %v is already declared by type %v", name, facet, owner))
+			}
+		}
+
+		errs.add(validateFacetValues("type "+name, "/types/"+name, t.FacetValues, inherited, unknownKeys, apiDef))
+		for _, facet := range sortedKeys(inherited) {
+			if !inherited[facet].Required {
+				continue
+			}
+			if _, ok := t.FacetValue(apiDef, facet); !ok {
+				errs.add(fmt.Errorf("type %v: facet %v of type %v is required", name, facet, declaredBy[facet]))
+			}
+		}
+
+		// the properties are inline subtypes of their type
+		for _, p := range t.AllProperties(apiDef) {
+			if len(p.FacetValues) == 0 {
+				continue
+			}
+			facets := map[string]Property{}
+			if pt, ok := apiDef.parentType(t, p.TypeString()); ok {
+				facets = pt.AllFacets(apiDef)
+			}
+			errs.add(validateFacetValues(fmt.Sprintf("type %v: property %v", name, p.Name),
+				fmt.Sprintf("/types/%v/properties/%v", name, p.Name), p.FacetValues, facets, unknownKeys, apiDef))
+		}
+	}
+	return errs.errOrNil()
+}
+
+// validateFacetValues checks the values of the facets of a type or a property,
+// the undeclared ones are reported like the unknown keys, at the path of the declaration
+func validateFacetValues(location, path string, values map[string]interface{}, facets map[string]Property,
+	unknownKeys bool, apiDef *APIDefinition) error {
+	errs := new(Error)
+	for _, facet := range sortedKeys(values) {
+		f, ok := facets[facet]
+		if !ok {
+			if unknownKeys {
+				errs.add(fmt.Errorf("unknown key %v at %v", facet, path))
+			}
+			continue
+		}
+		format := ""
+		if f.Format != nil {
+			format = *f.Format
+		}
+		if err := validateValue(values[facet], f.TypeString(), format, apiDef, 0); err != nil {
+			errs.add(fmt.Errorf("%v: invalid facet %v: %v", location, facet, err))
+		}
+	}
+	return errs.errOrNil()
+}
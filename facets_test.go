@@ -0,0 +1,84 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCustomFacets(t *testing.T) {
+	Convey("custom facets", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/facets.raml", apiDef, WithUnknownKeyErrors()), ShouldBeNil)
+
+		Convey("declarations", func() {
+			money := apiDef.Types["Money"]
+			So(money.Facets, ShouldHaveLength, 2)
+			So(money.Facets["maxAmount"].TypeString(), ShouldEqual, "number")
+			So(money.Facets["maxAmount"].Required, ShouldBeTrue)
+			So(money.Facets["currency"].Required, ShouldBeFalse)
+			So(money.FacetValues, ShouldBeNil)
+			So(apiDef.Types["Discount"].AllFacets(apiDef), ShouldContainKey, "currency")
+		})
+
+		Convey("values given by the subtypes", func() {
+			So(apiDef.Types["Price"].FacetValues, ShouldResemble, map[string]interface{}{"maxAmount": 1000, "currency": "EUR"})
+			discount := apiDef.Types["Discount"]
+			So(discount.FacetValues, ShouldBeNil)
+			v, ok := discount.FacetValue(apiDef, "maxAmount")
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, 1000)
+			_, ok = apiDef.Types["Money"].FacetValue(apiDef, "maxAmount")
+			So(ok, ShouldBeFalse)
+
+			resolved := discount.Resolve(apiDef)
+			So(resolved.FacetValues, ShouldResemble, map[string]interface{}{"maxAmount": 1000, "currency": "EUR"})
+			So(resolved.Facets, ShouldContainKey, "maxAmount")
+		})
+
+		Convey("values given by the properties", func() {
+			order := apiDef.Types["Order"]
+			So(order.GetProperty("tip").FacetValues, ShouldResemble, map[string]interface{}{"maxAmount": 50})
+			So(order.GetProperty("total").FacetValues, ShouldBeNil)
+		})
+
+		Convey("validation", func() {
+			parse := func(types string, opts ...ParseOption) error {
+				doc := "#%RAML 1.0\ntitle: API\ntypes:\n" +
+					"  Money:\n    type: number\n    facets:\n      maxAmount: number\n      currency?: string\n" + types
+				return ParseBytes([]byte(doc), new(APIDefinition), opts...)
+			}
+			So(parse(""), ShouldBeNil)
+
+			err := parse("  Cheap:\n    type: Money\n    currency: EUR\n")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "type Cheap: facet maxAmount of type Money is required")
+
+			err = parse("  Cheap:\n    type: Money\n    maxAmount: lots\n")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "type Cheap: invalid facet maxAmount")
+
+			err = parse("  Code:\n    type: string\n    facets:\n      pattern: string\n")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "type Code: facet pattern is a builtin facet")
+
+			err = parse("  Price:\n    type: Money\n    maxAmount: 10\n    facets:\n      currency: string\n")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "type Price: facet currency is already declared by type Money")
+
+			Convey("the undeclared facets are unknown keys", func() {
+				price := "  Price:\n    type: Money\n    maxAmount: 10\n    maxAmont: 10\n"
+				So(parse(price), ShouldBeNil)
+				err := parse(price, WithUnknownKeyErrors())
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "unknown key maxAmont at /types/Price")
+
+				order := "  Order:\n    properties:\n      tip:\n        type: Money\n        maxAmount: 5\n        maxAmont: 5\n"
+				err = parse(order, WithUnknownKeyErrors())
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "unknown key maxAmont at /types/Order/properties/tip")
+				So(err.Error(), ShouldNotContainSubstring, "maxAmount")
+			})
+		})
+	})
+}
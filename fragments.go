@@ -0,0 +1,309 @@
+package raml
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// The kinds of the typed fragments, from their header, e.g. `#%RAML 1.0 Trait`
+const (
+	DataTypeFragment          = "DataType"
+	TraitFragment             = "Trait"
+	ResourceTypeFragment      = "ResourceType"
+	SecuritySchemeFragment    = "SecurityScheme"
+	DocumentationItemFragment = "DocumentationItem"
+	NamedExampleFragment      = "NamedExample"
+	AnnotationTypeFragment    = "AnnotationTypeDeclaration"
+)
+
+// fragmentRoot is the root of a typed fragment document,
+// its content is decoded into the value of the fragment
+type fragmentRoot interface {
+	Root
+
+	// kind of the fragment, e.g. TraitFragment
+	fragmentKind() string
+
+	// pointer to the value the fragment is decoded into, e.g. *Trait
+	fragmentValue() interface{}
+
+	// sets the configuration of the parsing
+	setConfig(cfg *parseConfig)
+}
+
+// fragmentConfig is the configuration of the parsing of a fragment
+type fragmentConfig struct {
+	cfg *parseConfig
+}
+
+func (f *fragmentConfig) setConfig(cfg *parseConfig) {
+	f.cfg = cfg
+}
+
+// ParseDataType parses a `#%RAML 1.0 DataType` fragment, e.g. a file included
+// in the `types` of an API definition. The type is named after the file,
+// e.g. `User` for `User.raml`. The types of its inline properties are not
+// declared by any document, they are known by the returned type only.
+func ParseDataType(filePath string, opts ...ParseOption) (Type, error) {
+	f := &dataTypeFragment{}
+	err := ParseFile(filePath, f, opts...)
+	return f.Type, err
+}
+
+// ParseTrait parses a `#%RAML 1.0 Trait` fragment, named after the file
+func ParseTrait(filePath string, opts ...ParseOption) (Trait, error) {
+	f := &traitFragment{}
+	err := ParseFile(filePath, f, opts...)
+	return f.Trait, err
+}
+
+// ParseResourceType parses a `#%RAML 1.0 ResourceType` fragment, named after the file
+func ParseResourceType(filePath string, opts ...ParseOption) (ResourceType, error) {
+	f := &resourceTypeFragment{}
+	err := ParseFile(filePath, f, opts...)
+	return f.ResourceType, err
+}
+
+// ParseSecurityScheme parses a `#%RAML 1.0 SecurityScheme` fragment, named after the file
+func ParseSecurityScheme(filePath string, opts ...ParseOption) (SecurityScheme, error) {
+	f := &securitySchemeFragment{}
+	err := ParseFile(filePath, f, opts...)
+	return f.SecurityScheme, err
+}
+
+// ParseDocumentationItem parses a `#%RAML 1.0 DocumentationItem` fragment
+func ParseDocumentationItem(filePath string, opts ...ParseOption) (Documentation, error) {
+	f := &documentationItemFragment{}
+	err := ParseFile(filePath, f, opts...)
+	return f.Documentation, err
+}
+
+// ParseNamedExample parses a `#%RAML 1.0 NamedExample` fragment, e.g. a file included
+// in the `examples` of a type or a body. The examples are sorted by name.
+func ParseNamedExample(filePath string, opts ...ParseOption) ([]Example, error) {
+	f := &namedExampleFragment{}
+	err := ParseFile(filePath, f, opts...)
+	return allExamples(nil, f.Examples), err
+}
+
+// ParseAnnotationType parses a `#%RAML 1.0 AnnotationTypeDeclaration` fragment, named after the file
+func ParseAnnotationType(filePath string, opts ...ParseOption) (AnnotationType, error) {
+	f := &annotationTypeFragment{}
+	err := ParseFile(filePath, f, opts...)
+	return f.AnnotationType, err
+}
+
+// fragmentName returns the name of the declaration of a fragment file, e.g. `paged` for `traits/paged.raml`
+func fragmentName(fileName string) string {
+	base := path.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+type dataTypeFragment struct {
+	fragmentConfig
+	Type Type
+
+	// declares the types of the inline properties
+	apiDef *APIDefinition
+}
+
+func (f *dataTypeFragment) fragmentKind() string       { return DataTypeFragment }
+func (f *dataTypeFragment) fragmentValue() interface{} { return &f.Type }
+
+// UnmarshalYAML decodes the type
+func (f *dataTypeFragment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshal(&f.Type)
+}
+
+// PostProcess processes the type like a declared one, then validates its examples
+func (f *dataTypeFragment) PostProcess(workDir, fileName string) error {
+	f.apiDef = &APIDefinition{Types: map[string]Type{}}
+	if err := f.Type.postProcess(fragmentName(fileName), f.apiDef); err != nil {
+		return err
+	}
+	return f.Type.validateExamples(f.apiDef)
+}
+
+type traitFragment struct {
+	fragmentConfig
+	Trait Trait
+}
+
+func (f *traitFragment) fragmentKind() string       { return TraitFragment }
+func (f *traitFragment) fragmentValue() interface{} { return &f.Trait }
+
+// UnmarshalYAML decodes the trait
+func (f *traitFragment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshal(&f.Trait)
+}
+
+// PostProcess names the trait and parses its libraries
+func (f *traitFragment) PostProcess(workDir, fileName string) error {
+	if err := f.Trait.postProcess(fragmentName(fileName)); err != nil {
+		return err
+	}
+	_, err := parseUses(libraryDir(workDir, fileName), f.Trait.Uses, f.cfg)
+	return err
+}
+
+type resourceTypeFragment struct {
+	fragmentConfig
+	ResourceType ResourceType
+}
+
+func (f *resourceTypeFragment) fragmentKind() string       { return ResourceTypeFragment }
+func (f *resourceTypeFragment) fragmentValue() interface{} { return &f.ResourceType }
+
+// UnmarshalYAML decodes the resource type
+func (f *resourceTypeFragment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshal(&f.ResourceType)
+}
+
+// PostProcess processes the resource type like a declared one,
+// with the traits of its libraries
+func (f *resourceTypeFragment) PostProcess(workDir, fileName string) error {
+	libraries, err := parseUses(libraryDir(workDir, fileName), f.ResourceType.Uses, f.cfg)
+	if err != nil {
+		return err
+	}
+	traits := new(APIDefinition).allTraits(nil, libraries)
+	return f.ResourceType.postProcess(fragmentName(fileName), traits, nil)
+}
+
+type securitySchemeFragment struct {
+	fragmentConfig
+	SecurityScheme SecurityScheme
+}
+
+func (f *securitySchemeFragment) fragmentKind() string       { return SecuritySchemeFragment }
+func (f *securitySchemeFragment) fragmentValue() interface{} { return &f.SecurityScheme }
+
+// UnmarshalYAML decodes the security scheme
+func (f *securitySchemeFragment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshal(&f.SecurityScheme)
+}
+
+// PostProcess names the security scheme
+func (f *securitySchemeFragment) PostProcess(workDir, fileName string) error {
+	f.SecurityScheme.Name = fragmentName(fileName)
+	return nil
+}
+
+type documentationItemFragment struct {
+	fragmentConfig
+	Documentation Documentation
+}
+
+func (f *documentationItemFragment) fragmentKind() string       { return DocumentationItemFragment }
+func (f *documentationItemFragment) fragmentValue() interface{} { return &f.Documentation }
+
+// UnmarshalYAML decodes the documentation item
+func (f *documentationItemFragment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshal(&f.Documentation)
+}
+
+// PostProcess does nothing, a documentation item has no declarations
+func (f *documentationItemFragment) PostProcess(workDir, fileName string) error {
+	return nil
+}
+
+// parseUses parses the libraries of `uses`, relative to dir
+func parseUses(dir string, uses map[string]string, cfg *parseConfig) (map[string]*Library, error) {
+	libraries := map[string]*Library{}
+	for _, name := range sortedKeys(uses) {
+		lib := &Library{Filename: uses[name]}
+		if _, err := parseLibrary(dir, uses[name], lib, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse library	name=%v, path=%v, err=%v", name, uses[name], err)
+		}
+		libraries[name] = lib
+	}
+	return libraries, nil
+}
+
+// addFragmentLibraries parses the libraries used by the trait and resource type fragments
+// included by a document, relative to the fragment files, and adds them to the libraries
+// of the document. A library name of the document can't be used for another library.
+func addFragmentLibraries(libraries map[string]*Library, traits map[string]Trait,
+	rts map[string]ResourceType, sm *sourceMap, workDir string, cfg *parseConfig) error {
+	errs := new(Error)
+	add := func(kind, name string, uses map[string]string, pointer string) {
+		dir := workDir
+		if loc, ok := sm.location(pointer); ok && loc.File != "" {
+			dir = fileDir(loc.File)
+		}
+		for _, useName := range sortedKeys(uses) {
+			resolved := resolvePath(dir, uses[useName])
+			if lib, ok := libraries[useName]; ok {
+				if lib.resolved != resolved {
+					errs.add(fmt.Errorf("%v %v: library %v is %v, not %v", kind, name, useName, lib.resolved, resolved))
+				}
+				continue
+			}
+			used, err := parseUses(dir, map[string]string{useName: uses[useName]}, cfg)
+			if err != nil {
+				errs.add(fmt.Errorf("%v %v: %v", kind, name, err))
+				continue
+			}
+			libraries[useName] = used[useName]
+		}
+	}
+	for _, name := range sortedKeys(traits) {
+		if uses := traits[name].Uses; len(uses) > 0 {
+			add("trait", name, uses, jsonPointer([]string{"traits", name, "uses"}))
+		}
+	}
+	for _, name := range sortedKeys(rts) {
+		if uses := rts[name].Uses; len(uses) > 0 {
+			add("resource type", name, uses, jsonPointer([]string{"resourceTypes", name, "uses"}))
+		}
+	}
+	return errs.errOrNil()
+}
+
+// fileDir returns the directory of a file or URL, to resolve its libraries
+func fileDir(file string) string {
+	if isURL(file) {
+		return file[:strings.LastIndex(file, "/")+1]
+	}
+	return filepath.Dir(file)
+}
+
+type namedExampleFragment struct {
+	fragmentConfig
+	Examples map[string]interface{}
+}
+
+func (f *namedExampleFragment) fragmentKind() string       { return NamedExampleFragment }
+func (f *namedExampleFragment) fragmentValue() interface{} { return &f.Examples }
+
+// UnmarshalYAML decodes the examples, keyed by name
+func (f *namedExampleFragment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshal(&f.Examples)
+}
+
+// PostProcess does nothing, the examples are validated where they are included
+func (f *namedExampleFragment) PostProcess(workDir, fileName string) error {
+	return nil
+}
+
+type annotationTypeFragment struct {
+	fragmentConfig
+	AnnotationType AnnotationType
+}
+
+func (f *annotationTypeFragment) fragmentKind() string       { return AnnotationTypeFragment }
+func (f *annotationTypeFragment) fragmentValue() interface{} { return &f.AnnotationType }
+
+// UnmarshalYAML decodes the annotation type
+func (f *annotationTypeFragment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshal(&f.AnnotationType)
+}
+
+// PostProcess names the annotation type
+func (f *annotationTypeFragment) PostProcess(workDir, fileName string) error {
+	f.AnnotationType.Name = fragmentName(fileName)
+	f.AnnotationType.Type.Name = f.AnnotationType.Name
+	return nil
+}
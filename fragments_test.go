@@ -0,0 +1,136 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFragments(t *testing.T) {
+	Convey("typed fragments", t, func() {
+		Convey("the fragments are included by the API definition", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/fragments/api.raml", apiDef, WithUnknownKeyErrors()), ShouldBeNil)
+			So(apiDef.Documentation[0].Title, ShouldEqual, "Introduction")
+			So(apiDef.Resources["/users"].Get.QueryParameters, ShouldContainKey, "page")
+			oauth, ok := apiDef.GetSecurityScheme("oauth_2_0")
+			So(ok, ShouldBeTrue)
+			So(oauth.Name, ShouldEqual, "oauth_2_0")
+			So(oauth.Settings["accessTokenUri"], ShouldEqual, "https://auth.example.com/token")
+		})
+
+		Convey("data type", func() {
+			user, err := ParseDataType("./samples/fragments/User.raml", WithUnknownKeyErrors())
+			So(err, ShouldBeNil)
+			So(user.Name, ShouldEqual, "User")
+			So(user.Description, ShouldEqual, "a user of the API")
+			So(user.Properties, ShouldContainKey, "id")
+			So(user.Properties["name"].Type, ShouldEqual, "string")
+			So(user.Properties["name"].Required, ShouldBeTrue)
+		})
+
+		Convey("the examples of a data type are validated", func() {
+			doc := []byte("#%RAML 1.0 DataType\ntype: integer\nexample: hello\n")
+			So(ParseBytes(doc, &dataTypeFragment{}), ShouldNotBeNil)
+		})
+
+		Convey("trait", func() {
+			paged, err := ParseTrait("./samples/fragments/paged.raml")
+			So(err, ShouldBeNil)
+			So(paged.Name, ShouldEqual, "paged")
+			So(paged.Usage, ShouldEqual, "apply to the collections")
+			So(paged.QueryParameters["page"].Type, ShouldEqual, "integer")
+		})
+
+		Convey("resource type", func() {
+			collection, err := ParseResourceType("./samples/fragments/collection.raml")
+			So(err, ShouldBeNil)
+			So(collection.Name, ShouldEqual, "collection")
+			So(collection.Get.Name, ShouldEqual, "GET")
+			So(collection.Get.Description, ShouldEqual, "list the <<resourcePathName>>")
+		})
+
+		Convey("security scheme", func() {
+			oauth, err := ParseSecurityScheme("./samples/fragments/oauth_2_0.raml")
+			So(err, ShouldBeNil)
+			So(oauth.Name, ShouldEqual, "oauth_2_0")
+			So(oauth.Type, ShouldEqual, "OAuth 2.0")
+			So(oauth.DescribedBy.Headers, ShouldContainKey, HTTPHeader("Authorization"))
+		})
+
+		Convey("documentation item", func() {
+			intro, err := ParseDocumentationItem("./samples/fragments/intro.raml")
+			So(err, ShouldBeNil)
+			So(intro, ShouldResemble, Documentation{Title: "Introduction", Content: "The users of the example API."})
+		})
+
+		Convey("named example", func() {
+			examples, err := ParseNamedExample("./samples/named_examples/users.raml")
+			So(err, ShouldBeNil)
+			So(examples, ShouldHaveLength, 2)
+			So(examples[0].Name, ShouldEqual, "jane")
+			So(examples[0].DisplayName, ShouldEqual, "Jane")
+			So(examples[1].Value, ShouldResemble, map[interface{}]interface{}{"name": "John", "age": 30})
+		})
+
+		Convey("the header must match the kind of fragment", func() {
+			_, err := ParseTrait("./samples/fragments/intro.raml")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "not a RAML 1.0 Trait fragment")
+
+			_, err = ParseDataType("./samples/types.raml")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("the unknown keys are checked against the fragment", func() {
+			doc := []byte("#%RAML 1.0 DocumentationItem\ntitle: Intro\ncontnet: misspelled\n")
+			err := ParseBytes(doc, &documentationItemFragment{}, WithUnknownKeyErrors())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "contnet")
+		})
+
+		Convey("the libraries of the fragments", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/fragments/uses.raml", apiDef, WithUnknownKeyErrors(), WithStrictMode()), ShouldBeNil)
+			So(apiDef.Libraries, ShouldContainKey, "shared")
+			So(apiDef.Libraries, ShouldContainKey, "common")
+
+			get := apiDef.Resources["/books"].Get
+			So(get.QueryParameters, ShouldContainKey, "page")
+			So(get.QueryParameters, ShouldContainKey, "sort")
+			page := get.Responses["200"].Bodies.ForMIMEType["application/json"].ResolvedType
+			So(page, ShouldNotBeNil)
+			So(page.Properties, ShouldContainKey, "total")
+
+			Convey("standalone", func() {
+				paginated, err := ParseTrait("./samples/fragments/traits/paginated.raml")
+				So(err, ShouldBeNil)
+				So(paginated.Uses, ShouldResemble, map[string]string{"shared": "../libs/shared.raml"})
+
+				list, err := ParseResourceType("./samples/fragments/resourceTypes/list.raml", WithStrictMode())
+				So(err, ShouldBeNil)
+				So(list.Get.QueryParameters, ShouldContainKey, "sort")
+			})
+
+			Convey("a library name is used for one library", func() {
+				doc := []byte(`#%RAML 1.0
+title: Conflict
+uses:
+  shared: samples/fragments/libs/shared.raml
+traits:
+  paged:
+    uses:
+      shared: samples/types.raml
+`)
+				err := ParseBytes(doc, new(APIDefinition))
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "trait paged: library shared is samples/fragments/libs/shared.raml")
+			})
+		})
+
+		Convey("fragment names", func() {
+			So(fragmentName("traits/paged.raml"), ShouldEqual, "paged")
+			So(fragmentName(`types\User.yaml`), ShouldEqual, "User")
+		})
+	})
+}
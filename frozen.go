@@ -0,0 +1,506 @@
+package raml
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gigforks/yaml"
+)
+
+// FrozenAPIDefinition is an order-stable projection of an API definition,
+// for code generators: resources, methods, responses, parameters, bodies,
+// types and properties are slices in declaration order instead of maps,
+// and the annotations, facets and facet values are slices sorted by name,
+// so the code generated from it is the same across runs and Go versions.
+type FrozenAPIDefinition struct {
+	Title     string
+	Version   string
+	BaseURI   string
+	MediaType string
+	Protocols []string
+
+	BaseURIParameters []FrozenParameter
+
+	Types []FrozenType
+
+	Resources []FrozenResource
+}
+
+// FrozenValue is a named value, e.g. an annotation or the value of a facet
+type FrozenValue struct {
+	Name  string
+	Value interface{}
+}
+
+// FrozenType is a type with its properties and examples in declaration order.
+// The raw declarations of the properties and facets are left out.
+type FrozenType struct {
+	Name         string
+	LibraryChain LibraryChain
+	Location     SourceLocation
+	DisplayName  string
+	Description  string
+
+	// type, schema and default value, as in Type
+	Type    interface{}
+	Schema  interface{}
+	Default interface{}
+
+	// Own properties of the type, see Type.AllProperties for the inherited ones.
+	Properties []FrozenProperty
+
+	// facets declared by the type, and values of the facets of its parents
+	Facets      []FrozenProperty
+	FacetValues []FrozenValue
+
+	Annotations []FrozenValue
+
+	// the `example` facet, without name, followed by the named examples
+	Examples []Example
+
+	// object
+	MinProperties        int
+	MaxProperties        int
+	AdditionalProperties string
+	Discriminator        string
+	DiscriminatorValue   string
+
+	// array
+	Items       interface{}
+	MinItems    int
+	MaxItems    int
+	UniqueItems bool
+
+	// scalar
+	Enum       interface{}
+	Pattern    string
+	MinLength  int
+	MaxLength  int
+	Minimum    int
+	Maximum    int
+	Format     string
+	MultipleOf int
+	FileTypes  string
+
+	XML *XML
+}
+
+// FrozenProperty is a property of a type or of a body
+type FrozenProperty struct {
+	Name        string
+	Type        interface{}
+	Required    bool
+	Enum        interface{}
+	Description string
+	Default     interface{}
+
+	// string
+	Pattern   *string
+	MinLength *int
+	MaxLength *int
+
+	// number
+	Minimum    *float64
+	Maximum    *float64
+	MultipleOf *float64
+	Format     *string
+
+	// array
+	MinItems    *int
+	MaxItems    *int
+	UniqueItems bool
+	Items       FrozenItems
+
+	XML       *XML
+	CapnpType string
+
+	FacetValues []FrozenValue
+}
+
+// FrozenItems is the items of an array property
+type FrozenItems struct {
+	Type   string
+	Format string
+
+	// the inline declaration of the items, nil if none
+	Declaration *FrozenType
+}
+
+// FrozenParameter is a named parameter, e.g. a query parameter or header,
+// with its examples in declaration order
+type FrozenParameter struct {
+	Name        string
+	DisplayName string
+	Description string
+	Type        string
+	Pattern     *string
+	MinLength   *int
+	MaxLength   *int
+	Minimum     *float64
+	Maximum     *float64
+	Repeat      *bool
+	Required    bool
+	Default     Any
+	Annotations []FrozenValue
+
+	// the `example` facet, without name, followed by the named examples
+	Examples []Example
+}
+
+// FrozenBody is the body of a request or response for a media type
+type FrozenBody struct {
+	// media type of the body, empty for the body declared without media type
+	MediaType string
+
+	Type        string
+	Schema      string
+	Description string
+	Example     string
+	Properties  []FrozenProperty
+}
+
+// FrozenResponse is a response of a method
+type FrozenResponse struct {
+	Code        HTTPCode
+	Description string
+	Headers     []FrozenParameter
+	Bodies      []FrozenBody
+}
+
+// FrozenMethod is a method of a resource
+type FrozenMethod struct {
+	// name of the method, e.g. GET
+	Name            string
+	DisplayName     string
+	Description     string
+	QueryParameters []FrozenParameter
+	Headers         []FrozenParameter
+	Bodies          []FrozenBody
+	Responses       []FrozenResponse
+	Protocols       []string
+	SecuredBy       []string
+}
+
+// FrozenResource is a resource with its methods and nested resources
+type FrozenResource struct {
+	// relative URI of the resource
+	URI           string
+	FullURI       string
+	DisplayName   string
+	Description   string
+	Type          string
+	URIParameters []FrozenParameter
+	Methods       []FrozenMethod
+	Nested        []FrozenResource
+}
+
+// Frozen returns the order-stable projection of this API definition.
+// Elements which are not declared in the document, e.g. the methods inherited
+// from resource types or the types created from inline declarations,
+// follow the declared ones, sorted by name.
+func (apiDef *APIDefinition) Frozen() FrozenAPIDefinition {
+	tree := apiDef.declTree
+	frozen := FrozenAPIDefinition{
+		Title:             apiDef.Title,
+		Version:           apiDef.Version,
+		BaseURI:           apiDef.BaseURI,
+		MediaType:         apiDef.MediaType,
+		Protocols:         apiDef.Protocols,
+		BaseURIParameters: frozenParams(apiDef.BaseURIParameters, treeValue(tree, "baseUriParameters")),
+	}
+
+	var typeNames []string
+	for name := range apiDef.Types {
+		typeNames = append(typeNames, name)
+	}
+	for _, name := range declarationOrder(treeKeys(treeValue(tree, "types")), typeNames) {
+		frozen.Types = append(frozen.Types, frozenType(apiDef.Types[name]))
+	}
+
+	var uris []string
+	for uri := range apiDef.Resources {
+		uris = append(uris, uri)
+	}
+	for _, uri := range declarationOrder(treeKeys(tree), uris) {
+		r := apiDef.Resources[uri]
+		frozen.Resources = append(frozen.Resources, frozenResource(&r, treeValue(tree, uri)))
+	}
+	return frozen
+}
+
+func frozenType(t Type) FrozenType {
+	ft := FrozenType{
+		Name:                 t.Name,
+		LibraryChain:         t.LibraryChain,
+		Location:             t.Location,
+		DisplayName:          t.DisplayName,
+		Description:          t.Description,
+		Type:                 t.Type,
+		Schema:               t.Schema,
+		Default:              t.Default,
+		FacetValues:          frozenValues(t.FacetValues),
+		Annotations:          frozenValues(t.Annotations),
+		Examples:             t.AllExamples(),
+		MinProperties:        t.MinProperties,
+		MaxProperties:        t.MaxProperties,
+		AdditionalProperties: t.AdditionalProperties,
+		Discriminator:        t.Discriminator,
+		DiscriminatorValue:   t.DiscriminatorValue,
+		Items:                t.Items,
+		MinItems:             t.MinItems,
+		MaxItems:             t.MaxItems,
+		UniqueItems:          t.UniqueItems,
+		Enum:                 t.Enum,
+		Pattern:              t.Pattern,
+		MinLength:            t.MinLength,
+		MaxLength:            t.MaxLength,
+		Minimum:              t.Minimum,
+		Maximum:              t.Maximum,
+		Format:               t.Format,
+		MultipleOf:           t.MultipleOf,
+		FileTypes:            t.FileTypes,
+		XML:                  t.XML,
+	}
+	for _, name := range t.propertyNames() {
+		ft.Properties = append(ft.Properties, frozenProperty(t.GetProperty(name)))
+	}
+	for _, name := range sortedKeys(t.Facets) {
+		ft.Facets = append(ft.Facets, frozenProperty(t.Facets[name]))
+	}
+	return ft
+}
+
+func frozenProperty(p Property) FrozenProperty {
+	fp := FrozenProperty{
+		Name:        p.Name,
+		Type:        p.Type,
+		Required:    p.Required,
+		Enum:        p.Enum,
+		Description: p.Description,
+		Default:     p.Default,
+		Pattern:     p.Pattern,
+		MinLength:   p.MinLength,
+		MaxLength:   p.MaxLength,
+		Minimum:     p.Minimum,
+		Maximum:     p.Maximum,
+		MultipleOf:  p.MultipleOf,
+		Format:      p.Format,
+		MinItems:    p.MinItems,
+		MaxItems:    p.MaxItems,
+		UniqueItems: p.UniqueItems,
+		Items:       FrozenItems{Type: p.Items.Type, Format: p.Items.Format},
+		XML:         p.XML,
+		CapnpType:   p.CapnpType,
+		FacetValues: frozenValues(p.FacetValues),
+	}
+	if p.Items.Declaration != nil {
+		decl := frozenType(*p.Items.Declaration)
+		fp.Items.Declaration = &decl
+	}
+	return fp
+}
+
+// frozenValues returns the values of a map sorted by name, nil if there is none
+func frozenValues(values map[string]interface{}) []FrozenValue {
+	var frozen []FrozenValue
+	for _, name := range sortedKeys(values) {
+		frozen = append(frozen, FrozenValue{Name: name, Value: values[name]})
+	}
+	return frozen
+}
+
+func frozenResource(r *Resource, tree interface{}) FrozenResource {
+	fr := FrozenResource{
+		URI:           r.URI,
+		FullURI:       r.FullURI(),
+		DisplayName:   r.DisplayName,
+		Description:   r.Description,
+		URIParameters: frozenParams(r.URIParameters, treeValue(tree, "uriParameters")),
+	}
+	if r.Type != nil {
+		fr.Type = r.Type.Name
+	}
+
+	// methods in declaration order, followed by the inherited ones
+	var names []string
+	for _, m := range r.methods() {
+		names = append(names, m.Name)
+	}
+	var declared []string
+	for _, key := range treeKeys(tree) {
+		declared = append(declared, strings.ToUpper(key))
+	}
+	for _, name := range declarationOrder(declared, names) {
+		m := r.MethodByName(name)
+		fr.Methods = append(fr.Methods, frozenMethod(m, treeValue(tree, strings.ToLower(name))))
+	}
+
+	var uris []string
+	for uri := range r.Nested {
+		if r.Nested[uri] != nil {
+			uris = append(uris, uri)
+		}
+	}
+	for _, uri := range declarationOrder(treeKeys(tree), uris) {
+		fr.Nested = append(fr.Nested, frozenResource(r.Nested[uri], treeValue(tree, uri)))
+	}
+	return fr
+}
+
+func frozenMethod(m *Method, tree interface{}) FrozenMethod {
+	fm := FrozenMethod{
+		Name:            m.Name,
+		DisplayName:     m.DisplayName,
+		Description:     m.Description,
+		QueryParameters: frozenParams(m.QueryParameters, treeValue(tree, "queryParameters")),
+		Headers:         frozenHeaders(m.Headers, treeValue(tree, "headers")),
+		Bodies:          frozenBodies(m.Bodies, treeValue(tree, "body")),
+		Protocols:       m.Protocols,
+	}
+	for _, s := range m.SecuredBy {
+		fm.SecuredBy = append(fm.SecuredBy, s.Name)
+	}
+
+	responsesTree := treeValue(tree, "responses")
+	var codes []string
+	for code := range m.Responses {
+		codes = append(codes, string(code))
+	}
+	for _, code := range declarationOrder(treeKeys(responsesTree), codes) {
+		resp := m.Responses[HTTPCode(code)]
+		respTree := treeValue(responsesTree, code)
+		fm.Responses = append(fm.Responses, FrozenResponse{
+			Code:        HTTPCode(code),
+			Description: resp.Description,
+			Headers:     frozenHeaders(resp.Headers, treeValue(respTree, "headers")),
+			Bodies:      frozenBodies(resp.Bodies, treeValue(respTree, "body")),
+		})
+	}
+	return fm
+}
+
+func frozenBodies(b Bodies, tree interface{}) []FrozenBody {
+	var bodies []FrozenBody
+	if b.Default != nil {
+		bodies = append(bodies, frozenBody(*b.Default, tree))
+	}
+	for _, mediaType := range declarationOrder(treeKeys(tree), b.MediaTypes()) {
+		bodies = append(bodies, frozenBody(b.ForMIMEType[mediaType], treeValue(tree, mediaType)))
+	}
+	return bodies
+}
+
+func frozenBody(body Body, tree interface{}) FrozenBody {
+	fb := FrozenBody{
+		MediaType:   body.MediaType(),
+		Type:        body.TypeString(),
+		Schema:      body.Schema,
+		Description: body.Description,
+		Example:     body.Example,
+	}
+	var names []string
+	for name := range body.Properties {
+		names = append(names, name)
+	}
+	for _, name := range declarationOrder(treeKeys(treeValue(tree, "properties")), names) {
+		fb.Properties = append(fb.Properties, frozenProperty(body.Properties[name]))
+	}
+	return fb
+}
+
+func frozenParams(params map[string]NamedParameter, tree interface{}) []FrozenParameter {
+	var names []string
+	for name := range params {
+		names = append(names, name)
+	}
+
+	var frozen []FrozenParameter
+	for _, name := range declarationOrder(treeKeys(tree), names) {
+		np := params[name]
+		np.Name = name
+		frozen = append(frozen, frozenParam(np))
+	}
+	return frozen
+}
+
+func frozenHeaders(headers map[HTTPHeader]Header, tree interface{}) []FrozenParameter {
+	params := make(map[string]NamedParameter, len(headers))
+	for name, h := range headers {
+		params[string(name)] = NamedParameter(h)
+	}
+	return frozenParams(params, tree)
+}
+
+func frozenParam(np NamedParameter) FrozenParameter {
+	return FrozenParameter{
+		Name:        np.Name,
+		DisplayName: np.DisplayName,
+		Description: np.Description,
+		Type:        np.Type,
+		Pattern:     np.Pattern,
+		MinLength:   np.MinLength,
+		MaxLength:   np.MaxLength,
+		Minimum:     np.Minimum,
+		Maximum:     np.Maximum,
+		Repeat:      np.Repeat,
+		Required:    np.Required,
+		Default:     np.Default,
+		Annotations: frozenValues(np.Annotations),
+		Examples:    np.AllExamples(),
+	}
+}
+
+// declarationOrder returns the present names in the declared order,
+// followed by the names which are not declared, sorted
+func declarationOrder(declared, present []string) []string {
+	isPresent := map[string]bool{}
+	for _, name := range present {
+		isPresent[name] = true
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range declared {
+		if isPresent[name] && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	var rest []string
+	for _, name := range present {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
+
+// treeKeys returns the keys of a node of the YAML tree, without the `?` suffix
+func treeKeys(node interface{}) []string {
+	m, ok := node.(yaml.MapSlice)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for _, item := range m {
+		keys = append(keys, strings.TrimSuffix(fmt.Sprint(item.Key), "?"))
+	}
+	return keys
+}
+
+// treeValue returns the value of a key of a node of the YAML tree, nil if not exist
+func treeValue(node interface{}, key string) interface{} {
+	m, ok := node.(yaml.MapSlice)
+	if !ok {
+		return nil
+	}
+	for _, item := range m {
+		if k := fmt.Sprint(item.Key); k == key || k == key+"?" {
+			return item.Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFrozen(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/frozen.raml", apiDef)
+	Convey("frozen API definition", t, func() {
+		So(err, ShouldBeNil)
+		frozen := apiDef.Frozen()
+
+		Convey("types in declaration order", func() {
+			So(frozen.Types, ShouldHaveLength, 2)
+			So(frozen.Types[0].Name, ShouldEqual, "Zebra")
+			So(frozen.Types[1].Name, ShouldEqual, "Ant")
+
+			props := frozen.Types[0].Properties
+			So(props, ShouldHaveLength, 2)
+			So(props[0].Name, ShouldEqual, "stripes")
+			So(props[1].Name, ShouldEqual, "name")
+		})
+
+		Convey("annotations and facets sorted by name", func() {
+			zebra := frozen.Types[0]
+			So(zebra.Annotations, ShouldResemble, []FrozenValue{
+				{Name: "(origin)", Value: "africa"},
+				{Name: "(zoo)", Value: "savanna"},
+			})
+			So(zebra.Facets, ShouldHaveLength, 2)
+			So(zebra.Facets[0].Name, ShouldEqual, "age")
+			So(zebra.Facets[1].Name, ShouldEqual, "wild")
+
+			zeta := frozen.Resources[0].Methods[0].QueryParameters[0]
+			So(zeta.Annotations, ShouldResemble, []FrozenValue{
+				{Name: "(origin)", Value: "o"},
+				{Name: "(zoo)", Value: "z"},
+			})
+		})
+
+		Convey("resources in declaration order", func() {
+			So(frozen.Resources, ShouldHaveLength, 2)
+			zoo := frozen.Resources[0]
+			So(zoo.URI, ShouldEqual, "/zoo")
+			So(zoo.Type, ShouldEqual, "collection")
+			So(frozen.Resources[1].URI, ShouldEqual, "/ants")
+
+			So(zoo.Nested, ShouldHaveLength, 2)
+			So(zoo.Nested[0].FullURI, ShouldEqual, "/zoo/{id}")
+			So(zoo.Nested[1].FullURI, ShouldEqual, "/zoo/animals")
+			So(zoo.Nested[0].Methods[0].Name, ShouldEqual, "DELETE")
+		})
+
+		Convey("declared methods followed by the inherited ones", func() {
+			methods := frozen.Resources[0].Methods
+			So(methods, ShouldHaveLength, 2)
+			So(methods[0].Name, ShouldEqual, "POST")
+			So(methods[1].Name, ShouldEqual, "GET")
+			So(methods[1].Description, ShouldEqual, "list zoo")
+		})
+
+		Convey("parameters, bodies and responses in declaration order", func() {
+			post := frozen.Resources[0].Methods[0]
+			So(post.QueryParameters, ShouldHaveLength, 2)
+			So(post.QueryParameters[0].Name, ShouldEqual, "zeta")
+			So(post.QueryParameters[1].Name, ShouldEqual, "alpha")
+
+			So(post.Bodies, ShouldHaveLength, 2)
+			So(post.Bodies[0].MediaType, ShouldEqual, "application/xml")
+			So(post.Bodies[0].Type, ShouldEqual, "Zebra")
+			So(post.Bodies[1].MediaType, ShouldEqual, "application/json")
+			So(post.Bodies[1].Properties[0].Name, ShouldEqual, "zz")
+			So(post.Bodies[1].Properties[1].Name, ShouldEqual, "aa")
+
+			So(post.Responses, ShouldHaveLength, 2)
+			So(post.Responses[0].Code, ShouldEqual, HTTPCode("201"))
+			So(post.Responses[1].Code, ShouldEqual, HTTPCode("200"))
+
+			headers := frozen.Resources[1].Methods[0].Headers
+			So(headers, ShouldHaveLength, 2)
+			So(headers[0].Name, ShouldEqual, "X-Z")
+			So(headers[1].Name, ShouldEqual, "X-A")
+		})
+
+		Convey("same projection on every call", func() {
+			So(apiDef.Frozen(), ShouldResemble, frozen)
+		})
+	})
+}
@@ -0,0 +1,55 @@
+package raml
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const fuzzWorkDir = "./samples/"
+
+// fuzzConfig returns the configuration of the parsing of the fuzzed documents:
+// no network requests and small limits, so that an input can't stall the fuzzer
+func fuzzConfig() *parseConfig {
+	return newParseConfig([]ParseOption{
+		WithNoRemoteIncludes(),
+		WithMaxIncludeDepth(8),
+		WithMaxIncludes(64),
+		WithMaxDocumentSize(1 << 20),
+		WithMaxExpandedSize(4 << 20),
+	})
+}
+
+// addSampleCorpus adds all RAML samples as seed corpus
+func addSampleCorpus(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join(fuzzWorkDir, "*.raml"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	f.Add([]byte("#%RAML 1.0\n/a:\n  get:\n    is: [ t: { p: <<>> } ]\n"))
+	f.Add([]byte("#%RAML 1.0\ntypes:\n  a:\n    properties:\n      b?:\n      c: { type: [x], minLength: z }\n"))
+	f.Add([]byte("#%RAML 1.0\ntitle: !include\n"))
+	f.Add([]byte("#%RAML 1.0\ntitle: !include http://192.0.2.1/title.md\n"))
+}
+
+func FuzzParseBytes(f *testing.F) {
+	addSampleCorpus(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		parseBytes(data, fuzzWorkDir, "fuzz.raml", new(APIDefinition), fuzzConfig())
+	})
+}
+
+func FuzzPreProcess(f *testing.F) {
+	addSampleCorpus(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		preProcess(bytes.NewReader(data), fuzzWorkDir, fuzzConfig())
+	})
+}
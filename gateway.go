@@ -0,0 +1,534 @@
+package raml
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gigforks/yaml"
+)
+
+// CORSPolicy is the cross-origin resource sharing policy of a resource,
+// described by the response headers of its OPTIONS method
+type CORSPolicy struct {
+	// from Access-Control-Allow-Origin
+	Origins []string
+
+	// from Access-Control-Allow-Methods, the methods of the resource if not declared
+	Methods []string
+
+	// from Access-Control-Allow-Headers and Access-Control-Expose-Headers
+	Headers        []string
+	ExposedHeaders []string
+
+	// from Access-Control-Allow-Credentials
+	Credentials bool
+
+	// from Access-Control-Max-Age, in seconds, 0 if not declared
+	MaxAge int
+}
+
+// GatewayRoute is the configuration of an operation for an API gateway
+type GatewayRoute struct {
+	// name of the route, e.g. `get_users_userId`
+	Name string
+
+	// HTTP method, upper case
+	Method string
+
+	// full URI template of the resource, e.g. `/users/{userId}`
+	Path string
+
+	// regular expression of the path, the URI parameters match a segment,
+	// e.g. `^/users/[^/]+$`
+	PathRegexp string
+
+	// effective security schemes of the operation, with their name, they are
+	// alternatives: a request is authorized by any of them
+	SecuritySchemes []SecurityScheme
+
+	// rate limit of the operation, see Method.OperationMetadata
+	RateLimit *RateLimit
+
+	// CORS policy of the resource, nil if it has no OPTIONS method
+	// or if its OPTIONS method does not declare the allowed origins
+	CORS *CORSPolicy
+}
+
+// GatewayRoutes returns the routes of the operations of the API,
+// in the order of Operations
+func (apiDef *APIDefinition) GatewayRoutes() []GatewayRoute {
+	var routes []GatewayRoute
+	policies := map[*Resource]*CORSPolicy{}
+	for _, op := range apiDef.Operations() {
+		cors, ok := policies[op.Resource]
+		if !ok {
+			cors = corsPolicy(op.Resource)
+			policies[op.Resource] = cors
+		}
+		route := GatewayRoute{
+			Name:       routeName(op.Method.Name, op.Path),
+			Method:     op.Method.Name,
+			Path:       op.Path,
+			PathRegexp: pathRegexp(op.Path),
+			RateLimit:  op.Method.OperationMetadata().RateLimit,
+			CORS:       cors,
+		}
+		for _, name := range op.SecuredBy {
+			// an undeclared scheme is kept without type, so that the route stays secured
+			ss, _ := apiDef.GetSecurityScheme(name)
+			ss.Name = name
+			route.SecuritySchemes = append(route.SecuritySchemes, ss)
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// corsPolicy returns the CORS policy of a resource, nil if it has no OPTIONS method
+// or if the allowed origins are not declared: no origin is allowed by default
+func corsPolicy(r *Resource) *CORSPolicy {
+	if r.Options == nil {
+		return nil
+	}
+	headers := map[string][]string{}
+	for _, code := range sortedKeys(r.Options.Responses) {
+		for name, h := range r.Options.Responses[code].Headers {
+			lower := strings.ToLower(string(name))
+			if _, ok := headers[lower]; ok || !strings.HasPrefix(lower, "access-control-") {
+				continue
+			}
+			headers[lower] = headerValues(NamedParameter(h))
+		}
+	}
+
+	origins := headers["access-control-allow-origin"]
+	if len(origins) == 0 {
+		return nil
+	}
+	cors := &CORSPolicy{
+		Origins:        origins,
+		Methods:        headers["access-control-allow-methods"],
+		Headers:        headers["access-control-allow-headers"],
+		ExposedHeaders: headers["access-control-expose-headers"],
+	}
+	if len(cors.Methods) == 0 {
+		for _, m := range r.methods() {
+			cors.Methods = append(cors.Methods, m.Name)
+		}
+	}
+	if v := headers["access-control-allow-credentials"]; len(v) > 0 {
+		cors.Credentials = strings.EqualFold(v[0], "true")
+	}
+	if v := headers["access-control-max-age"]; len(v) > 0 {
+		cors.MaxAge = toInt(v[0])
+	}
+	return cors
+}
+
+// headerValues returns the comma separated values of a header,
+// from its example or default value
+func headerValues(np NamedParameter) []string {
+	var values []string
+	add := func(v interface{}) {
+		for _, s := range strings.Split(fmt.Sprint(v), ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = appendStrNotExist(s, values)
+			}
+		}
+	}
+	if v := np.exampleValue(); v != nil {
+		add(v)
+	}
+	return values
+}
+
+var underscoresRe = regexp.MustCompile(`_+`)
+
+// gatewayName converts a text into a name of the gateway configuration,
+// made of letters, digits and `_`, e.g. `users_userId` for `/users/{userId}`
+func gatewayName(s string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, s)
+	return strings.Trim(underscoresRe.ReplaceAllString(name, "_"), "_")
+}
+
+// routeName returns the name of the route of an operation, e.g. `get_users_userId`
+func routeName(method, path string) string {
+	if name := gatewayName(path); name != "" {
+		return strings.ToLower(method) + "_" + name
+	}
+	return strings.ToLower(method)
+}
+
+// pathRegexp returns the regular expression of an URI template,
+// a parameter matches a path segment
+func pathRegexp(template string) string {
+	var re strings.Builder
+	re.WriteString("^")
+	for {
+		start := strings.Index(template, "{")
+		end := strings.Index(template, "}")
+		if start < 0 || end < start {
+			re.WriteString(regexp.QuoteMeta(template))
+			break
+		}
+		re.WriteString(regexp.QuoteMeta(template[:start]))
+		re.WriteString("[^/]+")
+		template = template[end+1:]
+	}
+	re.WriteString("$")
+	return re.String()
+}
+
+// rateLimitWindow returns the duration of the window of a rate limit,
+// e.g. `1m`, `minute` or `15 minutes`, a minute if not declared
+func rateLimitWindow(window string) (time.Duration, error) {
+	window = strings.ToLower(strings.TrimSpace(window))
+	if window == "" {
+		return time.Minute, nil
+	}
+	number := strings.TrimRightFunc(window, unicode.IsLetter)
+	unit := strings.TrimSpace(window[len(number):])
+	n := 1
+	if number = strings.TrimSpace(number); number != "" {
+		var err error
+		if n, err = strconv.Atoi(number); err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid rate limit window %v", window)
+		}
+	}
+	switch strings.TrimSuffix(unit, "s") {
+	case "", "sec", "second":
+		return time.Duration(n) * time.Second, nil
+	case "m", "min", "minute":
+		return time.Duration(n) * time.Minute, nil
+	case "h", "hour":
+		return time.Duration(n) * time.Hour, nil
+	case "d", "day":
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return 0, fmt.Errorf("invalid rate limit window %v", window)
+}
+
+// gatewayUpstream returns the base URI of the API, its parameters are
+// replaced by their example or default value, or left as is
+func (apiDef *APIDefinition) gatewayUpstream() string {
+	return substituteURIParams(apiDef.BaseURI, apiDef.baseURIParams(), func(p requestParam) string {
+		if p.Variable {
+			return "{" + p.Name + "}"
+		}
+		return p.Value
+	})
+}
+
+// unmappedSchemesError returns the error of a secured route whose security schemes
+// have no equivalent in a gateway, the route is not generated as it would not be secured
+func unmappedSchemesError(route GatewayRoute, gateway string) error {
+	var schemes []string
+	for _, ss := range route.SecuritySchemes {
+		schemes = append(schemes, fmt.Sprintf("%v (%v)", ss.Name, ss.Type))
+	}
+	return fmt.Errorf("%v %v: no %v equivalent of the security schemes %v",
+		route.Method, route.Path, gateway, strings.Join(schemes, ", "))
+}
+
+// gatewayServiceName returns the name of the upstream service of the API
+func (apiDef *APIDefinition) gatewayServiceName() string {
+	name := gatewayName(strings.ToLower(apiDef.Title))
+	if name == "" {
+		return "api"
+	}
+	return name
+}
+
+type (
+	kongConfig struct {
+		FormatVersion string        `yaml:"_format_version"`
+		Services      []kongService `yaml:"services"`
+	}
+
+	kongService struct {
+		Name   string      `yaml:"name"`
+		URL    string      `yaml:"url"`
+		Routes []kongRoute `yaml:"routes"`
+	}
+
+	kongRoute struct {
+		Name      string       `yaml:"name"`
+		Paths     []string     `yaml:"paths"`
+		Methods   []string     `yaml:"methods"`
+		StripPath bool         `yaml:"strip_path"`
+		Plugins   []kongPlugin `yaml:"plugins,omitempty"`
+	}
+
+	kongPlugin struct {
+		Name   string        `yaml:"name"`
+		Config yaml.MapSlice `yaml:"config,omitempty"`
+	}
+)
+
+// kong rate limiting units, from the longest
+var kongUnits = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+	{"second", time.Second},
+}
+
+// WriteKongConfig writes the declarative configuration of the Kong gateway,
+// in YAML: a service for the base URI, with a route by operation and the plugins of
+//   - the security schemes: `basic-auth` for Basic Authentication, `oauth2` for OAuth 2.0
+//     with its scopes and grants, and `key-auth` for Pass Through and custom schemes
+//     with the described headers and query parameters as key names.
+//     The schemes of an operation are alternatives while Kong requires all the plugins
+//     to pass, so only the first scheme with a plugin is used. It is an error if none
+//     of them has one, e.g. OAuth 1.0 or Digest Authentication.
+//   - the rate limit: `rate-limiting`, converted to the longest unit dividing its window
+//   - the CORS policy of the resource: `cors`
+func (apiDef *APIDefinition) WriteKongConfig(w io.Writer) error {
+	service := kongService{
+		Name: apiDef.gatewayServiceName(),
+		URL:  apiDef.gatewayUpstream(),
+	}
+	for _, route := range apiDef.GatewayRoutes() {
+		kr := kongRoute{
+			Name:    route.Name,
+			Paths:   []string{"~" + strings.TrimPrefix(route.PathRegexp, "^")},
+			Methods: []string{route.Method},
+		}
+		if len(route.SecuritySchemes) > 0 {
+			plugin, ok := kongPlugin{}, false
+			for _, ss := range route.SecuritySchemes {
+				if plugin, ok = kongAuthPlugin(ss); ok {
+					break
+				}
+			}
+			if !ok {
+				return unmappedSchemesError(route, "Kong plugin")
+			}
+			kr.Plugins = append(kr.Plugins, plugin)
+		}
+		if rl := route.RateLimit; rl != nil && rl.Limit > 0 {
+			window, err := rateLimitWindow(rl.Window)
+			if err != nil {
+				return fmt.Errorf("%v %v: %v", route.Method, route.Path, err)
+			}
+			for _, unit := range kongUnits {
+				if window%unit.duration == 0 {
+					limit := rl.Limit * int(unit.duration) / int(window)
+					if limit < 1 {
+						limit = 1
+					}
+					kr.Plugins = append(kr.Plugins, kongPlugin{
+						Name:   "rate-limiting",
+						Config: yaml.MapSlice{{Key: unit.name, Value: limit}},
+					})
+					break
+				}
+			}
+		}
+		if cors := route.CORS; cors != nil {
+			config := yaml.MapSlice{
+				{Key: "origins", Value: cors.Origins},
+				{Key: "methods", Value: cors.Methods},
+			}
+			if len(cors.Headers) > 0 {
+				config = append(config, yaml.MapItem{Key: "headers", Value: cors.Headers})
+			}
+			if len(cors.ExposedHeaders) > 0 {
+				config = append(config, yaml.MapItem{Key: "exposed_headers", Value: cors.ExposedHeaders})
+			}
+			config = append(config, yaml.MapItem{Key: "credentials", Value: cors.Credentials})
+			if cors.MaxAge > 0 {
+				config = append(config, yaml.MapItem{Key: "max_age", Value: cors.MaxAge})
+			}
+			kr.Plugins = append(kr.Plugins, kongPlugin{Name: "cors", Config: config})
+		}
+		service.Routes = append(service.Routes, kr)
+	}
+	return writeYAML(w, kongConfig{FormatVersion: "3.0", Services: []kongService{service}})
+}
+
+// kongAuthPlugin returns the plugin of a security scheme, false if there is none
+func kongAuthPlugin(ss SecurityScheme) (kongPlugin, bool) {
+	switch ss.Type {
+	case "Basic Authentication":
+		return kongPlugin{Name: "basic-auth"}, true
+	case "OAuth 2.0":
+		config := yaml.MapSlice{}
+		if scopes := settingStrings(ss.Settings["scopes"]); len(scopes) > 0 {
+			config = append(config, yaml.MapItem{Key: "scopes", Value: scopes})
+		}
+		grants := settingStrings(ss.Settings["authorizationGrants"])
+		for _, g := range []struct{ grant, option string }{
+			{"authorization_code", "enable_authorization_code"},
+			{"client_credentials", "enable_client_credentials"},
+			{"implicit", "enable_implicit_grant"},
+			{"password", "enable_password_grant"},
+		} {
+			for _, grant := range grants {
+				if grant == g.grant {
+					config = append(config, yaml.MapItem{Key: g.option, Value: true})
+				}
+			}
+		}
+		return kongPlugin{Name: "oauth2", Config: config}, true
+	case "OAuth 1.0", "Digest Authentication":
+		return kongPlugin{}, false
+	}
+	var names []string
+	for _, name := range sortedHeaderNames(ss.DescribedBy.Headers) {
+		names = append(names, string(name))
+	}
+	names = append(names, sortedParamNames(ss.DescribedBy.QueryParameters)...)
+	if len(names) == 0 {
+		return kongPlugin{}, false
+	}
+	return kongPlugin{Name: "key-auth", Config: yaml.MapSlice{{Key: "key_names", Value: names}}}, true
+}
+
+// settingStrings returns a setting of a security scheme as a list of strings
+func settingStrings(v interface{}) []string {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var values []string
+		for _, elem := range val {
+			values = append(values, fmt.Sprint(elem))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(val)}
+	}
+}
+
+type (
+	traefikConfig struct {
+		HTTP traefikHTTP `yaml:"http"`
+	}
+
+	traefikHTTP struct {
+		Routers     map[string]traefikRouter  `yaml:"routers"`
+		Middlewares map[string]yaml.MapSlice  `yaml:"middlewares,omitempty"`
+		Services    map[string]traefikService `yaml:"services"`
+	}
+
+	traefikRouter struct {
+		Rule        string   `yaml:"rule"`
+		Service     string   `yaml:"service"`
+		Middlewares []string `yaml:"middlewares,omitempty"`
+	}
+
+	traefikService struct {
+		LoadBalancer struct {
+			Servers []map[string]string `yaml:"servers"`
+		} `yaml:"loadBalancer"`
+	}
+)
+
+// WriteTraefikConfig writes the dynamic configuration of the Traefik proxy,
+// in YAML: a service for the base URI, with a router by operation and the middlewares of
+//   - the security schemes: `basicAuth` for Basic Authentication and `digestAuth` for
+//     Digest Authentication, their users must be added. The schemes of an operation
+//     are alternatives, so only the first scheme with a middleware is used. It is
+//     an error if none of them has one.
+//   - the rate limit: `rateLimit`, with its window as period
+//   - the CORS policy of the resource: `headers`
+func (apiDef *APIDefinition) WriteTraefikConfig(w io.Writer) error {
+	serviceName := apiDef.gatewayServiceName()
+	var service traefikService
+	service.LoadBalancer.Servers = []map[string]string{{"url": apiDef.gatewayUpstream()}}
+	config := traefikConfig{HTTP: traefikHTTP{
+		Routers:     map[string]traefikRouter{},
+		Middlewares: map[string]yaml.MapSlice{},
+		Services:    map[string]traefikService{serviceName: service},
+	}}
+	middleware := func(name string, m yaml.MapSlice) string {
+		config.HTTP.Middlewares[name] = m
+		return name
+	}
+
+	for _, route := range apiDef.GatewayRoutes() {
+		router := traefikRouter{
+			Rule:    fmt.Sprintf("Method(`%v`) && PathRegexp(`%v`)", route.Method, route.PathRegexp),
+			Service: serviceName,
+		}
+		if len(route.SecuritySchemes) > 0 {
+			auth := ""
+			for _, ss := range route.SecuritySchemes {
+				if m, ok := traefikAuthMiddleware(ss); ok {
+					auth = middleware("auth_"+gatewayName(ss.Name), m)
+					break
+				}
+			}
+			if auth == "" {
+				return unmappedSchemesError(route, "Traefik middleware")
+			}
+			router.Middlewares = append(router.Middlewares, auth)
+		}
+		if rl := route.RateLimit; rl != nil && rl.Limit > 0 {
+			window, err := rateLimitWindow(rl.Window)
+			if err != nil {
+				return fmt.Errorf("%v %v: %v", route.Method, route.Path, err)
+			}
+			router.Middlewares = append(router.Middlewares, middleware("ratelimit_"+route.Name,
+				yaml.MapSlice{{Key: "rateLimit", Value: yaml.MapSlice{
+					{Key: "average", Value: rl.Limit},
+					{Key: "period", Value: window.String()},
+				}}}))
+		}
+		if cors := route.CORS; cors != nil {
+			headers := yaml.MapSlice{
+				{Key: "accessControlAllowOriginList", Value: cors.Origins},
+				{Key: "accessControlAllowMethods", Value: cors.Methods},
+			}
+			if len(cors.Headers) > 0 {
+				headers = append(headers, yaml.MapItem{Key: "accessControlAllowHeaders", Value: cors.Headers})
+			}
+			if len(cors.ExposedHeaders) > 0 {
+				headers = append(headers, yaml.MapItem{Key: "accessControlExposeHeaders", Value: cors.ExposedHeaders})
+			}
+			headers = append(headers, yaml.MapItem{Key: "accessControlAllowCredentials", Value: cors.Credentials})
+			if cors.MaxAge > 0 {
+				headers = append(headers, yaml.MapItem{Key: "accessControlMaxAge", Value: cors.MaxAge})
+			}
+			router.Middlewares = append(router.Middlewares, middleware(routeName("cors", route.Path),
+				yaml.MapSlice{{Key: "headers", Value: headers}}))
+		}
+		config.HTTP.Routers[route.Name] = router
+	}
+	return writeYAML(w, config)
+}
+
+// traefikAuthMiddleware returns the middleware of a security scheme, false if there is none
+func traefikAuthMiddleware(ss SecurityScheme) (yaml.MapSlice, bool) {
+	switch ss.Type {
+	case "Basic Authentication":
+		return yaml.MapSlice{{Key: "basicAuth", Value: yaml.MapSlice{{Key: "realm", Value: ss.Name}}}}, true
+	case "Digest Authentication":
+		return yaml.MapSlice{{Key: "digestAuth", Value: yaml.MapSlice{{Key: "realm", Value: ss.Name}}}}, true
+	}
+	return nil, false
+}
+
+// writeYAML writes a value as a YAML document
+func writeYAML(w io.Writer, v interface{}) error {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,129 @@
+package raml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gigforks/yaml"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGatewayConfig(t *testing.T) {
+	Convey("gateway configuration", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/gateway.raml", apiDef), ShouldBeNil)
+
+		Convey("operations", func() {
+			ops := apiDef.Operations()
+			So(len(ops), ShouldEqual, 5)
+			So(ops[0].Path, ShouldEqual, "/health")
+			So(ops[0].SecuredBy, ShouldBeEmpty)
+			So(ops[1].Path, ShouldEqual, "/users")
+			So(ops[1].Method.Name, ShouldEqual, "GET")
+			So(ops[1].SecuredBy, ShouldResemble, []string{"oauth", "basic"})
+			So(ops[4].Path, ShouldEqual, "/users/{userId}")
+			So(ops[4].SecuredBy, ShouldResemble, []string{"apiKey", "basic"})
+		})
+
+		Convey("routes", func() {
+			routes := apiDef.GatewayRoutes()
+			So(len(routes), ShouldEqual, 5)
+			So(routes[0].Name, ShouldEqual, "get_health")
+			So(routes[0].CORS, ShouldBeNil)
+
+			get := routes[1]
+			So(get.Name, ShouldEqual, "get_users")
+			So(get.PathRegexp, ShouldEqual, "^/users$")
+			So(get.SecuritySchemes[0].Name, ShouldEqual, "oauth")
+			So(get.RateLimit.Limit, ShouldEqual, 300)
+			So(get.CORS, ShouldResemble, &CORSPolicy{
+				Origins:     []string{"https://app.example.com"},
+				Methods:     []string{"GET", "POST", "OPTIONS"},
+				Headers:     []string{"Content-Type", "Authorization"},
+				Credentials: true,
+				MaxAge:      600,
+			})
+			So(routes[4].PathRegexp, ShouldEqual, "^/users/[^/]+$")
+			So(routes[4].CORS, ShouldBeNil)
+		})
+
+		Convey("Kong", func() {
+			var buf bytes.Buffer
+			So(apiDef.WriteKongConfig(&buf), ShouldBeNil)
+
+			var config kongConfig
+			So(yaml.Unmarshal(buf.Bytes(), &config), ShouldBeNil)
+			So(config.FormatVersion, ShouldEqual, "3.0")
+			service := config.Services[0]
+			So(service.Name, ShouldEqual, "gateway_api")
+			So(service.URL, ShouldEqual, "https://api.example.com/api/v1")
+			So(len(service.Routes), ShouldEqual, 5)
+
+			get := service.Routes[1]
+			So(get.Paths, ShouldResemble, []string{"~/users$"})
+			So(get.Methods, ShouldResemble, []string{"GET"})
+			// the first alternative only
+			So(get.Plugins[0].Name, ShouldEqual, "oauth2")
+			So(buf.String(), ShouldContainSubstring, "enable_client_credentials: true")
+			So(get.Plugins[1].Name, ShouldEqual, "rate-limiting")
+			So(get.Plugins[1].Config, ShouldResemble, yaml.MapSlice{{Key: "minute", Value: 60}})
+			So(get.Plugins[2].Name, ShouldEqual, "cors")
+
+			So(service.Routes[2].Plugins[0].Name, ShouldEqual, "basic-auth")
+			So(len(service.Routes[3].Plugins), ShouldEqual, 1)
+			So(service.Routes[4].Paths, ShouldResemble, []string{"~/users/[^/]+$"})
+			So(service.Routes[4].Plugins[0].Name, ShouldEqual, "key-auth")
+			So(service.Routes[4].Plugins[0].Config, ShouldResemble,
+				yaml.MapSlice{{Key: "key_names", Value: []interface{}{"X-API-Key"}}})
+		})
+
+		Convey("Traefik", func() {
+			var buf bytes.Buffer
+			So(apiDef.WriteTraefikConfig(&buf), ShouldBeNil)
+
+			var config traefikConfig
+			So(yaml.Unmarshal(buf.Bytes(), &config), ShouldBeNil)
+			So(config.HTTP.Services["gateway_api"].LoadBalancer.Servers[0]["url"], ShouldEqual,
+				"https://api.example.com/api/v1")
+
+			get := config.HTTP.Routers["get_users"]
+			So(get.Rule, ShouldEqual, "Method(`GET`) && PathRegexp(`^/users$`)")
+			So(get.Service, ShouldEqual, "gateway_api")
+			So(get.Middlewares, ShouldResemble, []string{"auth_basic", "ratelimit_get_users", "cors_users"})
+			So(config.HTTP.Routers["post_users"].Middlewares, ShouldResemble, []string{"auth_basic", "cors_users"})
+			So(config.HTTP.Routers["get_users_userId"].Middlewares, ShouldResemble, []string{"auth_basic"})
+			So(buf.String(), ShouldContainSubstring, "period: 5m0s")
+			So(buf.String(), ShouldContainSubstring, "accessControlMaxAge: 600")
+		})
+
+		Convey("unmapped security schemes", func() {
+			doc := "#%RAML 1.0\ntitle: API\nsecuritySchemes:\n  digest:\n    type: Digest Authentication\n" +
+				"  oauth:\n    type: OAuth 1.0\n/items:\n  get:\n    securedBy: [ oauth, digest ]\n" +
+				"  options:\n    responses:\n      204:\n        headers:\n          Access-Control-Max-Age:\n" +
+				"            example: \"600\"\n"
+			apiDef := new(APIDefinition)
+			So(ParseBytes([]byte(doc), apiDef), ShouldBeNil)
+
+			err := apiDef.WriteKongConfig(new(bytes.Buffer))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "GET /items: no Kong plugin equivalent of the security schemes "+
+				"oauth (OAuth 1.0), digest (Digest Authentication)")
+
+			var buf bytes.Buffer
+			So(apiDef.WriteTraefikConfig(&buf), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, "digestAuth")
+
+			// no allowed origin
+			So(apiDef.GatewayRoutes()[1].CORS, ShouldBeNil)
+			So(buf.String(), ShouldNotContainSubstring, "accessControlAllowOriginList")
+
+			doc = "#%RAML 1.0\ntitle: API\nsecuritySchemes:\n  oauth:\n    type: OAuth 1.0\n" +
+				"securedBy: [ oauth ]\n/items:\n  get:\n    description: items\n"
+			apiDef = new(APIDefinition)
+			So(ParseBytes([]byte(doc), apiDef), ShouldBeNil)
+			err = apiDef.WriteTraefikConfig(new(bytes.Buffer))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "GET /items: no Traefik middleware equivalent of the security schemes oauth (OAuth 1.0)")
+		})
+	})
+}
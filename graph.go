@@ -0,0 +1,321 @@
+package raml
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+// GraphSchemaVersion is the version of the schema of the spec graph,
+// it is incremented on incompatible changes of the schema only.
+const GraphSchemaVersion = 1
+
+// Kinds of the nodes of the spec graph
+const (
+	GraphAPI          = "api"
+	GraphLibrary      = "library"
+	GraphResource     = "resource"
+	GraphMethod       = "method"
+	GraphType         = "type"
+	GraphTrait        = "trait"
+	GraphResourceType = "resourceType"
+)
+
+// Kinds of the edges of the spec graph
+const (
+	// a resource is of a resource type
+	GraphIsOfType = "resourceType"
+
+	// a resource or method applies a trait
+	GraphAppliesTrait = "trait"
+
+	// the request body of a method is of a type
+	GraphRequestBody = "request"
+
+	// a response body of a method is of a type
+	GraphResponseBody = "response"
+
+	// a type inherits from a type
+	GraphInherits = "inherits"
+
+	// a property or the items of a type are of a type
+	GraphReferences = "references"
+)
+
+// Graph is the resource tree, the declarations and their relationships
+// of an API definition, for documentation portals.
+//
+// Its JSON schema is stable, unlike the Go structs of the parser:
+// fields could be added, but are only removed or changed with a new
+// GraphSchemaVersion.
+type Graph struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Nodes         []GraphNode `json:"nodes"`
+	Edges         []GraphEdge `json:"edges"`
+}
+
+// GraphNode is an element of the API definition.
+//
+// The ID is made of the kind and name of the node, e.g. `type:files.Link`
+// or `method:GET /users/{userId}`, so it is the same across exports.
+// The parent of a resource is its parent resource or the API, the parent
+// of a method is its resource, the parent of a declaration is its library or the API.
+type GraphNode struct {
+	ID       string                 `json:"id"`
+	Parent   string                 `json:"parent,omitempty"`
+	Kind     string                 `json:"kind"`
+	Name     string                 `json:"name"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// GraphEdge is a relationship between two nodes
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// graphBuilder builds the graph, the nodes are indexed by ID
+type graphBuilder struct {
+	graph Graph
+	ids   map[string]bool
+	edges map[GraphEdge]bool
+}
+
+// WriteGraph writes the spec graph of the API as JSON, see Graph
+func (apiDef *APIDefinition) WriteGraph(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(apiDef.Graph())
+}
+
+// Graph returns the spec graph of the API.
+// The nodes are in a deterministic order: the API, the libraries
+// followed by their declarations, the declarations of the API,
+// then the resources sorted by URI, each followed by its methods. The edges are sorted by their nodes.
+// Edges to builtin or unknown types are not included.
+func (apiDef *APIDefinition) Graph() Graph {
+	b := &graphBuilder{
+		graph: Graph{SchemaVersion: GraphSchemaVersion},
+		ids:   map[string]bool{},
+		edges: map[GraphEdge]bool{},
+	}
+	apiID := b.addNode(GraphNode{
+		Kind: GraphAPI,
+		Name: apiDef.Title,
+		Metadata: graphMetadata(
+			"version", apiDef.Version,
+			"baseUri", apiDef.BaseURI,
+			"mediaType", apiDef.MediaType,
+		),
+	})
+
+	// declarations, the references are resolved once all of them are added
+	var resolve []func()
+	addDecls := func(prefix, parent string, types map[string]Type, traits map[string]Trait,
+		rts map[string]ResourceType) {
+		for _, name := range sortedKeys(rts) {
+			rt := rts[name]
+			b.addNode(GraphNode{
+				Parent:   parent,
+				Kind:     GraphResourceType,
+				Name:     prefix + name,
+				Metadata: graphMetadata("description", rt.Description),
+			})
+		}
+		for _, name := range sortedKeys(traits) {
+			t := traits[name]
+			b.addNode(GraphNode{
+				Parent:   parent,
+				Kind:     GraphTrait,
+				Name:     prefix + name,
+				Metadata: graphMetadata("displayName", t.DisplayName, "description", t.Description),
+			})
+		}
+		for _, name := range sortedKeys(types) {
+			t := types[name]
+			id := b.addNode(GraphNode{
+				Parent: parent,
+				Kind:   GraphType,
+				Name:   prefix + name,
+				Metadata: graphMetadata(
+					"type", t.TypeString(),
+					"displayName", t.DisplayName,
+					"description", t.Description,
+				),
+			})
+			resolve = append(resolve, func() {
+				if !t.IsJSONType() {
+					b.addTypeEdges(id, GraphInherits, prefix, t.TypeString())
+				}
+				b.addTypeEdges(id, GraphReferences, prefix, itemsTypeString(t.Items))
+				for _, prop := range t.Properties {
+					prop._type = &t
+					b.addTypeEdges(id, GraphReferences, prefix, prop.TypeString())
+					b.addTypeEdges(id, GraphReferences, prefix, prop.Items.Type)
+				}
+			})
+		}
+	}
+
+	var addLibs func(prefix, parent string, libs map[string]*Library)
+	addLibs = func(prefix, parent string, libs map[string]*Library) {
+		for _, name := range sortedKeys(libs) {
+			lib := libs[name]
+			qualified := prefix + name + "."
+			id := b.addNode(GraphNode{
+				Parent:   parent,
+				Kind:     GraphLibrary,
+				Name:     prefix + name,
+				Metadata: graphMetadata("usage", lib.Usage, "file", lib.Filename),
+			})
+			addDecls(qualified, id, lib.Types, lib.Traits, lib.ResourceTypes)
+			addLibs(qualified, id, lib.Libraries)
+		}
+	}
+	addLibs("", apiID, apiDef.Libraries)
+	addDecls("", apiID, apiDef.Types, apiDef.Traits, apiDef.ResourceTypes)
+
+	apiDef.walkResources(func(r *Resource) {
+		parent := apiID
+		if r.Parent != nil {
+			parent = graphID(GraphResource, r.Parent.FullURI())
+		}
+		id := b.addNode(GraphNode{
+			Parent: parent,
+			Kind:   GraphResource,
+			Name:   r.FullURI(),
+			Metadata: graphMetadata(
+				"uri", r.URI,
+				"displayName", r.DisplayName,
+				"description", r.Description,
+			),
+		})
+		if r.Type != nil {
+			b.addEdge(id, graphID(GraphResourceType, r.Type.Name), GraphIsOfType)
+		}
+		for _, t := range r.Is {
+			b.addEdge(id, graphID(GraphTrait, t.Name), GraphAppliesTrait)
+		}
+
+		for _, m := range r.methods() {
+			methodID := b.addNode(GraphNode{
+				Parent: id,
+				Kind:   GraphMethod,
+				Name:   m.Name + " " + r.FullURI(),
+				Metadata: graphMetadata(
+					"method", m.Name,
+					"displayName", m.DisplayName,
+					"description", m.Description,
+				),
+			})
+			for _, t := range m.Is {
+				b.addEdge(methodID, graphID(GraphTrait, t.Name), GraphAppliesTrait)
+			}
+			b.addBodyEdges(methodID, GraphRequestBody, m.Bodies)
+			for _, resp := range m.Responses {
+				b.addBodyEdges(methodID, GraphResponseBody, resp.Bodies)
+			}
+		}
+	})
+
+	for _, fn := range resolve {
+		fn()
+	}
+	for e := range b.edges {
+		if b.ids[e.To] {
+			b.graph.Edges = append(b.graph.Edges, e)
+		}
+	}
+	sort.Slice(b.graph.Edges, func(i, j int) bool {
+		ei, ej := b.graph.Edges[i], b.graph.Edges[j]
+		if ei.From != ej.From {
+			return ei.From < ej.From
+		}
+		if ei.To != ej.To {
+			return ei.To < ej.To
+		}
+		return ei.Kind < ej.Kind
+	})
+	return b.graph
+}
+
+// addNode adds a node, its ID is made of its kind and name.
+// A node is added once, e.g. the traits of the libraries are
+// also in the traits of the API.
+func (b *graphBuilder) addNode(n GraphNode) string {
+	n.ID = graphID(n.Kind, n.Name)
+	if n.Kind == GraphAPI {
+		n.ID = GraphAPI
+	}
+	if b.ids[n.ID] {
+		return n.ID
+	}
+	b.ids[n.ID] = true
+	b.graph.Nodes = append(b.graph.Nodes, n)
+	return n.ID
+}
+
+// addEdge adds an edge, the edges to unknown nodes are removed
+// when the graph is complete
+func (b *graphBuilder) addEdge(from, to, kind string) {
+	b.edges[GraphEdge{From: from, To: to, Kind: kind}] = true
+}
+
+// addTypeEdges adds edges to the types referenced by a type expression.
+// A type of a library could reference the types of the same library
+// unqualified, prefix is the qualifier of the library.
+func (b *graphBuilder) addTypeEdges(from, kind, prefix, expr string) {
+	for _, name := range typeExprRefs(expr) {
+		to := graphID(GraphType, prefix+name)
+		if !b.ids[to] {
+			to = graphID(GraphType, name)
+		}
+		b.addEdge(from, to, kind)
+	}
+}
+
+// addBodyEdges adds edges to the types of the bodies
+func (b *graphBuilder) addBodyEdges(from, kind string, bodies Bodies) {
+	add := func(body Body) {
+		b.addTypeEdges(from, kind, "", body.TypeString())
+		b.addTypeEdges(from, kind, "", itemsTypeString(body.Items))
+	}
+	if bodies.Default != nil {
+		add(*bodies.Default)
+	}
+	for _, body := range bodies.ForMIMEType {
+		add(body)
+	}
+}
+
+// sortedKeys returns the keys of a map, sorted
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// graphID returns the ID of a node
+func graphID(kind, name string) string {
+	return kind + ":" + strings.TrimSpace(name)
+}
+
+// graphMetadata returns the metadata of a node from key value pairs,
+// the empty values are omitted
+func graphMetadata(kv ...string) map[string]interface{} {
+	md := map[string]interface{}{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i+1] != "" {
+			md[kv[i]] = kv[i+1]
+		}
+	}
+	if len(md) == 0 {
+		return nil
+	}
+	return md
+}
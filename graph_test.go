@@ -0,0 +1,87 @@
+package raml
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGraph(t *testing.T) {
+	Convey("spec graph", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/graph.raml", apiDef), ShouldBeNil)
+
+		g := apiDef.Graph()
+		So(g.SchemaVersion, ShouldEqual, GraphSchemaVersion)
+
+		nodes := map[string]GraphNode{}
+		for _, n := range g.Nodes {
+			So(nodes, ShouldNotContainKey, n.ID)
+			nodes[n.ID] = n
+		}
+
+		Convey("nodes", func() {
+			So(g.Nodes[0].ID, ShouldEqual, "api")
+			So(g.Nodes[0].Name, ShouldEqual, "Graph API")
+			So(g.Nodes[0].Metadata["version"], ShouldEqual, "v1")
+
+			So(nodes["type:User"].Parent, ShouldEqual, "api")
+			So(nodes["type:User"].Metadata["description"], ShouldEqual, "A user of the API")
+			So(nodes["trait:paged"].Kind, ShouldEqual, GraphTrait)
+
+			// library declarations
+			So(nodes["library:files"].Metadata["file"], ShouldEqual, "libraries/files.raml")
+			So(nodes["trait:files.drm"].Parent, ShouldEqual, "library:files")
+			So(nodes["resourceType:files.link"].Parent, ShouldEqual, "library:files")
+			So(nodes["library:files.file-type"].Parent, ShouldEqual, "library:files")
+			So(nodes["type:files.file-type.File"].Parent, ShouldEqual, "library:files.file-type")
+
+			// resource tree
+			So(nodes["resource:/users"].Parent, ShouldEqual, "api")
+			So(nodes["resource:/users/{userId}"].Parent, ShouldEqual, "resource:/users")
+			So(nodes["resource:/users/{userId}"].Metadata["uri"], ShouldEqual, "/{userId}")
+			So(nodes["method:GET /users/{userId}"].Parent, ShouldEqual, "resource:/users/{userId}")
+			So(nodes["method:GET /users/{userId}"].Kind, ShouldEqual, GraphMethod)
+
+			// inherited from the resource type
+			So(nodes, ShouldContainKey, "method:POST /users/{userId}/links")
+		})
+
+		Convey("edges", func() {
+			So(g.Edges, ShouldContain, GraphEdge{From: "type:User", To: "type:Entity", Kind: GraphInherits})
+			So(g.Edges, ShouldContain, GraphEdge{From: "type:User", To: "type:files.Link", Kind: GraphReferences})
+			So(g.Edges, ShouldContain, GraphEdge{From: "resource:/users", To: "trait:paged", Kind: GraphAppliesTrait})
+			So(g.Edges, ShouldContain, GraphEdge{From: "resource:/users/{userId}/links",
+				To: "resourceType:files.link", Kind: GraphIsOfType})
+			So(g.Edges, ShouldContain, GraphEdge{From: "method:POST /users", To: "type:User", Kind: GraphRequestBody})
+			So(g.Edges, ShouldContain, GraphEdge{From: "method:GET /users", To: "type:User", Kind: GraphResponseBody})
+			So(g.Edges, ShouldContain, GraphEdge{From: "method:POST /users/{userId}/links",
+				To: "type:files.Link", Kind: GraphRequestBody})
+
+			// builtin types
+			for _, e := range g.Edges {
+				So(nodes, ShouldContainKey, e.To)
+			}
+		})
+
+		Convey("JSON export", func() {
+			var buf bytes.Buffer
+			So(apiDef.WriteGraph(&buf), ShouldBeNil)
+
+			var doc map[string]interface{}
+			So(json.Unmarshal(buf.Bytes(), &doc), ShouldBeNil)
+			So(doc["schemaVersion"], ShouldEqual, 1)
+			node := doc["nodes"].([]interface{})[1].(map[string]interface{})
+			So(node, ShouldContainKey, "id")
+			So(node, ShouldContainKey, "parent")
+			So(node, ShouldContainKey, "kind")
+
+			// the export is deterministic
+			var again bytes.Buffer
+			So(apiDef.WriteGraph(&again), ShouldBeNil)
+			So(again.String(), ShouldEqual, buf.String())
+		})
+	})
+}
@@ -0,0 +1,193 @@
+package raml
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// types of the HAR 1.2 format, only the fields needed by the generated entries
+type (
+	harLog struct {
+		Log harContent `json:"log"`
+	}
+
+	harContent struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	}
+
+	harCreator struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	harEntry struct {
+		StartedDateTime string                 `json:"startedDateTime"`
+		Time            int                    `json:"time"`
+		Request         harRequest             `json:"request"`
+		Response        harResponse            `json:"response"`
+		Cache           map[string]interface{} `json:"cache"`
+		Timings         harTimings             `json:"timings"`
+		Comment         string                 `json:"comment,omitempty"`
+	}
+
+	harRequest struct {
+		Method      string       `json:"method"`
+		URL         string       `json:"url"`
+		HTTPVersion string       `json:"httpVersion"`
+		Cookies     []harPair    `json:"cookies"`
+		Headers     []harPair    `json:"headers"`
+		QueryString []harPair    `json:"queryString"`
+		PostData    *harPostData `json:"postData,omitempty"`
+		HeadersSize int          `json:"headersSize"`
+		BodySize    int          `json:"bodySize"`
+	}
+
+	harResponse struct {
+		Status      int       `json:"status"`
+		StatusText  string    `json:"statusText"`
+		HTTPVersion string    `json:"httpVersion"`
+		Cookies     []harPair `json:"cookies"`
+		Headers     []harPair `json:"headers"`
+		Content     harBody   `json:"content"`
+		RedirectURL string    `json:"redirectURL"`
+		HeadersSize int       `json:"headersSize"`
+		BodySize    int       `json:"bodySize"`
+	}
+
+	harPair struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	harPostData struct {
+		MimeType string    `json:"mimeType"`
+		Params   []harPair `json:"params"`
+		Text     string    `json:"text"`
+	}
+
+	harBody struct {
+		Size     int    `json:"size"`
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text,omitempty"`
+	}
+
+	harTimings struct {
+		Send    int `json:"send"`
+		Wait    int `json:"wait"`
+		Receive int `json:"receive"`
+	}
+)
+
+// WriteHAR writes a HAR 1.2 file with one synthetic request/response entry per operation,
+// built from the declared examples.
+//
+// The response of an entry is the first declared success response,
+// or the first declared response if the operation has no success response.
+// Values which are not known from the API definition, e.g. URI parameters without example,
+// are written as their name and the credentials as `<name>` placeholders.
+func (apiDef *APIDefinition) WriteHAR(w io.Writer) error {
+	har := harLog{Log: harContent{
+		Version: "1.2",
+		Creator: harCreator{Name: "raml", Version: apiDef.Version},
+		Entries: []harEntry{},
+	}}
+	started := time.Now().UTC().Format(time.RFC3339)
+
+	apiDef.walkResources(func(r *Resource) {
+		for _, m := range r.methods() {
+			entry := harEntry{
+				StartedDateTime: started,
+				Request:         apiDef.harRequest(apiDef.exampleRequest(r, m)),
+				Response:        apiDef.harResponse(m),
+				Cache:           map[string]interface{}{},
+				Comment:         m.Description,
+			}
+			har.Log.Entries = append(har.Log.Entries, entry)
+		}
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(har)
+}
+
+func (apiDef *APIDefinition) harRequest(req exampleRequest) harRequest {
+	hr := harRequest{
+		Method:      req.Method,
+		URL:         req.resolveURL(harVar),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harPair{},
+		Headers:     []harPair{},
+		QueryString: []harPair{},
+		HeadersSize: -1,
+	}
+	for _, q := range req.Query {
+		hr.QueryString = append(hr.QueryString, harPair{Name: q.Name, Value: harVar(q)})
+	}
+	switch req.Auth {
+	case authBasic:
+		hr.Headers = append(hr.Headers, harPair{Name: "Authorization", Value: "Basic <credentials>"})
+	case authDigest:
+		hr.Headers = append(hr.Headers, harPair{Name: "Authorization", Value: "Digest <credentials>"})
+	}
+	for _, h := range req.Headers {
+		hr.Headers = append(hr.Headers, harPair{Name: h.Name, Value: h.Prefix + placeholderVar(h)})
+	}
+	if req.Body != "" {
+		hr.Headers = append(hr.Headers, harPair{Name: "Content-Type", Value: req.ContentType})
+		hr.PostData = &harPostData{MimeType: req.ContentType, Params: []harPair{}, Text: req.Body}
+	}
+	hr.BodySize = len(req.Body)
+	return hr
+}
+
+func (apiDef *APIDefinition) harResponse(m *Method) harResponse {
+	hr := harResponse{
+		Status:      http.StatusOK,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harPair{},
+		Headers:     []harPair{},
+		HeadersSize: -1,
+	}
+
+	resps := m.SuccessResponses()
+	if len(resps) == 0 {
+		resps = m.responsesOf(func(int) bool { return true })
+	}
+	if len(resps) > 0 {
+		resp := resps[0]
+		var ok bool
+		if hr.Status, ok = resp.HTTPCode.Int(); !ok {
+			// range of codes, e.g. 2XX
+			class, _ := resp.HTTPCode.class()
+			hr.Status = class * 100
+		}
+		for _, name := range sortedHeaderNames(resp.Headers) {
+			p := paramExample(string(name), NamedParameter(resp.Headers[name]))
+			if !p.Variable {
+				hr.Headers = append(hr.Headers, harPair{Name: p.Name, Value: p.Value})
+			}
+		}
+		hr.Content.MimeType, hr.Content.Text = apiDef.bodyExample(&resp.Bodies)
+	}
+	hr.StatusText = http.StatusText(hr.Status)
+	if hr.Content.Text != "" {
+		hr.Headers = append(hr.Headers, harPair{Name: "Content-Type", Value: hr.Content.MimeType})
+	}
+	hr.Content.Size = len(hr.Content.Text)
+	hr.BodySize = hr.Content.Size
+	if hr.Content.MimeType == "" {
+		hr.Content.MimeType = "x-unknown"
+	}
+	return hr
+}
+
+// harVar returns the value of the parameter, the parameter name if the value is not known
+func harVar(p requestParam) string {
+	return p.Value
+}
@@ -0,0 +1,53 @@
+package raml
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteHAR(t *testing.T) {
+	Convey("HAR generation", t, func() {
+		apiDef := new(APIDefinition)
+		err := ParseFile("./samples/commands.raml", apiDef)
+		So(err, ShouldBeNil)
+
+		var buf bytes.Buffer
+		err = apiDef.WriteHAR(&buf)
+		So(err, ShouldBeNil)
+
+		var har harLog
+		err = json.Unmarshal(buf.Bytes(), &har)
+		So(err, ShouldBeNil)
+		So(har.Log.Version, ShouldEqual, "1.2")
+
+		entries := har.Log.Entries
+		So(len(entries), ShouldEqual, 3)
+
+		Convey("requests", func() {
+			So(entries[0].Request.URL, ShouldEqual, "https://api.example.com/api/v1/users?page=2")
+			So(entries[0].Request.QueryString, ShouldResemble, []harPair{{Name: "page", Value: "2"}})
+			So(entries[0].Request.Headers, ShouldResemble, []harPair{{Name: "Authorization", Value: "Bearer <access_token>"}})
+
+			So(entries[1].Request.Method, ShouldEqual, "POST")
+			So(entries[1].Request.PostData.MimeType, ShouldEqual, "application/json")
+			So(entries[1].Request.PostData.Text, ShouldContainSubstring, `"age": 30`)
+
+			So(entries[2].Request.URL, ShouldEqual, "https://api.example.com/api/v1/users/userId")
+		})
+
+		Convey("responses", func() {
+			So(entries[0].Response.Status, ShouldEqual, 200)
+			So(entries[0].Response.Content.Size, ShouldEqual, 0)
+
+			resp := entries[2].Response
+			So(resp.Status, ShouldEqual, 200)
+			So(resp.StatusText, ShouldEqual, "OK")
+			So(resp.Content.MimeType, ShouldEqual, "application/json")
+			So(resp.Content.Text, ShouldContainSubstring, `"name": "John's"`)
+			So(resp.Content.Size, ShouldEqual, len(resp.Content.Text))
+		})
+	})
+}
@@ -0,0 +1,40 @@
+package raml
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+// LibraryFragment is the kind of the libraries, `#%RAML 1.0 Library`
+const LibraryFragment = "Library"
+
+// utf8BOM is the byte order mark some editors write at the start of the files
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// DocumentHeader is the first line of a RAML document, e.g. `#%RAML 1.0 Trait`
+type DocumentHeader struct {
+	// version of RAML, e.g. `1.0`
+	Version string
+
+	// kind of the document, e.g. TraitFragment, LibraryFragment or OverlayDocument,
+	// empty for an API definition
+	Kind string
+}
+
+// ParseHeader returns the header of a RAML document, so callers could branch
+// on the kind of document before parsing it, e.g. into a Library or with ParseTrait.
+// A byte order mark, a CRLF line ending and extra whitespace are tolerated.
+func ParseHeader(contents []byte) (DocumentHeader, error) {
+	line, _ := splitHeader(bytes.TrimPrefix(contents, utf8BOM))
+	return parseHeaderLine(line)
+}
+
+// parseHeaderLine parses the first line of a document, e.g. `#%RAML 1.0 Trait`
+func parseHeaderLine(line string) (DocumentHeader, error) {
+	fields := strings.Fields(strings.TrimPrefix(line, string(utf8BOM)))
+	if len(fields) < 2 || fields[0] != "#%RAML" {
+		return DocumentHeader{}, errors.New("input file is not a RAML file. Make sure the file starts with #%RAML 1.0")
+	}
+	return DocumentHeader{Version: fields[1], Kind: strings.Join(fields[2:], " ")}, nil
+}
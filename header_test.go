@@ -0,0 +1,59 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDocumentHeader(t *testing.T) {
+	Convey("document headers", t, func() {
+		Convey("the version and the kind of document", func() {
+			h, err := ParseHeader([]byte("#%RAML 1.0 Trait\nusage: paging\n"))
+			So(err, ShouldBeNil)
+			So(h, ShouldResemble, DocumentHeader{Version: "1.0", Kind: TraitFragment})
+
+			h, err = ParseHeader([]byte("#%RAML 1.0\ntitle: API\n"))
+			So(err, ShouldBeNil)
+			So(h, ShouldResemble, DocumentHeader{Version: "1.0"})
+
+			h, err = ParseHeader([]byte("#%RAML 0.8"))
+			So(err, ShouldBeNil)
+			So(h.Version, ShouldEqual, "0.8")
+		})
+
+		Convey("a byte order mark, CRLF and extra whitespace are tolerated", func() {
+			h, err := ParseHeader([]byte("\xef\xbb\xbf#%RAML  1.0   AnnotationTypeDeclaration \r\ntype: string\r\n"))
+			So(err, ShouldBeNil)
+			So(h, ShouldResemble, DocumentHeader{Version: "1.0", Kind: AnnotationTypeFragment})
+
+			apiDef := new(APIDefinition)
+			So(ParseBytes([]byte("\xef\xbb\xbf#%RAML 1.0 \r\ntitle: API\r\n"), apiDef), ShouldBeNil)
+			So(apiDef.Title, ShouldEqual, "API")
+			So(apiDef.RAMLVersion, ShouldEqual, "1.0")
+			So(apiDef.FragmentKind, ShouldEqual, "")
+
+			trait := new(traitFragment)
+			So(ParseBytes([]byte("#%RAML 1.0   Trait\r\nusage: paging\r\n"), trait), ShouldBeNil)
+			So(trait.Trait.Usage, ShouldEqual, "paging")
+		})
+
+		Convey("not a RAML document", func() {
+			_, err := ParseHeader([]byte("title: API\n"))
+			So(err, ShouldNotBeNil)
+			So(ParseBytes([]byte("#%RAML 0.8\ntitle: API\n"), new(APIDefinition)), ShouldNotBeNil)
+		})
+
+		Convey("the kind of the parsed documents", func() {
+			lib := new(Library)
+			So(ParseFile("./samples/libraries/files.raml", lib), ShouldBeNil)
+			So(lib.RAMLVersion, ShouldEqual, "1.0")
+			So(lib.FragmentKind, ShouldEqual, LibraryFragment)
+
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/overlays/admin.raml", apiDef), ShouldBeNil)
+			So(apiDef.RAMLVersion, ShouldEqual, "1.0")
+			So(apiDef.FragmentKind, ShouldEqual, ExtensionDocument)
+		})
+	})
+}
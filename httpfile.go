@@ -0,0 +1,69 @@
+package raml
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteHTTPFile writes all operations of the API as a `.http` file,
+// as used by the VS Code REST Client and JetBrains HTTP Client.
+//
+// The base URI and all parameters are declared as file variables,
+// initialized with their example or default value, so they could be
+// changed in a single place. Credentials are declared as empty variables.
+func (apiDef *APIDefinition) WriteHTTPFile(w io.Writer) error {
+	hf := newExampleVars()
+
+	baseParams := hf.toVars(apiDef.baseURIParams())
+	base := exampleRequest{BaseURI: apiDef.BaseURI, BaseURIParams: baseParams}
+
+	var requests bytes.Buffer
+	apiDef.walkResources(func(r *Resource) {
+		for _, m := range r.methods() {
+			writeHTTPRequest(&requests, hf, apiDef.exampleRequest(r, m))
+		}
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, name := range hf.vars {
+		fmt.Fprintf(bw, "%v\n", strings.TrimSpace("@"+name+" = "+hf.values[name]))
+	}
+	fmt.Fprintf(bw, "@baseUri = %v\n", strings.TrimSuffix(base.resolveBase(httpVar), "/"))
+	bw.Write(requests.Bytes())
+	return bw.Flush()
+}
+
+func writeHTTPRequest(w io.Writer, hf *exampleVars, req exampleRequest) {
+	req.URIParams = hf.toVars(req.URIParams)
+	req.Query = hf.toVars(req.Query)
+
+	fmt.Fprintf(w, "\n### %v %v\n", req.Method, req.Path)
+	fmt.Fprintf(w, "%v {{baseUri}}%v\n", req.Method, req.resolvePath(httpVar))
+
+	switch req.Auth {
+	case authBasic, authDigest:
+		scheme := "Basic"
+		if req.Auth == authDigest {
+			scheme = "Digest"
+		}
+		fmt.Fprintf(w, "Authorization: %v {{%v}} {{%v}}\n", scheme, hf.declare("username", ""), hf.declare("password", ""))
+	}
+	for _, h := range req.Headers {
+		value := h.Value
+		if h.Variable {
+			value = "{{" + hf.declare(h.Value, "") + "}}"
+		}
+		fmt.Fprintf(w, "%v: %v%v\n", h.Name, h.Prefix, value)
+	}
+	if req.Body != "" {
+		fmt.Fprintf(w, "Content-Type: %v\n\n%v\n", req.ContentType, strings.TrimSuffix(req.Body, "\n"))
+	}
+}
+
+// httpVar returns reference to the variable of the parameter
+func httpVar(p requestParam) string {
+	return "{{" + p.Value + "}}"
+}
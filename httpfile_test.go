@@ -0,0 +1,48 @@
+package raml
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteHTTPFile(t *testing.T) {
+	Convey(".http file export", t, func() {
+		apiDef := new(APIDefinition)
+		err := ParseFile("./samples/commands.raml", apiDef)
+		So(err, ShouldBeNil)
+
+		var buf bytes.Buffer
+		err = apiDef.WriteHTTPFile(&buf)
+		So(err, ShouldBeNil)
+		So(buf.String(), ShouldEqual, `@host = api.example.com
+@version = v1
+@page = 2
+@access_token =
+@userId =
+@username =
+@password =
+@baseUri = https://{{host}}/api/{{version}}
+
+### GET /users
+GET {{baseUri}}/users?page={{page}}
+Authorization: Bearer {{access_token}}
+
+### POST /users
+POST {{baseUri}}/users
+Authorization: Bearer {{access_token}}
+Content-Type: application/json
+
+{
+  "age": 30,
+  "name": "John's"
+}
+
+### GET /users/{userId}
+GET {{baseUri}}/users/{{userId}}
+Authorization: Basic {{username}} {{password}}
+X-Request-Id: abc 123
+`)
+	})
+}
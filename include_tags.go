@@ -0,0 +1,204 @@
+package raml
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const includeTagName = "!include"
+
+// blockScalarRe matches the indicator of a block scalar at the end of a line, e.g. `|` or `>-`
+var blockScalarRe = regexp.MustCompile(`[|>][1-9+-]{0,2}$`)
+
+// includeTag is an `!include` tag of a line
+type includeTag struct {
+	// byte offsets of the tag and of the end of its path in the line
+	start, end int
+
+	// path of the included file, as written
+	path string
+
+	// true if the tag is in a flow collection, e.g. `[ !include a.raml ]`
+	flow bool
+}
+
+// includeScanner finds the `!include` tags of a document line by line.
+// It follows the YAML structure of the lines: the text of the block scalars,
+// of the quoted and plain scalars, also over several lines, and of the comments
+// is not a tag, and the tags of the flow collections end at the next `,`, `]` or `}`.
+type includeScanner struct {
+	// indentation of the line starting the block scalar being scanned, -1 if none
+	blockIndent int
+
+	// indentation of the line starting the plain scalar being scanned, -1 if none,
+	// its more indented lines are its text, e.g.
+	//
+	//	description: first line
+	//	  !include notes.md
+	plainIndent int
+
+	// quote of the quoted scalar left open by the previous lines, 0 if none
+	quote byte
+
+	// depth of the flow collections left open by the previous lines
+	flowDepth int
+}
+
+func newIncludeScanner() *includeScanner {
+	return &includeScanner{blockIndent: -1, plainIndent: -1}
+}
+
+// scan returns the `!include` tags of the next line of the document
+func (s *includeScanner) scan(line string) []includeTag {
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	if s.blockIndent >= 0 {
+		if strings.TrimSpace(line) == "" || indent > s.blockIndent {
+			return nil
+		}
+		s.blockIndent = -1
+	}
+	if s.plainIndent >= 0 {
+		if strings.TrimSpace(line) == "" || indent > s.plainIndent {
+			return nil
+		}
+		s.plainIndent = -1
+	}
+
+	var tags []includeTag
+	// offset of the last plain scalar started in the block context, -1 if none
+	plain := -1
+	codeEnd := len(line)
+scan:
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case s.quote == '"':
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				s.quote = 0
+			}
+		case s.quote == '\'':
+			if c == '\'' && i+1 < len(line) && line[i+1] == '\'' {
+				i++
+			} else if c == '\'' {
+				s.quote = 0
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			codeEnd = i
+			break scan
+		case (c == '"' || c == '\'') && startsScalar(line, i):
+			s.quote, plain = c, -1
+		case (c == '[' || c == '{') && (s.flowDepth > 0 || startsScalar(line, i)):
+			s.flowDepth, plain = s.flowDepth+1, -1
+		case c == ']' || c == '}':
+			if s.flowDepth > 0 {
+				s.flowDepth--
+			}
+		case strings.HasPrefix(line[i:], includeTagName) && startsScalar(line, i):
+			tag, ok := s.tagAt(line, i)
+			if !ok {
+				continue
+			}
+			tags = append(tags, tag)
+			if !tag.flow {
+				// the rest of the line is the path or a comment
+				return tags
+			}
+			i = tag.end - 1
+		case c != ' ' && c != '\t' && s.flowDepth == 0 && startsScalar(line, i):
+			plain = i
+		}
+	}
+
+	code := strings.TrimRight(line[:codeEnd], " \t")
+	if s.quote == 0 && s.flowDepth == 0 {
+		if loc := blockScalarRe.FindStringIndex(code); loc != nil && startsScalar(code, loc[0]) {
+			s.blockIndent = indent
+		} else if plain >= 0 && plain < len(code) && isPlainValue(code[plain:]) {
+			s.plainIndent = indent
+		}
+	}
+	return tags
+}
+
+// isPlainValue returns true if the text of a line from the start of its last
+// plain scalar is a value, which could go on over the next lines,
+// and not a key, e.g. `key:`, or an indicator, e.g. `-`
+func isPlainValue(text string) bool {
+	switch text {
+	case "-", "?", ":":
+		return false
+	}
+	return !strings.HasSuffix(text, ":") && !strings.Contains(text, ": ")
+}
+
+// tagAt returns the `!include` tag at the offset i of a line,
+// false if it is not followed by a path, e.g. `!includes`
+func (s *includeScanner) tagAt(line string, i int) (includeTag, bool) {
+	start := i + len(includeTagName)
+	if start >= len(line) || (line[start] != ' ' && line[start] != '\t') {
+		return includeTag{}, false
+	}
+	for start < len(line) && (line[start] == ' ' || line[start] == '\t') {
+		start++
+	}
+	end := start
+	for ; end < len(line); end++ {
+		c := line[end]
+		if c == '#' && (line[end-1] == ' ' || line[end-1] == '\t') {
+			break
+		}
+		if s.flowDepth > 0 && (c == ',' || c == ']' || c == '}') {
+			break
+		}
+	}
+	p := strings.TrimSpace(line[start:end])
+	if p == "" {
+		return includeTag{}, false
+	}
+	return includeTag{start: i, end: end, path: p, flow: s.flowDepth > 0}, true
+}
+
+// startsScalar returns true if a scalar could start at the offset i of a line:
+// at the beginning of the line, after `: `, `- ` or `? `, or in a flow collection
+// after `[`, `{` or `,`
+func startsScalar(line string, i int) bool {
+	before := strings.TrimRight(line[:i], " \t")
+	if before == "" {
+		return true
+	}
+	switch before[len(before)-1] {
+	case '[', '{', ',':
+		return true
+	case ':', '-', '?':
+		return len(before) < i
+	}
+	return false
+}
+
+// isJSONFile returns true if the included file is a JSON document, e.g. a JSON schema.
+// Its text is included as a string, which keeps it as written.
+func isJSONFile(fileName string) bool {
+	return strings.EqualFold(path.Ext(fileName), ".json")
+}
+
+// flowInclude returns the content of a file included in a flow collection as a flow
+// scalar or collection: the RAML and YAML documents as JSON, the others as a string
+func flowInclude(fileName string, contents []byte) (string, error) {
+	var value interface{} = string(contents)
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".raml", ".yaml", ".yml":
+		if err := unmarshalYAML(contents, &value); err != nil {
+			return "", err
+		}
+		value = jsonValue(value)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,177 @@
+package raml
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestIncludeTags(t *testing.T) {
+	Convey("include tags", t, func() {
+		Convey("scanner", func() {
+			s := newIncludeScanner()
+			So(s.scan("description: !include usage.md # the usage"), ShouldResemble,
+				[]includeTag{{start: 13, end: 31, path: "usage.md"}})
+			So(s.scan("# see !include usage.md"), ShouldBeEmpty)
+			So(s.scan(`title: "!include usage.md"`), ShouldBeEmpty)
+			So(s.scan("title: Users !include usage.md"), ShouldBeEmpty)
+			So(s.scan("type: !includes"), ShouldBeEmpty)
+
+			So(s.scan("items: [ !include a.raml, b, !include c.json ]"), ShouldResemble, []includeTag{
+				{start: 9, end: 24, path: "a.raml", flow: true},
+				{start: 29, end: 45, path: "c.json", flow: true},
+			})
+
+			// the text of the block scalars
+			So(s.scan("description: |"), ShouldBeEmpty)
+			So(s.scan("  type: !include user.json"), ShouldBeEmpty)
+			So(s.scan(""), ShouldBeEmpty)
+			So(s.scan("  !include user.json"), ShouldBeEmpty)
+			So(s.scan("type: !include user.json"), ShouldHaveLength, 1)
+
+			// a flow collection over several lines
+			So(s.scan("is: ["), ShouldBeEmpty)
+			So(s.scan("  !include paged.raml ]"), ShouldResemble,
+				[]includeTag{{start: 2, end: 22, path: "paged.raml", flow: true}})
+			So(s.scan("body: !include body.raml"), ShouldResemble,
+				[]includeTag{{start: 6, end: 24, path: "body.raml"}})
+
+			// the text of the quoted and plain scalars over several lines
+			So(s.scan(`description: "first line`), ShouldBeEmpty)
+			So(s.scan(`  !include notes.md and more"`), ShouldBeEmpty)
+			So(s.scan("description: first line"), ShouldBeEmpty)
+			So(s.scan(""), ShouldBeEmpty)
+			So(s.scan("  !include notes.md"), ShouldBeEmpty)
+			So(s.scan("- first item"), ShouldBeEmpty)
+			So(s.scan("  !include notes.md"), ShouldBeEmpty)
+			So(s.scan("notes:"), ShouldBeEmpty)
+			So(s.scan("  !include notes.md"), ShouldHaveLength, 1)
+			So(s.scan("- !include notes.md"), ShouldHaveLength, 1)
+		})
+
+		Convey("parsing", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/included/flow.raml", apiDef), ShouldBeNil)
+			So(apiDef.Title, ShouldEqual, "Flow includes")
+			So(apiDef.Annotations["(owners)"], ShouldResemble, []interface{}{
+				map[interface{}]interface{}{"name": "Notes team", "email": "notes@example.com"},
+				map[interface{}]interface{}{"name": "Platform team"},
+			})
+			So(apiDef.Documentation, ShouldResemble, []Documentation{{Title: "Usage", Content: "Notes of the files.\n"}})
+			So(apiDef.Types["User"].Type, ShouldNotBeEmpty)
+
+			users := apiDef.Resources["/users"]
+			So(users.DisplayName, ShouldEqual, "!include is a tag, not text")
+			So(users.Description, ShouldEndWith, "e.g. `description: !include usage.md`\n")
+			So(users.Get.Description, ShouldEqual, "The users, !include user.json")
+			So(users.Post.Description, ShouldEqual, "The user's !include list")
+			So(users.Put.Description, ShouldEqual, "The user, !include user.json and more")
+			So(users.Delete.Description, ShouldEqual, "Deletes the user, !include user.json")
+
+			var paths []string
+			for _, f := range apiDef.ListIncludedFiles() {
+				paths = append(paths, f.Path)
+			}
+			So(paths, ShouldResemble, []string{"samples/included/flow.raml", "owners.yaml", "usage.md", "user.json"})
+		})
+	})
+}
+
+func TestJSONIncludes(t *testing.T) {
+	Convey("JSON includes", t, func() {
+		schema, err := ioutil.ReadFile("./samples/included/user.json")
+		So(err, ShouldBeNil)
+		example, err := ioutil.ReadFile("./samples/included/user_example.json")
+		So(err, ShouldBeNil)
+
+		apiDef := &APIDefinition{KeepRaw: true}
+		So(ParseFile("./samples/included/json.raml", apiDef), ShouldBeNil)
+		So(apiDef.Schemas, ShouldResemble, SchemaDeclarations{{"UserSchema": string(schema)}})
+		So(apiDef.Types["UserDocument"].Properties, ShouldContainKey, "name")
+		So(apiDef.Types["User"].Example, ShouldEqual, string(example))
+		So(apiDef.Types["User"].AllExamples()[0].Value, ShouldEqual, string(example))
+
+		body := apiDef.Resources["/users"].Get.Responses["200"].Bodies.ForMIMEType["application/json"]
+		So(body.Schema, ShouldEqual, string(schema))
+		So(body.Example, ShouldEqual, string(example))
+
+		Convey("survive round-tripping", func() {
+			var buf bytes.Buffer
+			So(apiDef.WriteRAML(&buf), ShouldBeNil)
+			written := new(APIDefinition)
+			So(ParseBytes(buf.Bytes(), written), ShouldBeNil)
+			So(written.Schemas, ShouldResemble, apiDef.Schemas)
+			So(written.Types["User"].Example, ShouldEqual, string(example))
+		})
+
+		Convey("invalid examples", func() {
+			So(validateTypeValue(`{"age": "old"}`, apiDef.Types["User"], apiDef, 0), ShouldNotBeNil)
+			So(validateTypeValue(`{"name": "Ada"}`, apiDef.Types["User"], apiDef, 0), ShouldBeNil)
+			So(validateValue(`[1, 2]`, "integer[]", "", apiDef, 0), ShouldBeNil)
+		})
+	})
+}
+
+func TestBinaryIncludes(t *testing.T) {
+	Convey("binary includes", t, func() {
+		logo, err := ioutil.ReadFile("./samples/binary/logo.png")
+		So(err, ShouldBeNil)
+		photo, err := ioutil.ReadFile("./samples/binary/photo.bin")
+		So(err, ShouldBeNil)
+
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/binary/api.raml", apiDef, WithStrictMode()), ShouldBeNil)
+		So(apiDef.Warnings, ShouldBeEmpty)
+
+		png := apiDef.Resources["/logo"].Get.Responses["200"].Bodies.ForMIMEType["image/png"]
+		So(png.Example, ShouldEqual, base64.StdEncoding.EncodeToString(logo))
+		So(png.ExampleMediaType, ShouldEqual, "image/png")
+
+		// the media type of an unknown extension is detected from the content
+		post := apiDef.Resources["/photos"].Post
+		upload := post.Bodies.ForMIMEType["application/octet-stream"]
+		So(upload.Example, ShouldEqual, base64.StdEncoding.EncodeToString(photo))
+		So(upload.ExampleMediaType, ShouldEqual, "image/jpeg")
+
+		// in a flow collection
+		So(apiDef.Types["Photo"].Example, ShouldResemble, map[interface{}]interface{}{
+			"data": base64.StdEncoding.EncodeToString(photo),
+		})
+
+		var mediaTypes []string
+		for _, f := range apiDef.ListIncludedFiles() {
+			mediaTypes = append(mediaTypes, f.MediaType)
+		}
+		So(mediaTypes, ShouldResemble, []string{"", "image/jpeg", "image/png", "image/jpeg"})
+	})
+}
+
+func TestCharsetIncludes(t *testing.T) {
+	Convey("charsets of the included files", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/charsets/api.raml", apiDef), ShouldBeNil)
+		notes := apiDef.Resources["/notes"]
+		So(notes.Description, ShouldEqual, "Café crème")
+
+		// byte order mark and XML declaration
+		bodies := notes.Get.Responses["200"].Bodies.ForMIMEType
+		So(bodies["application/json"].TypeString(), ShouldContainSubstring, `"title": "Utilisateur é"`)
+		So(bodies["application/xml"].TypeString(), ShouldContainSubstring, "<!-- Crème -->")
+
+		// the fallback charset
+		So(apiDef.Warnings, ShouldResemble, []Warning{
+			{Location: "notes.md", Message: "not an UTF-8 text file, decoded as ISO-8859-1"},
+		})
+		So(ParseFile("./samples/charsets/price.raml", new(APIDefinition)), ShouldNotBeNil)
+
+		apiDef = new(APIDefinition)
+		So(ParseFile("./samples/charsets/price.raml", apiDef, WithIncludeCharset(charmap.Windows1252)), ShouldBeNil)
+		So(apiDef.Resources["/prices"].Description, ShouldEqual, "Price of 5 €")
+		So(apiDef.Warnings[0].Message, ShouldEqual, "not an UTF-8 text file, decoded as windows-1252")
+		So(apiDef.ListIncludedFiles()[1].MediaType, ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,237 @@
+package raml
+
+import (
+	"sort"
+)
+
+// kinds of the files read when parsing an API definition
+const (
+	// the API definition document
+	RootFile = "root"
+
+	// a file included with the `!include` tag
+	IncludeFile = "include"
+
+	// a library file referenced by `uses`
+	LibraryFile = "library"
+)
+
+// IncludedFile is a file or URL read when parsing an API definition
+type IncludedFile struct {
+	// path or URL of the file as referenced by the document, e.g. `libraries/files.raml`
+	Path string
+
+	// path or URL of the file as it was read
+	Resolved string
+
+	// kind of the file, one of RootFile, IncludeFile or LibraryFile
+	Kind string
+
+	// resolved path or URL of the document referencing the file,
+	// empty for the root document
+	IncludedBy string
+
+	// media type of a binary file, e.g. `image/png`, which is included
+	// base64 encoded, empty for the text files
+	MediaType string
+}
+
+// ListIncludedFiles returns all the files and URLs read when parsing this
+// API definition: the root document, the included files and the libraries,
+// including the libraries used by other libraries and the files they include.
+// The files of a document are listed in the order they are referenced,
+// followed by its libraries sorted by name.
+// Build systems could use it to declare the inputs of an API definition.
+// The root document is not listed if it is not read from a file, see ParseBytes.
+func (apiDef *APIDefinition) ListIncludedFiles() []IncludedFile {
+	var files []IncludedFile
+	if apiDef.Filename != "" {
+		files = append(files, IncludedFile{
+			Path:     apiDef.Filename,
+			Resolved: apiDef.Filename,
+			Kind:     RootFile,
+		})
+	}
+	files = append(files, apiDef.includes...)
+	return append(files, libraryFiles(apiDef.Libraries, apiDef.Uses, apiDef.Filename)...)
+}
+
+// libraryFiles returns the files of the libraries, sorted by the library name
+func libraryFiles(libs map[string]*Library, uses map[string]string, includedBy string) []IncludedFile {
+	var names []string
+	for name := range libs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var files []IncludedFile
+	for _, name := range names {
+		lib := libs[name]
+		files = append(files, IncludedFile{
+			Path:       uses[name],
+			Resolved:   lib.resolved,
+			Kind:       LibraryFile,
+			IncludedBy: includedBy,
+		})
+		files = append(files, lib.includes...)
+		files = append(files, libraryFiles(lib.Libraries, lib.Uses, lib.resolved)...)
+	}
+	return files
+}
+
+// DependencyFile is a file of the dependency graph of an API definition
+type DependencyFile struct {
+	// path or URL of the file as it was read
+	Path string
+
+	// kind of the file, one of RootFile, IncludeFile or LibraryFile
+	Kind string
+
+	// paths or URLs of the files included by this file, in the order they are referenced
+	Includes []string `json:",omitempty"`
+
+	// paths or URLs of the libraries used by this file, by library name
+	Uses map[string]string `json:",omitempty"`
+
+	// names of the types, traits and resource types declared by this file,
+	// sorted. The declarations of a file included by a document are
+	// attributed to the document.
+	Types         []string `json:",omitempty"`
+	Traits        []string `json:",omitempty"`
+	ResourceTypes []string `json:",omitempty"`
+}
+
+// FileDependencies is the graph of the files read when parsing an API
+// definition: which file includes or uses which, and which declares what
+type FileDependencies struct {
+	// the root document first, then the other files in the order of ListIncludedFiles.
+	// A library used by several documents is listed once.
+	Files []DependencyFile
+}
+
+// File returns the file of the given path or URL, nil if it was not read
+func (d FileDependencies) File(path string) *DependencyFile {
+	for i := range d.Files {
+		if d.Files[i].Path == path {
+			return &d.Files[i]
+		}
+	}
+	return nil
+}
+
+// Dependents returns the files which include or use the given file,
+// directly or through other files, sorted.
+// These are the files whose parsing is invalidated when the file changes.
+func (d FileDependencies) Dependents(path string) []string {
+	seen := map[string]bool{}
+	var visit func(path string)
+	visit = func(path string) {
+		for _, f := range d.Files {
+			if seen[f.Path] || !f.dependsOn(path) {
+				continue
+			}
+			seen[f.Path] = true
+			visit(f.Path)
+		}
+	}
+	visit(path)
+	return sortedKeys(seen)
+}
+
+// dependsOn returns true if the file directly includes or uses the given file
+func (f DependencyFile) dependsOn(path string) bool {
+	for _, p := range f.Includes {
+		if p == path {
+			return true
+		}
+	}
+	for _, p := range f.Uses {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// FileDependencies returns the dependency graph of the files read when parsing
+// this API definition. The path of the root document is empty if it is not read
+// from a file, see ParseBytes.
+func (apiDef *APIDefinition) FileDependencies() FileDependencies {
+	var d FileDependencies
+	index := map[string]int{}
+	add := func(f DependencyFile) {
+		if _, ok := index[f.Path]; ok {
+			return
+		}
+		index[f.Path] = len(d.Files)
+		d.Files = append(d.Files, f)
+	}
+
+	// the traits of the libraries are also in the traits of the API
+	libTraits := apiDef.allTraits(nil, apiDef.Libraries)
+	rootTraits := map[string]Trait{}
+	for name, t := range apiDef.Traits {
+		if _, ok := libTraits[name]; !ok {
+			rootTraits[name] = t
+		}
+	}
+	add(DependencyFile{
+		Path:          apiDef.Filename,
+		Kind:          RootFile,
+		Uses:          libraryPaths(apiDef.Libraries),
+		Types:         declaredNames(apiDef.Types),
+		Traits:        declaredNames(rootTraits),
+		ResourceTypes: declaredNames(apiDef.ResourceTypes),
+	})
+
+	libs := map[string]*Library{}
+	var walkLibs func(m map[string]*Library)
+	walkLibs = func(m map[string]*Library) {
+		for _, lib := range m {
+			libs[lib.resolved] = lib
+			walkLibs(lib.Libraries)
+		}
+	}
+	walkLibs(apiDef.Libraries)
+
+	for _, inc := range apiDef.ListIncludedFiles() {
+		switch inc.Kind {
+		case IncludeFile:
+			add(DependencyFile{Path: inc.Resolved, Kind: IncludeFile})
+			if i, ok := index[inc.IncludedBy]; ok && !d.Files[i].dependsOn(inc.Resolved) {
+				d.Files[i].Includes = append(d.Files[i].Includes, inc.Resolved)
+			}
+		case LibraryFile:
+			lib := libs[inc.Resolved]
+			add(DependencyFile{
+				Path:          lib.resolved,
+				Kind:          LibraryFile,
+				Uses:          libraryPaths(lib.Libraries),
+				Types:         declaredNames(lib.Types),
+				Traits:        declaredNames(lib.Traits),
+				ResourceTypes: declaredNames(lib.ResourceTypes),
+			})
+		}
+	}
+	return d
+}
+
+// libraryPaths returns the paths of the libraries by name, nil if none
+func libraryPaths(libs map[string]*Library) map[string]string {
+	if len(libs) == 0 {
+		return nil
+	}
+	paths := map[string]string{}
+	for name, lib := range libs {
+		paths[name] = lib.resolved
+	}
+	return paths
+}
+
+// declaredNames returns the sorted names of the declarations, nil if none
+func declaredNames[V any](decls map[string]V) []string {
+	if len(decls) == 0 {
+		return nil
+	}
+	return sortedKeys(decls)
+}
@@ -0,0 +1,64 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestListIncludedFiles(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/included/api.raml", apiDef)
+	Convey("included files", t, func() {
+		So(err, ShouldBeNil)
+		So(apiDef.Resources["/notes"].Description, ShouldEqual, "Files of the users.")
+
+		root := "samples/included/api.raml"
+		So(apiDef.ListIncludedFiles(), ShouldResemble, []IncludedFile{
+			{Path: root, Resolved: root, Kind: RootFile},
+			{Path: "description.md", Resolved: "samples/included/description.md",
+				Kind: IncludeFile, IncludedBy: root},
+			{Path: "../libraries/files.raml", Resolved: "samples/libraries/files.raml",
+				Kind: LibraryFile, IncludedBy: root},
+			{Path: "libraries/file-type.raml", Resolved: "samples/libraries/libraries/file-type.raml",
+				Kind: LibraryFile, IncludedBy: "samples/libraries/files.raml"},
+			{Path: "notes.raml", Resolved: "samples/included/notes.raml",
+				Kind: LibraryFile, IncludedBy: root},
+			{Path: "usage.md", Resolved: "samples/included/usage.md",
+				Kind: IncludeFile, IncludedBy: "samples/included/notes.raml"},
+		})
+	})
+}
+
+func TestFileDependencies(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/included/api.raml", apiDef)
+	Convey("file dependencies", t, func() {
+		So(err, ShouldBeNil)
+
+		root := "samples/included/api.raml"
+		files := "samples/libraries/files.raml"
+		fileType := "samples/libraries/libraries/file-type.raml"
+		notes := "samples/included/notes.raml"
+		deps := apiDef.FileDependencies()
+		So(deps.Files, ShouldResemble, []DependencyFile{
+			{Path: root, Kind: RootFile, Includes: []string{"samples/included/description.md"},
+				Uses: map[string]string{"files": files, "notes": notes}},
+			{Path: "samples/included/description.md", Kind: IncludeFile},
+			{Path: files, Kind: LibraryFile, Uses: map[string]string{"file-type": fileType},
+				Types: []string{"Link"}, Traits: []string{"drm", "linked"},
+				ResourceTypes: []string{"file", "link"}},
+			{Path: fileType, Kind: LibraryFile, Types: []string{"File"}},
+			{Path: notes, Kind: LibraryFile, Includes: []string{"samples/included/usage.md"},
+				Types: []string{"Note"}},
+			{Path: "samples/included/usage.md", Kind: IncludeFile},
+		})
+
+		So(deps.File(notes).Types, ShouldResemble, []string{"Note"})
+		So(deps.File("unknown.raml"), ShouldBeNil)
+
+		So(deps.Dependents(fileType), ShouldResemble, []string{root, files})
+		So(deps.Dependents("samples/included/usage.md"), ShouldResemble, []string{root, notes})
+		So(deps.Dependents(root), ShouldBeEmpty)
+	})
+}
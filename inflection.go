@@ -1,42 +1,173 @@
 package raml
 
 import (
+	"errors"
+	"fmt"
 	"strings"
+	"sync"
 
 	"bitbucket.org/pkg/inflect"
 	chuckinflect "github.com/chuckpreslar/inflect"
 	jinzhuinflection "github.com/jinzhu/inflection"
 )
 
-var inflectFunc = map[string]func(string) string{
-	"!singularize":         singularize,
-	"!pluralize":           pluralize,
-	"!uppercase":           upperCase,
-	"!lowercase":           lowerCase,
-	"!lowercamelcase":      lowerCamelCase,
-	"!uppercamelcase":      upperCamelCase,
-	"!lowerunderscorecase": lowerUnderScoreCase,
-	"!upperunderscorecase": upperUnderScoreCase,
-	"!lowerhyphencase":     lowerHyphenCase,
-	"!upperhyphencase":     upperHyphenCase,
+var (
+	inflectorsMu sync.RWMutex
+
+	// registered functions which transform the value of the parameters of
+	// resource types and traits, keyed by name with the `!` prefix
+	inflectors = map[string]func(string) string{}
+)
+
+func init() {
+	// functions of the RAML spec
+	builtins := map[string]func(string) string{
+		"singularize":         singularize,
+		"pluralize":           pluralize,
+		"uppercase":           upperCase,
+		"lowercase":           lowerCase,
+		"lowercamelcase":      lowerCamelCase,
+		"uppercamelcase":      upperCamelCase,
+		"lowerunderscorecase": lowerUnderScoreCase,
+		"upperunderscorecase": upperUnderScoreCase,
+		"lowerhyphencase":     lowerHyphenCase,
+		"upperhyphencase":     upperHyphenCase,
+	}
+	for name, fn := range builtins {
+		if err := RegisterInflector(name, fn); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterInflector registers a function which transforms the value of a parameter
+// of resource types and traits, in addition to the functions of the RAML spec, e.g.
+// a function registered as `mycompany_prefix` is used as `<<param | !mycompany_prefix>>`.
+// A name could only be registered once.
+func RegisterInflector(name string, fn func(string) string) error {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "!")
+	if name == "" || fn == nil {
+		return errors.New("inflector needs a name and a function")
+	}
+
+	inflectorsMu.Lock()
+	defer inflectorsMu.Unlock()
+	if _, ok := inflectors["!"+name]; ok {
+		return fmt.Errorf("inflector %v is already registered", name)
+	}
+	inflectors["!"+name] = fn
+	return nil
+}
+
+// inflector returns the registered function, op is the name with the `!` prefix
+func inflector(op string) (func(string) string, bool) {
+	inflectorsMu.RLock()
+	defer inflectorsMu.RUnlock()
+	f, ok := inflectors[op]
+	return f, ok
 }
 
 func doInflect(s, op string) (string, bool) {
-	f, ok := inflectFunc[op]
+	f, ok := inflector(op)
 	if !ok {
 		return s, false
 	}
 	return f(s), true
 }
 
+// Pluralizer converts words between their singular and plural forms,
+// it is used by the `!singularize` and `!pluralize` functions
+// and by Singularize and Pluralize.
+type Pluralizer interface {
+	Singular(word string) string
+	Plural(word string) string
+}
+
+// englishPluralizer is the default Pluralizer, with United States English rules
+type englishPluralizer struct{}
+
+func (englishPluralizer) Singular(word string) string {
+	return jinzhuinflection.Singular(word)
+}
+
+func (englishPluralizer) Plural(word string) string {
+	return chuckinflect.Pluralize(word)
+}
+
+var (
+	pluralizerMu sync.RWMutex
+	pluralizer   Pluralizer = englishPluralizer{}
+
+	// irregular words, in lower case
+	irregularPlurals   = map[string]string{} // singular to plural
+	irregularSingulars = map[string]string{} // plural to singular
+)
+
+// SetPluralizer replaces the rules used to singularize and pluralize words,
+// nil restores the default United States English rules.
+// Irregular words added by AddIrregular take precedence over the pluralizer.
+func SetPluralizer(p Pluralizer) {
+	pluralizerMu.Lock()
+	defer pluralizerMu.Unlock()
+	if p == nil {
+		p = englishPluralizer{}
+	}
+	pluralizer = p
+}
+
+// AddIrregular adds an irregular word, e.g. `schema` and `schemata`.
+// Words which are the same in both forms are declared
+// with the same singular and plural, e.g. `data` and `data`.
+func AddIrregular(singular, plural string) {
+	pluralizerMu.Lock()
+	defer pluralizerMu.Unlock()
+	singular, plural = strings.ToLower(singular), strings.ToLower(plural)
+	irregularPlurals[singular] = plural
+	irregularSingulars[plural] = singular
+	// a word already in the wanted form stays unchanged
+	irregularPlurals[plural] = plural
+	irregularSingulars[singular] = singular
+}
+
+// Singularize returns singular version of a word,
+// with the same rules as the `!singularize` function
+func Singularize(s string) string {
+	return singularize(s)
+}
+
+// Pluralize returns plural version of a word,
+// with the same rules as the `!pluralize` function
+func Pluralize(s string) string {
+	return pluralize(s)
+}
+
 // singularize returns singular version of a word
 func singularize(s string) string {
-	return jinzhuinflection.Singular(s)
+	pluralizerMu.RLock()
+	defer pluralizerMu.RUnlock()
+	if w, ok := irregularSingulars[strings.ToLower(s)]; ok {
+		return matchFirstCase(w, s)
+	}
+	return pluralizer.Singular(s)
 }
 
 // pluralize returns plural version of a word
 func pluralize(s string) string {
-	return chuckinflect.Pluralize(s)
+	pluralizerMu.RLock()
+	defer pluralizerMu.RUnlock()
+	if w, ok := irregularPlurals[strings.ToLower(s)]; ok {
+		return matchFirstCase(w, s)
+	}
+	return pluralizer.Plural(s)
+}
+
+// matchFirstCase upper cases the first letter of the word
+// if the first letter of the original word is upper case
+func matchFirstCase(word, original string) string {
+	if word == "" || original == "" || strings.ToUpper(original[:1]) != original[:1] {
+		return word
+	}
+	return strings.ToUpper(word[:1]) + word[1:]
 }
 
 // upperCase returns upper case version of a word
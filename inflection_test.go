@@ -1,6 +1,7 @@
 package raml
 
 import (
+	"strings"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -136,3 +137,75 @@ func TestInflection(t *testing.T) {
 	})
 
 }
+
+func TestRegisterInflector(t *testing.T) {
+	Convey("custom inflector", t, func() {
+		err := RegisterInflector("!raml_test_prefix", func(s string) string { return "acme-" + s })
+		So(err, ShouldBeNil)
+		defer func() {
+			inflectorsMu.Lock()
+			delete(inflectors, "!raml_test_prefix")
+			inflectorsMu.Unlock()
+		}()
+
+		dicts := map[string]interface{}{"item": "books"}
+		So(substituteParams("", "<<item | !raml_test_prefix>>", dicts), ShouldEqual, "acme-books")
+		So(substituteParams("", "<<item | !singularize | !raml_test_prefix>>", dicts), ShouldEqual, "acme-book")
+
+		Convey("names are registered once", func() {
+			So(RegisterInflector("raml_test_prefix", strings.ToUpper), ShouldNotBeNil)
+			So(RegisterInflector("pluralize", strings.ToUpper), ShouldNotBeNil)
+			So(RegisterInflector("", strings.ToUpper), ShouldNotBeNil)
+		})
+
+		Convey("unknown function", func() {
+			err := checkParams("trait", "paged", Trait{Description: "<<item | !raml_test_prefix>> <<item | !shout>>"},
+				DefinitionParameters{"item": "books"}, traitReservedParams)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "trait paged: unknown function !shout")
+		})
+	})
+}
+
+// upperPluralizer is a Pluralizer for tests
+type upperPluralizer struct{}
+
+func (upperPluralizer) Singular(word string) string {
+	return strings.ToUpper(strings.TrimSuffix(word, "s"))
+}
+func (upperPluralizer) Plural(word string) string { return strings.ToUpper(word + "s") }
+
+func TestPluralizationRules(t *testing.T) {
+	Convey("irregular words", t, func() {
+		defer func() {
+			pluralizerMu.Lock()
+			irregularPlurals = map[string]string{}
+			irregularSingulars = map[string]string{}
+			pluralizerMu.Unlock()
+		}()
+		So(Pluralize("schemas"), ShouldEqual, "schemases")
+
+		AddIrregular("schema", "schemata")
+		AddIrregular("data", "data")
+		So(Pluralize("schema"), ShouldEqual, "schemata")
+		So(Pluralize("Schema"), ShouldEqual, "Schemata")
+		So(Pluralize("schemata"), ShouldEqual, "schemata")
+		So(Singularize("schemata"), ShouldEqual, "schema")
+		So(Singularize("schema"), ShouldEqual, "schema")
+		So(Singularize("data"), ShouldEqual, "data")
+
+		dicts := map[string]interface{}{"resourcePathName": "schema"}
+		So(substituteParams("", "<<resourcePathName | !pluralize>>", dicts), ShouldEqual, "schemata")
+	})
+
+	Convey("custom pluralizer", t, func() {
+		SetPluralizer(upperPluralizer{})
+		defer SetPluralizer(nil)
+
+		So(Pluralize("user"), ShouldEqual, "USERS")
+		So(Singularize("users"), ShouldEqual, "USER")
+
+		SetPluralizer(nil)
+		So(Pluralize("user"), ShouldEqual, "users")
+	})
+}
@@ -0,0 +1,62 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInlineTypes(t *testing.T) {
+	Convey("inline type declarations", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/inline_types.raml", apiDef, WithUnknownKeyErrors()), ShouldBeNil)
+
+		Convey("of the properties of the types", func() {
+			order := apiDef.Types["Order"]
+			So(order.GetProperty("customer").TypeString(), ShouldEqual, "Ordercustomer")
+
+			customer := apiDef.Types["Ordercustomer"]
+			So(customer.Description, ShouldEqual, "who ordered")
+			So(customer.AdditionalProperties, ShouldEqual, "false")
+			So(customer.GetProperty("address").TypeString(), ShouldEqual, "Ordercustomeraddress")
+
+			address := apiDef.Types["Ordercustomeraddress"]
+			So(address.GetProperty("city").Required, ShouldBeTrue)
+			So(address.GetProperty("zip").Required, ShouldBeFalse)
+		})
+
+		Convey("of the items of the array properties", func() {
+			order := apiDef.Types["Order"]
+			lines := order.GetProperty("lines")
+			So(lines.IsArray(), ShouldBeTrue)
+			So(lines.ArrayType(), ShouldEqual, "OrderlinesItem")
+
+			item := apiDef.Types["OrderlinesItem"]
+			So(item.Type, ShouldBeNil)
+			So(item.GetProperty("quantity").TypeString(), ShouldEqual, "integer")
+		})
+
+		Convey("of the properties of the bodies", func() {
+			post := apiDef.Resources["/orders"].Post
+			body := post.Bodies.ForMIMEType["application/json"]
+			So(body.GetProperty("shipping").TypeString(), ShouldEqual, "/orderspostbodyshipping")
+			shipping := apiDef.Types["/orderspostbodyshipping"]
+			So(shipping.GetProperty("tracking").Required, ShouldBeFalse)
+			So(body.GetProperty("parcels").ArrayType(), ShouldEqual, "/orderspostbodyparcelsItem")
+
+			receipt := post.Responses["201"].Bodies.ForMIMEType["application/json"].GetProperty("receipt")
+			So(receipt.TypeString(), ShouldEqual, "/orderspost201bodyreceipt")
+			receiptType := apiDef.Types["/orderspost201bodyreceipt"]
+			So(receiptType.GetProperty("number").TypeString(), ShouldEqual, "integer")
+		})
+
+		Convey("named in camel case", func() {
+			apiDef := &APIDefinition{TypeNaming: CamelCaseTypeNames}
+			So(ParseFile("./samples/inline_types.raml", apiDef), ShouldBeNil)
+			So(apiDef.Types, ShouldContainKey, "OrderCustomerAddress")
+			So(apiDef.Types, ShouldContainKey, "OrderLinesItem")
+			So(apiDef.Types, ShouldContainKey, "OrdersPostBodyShipping")
+			So(apiDef.Types, ShouldContainKey, "OrdersPost201BodyReceipt")
+		})
+	})
+}
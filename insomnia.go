@@ -0,0 +1,172 @@
+package raml
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// insomniaResource is a resource of the Insomnia v4 export format,
+// only the fields needed by the exported types are set
+type insomniaResource struct {
+	ID          string                 `json:"_id"`
+	Type        string                 `json:"_type"`
+	ParentID    *string                `json:"parentId"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Data        map[string]string      `json:"data,omitempty"`
+	Method      string                 `json:"method,omitempty"`
+	URL         string                 `json:"url,omitempty"`
+	Body        map[string]string      `json:"body,omitempty"`
+	Parameters  []insomniaPair         `json:"parameters,omitempty"`
+	Headers     []insomniaPair         `json:"headers,omitempty"`
+	Auth        map[string]interface{} `json:"authentication,omitempty"`
+}
+
+type insomniaPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type insomniaExport struct {
+	Type         string             `json:"_type"`
+	ExportFormat int                `json:"__export_format"`
+	ExportDate   string             `json:"__export_date"`
+	ExportSource string             `json:"__export_source"`
+	Resources    []insomniaResource `json:"resources"`
+}
+
+// WriteInsomnia writes all operations of the API as Insomnia v4 export JSON.
+//
+// The requests are grouped in folders by resource, nested like the resources.
+// The base environment holds the base URI, its parameters, the URI parameters
+// and the credentials, initialized with their example or default value.
+func (apiDef *APIDefinition) WriteInsomnia(w io.Writer) error {
+	vars := newExampleVars()
+	baseParams := vars.toVars(apiDef.baseURIParams())
+	base := exampleRequest{BaseURI: apiDef.BaseURI, BaseURIParams: baseParams}
+
+	workspaceID := "wrk_1"
+	export := insomniaExport{
+		Type:         "export",
+		ExportFormat: 4,
+		ExportDate:   time.Now().UTC().Format(time.RFC3339),
+		ExportSource: "raml",
+		Resources: []insomniaResource{{
+			ID:   workspaceID,
+			Type: "workspace",
+			Name: apiDef.Title,
+		}},
+	}
+
+	// folder IDs by resource full URI
+	folders := map[string]string{}
+	requests := 0
+	apiDef.walkResources(func(r *Resource) {
+		parentID := workspaceID
+		if r.Parent != nil {
+			if id, ok := folders[r.Parent.FullURI()]; ok {
+				parentID = id
+			}
+		}
+		folderID := fmt.Sprintf("fld_%d", len(folders)+1)
+		folders[r.FullURI()] = folderID
+		export.Resources = append(export.Resources, insomniaResource{
+			ID:          folderID,
+			Type:        "request_group",
+			ParentID:    &parentID,
+			Name:        r.URI,
+			Description: r.Description,
+		})
+
+		for _, m := range r.methods() {
+			req := apiDef.exampleRequest(r, m)
+			res := insomniaRequest(req, vars)
+			requests++
+			res.ID = fmt.Sprintf("req_%d", requests)
+			res.ParentID = &folderID
+			res.Description = m.Description
+			export.Resources = append(export.Resources, res)
+		}
+	})
+
+	env := map[string]string{
+		"baseUri": strings.TrimSuffix(base.resolveBase(insomniaVar), "/"),
+	}
+	for _, name := range vars.vars {
+		env[name] = vars.values[name]
+	}
+	export.Resources = append(export.Resources, insomniaResource{
+		ID:       "env_1",
+		Type:     "environment",
+		ParentID: &workspaceID,
+		Name:     "Base Environment",
+		Data:     env,
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(export)
+}
+
+// insomniaRequest creates request resource from the example request,
+// the variables are declared in vars
+func insomniaRequest(req exampleRequest, vars *exampleVars) insomniaResource {
+	// query parameters are in the parameters list, not in the URL
+	query := req.Query
+	req.URIParams, req.Query = vars.toVars(req.URIParams), nil
+	variable := func(name string) string {
+		return insomniaVar(requestParam{Value: vars.declare(name, "")})
+	}
+	res := insomniaResource{
+		Type:   "request",
+		Name:   req.Method + " " + req.Path,
+		Method: req.Method,
+		URL:    "{{ _.baseUri }}" + req.resolvePath(insomniaVar),
+	}
+	for _, q := range query {
+		value := q.Value
+		if q.Variable {
+			value = variable(q.Value)
+		}
+		res.Parameters = append(res.Parameters, insomniaPair{Name: q.Name, Value: value})
+	}
+
+	switch req.Auth {
+	case authBasic, authDigest:
+		res.Auth = map[string]interface{}{
+			"type":     req.Auth,
+			"username": variable("username"),
+			"password": variable("password"),
+		}
+	}
+	for _, h := range req.Headers {
+		if h.Name == "Authorization" && h.Prefix == "Bearer " {
+			res.Auth = map[string]interface{}{
+				"type":  "bearer",
+				"token": variable(h.Value),
+			}
+			continue
+		}
+		value := h.Value
+		if h.Variable {
+			value = variable(h.Value)
+		}
+		res.Headers = append(res.Headers, insomniaPair{Name: h.Name, Value: h.Prefix + value})
+	}
+	if req.Body != "" {
+		res.Body = map[string]string{
+			"mimeType": req.ContentType,
+			"text":     req.Body,
+		}
+		res.Headers = append(res.Headers, insomniaPair{Name: "Content-Type", Value: req.ContentType})
+	}
+	return res
+}
+
+// insomniaVar returns reference to the environment variable of the parameter
+func insomniaVar(p requestParam) string {
+	return "{{ _." + p.Value + " }}"
+}
@@ -0,0 +1,55 @@
+package raml
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteInsomnia(t *testing.T) {
+	Convey("Insomnia export", t, func() {
+		apiDef := new(APIDefinition)
+		err := ParseFile("./samples/commands.raml", apiDef)
+		So(err, ShouldBeNil)
+
+		var buf bytes.Buffer
+		err = apiDef.WriteInsomnia(&buf)
+		So(err, ShouldBeNil)
+
+		var export insomniaExport
+		err = json.Unmarshal(buf.Bytes(), &export)
+		So(err, ShouldBeNil)
+		So(export.ExportFormat, ShouldEqual, 4)
+
+		res := map[string]insomniaResource{}
+		for _, r := range export.Resources {
+			res[r.ID] = r
+		}
+		So(len(res), ShouldEqual, 7)
+
+		Convey("requests are grouped by resource", func() {
+			So(*res["fld_1"].ParentID, ShouldEqual, "wrk_1")
+			So(res["fld_2"].Name, ShouldEqual, "/{userId}")
+			So(*res["fld_2"].ParentID, ShouldEqual, "fld_1")
+			So(*res["req_3"].ParentID, ShouldEqual, "fld_2")
+		})
+
+		Convey("requests", func() {
+			So(res["req_1"].URL, ShouldEqual, "{{ _.baseUri }}/users")
+			So(res["req_1"].Parameters, ShouldResemble, []insomniaPair{{Name: "page", Value: "2"}})
+			So(res["req_1"].Auth["type"], ShouldEqual, "bearer")
+			So(res["req_2"].Body["text"], ShouldContainSubstring, `"name": "John's"`)
+			So(res["req_3"].URL, ShouldEqual, "{{ _.baseUri }}/users/{{ _.userId }}")
+			So(res["req_3"].Auth["type"], ShouldEqual, "basic")
+		})
+
+		Convey("environment from the base URI parameters", func() {
+			So(res["env_1"].Data["baseUri"], ShouldEqual, "https://{{ _.host }}/api/{{ _.version }}")
+			So(res["env_1"].Data["host"], ShouldEqual, "api.example.com")
+			So(res["env_1"].Data["version"], ShouldEqual, "v1")
+			So(res["env_1"].Data["userId"], ShouldEqual, "")
+		})
+	})
+}
@@ -1,9 +1,18 @@
 package raml
 
+import (
+	"github.com/gigforks/yaml"
+)
+
 // Items represent an RAML "items"
 type Items struct {
 	Type   string
 	Format string
+
+	// The items as a type declaration, a type name or expression or a complete
+	// inline type declaration with its facets, properties and examples,
+	// nil if the items are not declared
+	Declaration *Type
 }
 
 func newItems(i interface{}) Items {
@@ -12,12 +21,43 @@ func newItems(i interface{}) Items {
 	case string:
 		it.Type = v
 	case map[interface{}]interface{}:
-		it.Type = v["type"].(string)
+		if t, ok := v["type"].(string); ok {
+			it.Type = t
+		}
 		if f, ok := v["format"].(string); ok {
 			it.Format = f
 		}
-	default:
-		panic("unhandled type for new items")
 	}
+	it.Declaration = itemsDeclaration(i)
 	return it
 }
+
+// itemsDeclaration decodes the items of an array as a type declaration,
+// nil if there are no items or they could not be decoded
+func itemsDeclaration(items interface{}) *Type {
+	switch v := items.(type) {
+	case string:
+		return &Type{Type: v}
+	case map[interface{}]interface{}:
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		t := new(Type)
+		if err := unmarshalYAML(data, t); err != nil {
+			return nil
+		}
+		t.parseProperties()
+		return t
+	}
+	return nil
+}
+
+// itemsTypeString returns the type of the items of an array,
+// given by their type name or by the type of their inline declaration
+func itemsTypeString(items interface{}) string {
+	if decl, ok := items.(map[interface{}]interface{}); ok {
+		return interfaceToString(decl["type"])
+	}
+	return interfaceToString(items)
+}
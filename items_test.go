@@ -0,0 +1,49 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestItemsDeclaration(t *testing.T) {
+	Convey("items declared as types", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/items.raml", apiDef, WithUnknownKeyErrors()), ShouldBeNil)
+
+		Convey("with facets", func() {
+			tags := apiDef.Types["Tags"]
+			So(tags.ArrayType(), ShouldEqual, "string")
+			items := tags.ItemsDeclaration()
+			So(items, ShouldNotBeNil)
+			So(items.TypeString(), ShouldEqual, "string")
+			So(items.MinLength, ShouldEqual, 2)
+			So(items.Example, ShouldEqual, "ab")
+		})
+
+		Convey("with properties", func() {
+			points := apiDef.Types["Points"]
+			So(points.ArrayType(), ShouldEqual, "PointsItem")
+			item := apiDef.Types["PointsItem"]
+			So(item.Description, ShouldEqual, "a point")
+			So(item.Properties, ShouldContainKey, "x")
+		})
+
+		Convey("of the properties", func() {
+			shape := apiDef.Types["Shape"]
+			corners := shape.GetProperty("corners")
+			So(corners.ArrayType(), ShouldEqual, "string")
+			So(corners.Items.Declaration, ShouldNotBeNil)
+			So(corners.Items.Declaration.Description, ShouldEqual, "a corner")
+			So(corners.Items.Declaration.Enum, ShouldResemble, []interface{}{"north", "south"})
+		})
+
+		Convey("of the bodies", func() {
+			body := apiDef.Resources["/shapes"].Post.Bodies.ForMIMEType["application/json"]
+			So(body.ItemsDeclaration().TypeString(), ShouldEqual, "/shapespostbodyItem")
+			So(apiDef.Types["/shapespostbodyItem"].Properties, ShouldContainKey, "name")
+			json := apiDef.Resources["/shapes"].Post.Bodies.ApplicationJSON
+			So(json.ItemsDeclaration().TypeString(), ShouldEqual, "/shapespostbodyItem")
+		})
+	})
+}
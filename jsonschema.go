@@ -40,7 +40,7 @@ func NewJSONSchema(t Type, name string) JSONSchema {
 
 // NewJSONSchemaFromProps creates json schmema
 // from a map of properties
-func NewJSONSchemaFromProps(t *Type, properties map[string]interface{}, typ, name string) JSONSchema {
+func NewJSONSchemaFromProps(t *Type, properties map[string]Property, typ, name string) JSONSchema {
 	var required []string
 
 	if isTypeArray(typ) {
@@ -48,8 +48,8 @@ func NewJSONSchemaFromProps(t *Type, properties map[string]interface{}, typ, nam
 	}
 
 	props := make(map[string]property, len(properties))
-	for name := range properties {
-		rp := t.GetProperty(name)
+	for _, rp := range properties {
+		rp._type = t
 		if !isPropTypeSupported(rp) {
 			continue
 		}
@@ -181,7 +181,7 @@ func newProperty(rp Property) property {
 	_, isScalar := scalarTypes[rp.TypeString()]
 
 	// complex type
-	if rp.Type != "" && !isScalar && !rp.IsArray() && !rp.IsBidimensiArray() {
+	if rp.Type != "" && !isScalar && !rp.IsArray() {
 		return property{
 			Name:     rp.Name,
 			Ref:      rp.TypeString() + fileSuffix,
@@ -234,9 +234,9 @@ func newProperty(rp Property) property {
 	}
 
 	// array
-	if rp.IsArray() && !rp.IsBidimensiArray() {
+	if rp.IsArray() {
 		p.Type = "array"
-		p.Items = newArrayItem(rp.ArrayType())
+		p.Items = newNestedArrayItem(rp.ArrayDepth(), rp.ElementType())
 	}
 
 	if !p.Required {
@@ -263,5 +263,6 @@ func (p *property) toRAMLProperty() Property {
 }
 
 func isPropTypeSupported(p Property) bool {
-	return !p.IsBidimensiArray() && !p.IsUnion()
+	elem := Property{Type: p.ElementType()}
+	return !p.IsUnion() && !p.IsNullable() && !elem.IsUnion() && !elem.IsNullable()
 }
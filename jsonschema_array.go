@@ -1,16 +1,15 @@
 package raml
 
-import (
-	"fmt"
-	"strings"
-)
-
 func newArraySchema(t *Type, typ, name string) JSONSchema {
+	array := Type{Type: typ}
+	if t != nil {
+		array.Items = t.Items
+	}
 	js := JSONSchema{
 		Name:   name,
 		Schema: schemaVer,
 		Type:   "array",
-		Items:  newArrayItem(getArrayItemsType(t, typ)),
+		Items:  newNestedArrayItem(array.ArrayDepth(), array.ElementType()),
 		T: &Type{
 			Type: "array",
 		},
@@ -26,12 +25,13 @@ func newArraySchema(t *Type, typ, name string) JSONSchema {
 
 func isTypeArray(typ string) bool {
 	t := Type{Type: typ}
-	return typ == "array" || (t.IsArray() && !t.IsBidimensiArray())
+	return typ == "array" || t.IsArray()
 }
 
 type arrayItem struct {
-	Type string `json:"type,omitempty"`
-	Ref  string `json:"$ref,omitempty"`
+	Type  string     `json:"type,omitempty"`
+	Ref   string     `json:"$ref,omitempty"`
+	Items *arrayItem `json:"items,omitempty"`
 }
 
 func newArrayItem(typ string) *arrayItem {
@@ -45,9 +45,14 @@ func newArrayItem(typ string) *arrayItem {
 	}
 }
 
-func getArrayItemsType(t *Type, typ string) string {
-	if typ == "array" {
-		return fmt.Sprint(t.Items)
+// newNestedArrayItem creates the items of an array of the given number of dimensions,
+// the items of a multidimensional array are arrays of the remaining dimensions
+func newNestedArrayItem(depth int, elem string) *arrayItem {
+	if depth <= 1 {
+		return newArrayItem(elem)
+	}
+	return &arrayItem{
+		Type:  "array",
+		Items: newNestedArrayItem(depth-1, elem),
 	}
-	return strings.TrimSuffix(typ, "[]")
 }
@@ -2,7 +2,6 @@ package raml
 
 import (
 	"fmt"
-	"path/filepath"
 )
 
 // Library is used to combine any collection of data type declarations,
@@ -15,6 +14,7 @@ type Library struct {
 	ResourceTypes   map[string]ResourceType   `yaml:"resourceTypes"`
 	Traits          map[string]Trait          `yaml:"traits"`
 	SecuritySchemes map[string]SecurityScheme `yaml:"securitySchemes"`
+	AnnotationTypes map[string]AnnotationType `yaml:"annotationTypes"`
 	Uses            map[string]string         `yaml:"uses"`
 
 	// Describes the content or purpose of a specific library.
@@ -23,6 +23,51 @@ type Library struct {
 
 	Libraries map[string]*Library `yaml:"-"`
 	Filename  string              `yaml:"-"`
+
+	// version of RAML and kind of the document from its header,
+	// e.g. `1.0` and LibraryFragment
+	RAMLVersion  string `yaml:"-"`
+	FragmentKind string `yaml:"-"`
+
+	// resolved path or URL of the library file
+	resolved string
+
+	// files included by the library file
+	includes []IncludedFile
+
+	// locations of the nodes in the original files
+	sourceMap *sourceMap
+
+	// configuration of the parsing
+	cfg *parseConfig
+}
+
+// parseLibrary parses a library used by a document parsed with the given configuration,
+// nil for the default configuration. A library already parsed for the document is not
+// parsed again, lib gets its declarations, which are shared by the documents using it.
+func parseLibrary(workDir, fileName string, lib *Library, cfg *parseConfig) ([]byte, error) {
+	if cfg == nil {
+		cfg = &parseConfig{}
+	}
+	if err := cfg.checkRemote(workDir, fileName); err != nil {
+		return nil, err
+	}
+	resolved := resolvePath(workDir, fileName)
+	if parsed, ok := cfg.libraries[resolved]; ok {
+		filename := lib.Filename
+		*lib = *parsed
+		lib.Filename = filename
+		return nil, nil
+	}
+	nested, err := cfg.nested(resolved)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := parseFile(workDir, fileName, lib, nested)
+	if err == nil && cfg.libraries != nil {
+		cfg.libraries[resolved] = lib
+	}
+	return contents, err
 }
 
 // PostProcess doing additional processing
@@ -32,30 +77,86 @@ type Library struct {
 // - allocate map fields
 func (l *Library) PostProcess(workDir, fileName string) error {
 	// libraries
-	if !isURL(fileName) {
-		workDir = filepath.Join(workDir, filepath.Dir(fileName))
-	}
+	workDir = libraryDir(workDir, fileName)
 	l.Libraries = map[string]*Library{}
-	for name, path := range l.Uses {
+	for _, name := range sortedKeys(l.Uses) {
+		path := l.Uses[name]
 		lib := &Library{Filename: path}
-		if _, err := ParseReadFile(workDir, path, lib); err != nil {
+		if _, err := parseLibrary(workDir, path, lib, l.cfg); err != nil {
 			return fmt.Errorf("l.PostProcess() failed to parse library	name=%v, path=%v, err=%v",
 				name, path, err)
 		}
 		l.Libraries[name] = lib
 
 	}
+	if err := addFragmentLibraries(l.Libraries, l.Traits, l.ResourceTypes, l.sourceMap, workDir, l.cfg); err != nil {
+		return err
+	}
+
+	// the declarations are processed like the ones of an API definition,
+	// all of them even if some fail
+	apiDef := l.apiDefinition()
+	errs := new(Error)
+	errs.add(l.cfg.process(AfterLibraries, l))
+
+	nameAnnotationTypes(l.AnnotationTypes)
+	nameSecuritySchemes(l.SecuritySchemes)
 
 	// traits
-	for name, t := range l.Traits {
-		t.postProcess(name)
+	for _, name := range sortedKeys(l.Traits) {
+		t := l.Traits[name]
+		errs.add(t.postProcess(name))
 		l.Traits[name] = t
 	}
 
-	// resource types
-	for name, rt := range l.ResourceTypes {
-		rt.postProcess(name, l.Traits, nil)
+	// resource types, with the traits of the libraries
+	rtTraits := make(map[string]Trait, len(l.Traits))
+	for name, t := range l.Traits {
+		rtTraits[name] = t
+	}
+	rtTraits = apiDef.allTraits(rtTraits, l.Libraries)
+	for _, name := range sortedKeys(l.ResourceTypes) {
+		rt := l.ResourceTypes[name]
+		errs.add(rt.postProcess(name, rtTraits, apiDef))
 		l.ResourceTypes[name] = rt
 	}
-	return nil
+
+	// types, the types of the inline properties are added while processing
+	processed := map[string]bool{}
+	for len(processed) < len(l.Types) {
+		for _, name := range sortedKeys(l.Types) {
+			if processed[name] {
+				continue
+			}
+			processed[name] = true
+			t := l.Types[name]
+			errs.add(t.postProcess(name, apiDef))
+			l.Types[name] = t
+		}
+	}
+
+	// examples, discriminators and facets, need all types to be processed
+	for _, name := range sortedKeys(l.Types) {
+		errs.add(l.Types[name].validateExamples(apiDef))
+	}
+	errs.add(apiDef.validateDiscriminators())
+	errs.add(apiDef.validateFacets())
+	errs.add(apiDef.validateDefaults())
+	return errs.errOrNil()
+}
+
+// apiDefinition returns the declarations of the library as an API definition,
+// sharing the maps of the library, to process them like the ones of an API definition
+func (l *Library) apiDefinition() *APIDefinition {
+	return &APIDefinition{
+		Types:           l.Types,
+		ResourceTypes:   l.ResourceTypes,
+		Traits:          l.Traits,
+		SecuritySchemes: l.SecuritySchemes,
+		AnnotationTypes: l.AnnotationTypes,
+		Uses:            l.Uses,
+		Libraries:       l.Libraries,
+		sourceMap:       l.sourceMap,
+		cfg:             l.cfg,
+	}
 }
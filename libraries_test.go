@@ -54,5 +54,167 @@ func TestLibraries(t *testing.T) {
 			So(r.Post.Bodies.Type, ShouldEqual, "files.Link")
 		})
 
+		Convey("type by name", func() {
+			link, ok := apiDef.TypeByName("files.Link")
+			So(ok, ShouldBeTrue)
+			So(link.Name, ShouldEqual, "Link")
+			So(link.Properties, ShouldContainKey, "name")
+
+			// unqualified
+			_, ok = apiDef.TypeByName("Link")
+			So(ok, ShouldBeTrue)
+
+			// nested library
+			file, ok := apiDef.TypeByName("files.file-type.File")
+			So(ok, ShouldBeTrue)
+			So(len(file.Properties), ShouldEqual, 2)
+			_, ok = apiDef.TypeByName("File")
+			So(ok, ShouldBeFalse)
+
+			_, ok = apiDef.TypeByName("files.Unknown")
+			So(ok, ShouldBeFalse)
+			_, ok = apiDef.TypeByName("unknown.Link")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("qualified type names", func() {
+			So(apiDef.QualifyTypeName("Link", "files.link"), ShouldEqual, "files.Link")
+			So(apiDef.QualifyTypeName("Link[]", "files.linked"), ShouldEqual, "files.Link[]")
+			So(apiDef.QualifyTypeName("Link | file-type.File", "files.link"), ShouldEqual,
+				"files.Link | files.file-type.File")
+			So(apiDef.QualifyTypeName("string", "files.link"), ShouldEqual, "string")
+			So(apiDef.QualifyTypeName("Link", "collection"), ShouldEqual, "Link")
+
+			// types of the bodies, properties and responses of a library trait
+			post := apiDef.Resources["/shared"].Post
+			body := post.Bodies.ForMIMEType["application/json"]
+			So(body.TypeString(), ShouldEqual, "files.Link[]")
+			owner := body.GetProperty("owner")
+			So(owner.TypeString(), ShouldEqual, "files.Link")
+			So(owner.Required, ShouldBeFalse)
+
+			resp := post.Responses["200"].Bodies.ForMIMEType["application/json"]
+			So(resp.TypeString(), ShouldEqual, "files.Link | files.file-type.File")
+		})
+
+	})
+}
+
+func TestShadowedDeclarations(t *testing.T) {
+	Convey("shadowed declarations", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/shadowing.raml", apiDef), ShouldBeNil)
+
+		shadowed := apiDef.ShadowedDeclarations()
+		So(shadowed, ShouldResemble, []ShadowedDeclaration{
+			{Kind: "resourceType", Name: "file", Declarations: []string{"file", "files.file"}, Resolved: "file"},
+			{Kind: "trait", Name: "drm", Declarations: []string{"files.drm", "others.drm"}},
+			{Kind: "type", Name: "Link", Declarations: []string{"Link", "files.Link", "others.Link"}, Resolved: "Link"},
+		})
+		So(shadowed[2].Warning(), ShouldEqual,
+			"type Link is declared as Link, files.Link, others.Link: `Link` refers to the declaration of the document")
+		So(shadowed[1].Warning(), ShouldEqual,
+			"trait drm is declared as files.drm, others.drm: `drm` refers to none of them, use a qualified name")
+
+		// the resolution
+		link, ok := apiDef.TypeByName("Link")
+		So(ok, ShouldBeTrue)
+		So(link.Properties, ShouldContainKey, "url")
+
+		apiDef = new(APIDefinition)
+		So(ParseFile("./samples/simple_with_lib.raml", apiDef), ShouldBeNil)
+		So(apiDef.ShadowedDeclarations(), ShouldBeEmpty)
+	})
+}
+
+func TestCircularLibraries(t *testing.T) {
+	Convey("circular uses", t, func() {
+		err := ParseFile("./samples/circular/api.raml", new(APIDefinition))
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring,
+			"circular uses: samples/circular/users.raml -> samples/circular/groups.raml -> samples/circular/users.raml")
+
+		err = ParseFile("./samples/circular/self.raml", new(APIDefinition))
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring,
+			"circular uses: samples/circular/itself.raml -> samples/circular/itself.raml")
+
+		// nested libraries without cycle
+		So(ParseFile("./samples/simple_with_lib.raml", new(APIDefinition)), ShouldBeNil)
+	})
+}
+
+func TestLibraryChain(t *testing.T) {
+	Convey("library chain of the resolved declarations", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/simple_with_lib.raml", apiDef), ShouldBeNil)
+
+		file, ok := apiDef.TypeByName("files.file-type.File")
+		So(ok, ShouldBeTrue)
+		So(file.LibraryChain, ShouldResemble, LibraryChain{
+			{Name: "files", Filename: "libraries/files.raml"},
+			{Name: "file-type", Filename: "libraries/file-type.raml"},
+		})
+		So(file.LibraryChain.String(), ShouldEqual,
+			"root → files (libraries/files.raml) → file-type (libraries/file-type.raml)")
+
+		// unqualified
+		link, ok := apiDef.TypeByName("Link")
+		So(ok, ShouldBeTrue)
+		So(link.LibraryChain, ShouldResemble, LibraryChain{{Name: "files", Filename: "libraries/files.raml"}})
+
+		trait, ok := apiDef.TraitByName("files.drm")
+		So(ok, ShouldBeTrue)
+		So(trait.Headers, ShouldContainKey, HTTPHeader("drm-key"))
+		So(trait.LibraryChain.String(), ShouldEqual, "root → files (libraries/files.raml)")
+		_, ok = apiDef.TraitByName("drm")
+		So(ok, ShouldBeFalse)
+
+		rt, ok := apiDef.ResourceTypeByName("files.link")
+		So(ok, ShouldBeTrue)
+		So(rt.LibraryChain, ShouldHaveLength, 1)
+		_, ok = apiDef.ResourceTypeByName("files.unknown")
+		So(ok, ShouldBeFalse)
+
+		// declarations of the root document
+		apiDef = new(APIDefinition)
+		So(ParseFile("./samples/trait_order.raml", apiDef), ShouldBeNil)
+		trait, ok = apiDef.TraitByName("paged")
+		So(ok, ShouldBeTrue)
+		So(trait.LibraryChain, ShouldBeEmpty)
+		So(trait.LibraryChain.String(), ShouldEqual, "root")
+		rt, ok = apiDef.ResourceTypeByName("collection")
+		So(ok, ShouldBeTrue)
+		So(rt.LibraryChain, ShouldBeEmpty)
+	})
+}
+
+func TestLibraryPostProcess(t *testing.T) {
+	Convey("library parsed standalone", t, func() {
+		lib := new(Library)
+		So(ParseFile("./samples/libraries/processed/library.raml", lib, WithUnknownKeyErrors()), ShouldBeNil)
+		So(lib.Usage, ShouldEqual, "the declarations of the users")
+		So(lib.Libraries, ShouldContainKey, "common")
+
+		Convey("types are processed", func() {
+			user := lib.Types["User"]
+			So(user.Name, ShouldEqual, "User")
+			So(user.Properties, ShouldContainKey, "address")
+			So(user.Properties["address"].Required, ShouldBeFalse)
+			So(lib.Types["Admin"].Properties["level"].Type, ShouldEqual, "integer")
+		})
+
+		Convey("resource types get the traits of the library and of its libraries", func() {
+			get := lib.ResourceTypes["collection"].Get
+			So(get, ShouldNotBeNil)
+			So(get.QueryParameters, ShouldContainKey, "page")
+			So(get.QueryParameters, ShouldContainKey, "sort")
+		})
+
+		Convey("the examples of the types are validated", func() {
+			err := ParseFile("./samples/libraries/processed/bad_example.raml", new(Library))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "type Count: invalid example")
+		})
 	})
 }
@@ -0,0 +1,89 @@
+package raml
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LibraryRef is a library used by a document
+type LibraryRef struct {
+	// name of the library in the `uses` of the document, e.g. `files`
+	Name string
+
+	// file of the library as written in the `uses` of the document
+	Filename string
+}
+
+// LibraryChain is the chain of libraries a declaration is resolved through,
+// from the library used by the root document to the library declaring it.
+// It is empty for the declarations of the root document.
+type LibraryChain []LibraryRef
+
+// String returns the chain from the root document,
+// e.g. `root → files (libraries/files.raml) → file-type (libraries/file-type.raml)`
+func (c LibraryChain) String() string {
+	parts := []string{"root"}
+	for _, lib := range c {
+		parts = append(parts, fmt.Sprintf("%v (%v)", lib.Name, lib.Filename))
+	}
+	return strings.Join(parts, " → ")
+}
+
+// resolveLibrary returns the library declaring a qualified name, e.g. `lib.nested.Type`,
+// the chain of libraries to it and the unqualified name
+func (apiDef *APIDefinition) resolveLibrary(name string) (*Library, LibraryChain, string, bool) {
+	splitted := strings.Split(strings.TrimSpace(name), ".")
+	if len(splitted) < 2 {
+		return nil, nil, name, false
+	}
+	libs := apiDef.Libraries
+	var (
+		lib   *Library
+		chain LibraryChain
+	)
+	for _, libName := range splitted[:len(splitted)-1] {
+		var ok bool
+		if lib, ok = libs[libName]; !ok {
+			return nil, nil, name, false
+		}
+		chain = append(chain, LibraryRef{Name: libName, Filename: lib.Filename})
+		libs = lib.Libraries
+	}
+	return lib, chain, splitted[len(splitted)-1], true
+}
+
+// TraitByName gets trait by its name, declared in this document
+// or in a library and qualified by the library name, e.g. `lib.nested.trait`.
+// The returned trait is a copy.
+func (apiDef *APIDefinition) TraitByName(name string) (*Trait, bool) {
+	name = strings.TrimSpace(name)
+	if lib, chain, declName, ok := apiDef.resolveLibrary(name); ok {
+		if t, ok := lib.Traits[declName]; ok {
+			t.LibraryChain = chain
+			return &t, true
+		}
+	}
+	// the traits of the libraries are also in the traits of this document,
+	// qualified by the name of their library only
+	if t, ok := apiDef.Traits[name]; ok && !strings.Contains(name, ".") {
+		return &t, true
+	}
+	return nil, false
+}
+
+// ResourceTypeByName gets resource type by its name, declared in this document
+// or in a library and qualified by the library name, e.g. `lib.nested.collection`.
+// The returned resource type is a copy.
+func (apiDef *APIDefinition) ResourceTypeByName(name string) (*ResourceType, bool) {
+	name = strings.TrimSpace(name)
+	if lib, chain, declName, ok := apiDef.resolveLibrary(name); ok {
+		if rt, ok := lib.ResourceTypes[declName]; ok {
+			rt.LibraryChain = chain
+			return &rt, true
+		}
+	}
+	if rt, ok := apiDef.ResourceTypes[name]; ok && !strings.Contains(name, ".") {
+		return &rt, true
+	}
+	return nil, false
+}
@@ -0,0 +1,145 @@
+package raml
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Limit is a resource limit of the parsing of a document
+type Limit int
+
+const (
+	// IncludeDepthLimit is the nesting of the included files and libraries, see WithMaxIncludeDepth
+	IncludeDepthLimit Limit = iota
+
+	// FileSizeLimit is the size of a document, included file or library, see WithMaxDocumentSize
+	FileSizeLimit
+
+	// IncludeCountLimit is the number of included files and libraries, see WithMaxIncludes
+	IncludeCountLimit
+
+	// ExpandedSizeLimit is the size of all the files read for a document,
+	// counted every time they are included, see WithMaxExpandedSize
+	ExpandedSizeLimit
+)
+
+// LimitError is the error of a document exceeding a resource limit.
+// The parsing functions return it as is, e.g. errors.As(err, &limitErr).
+type LimitError struct {
+	Limit Limit
+
+	// maximum of the limit, a number of files or of bytes
+	Max int64
+
+	// path or URL of the file which exceeds the limit
+	File string
+}
+
+func (e *LimitError) Error() string {
+	switch e.Limit {
+	case IncludeDepthLimit:
+		return fmt.Sprintf("%v: maximum include depth %d exceeded", e.File, e.Max)
+	case FileSizeLimit:
+		return fmt.Sprintf("could not read %v: larger than %d bytes", e.File, e.Max)
+	case IncludeCountLimit:
+		return fmt.Sprintf("%v: maximum of %d included files exceeded", e.File, e.Max)
+	}
+	return fmt.Sprintf("%v: maximum expanded size of %d bytes exceeded", e.File, e.Max)
+}
+
+// WithMaxIncludes limits the number of included files and libraries
+// of a document, counted every time they are included
+func WithMaxIncludes(n int) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.maxIncludes = n
+	}
+}
+
+// WithMaxExpandedSize limits the size in bytes of all the files read for a document:
+// the document itself, its included files and its libraries, counted every time
+// they are included
+func WithMaxExpandedSize(n int64) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.maxExpandedSize = n
+	}
+}
+
+// includeUsage counts the files read for a document,
+// it is shared with the libraries of the document
+type includeUsage struct {
+	includes int
+	size     int64
+
+	// the first limit exceeded
+	err *LimitError
+}
+
+// exceeded returns the error of an exceeded limit and records it
+func (cfg *parseConfig) exceeded(limit Limit, max int64, file string) error {
+	err := &LimitError{Limit: limit, Max: max, File: file}
+	if cfg.usage != nil && cfg.usage.err == nil {
+		cfg.usage.err = err
+	}
+	return err
+}
+
+// limitError returns the recorded LimitError if the parsing failed because of it,
+// err otherwise, since the errors of the included files and libraries are text
+func (cfg *parseConfig) limitError(err error) error {
+	if err != nil && cfg.usage != nil && cfg.usage.err != nil {
+		return cfg.usage.err
+	}
+	return err
+}
+
+// countInclude counts an included file or library
+func (cfg *parseConfig) countInclude(address string) error {
+	if cfg.usage == nil {
+		return nil
+	}
+	cfg.usage.includes++
+	if cfg.maxIncludes > 0 && cfg.usage.includes > cfg.maxIncludes {
+		return cfg.exceeded(IncludeCountLimit, int64(cfg.maxIncludes), address)
+	}
+	return nil
+}
+
+// readLimited reads a file with the size limit of WithMaxDocumentSize,
+// length is the announced size of the file, -1 if unknown
+func (cfg *parseConfig) readLimited(address string, r io.Reader, length int64) ([]byte, error) {
+	max := cfg.maxDocumentSize
+	if max == 0 {
+		max = DefaultMaxDocumentSize
+	}
+	if max > 0 && length > max {
+		return nil, cfg.exceeded(FileSizeLimit, max, address)
+	}
+
+	var content []byte
+	var err error
+	if max > 0 {
+		content, err = ioutil.ReadAll(io.LimitReader(r, max+1))
+	} else {
+		content, err = ioutil.ReadAll(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if max > 0 && int64(len(content)) > max {
+		return nil, cfg.exceeded(FileSizeLimit, max, address)
+	}
+	return content, nil
+}
+
+// countSize counts the size of a file read for the document in the expanded size
+func (cfg *parseConfig) countSize(address string, size int) error {
+	if cfg.usage == nil {
+		return nil
+	}
+	cfg.usage.size += int64(size)
+	if cfg.maxExpandedSize > 0 && cfg.usage.size > cfg.maxExpandedSize {
+		return cfg.exceeded(ExpandedSizeLimit, cfg.maxExpandedSize, address)
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package raml
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -19,7 +21,9 @@ type Method struct {
 	// Its value is a string and MAY be formatted using markdown.
 	Description string `yaml:"description"`
 
-	// TODO : annotation
+	// Annotations of the method, keyed by the annotation name
+	// in parentheses as written in the document, e.g. `(owner)`.
+	Annotations map[string]interface{} `yaml:",regexp:\\(.*\\)"`
 
 	// Detailed information about any query parameters needed by this method.
 	// Mutually exclusive with queryString.
@@ -51,11 +55,21 @@ type Method struct {
 	// A list of the traits to apply to this method.
 	Is []DefinitionChoice `yaml:"is"`
 
+	// The names of the traits applied to this method, in the order they are applied,
+	// from the highest precedence: the traits of the method and of its resource,
+	// see TraitOrder, then the traits of the method of the resource type.
+	AppliedTraits []string `yaml:"-"`
+
 	// The security schemes that apply to this method.
 	SecuredBy []DefinitionChoice `yaml:"securedBy"`
 
 	// name of the resource type this method inherited
 	resourceTypeName string
+
+	_apiDef *APIDefinition
+
+	// deprecation of the resource of the method, see Deprecation
+	resourceDeprecation *Deprecation
 }
 
 func newMethod(name string) *Method {
@@ -65,21 +79,63 @@ func newMethod(name string) *Method {
 }
 
 // doing post processing that can't be done by YAML parser
-func (m *Method) postProcess(r *Resource, name string, traitsMap map[string]Trait, apiDef *APIDefinition) {
+func (m *Method) postProcess(r *Resource, name string, traitsMap map[string]Trait, apiDef *APIDefinition) error {
 	m.Name = name
-	m.inheritFromTraits(r, append(r.Is, m.Is...), traitsMap, apiDef)
+	m._apiDef = apiDef
+	if err := m.inheritFromTraits(r, r.Is, m.Is, traitsMap, apiDef); err != nil {
+		return fmt.Errorf("%v %v: %v", name, r.FullURI(), err)
+	}
 	r.Methods = append(r.Methods, m)
 
+	// query parameters
+	for qpName, qp := range m.QueryParameters {
+		if err := qp.validateSerialization(); err != nil {
+			return fmt.Errorf("%v %v: query parameter %v: %v", name, r.FullURI(), qpName, err)
+		}
+		if qp.Name == "" {
+			qp.Name = qpName
+			m.QueryParameters[qpName] = qp
+		}
+	}
+
 	// post process the responses
 	resps := make(map[HTTPCode]Response)
 	for code, resp := range m.Responses {
+		if err := code.Validate(); err != nil {
+			return fmt.Errorf("%v %v: %v", name, r.FullURI(), err)
+		}
+		resp.HTTPCode = code
 		resp.postProcess()
 		resps[code] = resp
 	}
 	m.Responses = resps
 
+	// headers declared several times in different cases
+	location := name + " " + r.FullURI()
+	apiDef.warnDuplicateHeaders(location, m.Headers)
+	for _, code := range sortedKeys(m.Responses) {
+		apiDef.warnDuplicateHeaders(fmt.Sprintf("%v: response %v", location, code), m.Responses[code].Headers)
+	}
+
 	// post process request body
 	m.Bodies.postProcess()
+	return nil
+}
+
+// validateResponseCodes checks the status codes of the responses declared by a trait or
+// a resource type, a code given by a parameter, e.g. `<<code>>`, is checked once applied
+func validateResponseCodes(responses ...map[HTTPCode]Response) error {
+	for _, resps := range responses {
+		for _, code := range sortedKeys(resps) {
+			if strings.Contains(string(code), "<<") {
+				continue
+			}
+			if err := code.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // inherit from resource type
@@ -90,7 +146,13 @@ func (m *Method) inheritFromResourceType(r *Resource, rtm *Method, apiDef *APIDe
 	if rtm == nil {
 		return
 	}
-	dicts := initResourceTypeDicts(r, r.Type.Parameters)
+	// the parameters are the ones of the resource, with the name of this method,
+	// so every method gets its own wording, e.g. `<<methodName>> a <<resourcePathName | !singularize>>`
+	dicts := map[string]interface{}{}
+	for k, v := range initResourceTypeDicts(r, r.Type.Parameters) {
+		dicts[k] = v
+	}
+	dicts["methodName"] = strings.ToLower(m.Name)
 
 	// inherit description
 	m.Description = substituteParams(m.Description, rtm.Description, dicts)
@@ -108,44 +170,76 @@ func (m *Method) inheritFromResourceType(r *Resource, rtm *Method, apiDef *APIDe
 	m.inheritQueryParams(rtm.QueryParameters, dicts)
 
 	// inherit response
-	m.inheritResponses(rtm.Responses, dicts, apiDef)
+	m.inheritResponses(rtm.Responses, dicts, m.resourceTypeName, apiDef)
 
 	// inherit protocols
 	m.inheritProtocols(rtm.Protocols)
+
+	m.AppliedTraits = append(m.AppliedTraits, rtm.AppliedTraits...)
 }
 
 // inherit from all traits, inherited traits are:
 // - resource level trait
 // - method trait
-func (m *Method) inheritFromTraits(r *Resource, is []DefinitionChoice, traitsMap map[string]Trait,
+// they are applied in the order of APIDefinition.TraitOrder
+func (m *Method) inheritFromTraits(r *Resource, resourceIs, methodIs []DefinitionChoice, traitsMap map[string]Trait,
 	apiDef *APIDefinition) error {
-	for _, tDef := range is {
+	order, text := MethodTraitsFirst, KeepMethodText
+	if apiDef != nil {
+		order, text = apiDef.TraitOrder, apiDef.TraitText
+	}
+	// the text of the trait of highest precedence overwrites the text of the method
+	displayNameText, descriptionText := text, text
+
+	for _, tDef := range order.apply(resourceIs, methodIs) {
 		// acquire traits object
 		t, ok := traitsMap[tDef.Name]
 		if !ok {
-			return fmt.Errorf("invalid traits name:%v", tDef.Name)
+			if apiDef != nil && apiDef.cfg != nil && apiDef.cfg.strict {
+				return fmt.Errorf("invalid traits name:%v", tDef.Name)
+			}
+			location := m.Name
+			if r != nil {
+				location += " " + r.FullURI()
+			}
+			apiDef.warn(location, "invalid traits name:%v", tDef.Name)
+			continue
+		}
+		if err := checkParams("trait", tDef.Name, t, tDef.Parameters, traitReservedParams); err != nil {
+			return err
 		}
 
-		if err := m.inheritFromATrait(r, &t, tDef.Parameters, apiDef); err != nil {
+		if err := m.inheritFromATrait(r, tDef.Name, &t, tDef.Parameters, displayNameText, descriptionText,
+			apiDef); err != nil {
 			return err
 		}
+		m.AppliedTraits = append(m.AppliedTraits, tDef.Name)
+		if displayNameText == OverwriteMethodText && t.DisplayName != "" {
+			displayNameText = KeepMethodText
+		}
+		if descriptionText == OverwriteMethodText && t.Description != "" {
+			descriptionText = KeepMethodText
+		}
 	}
 	return nil
 }
 
 // inherit from a trait
+// name is the name of the trait as used by the method, e.g. `files.drm`
 // dicts is map of trait parameters values
-func (m *Method) inheritFromATrait(r *Resource, t *Trait, dicts map[string]interface{},
-	apiDef *APIDefinition) error {
+// displayNameText and descriptionText tell how the text of the trait is merged
+func (m *Method) inheritFromATrait(r *Resource, name string, t *Trait, dicts map[string]interface{},
+	displayNameText, descriptionText TraitTextMerge, apiDef *APIDefinition) error {
 	dicts = initTraitDicts(r, m, dicts)
 
-	m.Description = substituteParams(m.Description, t.Description, dicts)
+	m.DisplayName = displayNameText.merge(m.DisplayName, t.DisplayName, " ", dicts)
+	m.Description = descriptionText.merge(m.Description, t.Description, "\n\n", dicts)
 
-	m.Bodies.inherit(t.Bodies, dicts, m.resourceTypeName, apiDef)
+	m.Bodies.inherit(t.Bodies, dicts, name, apiDef)
 
 	m.inheritHeaders(t.Headers, dicts)
 
-	m.inheritResponses(t.Responses, dicts, apiDef)
+	m.inheritResponses(t.Responses, dicts, name, apiDef)
 
 	m.inheritQueryParams(t.QueryParameters, dicts)
 
@@ -170,7 +264,12 @@ func inheritHeaders(childs, parents map[HTTPHeader]Header, dicts map[string]inte
 		childs = map[HTTPHeader]Header{}
 	}
 
-	for name, parent := range parents {
+	for _, name := range sortedKeys(parents) {
+		parent := parents[name]
+		// the header could be declared in another case by the child
+		if childName, _, ok := lookupHeader(childs, string(name)); ok {
+			name = childName
+		}
 		h, ok := childs[name]
 		if !ok {
 			if optionalTraitProperty(string(name)) { // don't inherit optional property if not exist
@@ -186,6 +285,50 @@ func inheritHeaders(childs, parents map[HTTPHeader]Header, dicts map[string]inte
 	return childs
 }
 
+// Annotation returns the value of an annotation of this method,
+// the name could be given with or without the parentheses, e.g. `owner`
+func (m *Method) Annotation(name string) (interface{}, bool) {
+	return annotation(m.Annotations, name)
+}
+
+// Header returns the header of this method with the given name,
+// including the headers inherited from traits and resource types.
+// The name is case-insensitive.
+func (m *Method) Header(name string) (Header, bool) {
+	_, h, ok := lookupHeader(m.Headers, name)
+	return h, ok
+}
+
+// lookupHeader finds a header by its case-insensitive name,
+// it returns the name of the header as declared. Among the headers
+// declared in different cases, the first one by name is found.
+func lookupHeader(headers map[HTTPHeader]Header, name string) (HTTPHeader, Header, bool) {
+	if h, ok := headers[HTTPHeader(name)]; ok {
+		return HTTPHeader(name), h, true
+	}
+	canonical := HTTPHeader(name).Canonical()
+	for _, declared := range sortedKeys(headers) {
+		if declared.Canonical() == canonical {
+			return declared, headers[declared], true
+		}
+	}
+	return "", Header{}, false
+}
+
+// warnDuplicateHeaders warns about the headers declared several times in different cases,
+// e.g. `X-Id` and `x-id`, the case-insensitive lookups find the first one by name
+func (apiDef *APIDefinition) warnDuplicateHeaders(location string, headers map[HTTPHeader]Header) {
+	first := map[HTTPHeader]HTTPHeader{}
+	for _, name := range sortedKeys(headers) {
+		canonical := name.Canonical()
+		if f, ok := first[canonical]; ok {
+			apiDef.warn(location, "header %v is also declared as %v, %v is used", name, f, f)
+			continue
+		}
+		first[canonical] = name
+	}
+}
+
 // inheritQueryParams inherit method's query params from parent query params.
 // parent query params could be from resource type or a trait
 func (m *Method) inheritQueryParams(parents map[string]NamedParameter, dicts map[string]interface{}) {
@@ -215,9 +358,18 @@ func (m *Method) inheritProtocols(parent []string) {
 	}
 }
 
+// defaultMediaType returns the media type of the bodies declared without media type:
+// the default media type of the API, a body of another media type declares it explicitly
+func (m *Method) defaultMediaType() string {
+	if m._apiDef == nil {
+		return ""
+	}
+	return m._apiDef.MediaType
+}
+
 // inheritResponses inherit method's responses from parent responses
-// parent responses could be from resource type or a trait
-func (m *Method) inheritResponses(parent map[HTTPCode]Response, dicts map[string]interface{},
+// parent responses could be from resource type or a trait, context is its name
+func (m *Method) inheritResponses(parent map[HTTPCode]Response, dicts map[string]interface{}, context string,
 	apiDef *APIDefinition) {
 	if len(m.Responses) == 0 { // allocate if needed
 		m.Responses = map[HTTPCode]Response{}
@@ -230,7 +382,7 @@ func (m *Method) inheritResponses(parent map[HTTPCode]Response, dicts map[string
 			}
 			resp = Response{HTTPCode: code}
 		}
-		resp.inherit(rParent, dicts, m.resourceTypeName, apiDef)
+		resp.inherit(rParent, dicts, context, apiDef)
 		m.Responses[code] = resp
 	}
 
@@ -246,13 +398,14 @@ type Response struct {
 
 	// HTTP status code of the response
 	HTTPCode HTTPCode
-	// TODO: Fill this during the post-processing phase
 
 	// A substantial, human-friendly description of a response.
 	// Its value is a string and MAY be formatted using markdown.
 	Description string
 
-	// TODO : annotation
+	// Annotations of the response, keyed by the annotation name
+	// in parentheses as written in the document, e.g. `(errorCode)`.
+	Annotations map[string]interface{} `yaml:",regexp:\\(.*\\)"`
 
 	// An API's methods may support custom header values in responses
 	// Detailed information about any response headers returned by this method
@@ -262,151 +415,91 @@ type Response struct {
 	Bodies Bodies `yaml:"body"`
 }
 
-func (resp *Response) postProcess() {
-	resp.Bodies.postProcess()
+// SuccessResponses returns the 2xx responses of this method, sorted by code
+func (m *Method) SuccessResponses() []Response {
+	return m.responsesOf(func(class int) bool { return class == 2 })
 }
 
-// inherit from parent response
-func (resp *Response) inherit(parent Response, dicts map[string]interface{}, rtName string,
-	apiDef *APIDefinition) {
-	resp.Description = substituteParams(resp.Description, parent.Description, dicts)
-	resp.Bodies.inherit(parent.Bodies, dicts, rtName, apiDef)
-	resp.Headers = inheritHeaders(resp.Headers, parent.Headers, dicts)
+// ErrorResponses returns the 4xx and 5xx responses of this method, sorted by code
+func (m *Method) ErrorResponses() []Response {
+	return m.responsesOf(func(class int) bool { return class == 4 || class == 5 })
 }
 
-// Body is the request/response body
-// Some method verbs expect the resource to be sent as a request body.
-// For example, to create a resource, the request must include the details of
-// the resource to create.
-// Resources CAN have alternate representations. For example, an API might
-// support both JSON and XML representations.
-type Body struct {
-	mediaType string `yaml:"mediaType"`
-	// TODO: Fill this during the post-processing phase
-
-	// The structure of a request or response body MAY be further specified
-	// by the schema property under the appropriate media type.
-	// The schema key CANNOT be specified if a body's media type is
-	// application/x-www-form-urlencoded or multipart/form-data.
-	// All parsers of RAML MUST be able to interpret JSON Schema [JSON_SCHEMA]
-	// and XML Schema [XML_SCHEMA].
-	// Alternatively, the value of the schema field MAY be the name of a schema
-	// specified in the root-level schemas property
-	Schema string `yaml:"schema"`
-
-	// Brief description
-	Description string `yaml:"description"`
-
-	// Example attribute to generate example invocations
-	Example string `yaml:"example"`
-
-	Headers map[HTTPHeader]Header `yaml:"headers"`
+// ResponseFor returns the response of this method for the given status code.
+// The response declared for the code is preferred, followed by the response
+// declared for the range of the code, e.g. `4XX`, and the `default` response.
+func (m *Method) ResponseFor(status int) (Response, bool) {
+	codes := []HTTPCode{
+		HTTPCode(strconv.Itoa(status)),
+		HTTPCode(strconv.Itoa(status/100) + "XX"),
+		HTTPCode(strconv.Itoa(status/100) + "xx"),
+		"default",
+	}
+	for _, code := range codes {
+		if resp, ok := m.Responses[code]; ok {
+			return resp, true
+		}
+	}
+	return Response{}, false
 }
 
-// Bodies is Container of Body types, necessary because of technical reasons.
-type Bodies struct {
-
-	// Instead of using a simple map[HTTPHeader]Body for the body
-	// property of the Response and Method, we use the Bodies struct. Why?
-	// Because some RAML APIs don't use the MIMEType part, instead relying
-	// on the mediaType property in the APIDefinition.
-	// So, you might see:
-	//
-	// responses:
-	//   200:
-	//     body:
-	//       example: "some_example" : "123"
-	//
-	// and also:
-	//
-	// responses:
-	//   200:
-	//     body:
-	//       application/json:
-	//         example: |
-	//           {
-	//             "some_example" : "123"
-	//           }
-
-	// As in the Body type.
-	Schema string `yaml:"schema"`
-
-	// As in the Body type.
-	Description string `yaml:"description"`
-
-	// As in the Body type.
-	Example string `yaml:"example"`
-
-	// Resources CAN have alternate representations. For example, an API
-	// might support both JSON and XML representations. This is the map
-	// between MIME-type and the body definition related to it.
-	ForMIMEType map[string]Body `yaml:",regexp:.*"`
-
-	// TODO: For APIs without a priori knowledge of the response types for
-	// their responses, "*/*" MAY be used to indicate that responses that do
-	// not matching other defined data types MUST be accepted. Processing
-	// applications MUST match the most descriptive media type first if
-	// "*/*" is used.
-	ApplicationJSON *BodiesProperty `yaml:"application/json"`
-
-	// Request/response body type
-	Type string `yaml:"type"`
+// RequestContentTypes returns the media types of the request body of this method, sorted.
+// A body declared without media type has the default media type of the API.
+func (m *Method) RequestContentTypes() []string {
+	return m.Bodies.contentTypes(m.defaultMediaType())
 }
 
-// IsEmpty returns true if the body is empty
-func (b *Bodies) IsEmpty() bool {
-	return b.Type == "" && b.ApplicationJSON == nil
+// ResponseContentTypes returns the media types of the body of the response
+// for the given status code, sorted. The response is selected by ResponseFor.
+// A body declared without media type has the default media type of the API.
+func (m *Method) ResponseContentTypes(status int) []string {
+	resp, ok := m.ResponseFor(status)
+	if !ok {
+		return nil
+	}
+	return resp.Bodies.contentTypes(m.defaultMediaType())
 }
 
-// inherit inherits bodies properties from a parent bodies
-// parent object could be from trait or response type
-func (b *Bodies) inherit(parent Bodies, dicts map[string]interface{}, rtName string, apiDef *APIDefinition) {
-	b.Schema = substituteParams(b.Schema, parent.Schema, dicts)
-	b.Description = substituteParams(b.Description, parent.Description, dicts)
-	b.Example = substituteParams(b.Example, parent.Example, dicts)
-
-	b.Type = mergeTypeName(substituteParams(b.Type, parent.Type, dicts), rtName, apiDef)
-
-	// request body
-	if parent.ApplicationJSON != nil {
-		if b.ApplicationJSON == nil { // allocate if needed
-			b.ApplicationJSON = &BodiesProperty{Properties: map[string]interface{}{}}
-		} else if b.ApplicationJSON.Properties == nil {
-			b.ApplicationJSON.Properties = map[string]interface{}{}
-		}
-
-		b.ApplicationJSON.Type = substituteParams(b.ApplicationJSON.TypeString(), parent.ApplicationJSON.TypeString(), dicts)
-		// check if type name is in library
-		if typeStr, ok := b.ApplicationJSON.Type.(string); ok {
-			b.ApplicationJSON.Type = mergeTypeName(typeStr, rtName, apiDef)
+// responsesOf returns the responses which status code class, e.g. 2 for 2xx,
+// is accepted by the filter, sorted by code
+func (m *Method) responsesOf(filter func(class int) bool) []Response {
+	var codes []string
+	for code := range m.Responses {
+		if class, ok := code.class(); ok && filter(class) {
+			codes = append(codes, string(code))
 		}
+	}
+	sort.Strings(codes)
 
-		for k, p := range parent.ApplicationJSON.Properties {
-			if _, ok := b.ApplicationJSON.Properties[k]; !ok {
-
-				// handle optional properties as described in
-				// https://github.com/raml-org/raml-spec/blob/raml-10/versions/raml-10/raml-10.md#optional-properties
-				switch {
-				case strings.HasSuffix(k, `\?`): // if ended with `\?` we make it optional property
-					k = k[:len(k)-2] + "?"
-				case strings.HasSuffix(k, "?"): // if only ended with `?`, we can ignore it
-					continue
-				}
-				k = substituteParams(k, k, dicts)
-				prop := toProperty(k, p)
-				inheritedType := substituteParams(prop.TypeString(), prop.TypeString(), dicts)
-				b.ApplicationJSON.Properties[k] = mergeTypeName(inheritedType, rtName, apiDef)
-			}
-		}
+	var resps []Response
+	for _, code := range codes {
+		resps = append(resps, m.Responses[HTTPCode(code)])
 	}
+	return resps
+}
 
-	// TODO : formimeytype
+// Header returns the header of this response with the given name.
+// The name is case-insensitive.
+func (resp *Response) Header(name string) (Header, bool) {
+	_, h, ok := lookupHeader(resp.Headers, name)
+	return h, ok
 }
 
-func (b *Bodies) postProcess() {
-	if b.ApplicationJSON == nil {
-		return
-	}
+// Annotation returns the value of an annotation of this response,
+// the name could be given with or without the parentheses, e.g. `errorCode`
+func (resp *Response) Annotation(name string) (interface{}, bool) {
+	return annotation(resp.Annotations, name)
+}
+
+func (resp *Response) postProcess() {
+	resp.Bodies.postProcess()
+}
 
-	b.ApplicationJSON.postProcess()
+// inherit from parent response
+func (resp *Response) inherit(parent Response, dicts map[string]interface{}, context string,
+	apiDef *APIDefinition) {
+	resp.Description = substituteParams(resp.Description, parent.Description, dicts)
+	resp.Bodies.inherit(parent.Bodies, dicts, context, apiDef)
+	resp.Headers = inheritHeaders(resp.Headers, parent.Headers, dicts)
+	resp.Annotations = inheritAnnotations(resp.Annotations, parent.Annotations)
 }
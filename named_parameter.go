@@ -1,5 +1,9 @@
 package raml
 
+import (
+	"strings"
+)
+
 // NamedParameter is collection of named parameters
 // The RAML Specification uses collections of named parameters for the
 // following properties: URI parameters, query string parameters, form
@@ -67,8 +71,14 @@ type NamedParameter struct {
 
 	// An example value for the property. This can be used, e.g., by
 	// documentation generators to generate sample values for the property.
+	// The value keeps its YAML type, e.g. `example: 42` is an int.
 	Example interface{}
 
+	// Named examples of the property, keyed by the example name.
+	// An example could be the value itself or a structured example,
+	// use AllExamples to get them as Example.
+	Examples map[string]interface{}
+
 	// The repeat attribute specifies that the parameter can be repeated,
 	// i.e. the parameter can be used multiple times
 	Repeat *bool // TODO: What does this mean?
@@ -83,6 +93,10 @@ type NamedParameter struct {
 	// its value is not specified
 	Default Any
 
+	// Annotations of the parameter, keyed by the annotation name
+	// in parentheses as written in the document, e.g. `(serialization)`.
+	Annotations map[string]interface{} `yaml:",regexp:\\(.*\\)"`
+
 	format Any `ramlFormat:"Named parameters must be mappings. Example: userId: {displayName: 'User ID', description: 'Used to identify the user.', type: 'integer', minimum: 1, example: 5}"`
 }
 
@@ -125,6 +139,58 @@ func (np *NamedParameter) inherit(parent NamedParameter, dicts map[string]interf
 	if parent.Required {
 		np.Required = true
 	}
+	if np.Default == nil {
+		np.Default = substituteValue(parent.Default, dicts)
+	}
+	if np.Example == nil {
+		np.Example = parent.Example
+	}
+	for name, ex := range parent.Examples {
+		if np.Examples == nil {
+			np.Examples = map[string]interface{}{}
+		}
+		if _, ok := np.Examples[name]; !ok {
+			np.Examples[name] = ex
+		}
+	}
+	np.Annotations = inheritAnnotations(np.Annotations, parent.Annotations)
+}
+
+// Annotation returns the value of an annotation of this parameter,
+// the name could be given with or without the parentheses, e.g. `serialization`
+func (np NamedParameter) Annotation(name string) (interface{}, bool) {
+	return annotation(np.Annotations, name)
+}
+
+// AllExamples returns the `example` and the `examples` of this parameter.
+// The `example` comes first, followed by the `examples` sorted by name.
+func (np NamedParameter) AllExamples() []Example {
+	return allExamples(np.Example, np.Examples)
+}
+
+// exampleValue returns the value of the first example of this parameter,
+// or its default value if it has no example
+func (np NamedParameter) exampleValue() interface{} {
+	if examples := np.AllExamples(); len(examples) > 0 {
+		return examples[0].Value
+	}
+	return np.Default
+}
+
+// substituteValue substitutes the params of a value of any type, e.g. a default value.
+// A value which is only a param, e.g. `<<pageSize>>`, takes the value of the param
+// with its YAML type, the params of other strings are substituted as text.
+func substituteValue(v interface{}, dicts map[string]interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok || !strings.Contains(s, "<<") {
+		return v
+	}
+	if m := dcRe.FindStringSubmatch(s); m != nil && m[0] == strings.TrimSpace(s) {
+		if val, ok := dicts[strings.TrimSpace(m[1])]; ok {
+			return val
+		}
+	}
+	return substituteParams("", s, dicts)
 }
 
 func inheritStringPointer(val, parent *string, dicts map[string]interface{}) *string {
@@ -0,0 +1,106 @@
+package raml
+
+import "strings"
+
+// nilType is the builtin type of the nil value, e.g. of the nullable types `string | nil`
+const nilType = "nil"
+
+// unionMembers returns the members of a union type expression, e.g. `Cat | Dog`,
+// split at the top level only, e.g. `(Cat | Dog)[]` is not a union,
+// the grouping parentheses are removed, e.g. `(Cat | Dog) | Fish` has 3 members
+func unionMembers(expr string) []string {
+	expr = strings.TrimSpace(expr)
+	for isParenthesized(expr) {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	var members []string
+	add := func(m string) {
+		if m = strings.TrimSpace(m); isParenthesized(m) {
+			members = append(members, unionMembers(m)...)
+			return
+		}
+		members = append(members, m)
+	}
+	depth, start := 0, 0
+	for i, r := range expr {
+		switch r {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '|':
+			if depth == 0 {
+				add(expr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	add(expr[start:])
+	return members
+}
+
+// nonNilMembers returns the members of a type expression which are not nil,
+// and true if it is nullable: a union with nil, e.g. `string | nil`,
+// the `string?` shorthand or `nil` itself
+func nonNilMembers(expr string) ([]string, bool) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "{") || strings.HasPrefix(expr, "<") {
+		// JSON or XML schema
+		return []string{expr}, false
+	}
+	nullable := false
+	if strings.HasSuffix(expr, "?") {
+		expr = strings.TrimSpace(strings.TrimSuffix(expr, "?"))
+		nullable = true
+	}
+	var members []string
+	for _, m := range unionMembers(expr) {
+		if m == nilType {
+			nullable = true
+			continue
+		}
+		if m != "" {
+			members = append(members, m)
+		}
+	}
+	return members, nullable
+}
+
+// nonNullType returns a type expression without its nil members, `nil` if it has no other member
+func nonNullType(expr string) string {
+	members, nullable := nonNilMembers(expr)
+	if !nullable {
+		return strings.TrimSpace(expr)
+	}
+	if len(members) == 0 {
+		return nilType
+	}
+	return strings.Join(members, " | ")
+}
+
+// IsNullable returns true if the values of this type could be nil:
+// its type is a union with nil, e.g. `string | nil`, `string?` or `nil`
+func (t Type) IsNullable() bool {
+	_, nullable := nonNilMembers(t.TypeString())
+	return nullable
+}
+
+// NonNullType returns the type expression of this type without nil,
+// e.g. `string` for `string | nil` or `string?`
+func (t Type) NonNullType() string {
+	return nonNullType(t.TypeString())
+}
+
+// IsNullable returns true if the values of this property could be nil:
+// its type is a union with nil, e.g. `string | nil`, `string?` or `nil`.
+// An optional property, `name?`, could be missing but isn't nullable.
+func (p Property) IsNullable() bool {
+	_, nullable := nonNilMembers(p.TypeString())
+	return nullable
+}
+
+// NonNullType returns the type expression of this property without nil,
+// e.g. `string` for `string | nil` or `string?`
+func (p Property) NonNullType() string {
+	return nonNullType(p.TypeString())
+}
@@ -0,0 +1,75 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNullable(t *testing.T) {
+	Convey("nullable types", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/nullable.raml", apiDef, WithUnknownKeyErrors()), ShouldBeNil)
+		comment := apiDef.Types["Comment"]
+
+		Convey("properties", func() {
+			editedAt := comment.GetProperty("editedAt")
+			So(editedAt.IsNullable(), ShouldBeTrue)
+			So(editedAt.IsUnion(), ShouldBeFalse)
+			So(editedAt.NonNullType(), ShouldEqual, "datetime")
+
+			author := comment.GetProperty("author")
+			So(author.IsNullable(), ShouldBeTrue)
+			So(author.NonNullType(), ShouldEqual, "string")
+
+			parent := comment.GetProperty("parent")
+			So(parent.IsNullable(), ShouldBeTrue)
+			So(parent.Required, ShouldBeFalse)
+
+			// optional, not nullable
+			So(comment.GetProperty("text").IsNullable(), ShouldBeFalse)
+			// an array of nullable items
+			So(comment.GetProperty("tags").IsNullable(), ShouldBeFalse)
+		})
+
+		Convey("unions without nil", func() {
+			pet := apiDef.Types["Pet"]
+			So(pet.IsNullable(), ShouldBeTrue)
+			members, ok := pet.Union()
+			So(ok, ShouldBeTrue)
+			So(members, ShouldResemble, []string{"Cat", "Dog"})
+			So(pet.NonNullType(), ShouldEqual, "Cat | Dog")
+
+			nothing := apiDef.Types["Nothing"]
+			So(nothing.IsNullable(), ShouldBeTrue)
+			So(nothing.IsUnion(), ShouldBeFalse)
+			So(nothing.NonNullType(), ShouldEqual, "nil")
+		})
+
+		Convey("parenthesized unions", func() {
+			reactions := comment.GetProperty("reactions")
+			So(reactions.IsArray(), ShouldBeTrue)
+			So(reactions.IsUnion(), ShouldBeFalse)
+
+			members, ok := comment.GetProperty("reactedBy").Union()
+			So(ok, ShouldBeTrue)
+			So(members, ShouldResemble, []string{"Cat", "Dog"})
+
+			seenBy := comment.GetProperty("seenBy")
+			So(seenBy.IsNullable(), ShouldBeTrue)
+			members, ok = seenBy.Union()
+			So(ok, ShouldBeTrue)
+			So(members, ShouldResemble, []string{"Cat", "Dog"})
+
+			So(unionMembers("(Cat | Dog) | (Fish)"), ShouldResemble, []string{"Cat", "Dog", "Fish"})
+		})
+
+		Convey("examples", func() {
+			doc := "#%RAML 1.0\ntitle: API\ntypes:\n  Comment:\n    properties:\n" +
+				"      author: string?\n    example:\n      author: 3\n"
+			err := ParseBytes([]byte(doc), new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "3 is not a string")
+		})
+	})
+}
@@ -0,0 +1,251 @@
+package raml
+
+import "sort"
+
+// OperationDoc is the human-facing text of an operation, once the resource types
+// and traits are applied, for the documentation generators and the API portals
+type OperationDoc struct {
+	// full URI template of the resource, e.g. `/users/{userId}`
+	Path string
+
+	// method name, e.g. GET
+	Method string
+
+	// display name of the method, `GET /users/{userId}` if it has none
+	Title string
+
+	// the `documentation` sections of the API
+	APIDocs []Documentation
+
+	// display name of the resource, its full URI if it has none
+	ResourceTitle string
+
+	ResourceDescription string
+
+	// description of the method, including the text of the traits
+	// merged according to APIDefinition.TraitText
+	Description string
+
+	// the traits applied to the method, in order, see Method.AppliedTraits
+	Traits []TraitDoc
+
+	// URI parameters of the resource and of its parents, in the order of the URI
+	URIParameters []ParameterDoc
+
+	// query parameters and headers of the request, sorted by name
+	QueryParameters []ParameterDoc
+	Headers         []ParameterDoc
+
+	// request bodies, sorted by media type
+	Bodies []BodyDoc
+
+	// responses, sorted by code
+	Responses []ResponseDoc
+
+	// security schemes of the operation, see Operation.SecuredBy
+	SecuredBy []SecuritySchemeDoc
+
+	// tags of the operation, see Operation.Tags
+	Tags []string
+}
+
+// TraitDoc is the text of a trait applied to an operation,
+// its parameters are substituted
+type TraitDoc struct {
+	Name        string
+	DisplayName string
+	Description string
+	Usage       string
+}
+
+// ParameterDoc is the text of a parameter or a header
+type ParameterDoc struct {
+	Name        string
+	DisplayName string
+	Description string
+	Type        string
+	Required    bool
+}
+
+// BodyDoc is the text of a request or response body
+type BodyDoc struct {
+	MediaType   string
+	Description string
+
+	// name of the type of the body, empty if inline or unknown
+	Type string
+}
+
+// ResponseDoc is the text of a response
+type ResponseDoc struct {
+	Code        string
+	Description string
+	Headers     []ParameterDoc
+	Bodies      []BodyDoc
+}
+
+// SecuritySchemeDoc is the text of a security scheme
+type SecuritySchemeDoc struct {
+	Name        string
+	Type        string
+	DisplayName string
+	Description string
+}
+
+// OperationDocs returns the documentation of all the operations,
+// in the order of Operations
+func (apiDef *APIDefinition) OperationDocs() []OperationDoc {
+	var docs []OperationDoc
+	for _, op := range apiDef.Operations() {
+		docs = append(docs, apiDef.OperationDoc(op.Resource, op.Method))
+	}
+	return docs
+}
+
+// OperationDoc assembles the text of a method of a resource: the documentation
+// of the API, the descriptions of the resource, of the method, of its traits,
+// parameters, bodies, responses and security schemes
+func (apiDef *APIDefinition) OperationDoc(r *Resource, m *Method) OperationDoc {
+	uri := r.FullURI()
+	doc := OperationDoc{
+		Path:                uri,
+		Method:              m.Name,
+		Title:               orDefault(m.DisplayName, m.Name+" "+uri),
+		APIDocs:             apiDef.Documentation,
+		ResourceTitle:       orDefault(r.DisplayName, uri),
+		ResourceDescription: r.Description,
+		Description:         m.Description,
+		Traits:              apiDef.traitDocs(r, m),
+		QueryParameters:     parameterDocs(m.QueryParameters),
+		Headers:             headerDocs(m.Headers),
+		Bodies:              bodyDocs(&m.Bodies),
+		Tags:                operationTags(r, m),
+	}
+	for _, name := range uriTemplateParams(uri) {
+		doc.URIParameters = append(doc.URIParameters, parameterDoc(name, findURIParameter(r, name), true))
+	}
+
+	codes := make([]string, 0, len(m.Responses))
+	for code := range m.Responses {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		resp := m.Responses[HTTPCode(code)]
+		doc.Responses = append(doc.Responses, ResponseDoc{
+			Code:        code,
+			Description: resp.Description,
+			Headers:     headerDocs(resp.Headers),
+			Bodies:      bodyDocs(&resp.Bodies),
+		})
+	}
+
+	for _, name := range apiDef.effectiveSecuredBy(r, m) {
+		ss, _ := apiDef.GetSecurityScheme(name)
+		doc.SecuredBy = append(doc.SecuredBy, SecuritySchemeDoc{
+			Name:        name,
+			Type:        ss.Type,
+			DisplayName: ss.DisplayName,
+			Description: ss.Description,
+		})
+	}
+	return doc
+}
+
+// traitDocs returns the text of the traits applied to a method,
+// with the parameters of the `is` which applies them
+func (apiDef *APIDefinition) traitDocs(r *Resource, m *Method) []TraitDoc {
+	if len(m.AppliedTraits) == 0 {
+		return nil
+	}
+	traits := map[string]Trait{}
+	for name, t := range apiDef.Traits {
+		traits[name] = t
+	}
+	traits = apiDef.allTraits(traits, apiDef.Libraries)
+
+	var docs []TraitDoc
+	for _, name := range m.AppliedTraits {
+		t, ok := traits[name]
+		if !ok {
+			continue
+		}
+		var params DefinitionParameters
+		for _, dc := range append(append([]DefinitionChoice{}, m.Is...), r.Is...) {
+			if dc.Name == name {
+				params = dc.Parameters
+				break
+			}
+		}
+		dicts := initTraitDicts(r, m, copyParams(params))
+		docs = append(docs, TraitDoc{
+			Name:        name,
+			DisplayName: substituteParams("", t.DisplayName, dicts),
+			Description: substituteParams("", t.Description, dicts),
+			Usage:       t.Usage,
+		})
+	}
+	return docs
+}
+
+// copyParams copies the parameters of a definition choice,
+// the dicts of the traits are completed with the reserved parameters
+func copyParams(params DefinitionParameters) map[string]interface{} {
+	dicts := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		dicts[k] = v
+	}
+	return dicts
+}
+
+func parameterDoc(name string, np NamedParameter, required bool) ParameterDoc {
+	return ParameterDoc{
+		Name:        name,
+		DisplayName: np.DisplayName,
+		Description: np.Description,
+		Type:        orDefault(np.Type, "string"),
+		Required:    required,
+	}
+}
+
+func parameterDocs(params map[string]NamedParameter) []ParameterDoc {
+	var docs []ParameterDoc
+	for _, name := range sortedParamNames(params) {
+		np := params[name]
+		docs = append(docs, parameterDoc(name, np, np.Required))
+	}
+	return docs
+}
+
+func headerDocs(headers map[HTTPHeader]Header) []ParameterDoc {
+	var docs []ParameterDoc
+	for _, name := range sortedHeaderNames(headers) {
+		h := headers[name]
+		docs = append(docs, parameterDoc(string(name), NamedParameter(h), h.Required))
+	}
+	return docs
+}
+
+func bodyDocs(bodies *Bodies) []BodyDoc {
+	var docs []BodyDoc
+	if bodies.Default != nil {
+		docs = append(docs, bodyDoc("", *bodies.Default))
+	}
+	for _, mediaType := range bodies.MediaTypes() {
+		docs = append(docs, bodyDoc(mediaType, bodies.ForMIMEType[mediaType]))
+	}
+	return docs
+}
+
+func bodyDoc(mediaType string, b Body) BodyDoc {
+	doc := BodyDoc{MediaType: mediaType, Description: b.Description}
+	if name, ok := b.Type.(string); ok && !isJSONString(name) {
+		doc.Type = name
+	}
+	return doc
+}
+
+// isJSONString returns true if a type is an inline JSON schema
+func isJSONString(s string) bool {
+	return len(s) > 0 && (s[0] == '{' || s[0] == '[')
+}
@@ -0,0 +1,72 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOperationDocs(t *testing.T) {
+	Convey("operation documentation", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/operation_docs.raml", apiDef), ShouldBeNil)
+
+		docs := apiDef.OperationDocs()
+		So(docs, ShouldHaveLength, 1)
+		doc := docs[0]
+
+		So(doc.Path, ShouldEqual, "/users/{userId}/friends")
+		So(doc.Method, ShouldEqual, "GET")
+		So(doc.Title, ShouldEqual, "list friends")
+		So(doc.APIDocs, ShouldResemble, []Documentation{{Title: "Getting started", Content: "Ask for an API key."}})
+		So(doc.ResourceTitle, ShouldEqual, "/users/{userId}/friends")
+		So(doc.Description, ShouldEqual, "Lists the friends of a user.")
+
+		Convey("the text of the traits is substituted", func() {
+			So(doc.Traits, ShouldResemble, []TraitDoc{{
+				Name:        "paged",
+				DisplayName: "paged",
+				Description: "Returns at most 20 friends by page.",
+				Usage:       "apply to the collections",
+			}})
+		})
+
+		Convey("parameters", func() {
+			So(doc.URIParameters, ShouldResemble, []ParameterDoc{
+				{Name: "userId", Description: "id of the user", Type: "integer", Required: true},
+			})
+			So(doc.QueryParameters, ShouldResemble, []ParameterDoc{
+				{Name: "page", Description: "number of the page", Type: "integer"},
+			})
+			So(doc.Headers, ShouldResemble, []ParameterDoc{
+				{Name: "X-Request-Id", Description: "id of the request", Type: "string"},
+			})
+		})
+
+		Convey("responses", func() {
+			So(doc.Responses, ShouldResemble, []ResponseDoc{
+				{
+					Code:        "200",
+					Description: "the friends",
+					Bodies:      []BodyDoc{{MediaType: "application/json", Description: "a page of users", Type: "User[]"}},
+				},
+				{Code: "404", Description: "unknown user"},
+			})
+		})
+
+		Convey("security schemes", func() {
+			So(doc.SecuredBy, ShouldResemble, []SecuritySchemeDoc{
+				{Name: "apiKey", Type: "Pass Through", DisplayName: "API key", Description: "The key of the application."},
+			})
+		})
+
+		Convey("the resource text", func() {
+			users := apiDef.Resources["/users"]
+			get := &Method{Name: "GET"}
+			doc := apiDef.OperationDoc(&users, get)
+			So(doc.Title, ShouldEqual, "GET /users")
+			So(doc.ResourceTitle, ShouldEqual, "Users")
+			So(doc.ResourceDescription, ShouldEqual, "The users of the API.")
+		})
+	})
+}
@@ -0,0 +1,260 @@
+package raml
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// styles of pagination
+const (
+	// a page number, e.g. `?page=2&pageSize=50`
+	PagePagination = "page"
+
+	// an offset in the results, e.g. `?offset=100&limit=50`
+	OffsetPagination = "offset"
+
+	// an opaque cursor returned by the previous page, e.g. `?cursor=abc&limit=50`
+	CursorPagination = "cursor"
+)
+
+// conventional names of the traits, annotations and query parameters,
+// compared in lower case without `-` and `_`, e.g. `page_size` matches `pagesize`
+var (
+	paginationNames = []string{"pageable", "paged", "paginated", "pagination"}
+	rateLimitNames  = []string{"ratelimited", "ratelimit", "throttled", "throttle"}
+	sortingNames    = []string{"sortable", "sorted", "sorting"}
+
+	pageParams   = []string{"page", "pagenumber", "pageno"}
+	offsetParams = []string{"offset", "skip", "start"}
+	cursorParams = []string{"cursor", "after", "pagetoken", "continuationtoken"}
+	sizeParams   = []string{"pagesize", "perpage", "limit", "size", "count", "maxresults"}
+	sortParams   = []string{"sort", "sortby", "orderby", "order"}
+)
+
+// Pagination is how the results of an operation are paginated
+type Pagination struct {
+	// PagePagination, OffsetPagination or CursorPagination
+	Style string
+
+	// query parameter of the page number, offset or cursor, empty if not declared
+	Param string
+
+	// query parameter of the page size, empty if not declared
+	SizeParam string
+
+	// default and maximum page size, 0 if not declared
+	DefaultSize int
+	MaxSize     int
+}
+
+// RateLimit is the rate limit of an operation
+type RateLimit struct {
+	// number of requests allowed per window, 0 if not declared
+	Limit int
+
+	// window of the limit as written, e.g. `1m` or `hour`, empty if not declared
+	Window string
+
+	// rate limit headers of the responses, e.g. `X-RateLimit-Remaining`, sorted
+	Headers []string
+}
+
+// Sorting is how the results of an operation are sorted
+type Sorting struct {
+	// query parameter of the sort order, empty if not declared
+	Param string
+
+	// fields the results could be sorted by, empty if not declared
+	Fields []string
+}
+
+// OperationMetadata is the metadata of an operation for API gateways, recognized
+// from the conventional traits, annotations, query parameters and headers.
+// A nil field means the operation has no such metadata.
+type OperationMetadata struct {
+	Pagination *Pagination
+	RateLimit  *RateLimit
+	Sorting    *Sorting
+}
+
+// OperationMetadata returns the pagination, rate limit and sorting of this method,
+// once the traits and resource types are applied. They are recognized from:
+//   - the applied traits named e.g. `pageable`, `rateLimited` or `sortable`,
+//     possibly from a library
+//   - the annotations with the same names, whose values could give the details:
+//     `(pageable): {style: cursor, maxSize: 100}`, `(rateLimited): {limit: 100, window: 1m}`
+//     and `(sortable): [name, createdAt]`
+//   - the query parameters named e.g. `page`, `offset`, `cursor`, `limit` or `sort`
+//   - the response headers named `X-RateLimit-*`, `RateLimit-*` or `Retry-After`
+func (m *Method) OperationMetadata() OperationMetadata {
+	return OperationMetadata{
+		Pagination: m.pagination(),
+		RateLimit:  m.rateLimit(),
+		Sorting:    m.sorting(),
+	}
+}
+
+func (m *Method) pagination() *Pagination {
+	value, annotated := m.conventionAnnotation(paginationNames)
+	p := &Pagination{}
+	for _, name := range sortedKeys(m.QueryParameters) {
+		param := m.QueryParameters[name]
+		switch {
+		case matchesName(name, pageParams):
+			p.Style, p.Param = PagePagination, name
+		case matchesName(name, offsetParams):
+			p.Style, p.Param = OffsetPagination, name
+		case matchesName(name, cursorParams):
+			p.Style, p.Param = CursorPagination, name
+		case matchesName(name, sizeParams):
+			p.SizeParam = name
+			p.DefaultSize = toInt(param.Default)
+			if param.Maximum != nil {
+				p.MaxSize = int(*param.Maximum)
+			}
+		}
+	}
+	if !annotated && !m.appliesConventionTrait(paginationNames) && p.Param == "" {
+		return nil
+	}
+	if details, ok := value.(map[interface{}]interface{}); ok {
+		for k, v := range details {
+			switch fmt.Sprint(k) {
+			case "style":
+				p.Style = fmt.Sprint(v)
+			case "param":
+				p.Param = fmt.Sprint(v)
+			case "sizeParam":
+				p.SizeParam = fmt.Sprint(v)
+			case "defaultSize":
+				p.DefaultSize = toInt(v)
+			case "maxSize":
+				p.MaxSize = toInt(v)
+			}
+		}
+	}
+	if p.Style == "" {
+		p.Style = PagePagination
+	}
+	return p
+}
+
+func (m *Method) rateLimit() *RateLimit {
+	value, annotated := m.conventionAnnotation(rateLimitNames)
+	rl := &RateLimit{}
+	for _, resp := range m.Responses {
+		for name := range resp.Headers {
+			lower := strings.ToLower(string(name))
+			if strings.HasPrefix(lower, "x-ratelimit-") || strings.HasPrefix(lower, "ratelimit") ||
+				lower == "retry-after" {
+				rl.Headers = appendStrNotExist(string(name), rl.Headers)
+			}
+		}
+	}
+	if !annotated && !m.appliesConventionTrait(rateLimitNames) && len(rl.Headers) == 0 {
+		return nil
+	}
+	sort.Strings(rl.Headers)
+	switch details := value.(type) {
+	case map[interface{}]interface{}:
+		for k, v := range details {
+			switch fmt.Sprint(k) {
+			case "limit":
+				rl.Limit = toInt(v)
+			case "window":
+				rl.Window = fmt.Sprint(v)
+			}
+		}
+	case nil:
+	default:
+		rl.Limit = toInt(details)
+	}
+	return rl
+}
+
+func (m *Method) sorting() *Sorting {
+	value, annotated := m.conventionAnnotation(sortingNames)
+	s := &Sorting{}
+	for name := range m.QueryParameters {
+		if matchesName(name, sortParams) && (s.Param == "" || name < s.Param) {
+			s.Param = name
+		}
+	}
+	if !annotated && !m.appliesConventionTrait(sortingNames) && s.Param == "" {
+		return nil
+	}
+	fields := value
+	if details, ok := value.(map[interface{}]interface{}); ok {
+		fields = nil
+		for k, v := range details {
+			switch fmt.Sprint(k) {
+			case "param":
+				s.Param = fmt.Sprint(v)
+			case "fields":
+				fields = v
+			}
+		}
+	}
+	if list, ok := fields.([]interface{}); ok {
+		for _, f := range list {
+			s.Fields = append(s.Fields, fmt.Sprint(f))
+		}
+	}
+	return s
+}
+
+// appliesConventionTrait returns true if one of the traits is applied,
+// with or without the name of its library
+func (m *Method) appliesConventionTrait(names []string) bool {
+	for _, t := range m.AppliedTraits {
+		if i := strings.LastIndex(t, "."); i >= 0 {
+			t = t[i+1:]
+		}
+		if matchesName(t, names) {
+			return true
+		}
+	}
+	return false
+}
+
+// conventionAnnotation returns the value of the first annotation with one of the names
+func (m *Method) conventionAnnotation(names []string) (interface{}, bool) {
+	for _, name := range sortedKeys(m.Annotations) {
+		if matchesName(strings.Trim(name, "()"), names) {
+			return m.Annotations[name], true
+		}
+	}
+	return nil, false
+}
+
+// matchesName returns true if a name is one of the conventional names,
+// in lower case without `-` and `_`
+func matchesName(name string, names []string) bool {
+	name = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	for _, n := range names {
+		if name == n {
+			return true
+		}
+	}
+	return false
+}
+
+// toInt converts a YAML scalar to an int, 0 if it is not a number
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case uint64:
+		return int(n)
+	case float64:
+		return int(n)
+	case string:
+		i, _ := strconv.Atoi(strings.TrimSpace(n))
+		return i
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOperationMetadata(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/operation_metadata.raml", apiDef)
+	Convey("operation metadata", t, func() {
+		So(err, ShouldBeNil)
+
+		Convey("from traits and annotations", func() {
+			md := apiDef.Resources["/books"].Get.OperationMetadata()
+			So(md.Pagination, ShouldResemble, &Pagination{
+				Style:       PagePagination,
+				Param:       "page",
+				SizeParam:   "page_size",
+				DefaultSize: 20,
+				MaxSize:     100,
+			})
+			So(md.RateLimit, ShouldResemble, &RateLimit{Headers: []string{"Retry-After", "X-RateLimit-Remaining"}})
+			So(md.Sorting, ShouldResemble, &Sorting{Param: "sort", Fields: []string{"title", "publishedAt"}})
+		})
+
+		Convey("details of the annotations", func() {
+			md := apiDef.Resources["/events"].Get.OperationMetadata()
+			So(md.Pagination, ShouldResemble, &Pagination{
+				Style:     CursorPagination,
+				Param:     "after",
+				SizeParam: "limit",
+				MaxSize:   50,
+			})
+			So(md.RateLimit, ShouldResemble, &RateLimit{Limit: 100, Window: "1m"})
+			So(md.Sorting, ShouldBeNil)
+		})
+
+		Convey("no metadata", func() {
+			md := apiDef.Resources["/books"].Nested["/{id}"].Get.OperationMetadata()
+			So(md, ShouldResemble, OperationMetadata{})
+		})
+	})
+}
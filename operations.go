@@ -0,0 +1,90 @@
+package raml
+
+import "fmt"
+
+// tagsAnnotation is the annotation of the tags of the operations, e.g. `(tags): [billing]`
+const tagsAnnotation = "tags"
+
+// Operation is a method of a resource, once the resource types and traits are applied
+type Operation struct {
+	// full URI template of the resource, e.g. `/users/{userId}`
+	Path string
+
+	Resource *Resource
+	Method   *Method
+
+	// names of the security schemes which apply to the operation,
+	// from the method, the resource or the API, without `null`
+	SecuredBy []string
+
+	// tags of the operation, e.g. for the sections of the documentation,
+	// see OperationsByTag
+	Tags []string
+}
+
+// Operations returns the operations of the API, flattened from the nested
+// resources, by resource full URI then in the order of the methods
+func (apiDef *APIDefinition) Operations() []Operation {
+	var ops []Operation
+	apiDef.walkResources(func(r *Resource) {
+		for _, m := range r.methods() {
+			ops = append(ops, Operation{
+				Path:      r.FullURI(),
+				Resource:  r,
+				Method:    m,
+				SecuredBy: apiDef.effectiveSecuredBy(r, m),
+				Tags:      operationTags(r, m),
+			})
+		}
+	})
+	return ops
+}
+
+// OperationsByTag groups the operations by tag, in the order of Operations.
+// The tags of an operation are given by the `(tags)` annotation, a tag or a list of tags,
+// of its method, its resource and the parent resources, e.g.:
+//
+//	/invoices:
+//	  (tags): [billing]
+//	  get:
+//	    (tags): reports
+//
+// An operation with several tags is in every group, the operations without tags
+// are grouped under the empty tag.
+func (apiDef *APIDefinition) OperationsByTag() map[string][]Operation {
+	groups := map[string][]Operation{}
+	for _, op := range apiDef.Operations() {
+		if len(op.Tags) == 0 {
+			groups[""] = append(groups[""], op)
+		}
+		for _, tag := range op.Tags {
+			groups[tag] = append(groups[tag], op)
+		}
+	}
+	return groups
+}
+
+// operationTags returns the tags of the method,
+// then the ones of the resource and of its parents
+func operationTags(r *Resource, m *Method) []string {
+	var tags []string
+	add := func(v interface{}, ok bool) {
+		if !ok {
+			return
+		}
+		switch value := v.(type) {
+		case []interface{}:
+			for _, tag := range value {
+				tags = appendStrNotExist(fmt.Sprint(tag), tags)
+			}
+		case nil:
+		default:
+			tags = appendStrNotExist(fmt.Sprint(value), tags)
+		}
+	}
+	add(m.Annotation(tagsAnnotation))
+	for ; r != nil; r = r.Parent {
+		add(r.Annotation(tagsAnnotation))
+	}
+	return tags
+}
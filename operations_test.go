@@ -0,0 +1,38 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOperationsByTag(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/operation_tags.raml", apiDef)
+	Convey("operations grouped by tag", t, func() {
+		So(err, ShouldBeNil)
+
+		Convey("tags of the method, the resource and the parent resources", func() {
+			ops := apiDef.Operations()
+			So(ops, ShouldHaveLength, 4)
+			So(ops[0].Path, ShouldEqual, "/invoices")
+			So(ops[0].Tags, ShouldResemble, []string{"reports", "billing"})
+			So(ops[1].Tags, ShouldResemble, []string{"billing"})
+			So(ops[2].Path, ShouldEqual, "/invoices/{invoiceId}")
+			So(ops[2].Tags, ShouldResemble, []string{"billing", "details"})
+			So(ops[3].Tags, ShouldBeEmpty)
+		})
+
+		Convey("groups", func() {
+			groups := apiDef.OperationsByTag()
+			So(sortedKeys(groups), ShouldResemble, []string{"", "billing", "details", "reports"})
+			So(groups["billing"], ShouldHaveLength, 3)
+			So(groups["reports"][0].Method.Name, ShouldEqual, "GET")
+			So(groups[""][0].Path, ShouldEqual, "/users")
+		})
+
+		Convey("tags of the documentation", func() {
+			So(apiDef.OperationDocs()[2].Tags, ShouldResemble, []string{"billing", "details"})
+		})
+	})
+}
@@ -0,0 +1,238 @@
+package raml
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/gigforks/yaml"
+)
+
+// The kinds of the documents extending an API definition, from their header
+const (
+	// OverlayDocument is the kind of the overlays, `#%RAML 1.0 Overlay`,
+	// which only add or override the documentation of an API definition
+	OverlayDocument = "Overlay"
+
+	// ExtensionDocument is the kind of the extensions, `#%RAML 1.0 Extension`,
+	// which add or override any node of an API definition
+	ExtensionDocument = "Extension"
+)
+
+// overlayNodes are the nodes an overlay may add or override anywhere in its
+// master, besides the annotations. Their content is free.
+var overlayNodes = map[string]bool{
+	"title":           true,
+	"displayName":     true,
+	"description":     true,
+	"documentation":   true,
+	"usage":           true,
+	"example":         true,
+	"examples":        true,
+	"annotationTypes": true,
+	"uses":            true,
+}
+
+// parseExtension parses an overlay or an extension into the API definition it extends,
+// merged with the overlay or extension. The master document, given by `extends`,
+// could be an overlay or an extension too.
+// An overlay could only add or override the documentation nodes, e.g. the descriptions
+// and examples, and the annotations, the other nodes must have the value of the master.
+// An extension adds or overrides any node, e.g. resources, methods and parameters.
+// The items of the sequences, e.g. `documentation`, are added to the ones of the master.
+func parseExtension(kind string, contents []byte, workDir, fileName string, root Root, cfg *parseConfig) ([]byte, error) {
+	tree, masterDir, masterFile, err := extensionTree(kind, contents, workDir, fileName, cfg, nil)
+	if err != nil {
+		return []byte{}, err
+	}
+	merged, err := yaml.Marshal(tree)
+	if err != nil {
+		return []byte{}, err
+	}
+	return parseBytes(append([]byte("#%RAML 1.0\n"), merged...), masterDir, masterFile, root, cfg)
+}
+
+// extensionTree returns the tree of an overlay or extension merged with the documents it extends,
+// with the directory and the name of the master API definition.
+// contents is the document without its header line.
+// chain is the path of the overlays and extensions extending the document.
+func extensionTree(kind string, contents []byte, workDir, fileName string, cfg *parseConfig, chain []string) (
+	yaml.MapSlice, string, string, error) {
+	tree, err := preProcessedTree(contents, workDir, cfg)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	extends := ""
+	var extension yaml.MapSlice
+	for _, item := range tree {
+		switch key := fmt.Sprint(item.Key); key {
+		case "extends":
+			extends = strings.TrimSpace(fmt.Sprint(item.Value))
+		case "usage":
+			// the usage of the overlay or extension itself
+		default:
+			extension = append(extension, item)
+		}
+	}
+	if extends == "" {
+		return nil, "", "", fmt.Errorf("%v: an %v needs the API definition it extends, `extends`",
+			resolvePath(workDir, fileName), strings.ToLower(kind))
+	}
+
+	dir := libraryDir(workDir, fileName)
+	masterPath := resolvePath(dir, extends)
+	for _, p := range chain {
+		if p == masterPath {
+			return nil, "", "", fmt.Errorf("circular extends: %v", strings.Join(append(chain, masterPath), " -> "))
+		}
+	}
+	if err := cfg.checkRemote(dir, extends); err != nil {
+		return nil, "", "", err
+	}
+	masterContents, err := readFileOrURL(dir, extends, cfg)
+	if err != nil {
+		return nil, "", "", err
+	}
+	masterDir, masterFile := libraryDir(dir, extends), filepath.Base(extends)
+	header, masterContents := splitHeader(bytes.TrimPrefix(masterContents, utf8BOM))
+
+	masterHeader, err := parseHeaderLine(header)
+	if err != nil || masterHeader.Version != "1.0" {
+		return nil, "", "", fmt.Errorf("%v: an %v extends a RAML 1.0 API definition, not %v", masterPath, strings.ToLower(kind), header)
+	}
+	var master yaml.MapSlice
+	switch masterKind := masterHeader.Kind; masterKind {
+	case "":
+		if master, err = preProcessedTree(masterContents, masterDir, cfg); err != nil {
+			return nil, "", "", err
+		}
+	case OverlayDocument, ExtensionDocument:
+		chain = append(chain, resolvePath(workDir, fileName))
+		master, masterDir, masterFile, err = extensionTree(masterKind, masterContents, masterDir, masterFile, cfg, chain)
+		if err != nil {
+			return nil, "", "", err
+		}
+	default:
+		return nil, "", "", fmt.Errorf("%v: an %v extends an API definition, not %v", masterPath, strings.ToLower(kind), header)
+	}
+
+	rebaseUses(extension, dir, masterDir)
+	merged, err := mergeTrees(master, extension, kind == OverlayDocument, "")
+	return merged, masterDir, masterFile, err
+}
+
+// splitHeader returns the first line of a document and the rest of it
+func splitHeader(contents []byte) (string, []byte) {
+	i := bytes.IndexByte(contents, '\n')
+	if i < 0 {
+		return strings.TrimSpace(string(contents)), nil
+	}
+	return strings.TrimSpace(string(contents[:i])), contents[i+1:]
+}
+
+// preProcessedTree returns the tree of a document, without its header line, once the files are included
+func preProcessedTree(contents []byte, workDir string, cfg *parseConfig) (yaml.MapSlice, error) {
+	preprocessed, _, _, _, err := preProcess(bytes.NewReader(contents), workDir, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error preprocessing RAML file (Error: %s)", err.Error())
+	}
+	var tree yaml.MapSlice
+	if err := unmarshalYAML(preprocessed, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// rebaseUses makes the local libraries of an overlay or extension relative to the directory of its master
+func rebaseUses(tree yaml.MapSlice, dir, masterDir string) {
+	if isURL(dir) || isURL(masterDir) {
+		return
+	}
+	for _, item := range tree {
+		if fmt.Sprint(item.Key) != "uses" {
+			continue
+		}
+		uses, _ := item.Value.(yaml.MapSlice)
+		for i, lib := range uses {
+			p, ok := lib.Value.(string)
+			if !ok || isURL(p) || filepath.IsAbs(p) {
+				continue
+			}
+			if rel, err := filepath.Rel(masterDir, filepath.Join(dir, p)); err == nil {
+				uses[i].Value = filepath.ToSlash(rel)
+			}
+		}
+	}
+}
+
+// mergeTrees merges the nodes of an overlay or extension into the nodes of its master.
+// Only the overlay nodes and the annotations could be added or changed
+// in a restricted tree, the tree of an overlay, their content is not restricted.
+func mergeTrees(master, extension yaml.MapSlice, restricted bool, path string) (yaml.MapSlice, error) {
+	errs := new(Error)
+	merged := append(yaml.MapSlice{}, master...)
+	for _, item := range extension {
+		key := fmt.Sprint(item.Key)
+		free := !restricted || overlayNodes[key] || isAnnotationKey(key)
+		i := indexOfKey(merged, key)
+		if i < 0 {
+			if !free {
+				errs.add(fmt.Errorf("%v: an overlay can't add %v", displayPath(path), key))
+				continue
+			}
+			merged = append(merged, item)
+			continue
+		}
+		value, err := mergeNodes(merged[i].Value, item.Value, !free, childPath(path, key))
+		errs.add(err)
+		merged[i].Value = value
+	}
+	return merged, errs.errOrNil()
+}
+
+// mergeNodes merges a node of an overlay or extension into the node of its master
+func mergeNodes(master, extension interface{}, restricted bool, path string) (interface{}, error) {
+	switch e := extension.(type) {
+	case yaml.MapSlice:
+		if m, ok := master.(yaml.MapSlice); ok {
+			return mergeTrees(m, e, restricted, path)
+		}
+	case []interface{}:
+		if m, ok := master.([]interface{}); ok && !restricted {
+			merged := append([]interface{}{}, m...)
+			for _, item := range e {
+				if !containsNode(merged, item) {
+					merged = append(merged, item)
+				}
+			}
+			return merged, nil
+		}
+	}
+	if restricted && !reflect.DeepEqual(master, extension) {
+		return master, fmt.Errorf("%v: an overlay can't change this node", path)
+	}
+	return extension, nil
+}
+
+// indexOfKey returns the index of the item of a key, -1 if there is none
+func indexOfKey(tree yaml.MapSlice, key string) int {
+	for i, item := range tree {
+		if fmt.Sprint(item.Key) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// containsNode returns true if a sequence contains a node
+func containsNode(seq []interface{}, node interface{}) bool {
+	for _, item := range seq {
+		if reflect.DeepEqual(item, node) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOverlays(t *testing.T) {
+	Convey("overlays", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/overlays/fr/api.raml", apiDef), ShouldBeNil)
+
+		Convey("the overlay is merged into its master", func() {
+			So(apiDef.Filename, ShouldEqual, "samples/overlays/api.raml")
+			So(apiDef.Title, ShouldEqual, "Livres")
+			So(apiDef.Types["Book"].Description, ShouldEqual, "un livre")
+			So(apiDef.Types["Book"].Properties["pages"].Type, ShouldEqual, "integer")
+
+			books := apiDef.Resources["/books"]
+			So(books.Description, ShouldEqual, "tous les livres")
+			So(books.Get.Description, ShouldEqual, "Liste les livres.")
+			author := books.Get.QueryParameters["author"]
+			So(author.Description, ShouldEqual, "nom de l'auteur")
+			So(author.Type, ShouldEqual, "string")
+		})
+
+		Convey("the sequences are added to the master", func() {
+			So(apiDef.Documentation, ShouldResemble, []Documentation{
+				{Title: "Getting started", Content: "Ask for an API key."},
+				{Title: "Pour commencer", Content: "Demandez une clé."},
+			})
+		})
+
+		Convey("the libraries of the overlay are relative to the overlay", func() {
+			So(apiDef.Uses["i18n"], ShouldEqual, "lib/i18n.raml")
+			So(apiDef.Libraries, ShouldContainKey, "i18n")
+		})
+
+		Convey("an overlay extends an overlay", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/overlays/fr/ca.raml", apiDef), ShouldBeNil)
+			So(apiDef.Title, ShouldEqual, "Livres")
+			So(apiDef.Resources["/books"].Get.Description, ShouldEqual, "Liste les livres, en joual.")
+		})
+
+		Convey("an overlay doesn't change the behavior", func() {
+			err := ParseFile("./samples/overlays/bad.raml", new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "/types/Book/properties/pages: an overlay can't change this node")
+			So(err.Error(), ShouldContainSubstring, "/: an overlay can't add /authors")
+		})
+
+		Convey("an overlay needs its master", func() {
+			err := ParseBytes([]byte("#%RAML 1.0 Overlay\ntitle: Lost\n"), new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "needs the API definition it extends")
+		})
+	})
+}
+
+func TestExtensions(t *testing.T) {
+	Convey("extensions", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/overlays/admin.raml", apiDef), ShouldBeNil)
+
+		Convey("an extension adds resources, methods and parameters", func() {
+			So(apiDef.Title, ShouldEqual, "Books admin")
+			So(apiDef.Resources, ShouldContainKey, "/users")
+			So(apiDef.Resources["/users"].Get.Description, ShouldEqual, "Lists the users.")
+
+			books := apiDef.Resources["/books"]
+			So(books.Post, ShouldNotBeNil)
+			So(books.Post.Description, ShouldEqual, "Adds a book.")
+			So(books.Get.Description, ShouldEqual, "Lists the books.")
+			So(books.Get.QueryParameters, ShouldContainKey, "author")
+			So(books.Get.QueryParameters["deleted"].Type, ShouldEqual, "boolean")
+		})
+
+		Convey("an extension adds types and properties", func() {
+			So(apiDef.Types, ShouldContainKey, "User")
+			So(apiDef.Types["Book"].Properties, ShouldContainKey, "title")
+			So(apiDef.Types["Book"].Properties, ShouldContainKey, "isbn")
+		})
+
+		Convey("an extension extends an overlay", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/overlays/fr/admin.raml", apiDef), ShouldBeNil)
+			So(apiDef.Title, ShouldEqual, "Livres")
+			books := apiDef.Resources["/books"]
+			So(books.Get.Description, ShouldEqual, "Liste les livres.")
+			So(books.Delete.Description, ShouldEqual, "Supprime les livres.")
+		})
+	})
+}
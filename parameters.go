@@ -0,0 +1,244 @@
+package raml
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	// reserved parameters of resource types,
+	// their values are provided by the processing application
+	resourceTypeReservedParams = []string{"resourcePath", "resourcePathName"}
+
+	// reserved parameters of the methods of resource types
+	resourceTypeMethodReservedParams = []string{"resourcePath", "resourcePathName", "methodName"}
+
+	// reserved parameters of traits
+	traitReservedParams = []string{"resourcePath", "resourcePathName", "methodName"}
+)
+
+// checkParams validates the parameters passed when applying a resource type or trait:
+// all the parameters must be used by the declaration and all the parameters
+// used by the declaration must be passed, except the reserved ones.
+// kind is the kind of the declaration, e.g. `trait`.
+func checkParams(kind, name string, decl interface{}, params DefinitionParameters, reserved []string) error {
+	isReserved := map[string]bool{}
+	for _, p := range reserved {
+		isReserved[p] = true
+	}
+	refs, functions := paramRefs(decl)
+	for _, f := range functions {
+		if _, ok := inflector(f); !ok {
+			return fmt.Errorf("%v %v: unknown function %v", kind, name, f)
+		}
+	}
+
+	var unused, missing []string
+	for p := range params {
+		if !refs[p] && !isReserved[p] {
+			unused = append(unused, p)
+		}
+	}
+	for p := range refs {
+		if _, ok := params[p]; !ok && !isReserved[p] {
+			missing = append(missing, p)
+		}
+	}
+	sort.Strings(unused)
+	sort.Strings(missing)
+
+	switch {
+	case len(unused) > 0:
+		return fmt.Errorf("%v %v: parameter %v is not used", kind, name, strings.Join(unused, ", "))
+	case len(missing) > 0:
+		return fmt.Errorf("%v %v: missing value of parameter %v", kind, name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// checkResourceTypeParams validates the parameters passed when applying a resource type,
+// like checkParams. methodName is only provided in the methods of the resource type.
+func checkResourceTypeParams(name string, rt ResourceType, params DefinitionParameters) error {
+	if err := checkParams("resource type", name, rt, params, resourceTypeMethodReservedParams); err != nil {
+		return err
+	}
+	refs, _ := paramRefs(ResourceType{
+		Description:               rt.Description,
+		URIParameters:             rt.URIParameters,
+		BaseURIParameters:         rt.BaseURIParameters,
+		OptionalURIParameters:     rt.OptionalURIParameters,
+		OptionalBaseURIParameters: rt.OptionalBaseURIParameters,
+	})
+	// the resource level only gets the reserved parameters of the resource types
+	isReserved := map[string]bool{}
+	for _, p := range resourceTypeReservedParams {
+		isReserved[p] = true
+	}
+	for _, p := range resourceTypeMethodReservedParams {
+		if _, passed := params[p]; refs[p] && !isReserved[p] && !passed {
+			return fmt.Errorf("resource type %v: missing value of parameter %v", name, p)
+		}
+	}
+	return nil
+}
+
+// paramRefs returns names of the parameters referenced by
+// a resource type or trait declaration, without the functions,
+// and the names of the functions, e.g. `!pluralize`, sorted
+func paramRefs(decl interface{}) (map[string]bool, []string) {
+	refs := map[string]bool{}
+	var functions []string
+	walkStrings(reflect.ValueOf(decl), "", nil, map[uintptr]bool{}, func(_, s string) {
+		for _, m := range dcRe.FindAllStringSubmatch(s, -1) {
+			parts := strings.Split(m[1], "|")
+			refs[strings.TrimSpace(parts[0])] = true
+			for _, f := range parts[1:] {
+				functions = appendStrNotExist(strings.TrimSpace(f), functions)
+			}
+		}
+	})
+	sort.Strings(functions)
+	return refs, functions
+}
+
+// UnresolvedParameter is a `<<parameter>>` placeholder left in a resource
+// or method after the traits and resource types are applied
+type UnresolvedParameter struct {
+	// full URI of the resource
+	URI string
+
+	// method name, e.g. GET, empty if the placeholder is in the resource itself
+	Method string
+
+	// location of the placeholder in the resource or method,
+	// e.g. `queryParameters.page.description`
+	Path string
+
+	// the placeholder, e.g. `<<maxPages>>`
+	Placeholder string
+}
+
+// UnresolvedParameters returns the `<<parameter>>` placeholders which are left in
+// the resources and methods, e.g. in descriptions, types, URIs and property names,
+// after the traits and resource types are applied.
+// Such placeholders are caused by misspelled parameters or broken substitutions.
+func (apiDef *APIDefinition) UnresolvedParameters() []UnresolvedParameter {
+	var unresolved []UnresolvedParameter
+	apiDef.walkResources(func(r *Resource) {
+		uri := r.FullURI()
+		find := func(method string, v interface{}, skip func(field string) bool) {
+			walkStrings(reflect.ValueOf(v), "", skip, map[uintptr]bool{}, func(path, s string) {
+				for _, placeholder := range dcRe.FindAllString(s, -1) {
+					unresolved = append(unresolved, UnresolvedParameter{
+						URI:         uri,
+						Method:      method,
+						Path:        path,
+						Placeholder: placeholder,
+					})
+				}
+			})
+		}
+
+		find("", r, func(field string) bool {
+			switch field {
+			case "Nested", "Parent", "Get", "Patch", "Put", "Head", "Post", "Delete", "Options":
+				return true
+			}
+			return false
+		})
+		for _, m := range r.methods() {
+			find(m.Name, m, nil)
+		}
+	})
+
+	sort.SliceStable(unresolved, func(i, j int) bool {
+		a, b := unresolved[i], unresolved[j]
+		if a.URI != b.URI {
+			return a.URI < b.URI
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Path < b.Path
+	})
+	return unresolved
+}
+
+// walkStrings calls fn for all strings of a value, including the keys of the maps,
+// with the path of the string. The path is made of the YAML names of the fields.
+// Unexported fields, fields not decoded from YAML and fields
+// accepted by skip are not walked.
+func walkStrings(v reflect.Value, path string, skip func(field string) bool, visited map[uintptr]bool,
+	fn func(path, s string)) {
+	join := func(name string) string {
+		if path == "" {
+			return name
+		}
+		return path + "." + name
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		fn(path, v.String())
+	case reflect.Ptr:
+		if v.IsNil() || visited[v.Pointer()] {
+			return
+		}
+		visited[v.Pointer()] = true
+		walkStrings(v.Elem(), path, skip, visited, fn)
+	case reflect.Interface:
+		if !v.IsNil() {
+			walkStrings(v.Elem(), path, skip, visited, fn)
+		}
+	case reflect.Struct:
+		if bodies, ok := v.Interface().(Bodies); ok {
+			// the deprecated fields are views on the bodies
+			walkStrings(reflect.ValueOf(bodies.Default), path, nil, visited, fn)
+			walkStrings(reflect.ValueOf(bodies.ForMIMEType), path, nil, visited, fn)
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if field.PkgPath != "" || tag == "-" || (skip != nil && skip(field.Name)) {
+				continue
+			}
+			if tag == "" {
+				tag = lowerCamel(field.Name)
+			}
+			walkStrings(v.Field(i), join(tag), nil, visited, fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkStrings(v.Index(i), fmt.Sprintf("%v[%v]", path, i), nil, visited, fn)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			keyPath := join(fmt.Sprint(key.Interface()))
+			walkStrings(key, path, nil, visited, fn)
+			walkStrings(v.MapIndex(key), keyPath, nil, visited, fn)
+		}
+	}
+}
+
+// lowerCamel converts the name of a field to lower camel case, e.g. `URI` to `uri`
+// and `HTTPCode` to `httpCode`
+func lowerCamel(name string) string {
+	n := 0
+	for n < len(name) && 'A' <= name[n] && name[n] <= 'Z' {
+		n++
+	}
+	switch {
+	case n == len(name):
+		return strings.ToLower(name)
+	case n > 1:
+		n--
+	}
+	return strings.ToLower(name[:n]) + name[n:]
+}
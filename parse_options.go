@@ -0,0 +1,272 @@
+package raml
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding"
+)
+
+// default limits of the reading of a remote document, included file or library
+const (
+	DefaultFetchTimeout    = 30 * time.Second
+	DefaultMaxDocumentSize = 10 << 20
+)
+
+// ParseOption configures the parsing of a RAML document,
+// e.g. `raml.ParseFile("api.raml", apiDef, raml.WithStrictMode())`
+type ParseOption func(*parseConfig)
+
+// parseConfig is the configuration of the parsing,
+// the zero value is the default configuration
+type parseConfig struct {
+	// report the problems which are ignored by default as errors
+	strict bool
+
+	// keep the unknown root keys of the API definition
+	rootExtensions bool
+
+	// client to read the remote documents, http.DefaultClient if nil
+	httpClient *http.Client
+
+	// time and size limits of a remote document, 0 for the default,
+	// negative for no limit
+	fetchTimeout    time.Duration
+	maxDocumentSize int64
+
+	// maximum depth of the included files and libraries, 0 for no limit
+	maxIncludeDepth int
+
+	// maximum number of included files and libraries and size of all the
+	// files read for the document, 0 for no limit
+	maxIncludes     int
+	maxExpandedSize int64
+
+	// files read for the document, shared with its libraries
+	usage *includeUsage
+
+	// name of the trait which headers are added to every response
+	responseHeadersTrait string
+
+	// mark the included files in the preprocessed document
+	includeMarkers bool
+
+	// file system of the local files, the OS file system if nil
+	fsys fs.FS
+
+	// cache of the remote documents, nil to read them every time
+	urlCache URLCache
+
+	// report the unknown keys as errors
+	unknownKeys bool
+
+	// forbid the included files and libraries at http(s) URLs
+	noRemoteIncludes bool
+
+	// registry the types are pulled from and pushed to, nil for none
+	schemaRegistry SchemaRegistry
+
+	// names of the additional methods, upper case
+	extraMethods []string
+
+	// charset of the included text files which are neither UTF-8
+	// nor declare their charset, ISO-8859-1 if nil
+	includeCharset encoding.Encoding
+
+	// take part in the processing of the document and of its libraries
+	processors []Processor
+
+	// called for every warning, they are logged if nil
+	warningHandler func(Warning)
+
+	// warnings of the document, shared with its libraries
+	warnings *warningList
+
+	// context of the reading of the documents, context.Background() if nil
+	ctx context.Context
+
+	// depth of the document being parsed, 0 for the root document
+	depth int
+
+	// resolved paths of the document being parsed and of the documents
+	// using it as a library, from the root document
+	chain []string
+
+	// libraries already parsed for the document, shared with its libraries,
+	// by resolved path, so a library used several times is parsed once
+	libraries map[string]*Library
+}
+
+// WithStrictMode reports as errors the problems which are ignored by default:
+// unknown traits, `<<parameter>>` placeholders left after the traits and
+// resource types are applied and URI parameters declared but not in their URI.
+func WithStrictMode() ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.strict = true
+	}
+}
+
+// WithHTTPClient sets the client used to read the remote documents,
+// included files and libraries.
+func WithHTTPClient(c *http.Client) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.httpClient = c
+	}
+}
+
+// WithRoundTripper sets the transport used to read the remote documents,
+// included files and libraries, e.g. to add the authentication headers
+// of a gateway. It overrides WithHTTPClient.
+func WithRoundTripper(rt http.RoundTripper) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.httpClient = &http.Client{Transport: rt}
+	}
+}
+
+// WithFetchTimeout limits the time to read a remote document, included file
+// or library, DefaultFetchTimeout by default. A negative d removes the limit,
+// the reading could then only be aborted by the context of ParseFileCtx.
+func WithFetchTimeout(d time.Duration) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.fetchTimeout = d
+	}
+}
+
+// WithMaxDocumentSize limits the size in bytes of the document, of an included file
+// and of a library, local or remote, DefaultMaxDocumentSize by default.
+// A negative n removes the limit.
+func WithMaxDocumentSize(n int64) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.maxDocumentSize = n
+	}
+}
+
+// WithNoRemoteIncludes makes the parsing fail when a file is included
+// or a library is used from an http(s) URL, for untrusted documents:
+// no request is made, except for the root document if it is an URL.
+// The relative paths of a remote root document are URLs too.
+func WithNoRemoteIncludes() ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.noRemoteIncludes = true
+	}
+}
+
+// WithExtraMethods parses additional methods besides the ones of RAML,
+// e.g. QUERY or the methods of a vendor extension, declared in lower case
+// like the other methods. They are in Resource.ExtraMethods, and the
+// resource types and traits apply to them like to the other methods.
+func WithExtraMethods(names ...string) ParseOption {
+	return func(cfg *parseConfig) {
+		for _, name := range names {
+			if !isMethodName(name) {
+				cfg.extraMethods = append(cfg.extraMethods, strings.ToUpper(name))
+			}
+		}
+	}
+}
+
+// WithMaxIncludeDepth limits the depth of the included files and libraries:
+// the files included and the libraries used by the root document are at depth 1,
+// the ones of these libraries at depth 2, and so on.
+func WithMaxIncludeDepth(n int) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.maxIncludeDepth = n
+	}
+}
+
+// WithDefaultResponseHeaders adds the headers of a trait to every response
+// of every method, unless the response declares them, e.g. `X-Request-Id`:
+//
+//	traits:
+//	  responseHeaders:
+//	    headers:
+//	      X-Request-Id:
+//	        type: string
+//
+// The trait doesn't need to be applied to the methods.
+func WithDefaultResponseHeaders(trait string) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.responseHeadersTrait = trait
+	}
+}
+
+// WithIncludeMarkers marks the beginning and the end of the included files
+// in the preprocessed document returned by ParseReadFile, with comments:
+//
+//	# begin include: schemas/user.json
+//	...
+//	# end include: schemas/user.json
+func WithIncludeMarkers() ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.includeMarkers = true
+	}
+}
+
+func newParseConfig(opts []ParseOption) *parseConfig {
+	cfg := &parseConfig{warnings: &warningList{}, usage: &includeUsage{}, libraries: map[string]*Library{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// nested returns the configuration of the files included
+// and the libraries used by the document being parsed
+func (cfg *parseConfig) nested(address string) (*parseConfig, error) {
+	nested := *cfg
+	nested.depth++
+	if cfg.maxIncludeDepth > 0 && nested.depth > cfg.maxIncludeDepth {
+		return nil, cfg.exceeded(IncludeDepthLimit, int64(cfg.maxIncludeDepth), address)
+	}
+	if err := cfg.countInclude(address); err != nil {
+		return nil, err
+	}
+	return &nested, nil
+}
+
+// extraMethods returns the names of the additional methods
+func (apiDef *APIDefinition) extraMethods() []string {
+	if apiDef == nil || apiDef.cfg == nil {
+		return nil
+	}
+	return apiDef.cfg.extraMethods
+}
+
+// checkRemote returns an error if the included file or library is remote
+// and forbidden by WithNoRemoteIncludes
+func (cfg *parseConfig) checkRemote(workDir, fileName string) error {
+	if address := resolvePath(workDir, fileName); cfg.noRemoteIncludes && isURL(address) {
+		return fmt.Errorf("remote include %v is forbidden", address)
+	}
+	return nil
+}
+
+func (cfg *parseConfig) context() context.Context {
+	if cfg.ctx == nil {
+		return context.Background()
+	}
+	return cfg.ctx
+}
+
+// fetchContext returns the context of the reading of a remote document,
+// with the time limit of WithFetchTimeout
+func (cfg *parseConfig) fetchContext() (context.Context, context.CancelFunc) {
+	switch {
+	case cfg.fetchTimeout < 0:
+		return context.WithCancel(cfg.context())
+	case cfg.fetchTimeout == 0:
+		return context.WithTimeout(cfg.context(), DefaultFetchTimeout)
+	}
+	return context.WithTimeout(cfg.context(), cfg.fetchTimeout)
+}
+
+func (cfg *parseConfig) client() *http.Client {
+	if cfg.httpClient == nil {
+		return http.DefaultClient
+	}
+	return cfg.httpClient
+}
@@ -0,0 +1,391 @@
+package raml
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// roundTripFunc serves the HTTP requests of a client
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestParseOptions(t *testing.T) {
+	Convey("parse options", t, func() {
+		Convey("strict mode", func() {
+			// unknown trait
+			So(ParseFile("./samples/resource_types.raml", new(APIDefinition)), ShouldBeNil)
+			err := ParseFile("./samples/resource_types.raml", new(APIDefinition), WithStrictMode())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "invalid traits name:rateLimited")
+
+			// unresolved parameters
+			So(ParseFile("./samples/unresolved_params.raml", new(APIDefinition)), ShouldBeNil)
+			err = ParseFile("./samples/unresolved_params.raml", new(APIDefinition), WithStrictMode())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "unresolved parameter <<kind>> in /books at description")
+
+			So(ParseFile("./samples/frozen.raml", new(APIDefinition), WithStrictMode()), ShouldBeNil)
+		})
+
+		Convey("unknown keys", func() {
+			So(ParseFile("./samples/unknown_keys/api.raml", new(APIDefinition)), ShouldBeNil)
+			err := ParseFile("./samples/unknown_keys/api.raml", new(APIDefinition), WithUnknownKeyErrors())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "unknown key queryParamters at /books/get")
+			So(err.Error(), ShouldContainSubstring, "unknown key exemple at /books/get/responses/200/body/application/json")
+			So(err.Error(), ShouldContainSubstring, "unknown key displayname at /books/{id}")
+			So(err.Error(), ShouldContainSubstring, "unknown key additionalProperty at /types/Book")
+			So(err.Error(), ShouldNotContainSubstring, "owner")
+			So(err.Error(), ShouldNotContainSubstring, "pageParam")
+
+			So(ParseFile("./samples/included/api.raml", new(APIDefinition), WithUnknownKeyErrors()), ShouldBeNil)
+
+			var def APIDefinition
+			So(ParseBytes([]byte("#%RAML 1.0\ntitle: t\ndescription: d\n"), &def, WithUnknownKeyErrors()), ShouldBeNil)
+			So(def.Description, ShouldEqual, "d")
+		})
+
+		Convey("no unknown keys in the samples", func() {
+			// the samples with unknown keys on purpose, or which need other options
+			withUnknownKeys := map[string]bool{
+				"samples/extra_methods.raml":           true,
+				"samples/simple_example.raml":          true,
+				"samples/unknown_keys/api.raml":        true,
+				"samples/unknown_keys/extensions.raml": true,
+			}
+			files, err := filepath.Glob("./samples/*.raml")
+			So(err, ShouldBeNil)
+			nested, err := filepath.Glob("./samples/*/*.raml")
+			So(err, ShouldBeNil)
+			for _, f := range append(files, nested...) {
+				contents, err := ioutil.ReadFile(f)
+				So(err, ShouldBeNil)
+				header, err := ParseHeader(contents)
+				if err != nil || header.Kind != "" || withUnknownKeys[filepath.ToSlash(f)] {
+					continue
+				}
+				if ParseFile(f, new(APIDefinition)) != nil {
+					// the invalid samples
+					continue
+				}
+				So(ParseFile(f, new(APIDefinition), WithUnknownKeyErrors()), ShouldBeNil)
+			}
+		})
+
+		Convey("root extensions", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/unknown_keys/extensions.raml", apiDef), ShouldBeNil)
+			So(apiDef.Extensions, ShouldBeNil)
+
+			apiDef = &APIDefinition{KeepRaw: true}
+			So(ParseFile("./samples/unknown_keys/extensions.raml", apiDef, WithRootExtensions()), ShouldBeNil)
+			So(apiDef.Extensions, ShouldResemble, map[string]interface{}{
+				"x-generation-settings": map[string]interface{}{
+					"package": "books",
+					"targets": []interface{}{"go", "typescript"},
+					"client":  map[string]interface{}{"retries": 3},
+				},
+				"x-owner": "books team",
+			})
+			var buf bytes.Buffer
+			So(apiDef.WriteRAML(&buf), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, "x-generation-settings:")
+
+			err := ParseFile("./samples/unknown_keys/extensions.raml", new(APIDefinition), WithRootExtensions(),
+				WithUnknownKeyErrors())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "Error parsing RAML:\n  unknown key x-internal at /books/get\n")
+		})
+
+		Convey("maximum include depth", func() {
+			err := ParseFile("./samples/included/api.raml", new(APIDefinition), WithMaxIncludeDepth(1))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "maximum include depth 1 exceeded")
+
+			So(ParseFile("./samples/included/api.raml", new(APIDefinition), WithMaxIncludeDepth(2)), ShouldBeNil)
+			So(ParseFile("./samples/included/api.raml", new(APIDefinition)), ShouldBeNil)
+
+			var limitErr *LimitError
+			So(errors.As(err, &limitErr), ShouldBeTrue)
+			So(limitErr.Limit, ShouldEqual, IncludeDepthLimit)
+		})
+
+		Convey("include limits", func() {
+			limit := func(err error) *LimitError {
+				var limitErr *LimitError
+				if errors.As(err, &limitErr) {
+					return limitErr
+				}
+				return nil
+			}
+
+			// description.md, then the libraries by name: files.raml, file-type.raml, notes.raml and usage.md
+			err := ParseFile("./samples/included/api.raml", new(APIDefinition), WithMaxIncludes(4))
+			So(limit(err), ShouldResemble, &LimitError{Limit: IncludeCountLimit, Max: 4,
+				File: "samples/included/usage.md"})
+			So(ParseFile("./samples/included/api.raml", new(APIDefinition), WithMaxIncludes(5)), ShouldBeNil)
+
+			err = ParseFile("./samples/included/api.raml", new(APIDefinition), WithMaxDocumentSize(200))
+			So(limit(err), ShouldResemble, &LimitError{Limit: FileSizeLimit, Max: 200, File: "samples/included/api.raml"})
+			So(err.Error(), ShouldEqual, "could not read samples/included/api.raml: larger than 200 bytes")
+
+			err = ParseFile("./samples/included/api.raml", new(APIDefinition), WithMaxExpandedSize(1000))
+			So(limit(err).Limit, ShouldEqual, ExpandedSizeLimit)
+			So(limit(err).File, ShouldEqual, "samples/libraries/files.raml")
+
+			// the document is read from memory
+			data := []byte("#%RAML 1.0\ntitle: Limits\ndescription: !include samples/included/description.md\n")
+			So(ParseBytes(data, new(APIDefinition), WithMaxIncludes(1)), ShouldBeNil)
+			err = ParseBytes(data, new(APIDefinition), WithMaxExpandedSize(10))
+			So(limit(err).File, ShouldEqual, "samples/included/description.md")
+		})
+
+		Convey("HTTP client", func() {
+			docs := map[string]string{
+				"/api.raml":   "#%RAML 1.0\ntitle: Remote\nuses:\n  notes: notes.raml\n",
+				"/notes.raml": "#%RAML 1.0 Library\ntypes:\n  Note:\n    properties:\n      text: string\n",
+			}
+			var requested []string
+			client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				requested = append(requested, req.URL.String())
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(docs[req.URL.Path])),
+					Header:     http.Header{},
+					Request:    req,
+				}, nil
+			})}
+
+			apiDef := new(APIDefinition)
+			So(ParseFile("http://raml.test/api.raml", apiDef, WithHTTPClient(client)), ShouldBeNil)
+			So(apiDef.Title, ShouldEqual, "Remote")
+			_, ok := apiDef.TypeByName("notes.Note")
+			So(ok, ShouldBeTrue)
+			So(requested, ShouldResemble, []string{"http://raml.test/api.raml", "http://raml.test/notes.raml"})
+
+			// authenticated gateway
+			auth := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("Authorization") != "Bearer token" {
+					return &http.Response{
+						StatusCode: http.StatusUnauthorized,
+						Body:       ioutil.NopCloser(strings.NewReader("")),
+						Header:     http.Header{},
+						Request:    req,
+					}, nil
+				}
+				return client.Transport.RoundTrip(req)
+			})
+			requested = nil
+			apiDef = new(APIDefinition)
+			err := ParseFile("http://raml.test/api.raml", apiDef, WithRoundTripper(auth))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "could not read http://raml.test/api.raml: 401 Unauthorized")
+			So(requested, ShouldBeEmpty)
+
+			withToken := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				req = req.Clone(req.Context())
+				req.Header.Set("Authorization", "Bearer token")
+				return auth.RoundTrip(req)
+			})
+			So(ParseFile("http://raml.test/api.raml", apiDef, WithRoundTripper(withToken)), ShouldBeNil)
+			So(requested, ShouldResemble, []string{"http://raml.test/api.raml", "http://raml.test/notes.raml"})
+		})
+
+		Convey("context", func() {
+			docs := map[string]string{
+				"/api.raml":   "#%RAML 1.0\ntitle: Remote\nuses:\n  notes: notes.raml\n",
+				"/notes.raml": "#%RAML 1.0 Library\ntypes:\n  Note:\n    properties:\n      text: string\n",
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var requested []string
+			client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				requested = append(requested, req.URL.String())
+				if err := req.Context().Err(); err != nil {
+					return nil, err
+				}
+				// the library isn't read
+				cancel()
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(docs[req.URL.Path])),
+					Header:     http.Header{},
+					Request:    req,
+				}, nil
+			})}
+
+			err := ParseFileCtx(ctx, "http://raml.test/api.raml", new(APIDefinition), WithHTTPClient(client))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, context.Canceled.Error())
+			So(requested, ShouldResemble, []string{"http://raml.test/api.raml"})
+
+			// local files
+			err = ParseFileCtx(ctx, "./samples/simple_with_lib.raml", new(APIDefinition))
+			So(errors.Is(err, context.Canceled), ShouldBeTrue)
+			So(ParseFileCtx(context.Background(), "./samples/simple_with_lib.raml", new(APIDefinition)), ShouldBeNil)
+		})
+
+		Convey("fetch limits", func() {
+			doc := "#%RAML 1.0\ntitle: Remote\n"
+			serve := func(body io.Reader, length int64) *http.Client {
+				return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode:    http.StatusOK,
+						Body:          ioutil.NopCloser(body),
+						ContentLength: length,
+						Header:        http.Header{},
+						Request:       req,
+					}, nil
+				})}
+			}
+
+			// announced size
+			err := ParseFile("http://raml.test/api.raml", new(APIDefinition),
+				WithHTTPClient(serve(strings.NewReader(doc), int64(len(doc)))), WithMaxDocumentSize(10))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "larger than 10 bytes")
+
+			// unknown size
+			err = ParseFile("http://raml.test/api.raml", new(APIDefinition),
+				WithHTTPClient(serve(strings.NewReader(doc), -1)), WithMaxDocumentSize(10))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "larger than 10 bytes")
+
+			So(ParseFile("http://raml.test/api.raml", new(APIDefinition),
+				WithHTTPClient(serve(strings.NewReader(doc), -1)), WithMaxDocumentSize(int64(len(doc)))), ShouldBeNil)
+			So(ParseFile("http://raml.test/api.raml", new(APIDefinition),
+				WithHTTPClient(serve(strings.NewReader(doc), -1)), WithMaxDocumentSize(-1)), ShouldBeNil)
+
+			// a server which stalls
+			stalled := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			})}
+			err = ParseFile("http://raml.test/api.raml", new(APIDefinition),
+				WithHTTPClient(stalled), WithFetchTimeout(10*time.Millisecond))
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		})
+
+		Convey("no remote includes", func() {
+			var requested []string
+			client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				requested = append(requested, req.URL.String())
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader("#%RAML 1.0 Library\nusage: remote\n")),
+					Header:     http.Header{},
+					Request:    req,
+				}, nil
+			})}
+
+			include := []byte("#%RAML 1.0\ntitle: Untrusted\ndescription: !include http://169.254.169.254/latest\n")
+			err := ParseBytes(include, new(APIDefinition), WithHTTPClient(client), WithNoRemoteIncludes())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "remote include http://169.254.169.254/latest is forbidden")
+
+			uses := []byte("#%RAML 1.0\ntitle: Untrusted\nuses:\n  lib: https://internal.test/lib.raml\n")
+			err = ParseBytes(uses, new(APIDefinition), WithHTTPClient(client), WithNoRemoteIncludes())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "remote include https://internal.test/lib.raml is forbidden")
+			So(requested, ShouldBeEmpty)
+
+			So(ParseBytes(uses, new(APIDefinition), WithHTTPClient(client)), ShouldBeNil)
+			So(requested, ShouldResemble, []string{"https://internal.test/lib.raml"})
+
+			// local files
+			So(ParseFile("./samples/included/api.raml", new(APIDefinition), WithNoRemoteIncludes()), ShouldBeNil)
+		})
+
+		Convey("default response headers", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/response_headers.raml", apiDef), ShouldBeNil)
+			So(apiDef.Resources["/users"].Get.Responses["200"].Headers, ShouldBeEmpty)
+
+			apiDef = new(APIDefinition)
+			So(ParseFile("./samples/response_headers.raml", apiDef, WithDefaultResponseHeaders("responseHeaders")),
+				ShouldBeNil)
+			get := apiDef.Resources["/users"].Get
+
+			ok := get.Responses["200"]
+			h, found := ok.Header("x-request-id")
+			So(found, ShouldBeTrue)
+			So(h.Description, ShouldEqual, "id of the request")
+			So(ok.Headers, ShouldContainKey, HTTPHeader("X-Rate-Limit"))
+
+			// declared by the response
+			failed := get.Responses["500"]
+			h, _ = failed.Header("X-Request-Id")
+			So(h.Description, ShouldEqual, "id of the failed request")
+			So(failed.Headers, ShouldContainKey, HTTPHeader("X-Rate-Limit"))
+
+			// the trait isn't applied to the methods
+			So(get.Headers, ShouldBeEmpty)
+
+			err := ParseFile("./samples/response_headers.raml", new(APIDefinition), WithDefaultResponseHeaders("unknown"))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("include markers", func() {
+			plain := new(APIDefinition)
+			doc, err := ParseReadFile("./samples/included/", "schema.raml", plain)
+			So(err, ShouldBeNil)
+			So(string(doc), ShouldNotContainSubstring, "include:")
+
+			apiDef := new(APIDefinition)
+			doc, err = ParseReadFile("./samples/included/", "schema.raml", apiDef, WithIncludeMarkers())
+			So(err, ShouldBeNil)
+			So(string(doc), ShouldContainSubstring, "  # begin include: description.md\n  description: \n")
+			So(string(doc), ShouldContainSubstring, "        }\n        # end include: user.json\n  get:\n")
+
+			// the markers are comments
+			So(apiDef.Resources["/users"].Description, ShouldEqual, plain.Resources["/users"].Description)
+			So(apiDef.Resources["/users"].Post.Bodies.ForMIMEType["application/json"].TypeString(), ShouldEqual,
+				plain.Resources["/users"].Post.Bodies.ForMIMEType["application/json"].TypeString())
+		})
+
+		Convey("warnings", func() {
+			var handled []Warning
+			apiDef := new(APIDefinition)
+			err := ParseFile("./samples/warnings/api.raml", apiDef, WithWarningHandler(func(w Warning) {
+				handled = append(handled, w)
+			}))
+			So(err, ShouldBeNil)
+			So(apiDef.Warnings, ShouldResemble, []Warning{
+				{Location: "schemas", Message: "deprecated, use types"},
+				{Location: "GET /users", Message: "invalid traits name:paged"},
+				{Location: "GET /users: response 200 body application/json", Message: "schema is deprecated, use type"},
+			})
+			So(handled, ShouldResemble, apiDef.Warnings)
+			So(apiDef.Warnings[1].String(), ShouldEqual, "GET /users: invalid traits name:paged")
+
+			// the warnings are errors in strict mode
+			So(ParseFile("./samples/warnings/api.raml", new(APIDefinition), WithStrictMode()), ShouldNotBeNil)
+
+			apiDef = new(APIDefinition)
+			So(ParseFile("./samples/shadowing.raml", apiDef), ShouldBeNil)
+			So(apiDef.Warnings, ShouldNotBeEmpty)
+			So(apiDef.Warnings[0].Location, ShouldBeEmpty)
+			So(apiDef.Warnings[0].Message, ShouldEqual, apiDef.ShadowedDeclarations()[0].Warning())
+		})
+
+		Convey("options of in memory documents", func() {
+			doc := []byte("#%RAML 1.0\ntitle: In memory\n/users:\n  get:\n    is: [ paged ]\n")
+			So(ParseBytes(doc, new(APIDefinition)), ShouldBeNil)
+			So(ParseBytes(doc, new(APIDefinition), WithStrictMode()), ShouldNotBeNil)
+			So(ParseReader(bytes.NewReader(doc), "", new(APIDefinition), WithStrictMode()), ShouldNotBeNil)
+		})
+	})
+}
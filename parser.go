@@ -32,16 +32,21 @@ package raml
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
+	"os"
+	"path"
 	"path/filepath"
 	"reflect"
 	"strings"
-	"unicode/utf8"
 
 	"github.com/gigforks/yaml"
 )
@@ -55,62 +60,158 @@ var (
 	ramlFileDir string
 )
 
-var (
-	includeStringLen = len("!include ")
-)
-
 // ParseFile parses an RAML file.
 // Returns a raml.APIDefinition value or an error if
 // something went wrong.
-func ParseFile(filePath string, root Root) error {
+func ParseFile(filePath string, root Root, opts ...ParseOption) error {
 	workDir, fileName := filepath.Split(filePath)
-	_, err := ParseReadFile(workDir, fileName, root)
+	_, err := ParseReadFile(workDir, fileName, root, opts...)
 	return err
 }
 
+// ParseFileCtx parses an RAML file like ParseFile.
+// The reading of the remote documents, included files and libraries
+// is aborted when ctx is done.
+func ParseFileCtx(ctx context.Context, filePath string, root Root, opts ...ParseOption) error {
+	workDir, fileName := filepath.Split(filePath)
+	cfg := newParseConfig(opts)
+	cfg.ctx = ctx
+	_, err := parseFile(workDir, fileName, root, cfg)
+	return cfg.limitError(err)
+}
+
+// ParseFS parses a RAML file of a file system, e.g. an embed.FS.
+// The included files and libraries are read from the same file system,
+// except the remote ones.
+func ParseFS(fsys fs.FS, filePath string, root Root, opts ...ParseOption) error {
+	workDir, fileName := path.Split(filePath)
+	cfg := newParseConfig(opts)
+	cfg.fsys = fsys
+	_, err := parseFile(workDir, fileName, root, cfg)
+	return cfg.limitError(err)
+}
+
+// ParseBytes parses a RAML document from memory.
+// Included files and libraries are resolved from the current directory.
+func ParseBytes(data []byte, root Root, opts ...ParseOption) error {
+	cfg := newParseConfig(opts)
+	_, err := parseBytes(data, "", "", root, cfg)
+	return cfg.limitError(err)
+}
+
+// ParseReader parses a RAML document read from r.
+// workDir is used to resolve included files and libraries.
+func ParseReader(r io.Reader, workDir string, root Root, opts ...ParseOption) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("could not read RAML document (Error: %s)", err.Error())
+	}
+	cfg := newParseConfig(opts)
+	_, err = parseBytes(data, workDir, "", root, cfg)
+	return cfg.limitError(err)
+}
+
 // ParseReadFile parse an .raml file.
 // It returns API definition and the concatenated .raml file.
-func ParseReadFile(workDir, fileName string, root Root) ([]byte, error) {
+func ParseReadFile(workDir, fileName string, root Root, opts ...ParseOption) ([]byte, error) {
+	cfg := newParseConfig(opts)
+	contents, err := parseFile(workDir, fileName, root, cfg)
+	return contents, cfg.limitError(err)
+}
+
+// parseFile parses an .raml file with the given configuration
+func parseFile(workDir, fileName string, root Root, cfg *parseConfig) ([]byte, error) {
 	if strings.HasSuffix(fmt.Sprint(reflect.TypeOf(root)), "APIDefinition") { // when we parse for APIDefinition, we reset ramlFileDir
 		ramlFileDir = workDir
 	}
 
 	// Read original file contents into a byte array
-	mainFileBytes, err := readFileOrURL(workDir, fileName)
+	mainFileBytes, err := readFileOrURL(workDir, fileName, cfg)
 
 	if err != nil {
 		return []byte{}, err
 	}
 
+	return parseBytes(mainFileBytes, workDir, fileName, root, cfg)
+}
+
+// parseBytes parses the contents of a RAML document.
+// workDir is used to resolve !include and uses directives,
+// fileName is empty if the document is not read from a file.
+func parseBytes(mainFileBytes []byte, workDir, fileName string, root Root, cfg *parseConfig) ([]byte, error) {
 	// Get the contents of the main file
+	mainFileBytes = bytes.TrimPrefix(mainFileBytes, utf8BOM)
 	mainFileBuffer := bytes.NewBuffer(mainFileBytes)
 
-	// Verify the YAML version
-	var ramlVersion string
+	// Verify the RAML version
 	firstLine, err := mainFileBuffer.ReadString('\n')
-	if err != nil {
+	if err != nil && (err != io.EOF || firstLine == "") {
 		return []byte{}, fmt.Errorf("problem reading RAML file (Error: %s)", err.Error())
 	}
-
-	// We read some data...
-	if len(firstLine) >= 10 {
-		ramlVersion = firstLine[:10]
-	}
-	if ramlVersion != "#%RAML 1.0" {
+	header, err := parseHeaderLine(firstLine)
+	if err != nil || header.Version != "1.0" {
 		return []byte{}, errors.New("input file is not a RAML 1.0 file. Make  sure the file starts with #%RAML 1.0")
 	}
+	if header.Kind == OverlayDocument || header.Kind == ExtensionDocument {
+		if apiDef, ok := root.(*APIDefinition); ok {
+			contents, err := parseExtension(header.Kind, mainFileBuffer.Bytes(), workDir, fileName, root, cfg)
+			apiDef.FragmentKind = header.Kind
+			return contents, err
+		}
+	}
+	if f, ok := root.(fragmentRoot); ok {
+		if header.Kind != f.fragmentKind() {
+			return []byte{}, fmt.Errorf("input file is not a RAML 1.0 %v fragment. Make sure the file starts with #%%RAML 1.0 %v",
+				f.fragmentKind(), f.fragmentKind())
+		}
+	}
 
 	// Pre-process the original file, following !include directive
-	preprocessedContentsBytes, err := preProcess(mainFileBuffer, workDir)
+	preprocessedContentsBytes, includes, lines, binaries, err := preProcess(mainFileBuffer, workDir, cfg)
 
 	if err != nil {
 		return []byte{}, fmt.Errorf("error preprocessing RAML file (Error: %s)", err.Error())
 	}
 
+	var resolved string
+	if fileName != "" {
+		resolved = resolvePath(workDir, fileName)
+		for i, p := range cfg.chain {
+			if p == resolved {
+				cycle := append(append([]string{}, cfg.chain[i:]...), resolved)
+				return []byte{}, fmt.Errorf("circular uses: %v", strings.Join(cycle, " -> "))
+			}
+		}
+		// the libraries get their own copy of the chain
+		cfg.chain = append(cfg.chain[:len(cfg.chain):len(cfg.chain)], resolved)
+	}
+	for i := range includes {
+		includes[i].IncludedBy = resolved
+	}
+	sm := newSourceMap(resolved, preprocessedContentsBytes, lines)
+	sm.binaries = binaries
+	switch r := root.(type) {
+	case *APIDefinition:
+		r.RAMLVersion = header.Version
+		r.FragmentKind = header.Kind
+		r.includes = includes
+		r.sourceMap = sm
+		r.cfg = cfg
+	case *Library:
+		r.RAMLVersion = header.Version
+		r.FragmentKind = header.Kind
+		r.includes = includes
+		r.resolved = resolved
+		r.sourceMap = sm
+		r.cfg = cfg
+	case fragmentRoot:
+		r.setConfig(cfg)
+	}
+
 	// Unmarshal into an APIDefinition value
 
 	// Go!
-	err = yaml.Unmarshal(preprocessedContentsBytes, root)
+	err = unmarshalYAML(preprocessedContentsBytes, root)
 
 	// Any errors?
 	if err != nil {
@@ -123,54 +224,219 @@ func ParseReadFile(workDir, fileName string, root Root) ([]byte, error) {
 			populateRAMLError(ramlError, yamlErrors)
 		} else {
 			// Or just any other error, though this shouldn't happen.
-			ramlError.Errors = append(ramlError.Errors, err.Error())
+			ramlError.Errors = append(ramlError.Errors, err)
+		}
+
+		// with the lines of the original files, the other errors are kept as is
+		for i, currErr := range ramlError.Errors {
+			if msg := sm.fixErrorLines(currErr.Error(), resolved); msg != currErr.Error() {
+				ramlError.Errors[i] = errors.New(msg)
+			}
 		}
 
 		return []byte{}, ramlError
 	}
+	// the unknown keys are reported with the errors of the post processing
+	var keyErr error
+	if cfg.unknownKeys {
+		keyErr = checkUnknownKeys(preprocessedContentsBytes, root, cfg)
+	}
 
-	if err := root.PostProcess(workDir, fileName); err != nil {
-		return preprocessedContentsBytes, err
+	if apiDef, ok := root.(*APIDefinition); ok {
+		// the declaration order is lost by the maps of the API definition
+		if err := unmarshalYAML(preprocessedContentsBytes, &apiDef.declTree); err != nil {
+			return []byte{}, err
+		}
+		if cfg.rootExtensions {
+			apiDef.Extensions = rootExtensions(apiDef.declTree)
+		}
+		if apiDef.KeepRaw {
+			if err := apiDef.keepRaw(preprocessedContentsBytes, mainFileBytes); err != nil {
+				return []byte{}, err
+			}
+		}
+	}
+
+	procErr := cfg.process(AfterUnmarshal, root)
+	err = root.PostProcess(workDir, fileName)
+	switch r := root.(type) {
+	case *APIDefinition:
+		r.setSourceLocations()
+	case *Library:
+		r.setSourceLocations()
+	}
+	if err == nil {
+		err = cfg.process(AfterInheritance, root)
+	}
+	if apiDef, ok := root.(*APIDefinition); ok && cfg.warnings != nil {
+		apiDef.Warnings = cfg.warnings.warnings
+	}
+	if err != nil || keyErr != nil || procErr != nil {
+		errs := new(Error)
+		errs.add(keyErr)
+		errs.add(procErr)
+		errs.add(err)
+		return preprocessedContentsBytes, errs
+	}
+
+	if apiDef, ok := root.(*APIDefinition); ok && cfg.strict {
+		if unresolved := apiDef.UnresolvedParameters(); len(unresolved) > 0 {
+			p := unresolved[0]
+			location := strings.TrimSpace(p.Method + " " + p.URI)
+			return preprocessedContentsBytes, fmt.Errorf("unresolved parameter %v in %v at %v",
+				p.Placeholder, location, p.Path)
+		}
+		for _, m := range apiDef.URIParameterMismatches() {
+			if m.Unused {
+				return preprocessedContentsBytes, fmt.Errorf("%v", m)
+			}
+		}
 	}
 
 	// Good.
 	return preprocessedContentsBytes, nil
 }
 
+// unmarshalYAML unmarshals YAML document into out.
+// The YAML parser panics on some malformed input,
+// we convert it to error.
+func unmarshalYAML(in []byte, out interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("yaml: %v", r)
+		}
+	}()
+	return yaml.Unmarshal(in, out)
+}
+
 // read raml file/url
-func readFileOrURL(workingDir, fileName string) ([]byte, error) {
+func readFileOrURL(workingDir, fileName string, cfg *parseConfig) ([]byte, error) {
+	// the parsing is aborted before reading the next file
+	ctx := cfg.context()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// read from URL if it is an URL, otherwise read from local file.
-	if url := strings.Join([]string{workingDir, fileName}, ""); isURL(url) {
-		return readURL(url)
+	var contents []byte
+	var err error
+	if url, ok := remoteAddress(workingDir, fileName); ok {
+		if cfg.urlCache != nil {
+			contents, err = readCachedURL(url, cfg)
+		} else {
+			contents, err = readURL(url, cfg)
+		}
+	} else {
+		contents, err = readFileContents(workingDir, fileName, cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.countSize(resolvePath(workingDir, fileName), len(contents)); err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// resolvePath returns the path or URL of a file as read by readFileOrURL
+func resolvePath(workingDir, fileName string) string {
+	if url, ok := remoteAddress(workingDir, fileName); ok {
+		return url
+	}
+	return filepath.Join(workingDir, fileName)
+}
+
+// remoteAddress returns the URL of a file if it is remote: the file name is an URL,
+// or the working directory is an URL
+func remoteAddress(workingDir, fileName string) (string, bool) {
+	fileName = strings.TrimSpace(fileName)
+	if isURL(fileName) {
+		return fileName, true
 	}
-	return readFileContents(workingDir, fileName)
+	url := workingDir + fileName
+	return url, isURL(url)
 }
 
-func readURL(address string) ([]byte, error) {
-	resp, err := http.Get(address)
+// libraryDir returns the directory used to resolve the libraries of a document
+func libraryDir(workingDir, fileName string) string {
+	switch {
+	case isURL(fileName):
+		return workingDir
+	case isURL(workingDir):
+		if dir := path.Dir(fileName); dir != "." {
+			return workingDir + dir + "/"
+		}
+		return workingDir
+	}
+	return filepath.Join(workingDir, filepath.Dir(fileName))
+}
+
+// readURL reads a remote document, within the time and size limits of the configuration
+func readURL(address string, cfg *parseConfig) ([]byte, error) {
+	ctx, cancel := cfg.fetchContext()
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := cfg.client().Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	return ioutil.ReadAll(resp.Body)
+	if err := checkStatus(address, resp); err != nil {
+		return nil, err
+	}
+	return cfg.readLimited(address, resp.Body, resp.ContentLength)
 }
 
-// Reads the contents of a file, returns a bytes buffer
-func readFileContents(workingDirectory string, fileName string) ([]byte, error) {
+// checkStatus returns an error if the response of a remote document is not successful
+func checkStatus(address string, resp *http.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("could not read %v: %v %v", address, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return nil
+}
 
+// Reads the contents of a file, returns a bytes buffer.
+// The file is read from fsys if not nil.
+func readFileContents(workingDirectory string, fileName string, cfg *parseConfig) ([]byte, error) {
+	fsys := cfg.fsys
 	filePath := filepath.Join(workingDirectory, fileName)
+	if fsys != nil {
+		filePath = path.Join(workingDirectory, fileName)
+	}
 
 	if fileName == "" {
 		return nil, fmt.Errorf("file name cannot be nil: %s", filePath)
 	}
 
-	// Read the file
-	fileContentsArray, err := ioutil.ReadFile(filePath)
+	// Read the file, within the size limit
+	var f fs.File
+	var err error
+	if fsys != nil {
+		f, err = fsys.Open(filePath)
+	} else {
+		f, err = os.Open(filePath)
+	}
 	if err != nil {
 		return nil,
 			fmt.Errorf("could not read file %s (Error: %s)", filePath, err.Error())
 	}
+	defer f.Close()
 
+	length := int64(-1)
+	if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+		length = info.Size()
+	}
+	fileContentsArray, err := cfg.readLimited(filePath, f, length)
+	if err != nil {
+		if _, ok := err.(*LimitError); ok {
+			return nil, err
+		}
+		return nil,
+			fmt.Errorf("could not read file %s (Error: %s)", filePath, err.Error())
+	}
 	return fileContentsArray, nil
 }
 
@@ -185,97 +451,208 @@ func isURL(path string) bool {
 }
 
 // preProcess acts as a preprocessor for a RAML document in YAML format,
-// including files referenced via !include. It returns a pre-processed document.
-func preProcess(originalContents io.Reader, workingDirectory string) ([]byte, error) {
+// including files referenced via !include. It returns a pre-processed document,
+// the included files, in the order they are referenced, and the location of every
+// line of the pre-processed document. The File of the lines of the document is empty.
+// The first line of the document, e.g. `#%RAML 1.0`, is already read.
+func preProcess(originalContents io.Reader, workingDirectory string,
+	cfg *parseConfig) ([]byte, []IncludedFile, []SourceLocation, map[int]string, error) {
 
 	// NOTE: Since YAML doesn't support !include directives, and since go-yaml
-	// does NOT play nice with !include tags, this has to be done like this.
-	// I am considering modifying go-yaml to add custom handlers for specific
-	// tags, to add support for !include, but for now - this method is
-	// GoodEnough(TM) and since it will only happen once, I am not prematurely
-	// optimizing it.
+	// does NOT play nice with !include tags (the decoded values lose their tag
+	// and there is no node tree), the tags are found in the text.
+	// includeScanner follows the YAML structure of the lines, so the text of
+	// the block and quoted scalars and of the comments is kept as is, and the
+	// tags of the flow collections are replaced by flow values.
 
 	var preprocessedContents bytes.Buffer
+	var includes []IncludedFile
+	var lines []SourceLocation
+
+	// media types of the binary files, by line of their tag
+	binaries := map[int]string{}
 
 	// Go over each line, looking for !include tags
 	scanner := bufio.NewScanner(originalContents)
+	tags := newIncludeScanner()
 	var line string
+	lineNo := 1
 
 	// Scan the file until we reach EOF or error out
 	for scanner.Scan() {
 		line = scanner.Text()
+		lineNo++
+		location := SourceLocation{Line: lineNo}
+
+		// the markers are indented as the line, so they are
+		// not part of the included content
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		marker := func(kind, included string) string {
+			lines = append(lines, location)
+			return indent + "# " + kind + " include: " + included + "\n"
+		}
 
-		// Did we find an !include directive to handle?
-		if idx := strings.Index(line, "!include"); idx != -1 {
-
-			included := line[idx+includeStringLen:]
-
-			rightOfDelimiter := strings.Join(strings.Split(included, "#")[1:], "#")
-			included = strings.TrimSuffix(included, rightOfDelimiter)
-			included = strings.TrimSuffix(included, "#")
-
-			preprocessedContents.Write([]byte(line[:idx]))
+		// Did we find an !include tag to handle?
+		found := tags.scan(line)
+		if len(found) == 0 {
+			// No, just a simple line.. write it
+			preprocessedContents.WriteString(line)
+			preprocessedContents.WriteByte('\n')
+			lines = append(lines, location)
+			continue
+		}
 
-			// Get the included file contents
-			includedContents, err := readFileOrURL(workingDirectory, included)
-			if err != nil {
-				return nil, fmt.Errorf("Error including file %s:\n    %s",
-					included, err.Error())
+		// the tags of a flow collection are replaced in the line
+		// by the included content as a flow scalar or collection
+		if found[0].flow {
+			var expanded strings.Builder
+			last := 0
+			for _, tag := range found {
+				includedContents, mediaType, err := readInclude(workingDirectory, tag.path, cfg)
+				if err != nil {
+					return nil, nil, nil, nil, err
+				}
+				includes = append(includes, includedFile(workingDirectory, tag.path, mediaType))
+				if mediaType != "" {
+					binaries[len(lines)] = mediaType
+				}
+				value, err := flowInclude(tag.path, includedContents)
+				if err != nil {
+					return nil, nil, nil, nil, fmt.Errorf("Error including file %s:\n    %s", tag.path, err.Error())
+				}
+				expanded.WriteString(line[last:tag.start])
+				expanded.WriteString(value)
+				last = tag.end
 			}
-
-			// we only parse utf8 content
-			if !utf8.Valid(includedContents) {
-				includedContents = []byte("")
+			expanded.WriteString(line[last:])
+			for _, tag := range found {
+				if cfg.includeMarkers {
+					preprocessedContents.WriteString(marker("begin", tag.path))
+				}
 			}
-
-			// add newline to included content
-			prepender := []byte("\n")
-
-			// if it is in response body, we prepend "|" to make it as string
-			trimmedLine := strings.TrimSpace(line)
-			if strings.HasPrefix(trimmedLine, "type ") || strings.HasPrefix(trimmedLine, "type:") { // in body
-				prepender = []byte("|\n")
+			preprocessedContents.WriteString(expanded.String())
+			preprocessedContents.WriteByte('\n')
+			lines = append(lines, location)
+			for _, tag := range found {
+				if cfg.includeMarkers {
+					preprocessedContents.WriteString(marker("end", tag.path))
+				}
 			}
-			includedContents = append(prepender, includedContents...)
-
-			// TODO: Check that you only insert .yaml, .raml, .txt and .md files
-			// In case of .raml or .yaml, remove the comments
-			// In case of other files, Base64 them first.
+			continue
+		}
 
-			// TODO: Better, step by step checks .. though prolly it'll panic
-			// Write text files in the same indentation as the first line
-			internalScanner := bufio.NewScanner(bytes.NewBuffer(includedContents))
+		tag := found[0]
+		idx := tag.start
+		if cfg.includeMarkers {
+			preprocessedContents.WriteString(marker("begin", tag.path))
+		}
 
-			// Indent by this much
-			firstLine := true
-			indentationString := ""
+		preprocessedContents.Write([]byte(line[:idx]))
 
-			// Go over each line, write it
-			for internalScanner.Scan() {
-				internalLine := internalScanner.Text()
+		// Get the included file contents
+		includedContents, mediaType, err := readInclude(workingDirectory, tag.path, cfg)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		includes = append(includes, includedFile(workingDirectory, tag.path, mediaType))
+		if mediaType != "" {
+			binaries[len(lines)] = mediaType
+		}
+		includedLocation := SourceLocation{File: resolvePath(workingDirectory, tag.path)}
 
-				preprocessedContents.WriteString(indentationString)
-				if firstLine {
-					indentationString = strings.Repeat(" ", idx)
-					firstLine = false
-				}
+		// add newline to included content
+		prepender := []byte("\n")
 
-				preprocessedContents.WriteString(internalLine)
-				preprocessedContents.WriteByte('\n')
+		// if it is in response body, or if it is a JSON schema or example,
+		// we prepend "|" to make it as string
+		trimmedLine := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmedLine, "type ") || strings.HasPrefix(trimmedLine, "type:") || // in body
+			isJSONFile(tag.path) {
+			prepender = []byte("|\n")
+		}
+		includedContents = append(prepender, includedContents...)
+
+		// Write text files in the same indentation as the tag
+		internalScanner := bufio.NewScanner(bytes.NewBuffer(includedContents))
+
+		// Indent by this much
+		firstLine := true
+		indentationString := ""
+
+		// Go over each line, write it
+		for internalScanner.Scan() {
+			internalLine := internalScanner.Text()
+
+			preprocessedContents.WriteString(indentationString)
+			if firstLine {
+				// the line of the tag
+				lines = append(lines, location)
+				indentationString = strings.Repeat(" ", idx)
+				firstLine = false
+			} else {
+				includedLocation.Line++
+				lines = append(lines, includedLocation)
 			}
 
-		} else {
-
-			// No, just a simple line.. write it
-			preprocessedContents.WriteString(line)
+			preprocessedContents.WriteString(internalLine)
 			preprocessedContents.WriteByte('\n')
 		}
+		if cfg.includeMarkers {
+			preprocessedContents.WriteString(marker("end", tag.path))
+		}
 	}
 
 	// Any errors encountered?
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading YAML file: %s", err.Error())
+		return nil, nil, nil, nil, fmt.Errorf("error reading YAML file: %s", err.Error())
 	}
 	// Return the preprocessed contents
-	return preprocessedContents.Bytes(), nil
+	return preprocessedContents.Bytes(), includes, lines, binaries, nil
+}
+
+// readInclude reads the contents of an included file. The text files are
+// decoded to UTF-8, see WithIncludeCharset, a binary file is included
+// base64 encoded, with its media type.
+func readInclude(workingDirectory, included string, cfg *parseConfig) ([]byte, string, error) {
+	if _, err := cfg.nested(resolvePath(workingDirectory, included)); err != nil {
+		return nil, "", fmt.Errorf("Error including file %s:\n    %s", included, err.Error())
+	}
+	if err := cfg.checkRemote(workingDirectory, included); err != nil {
+		return nil, "", fmt.Errorf("Error including file %s:\n    %s", included, err.Error())
+	}
+	includedContents, err := readFileOrURL(workingDirectory, included, cfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("Error including file %s:\n    %s", included, err.Error())
+	}
+
+	// we only parse utf8 content
+	text, ok := cfg.decodeText(included, includedContents)
+	if !ok {
+		encoded := base64.StdEncoding.EncodeToString(includedContents)
+		return []byte(encoded), binaryMediaType(included, includedContents), nil
+	}
+	return text, "", nil
+}
+
+// binaryMediaType returns the media type of a binary file, from its extension
+// or else from its content, e.g. `image/png`
+func binaryMediaType(fileName string, contents []byte) string {
+	mediaType := mime.TypeByExtension(path.Ext(fileName))
+	if mediaType == "" || strings.HasPrefix(mediaType, "application/octet-stream") {
+		mediaType = http.DetectContentType(contents)
+	}
+	if i := strings.IndexByte(mediaType, ';'); i >= 0 {
+		mediaType = strings.TrimSpace(mediaType[:i])
+	}
+	return mediaType
+}
+
+// includedFile returns the file included with the `!include` tag
+func includedFile(workingDirectory, included, mediaType string) IncludedFile {
+	return IncludedFile{
+		Path:      included,
+		Resolved:  resolvePath(workingDirectory, included),
+		Kind:      IncludeFile,
+		MediaType: mediaType,
+	}
 }
@@ -0,0 +1,74 @@
+package raml
+
+import "fmt"
+
+// Phase is a phase of the processing of a root document
+type Phase int
+
+const (
+	// AfterUnmarshal is once the document is decoded, before its post processing
+	AfterUnmarshal Phase = iota
+
+	// AfterLibraries is once the libraries of `uses` are parsed,
+	// before the declarations are processed. It is the phase of the
+	// API definitions and libraries only, not of the custom root documents.
+	AfterLibraries
+
+	// AfterInheritance is once the document is post processed: the types
+	// are resolved, the resource types and traits are applied.
+	// It is skipped if the post processing fails.
+	AfterInheritance
+)
+
+func (p Phase) String() string {
+	switch p {
+	case AfterUnmarshal:
+		return "afterUnmarshal"
+	case AfterLibraries:
+		return "afterLibraries"
+	case AfterInheritance:
+		return "afterInheritance"
+	}
+	return fmt.Sprintf("Phase(%d)", int(p))
+}
+
+// Processor takes part in the processing of the root documents, e.g. to check
+// or complete the declarations of an API definition, without forking PostProcess.
+// A root document implementing Processor is called first, then the
+// processors of WithProcessors, in order. The libraries are processed
+// with their own root, a *Library.
+type Processor interface {
+	// Process is called at every phase of the processing of the document.
+	// The errors are reported with the errors of the post processing,
+	// the processing goes on.
+	Process(phase Phase, root Root) error
+}
+
+// ProcessorFunc is a function used as Processor
+type ProcessorFunc func(phase Phase, root Root) error
+
+// Process calls f(phase, root)
+func (f ProcessorFunc) Process(phase Phase, root Root) error {
+	return f(phase, root)
+}
+
+// WithProcessors adds processors to the processing of the document and of its libraries
+func WithProcessors(processors ...Processor) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.processors = append(cfg.processors, processors...)
+	}
+}
+
+// process calls the processors of a phase of the processing of the root document
+func (cfg *parseConfig) process(phase Phase, root Root) error {
+	errs := new(Error)
+	if p, ok := root.(Processor); ok {
+		errs.add(p.Process(phase, root))
+	}
+	if cfg != nil {
+		for _, p := range cfg.processors {
+			errs.add(p.Process(phase, root))
+		}
+	}
+	return errs.errOrNil()
+}
@@ -0,0 +1,88 @@
+package raml
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// titleDocument is a custom root document which processes itself
+type titleDocument struct {
+	Title  string `yaml:"title"`
+	phases []Phase
+}
+
+func (d *titleDocument) PostProcess(workDir, fileName string) error {
+	return nil
+}
+
+func (d *titleDocument) Process(phase Phase, root Root) error {
+	d.phases = append(d.phases, phase)
+	return nil
+}
+
+func TestProcessors(t *testing.T) {
+	Convey("processors", t, func() {
+		Convey("phases of the document and of its libraries", func() {
+			var calls []string
+			p := ProcessorFunc(func(phase Phase, root Root) error {
+				switch r := root.(type) {
+				case *APIDefinition:
+					calls = append(calls, fmt.Sprintf("%v %v", phase, r.Title))
+				case *Library:
+					calls = append(calls, fmt.Sprintf("%v %v", phase, r.Filename))
+				}
+				return nil
+			})
+			So(ParseFile("./samples/simple_with_lib.raml", new(APIDefinition), WithProcessors(p)), ShouldBeNil)
+			So(calls, ShouldResemble, []string{
+				"afterUnmarshal Example API",
+				"afterUnmarshal libraries/files.raml",
+				"afterUnmarshal libraries/file-type.raml",
+				"afterLibraries libraries/file-type.raml",
+				"afterInheritance libraries/file-type.raml",
+				"afterLibraries libraries/files.raml",
+				"afterInheritance libraries/files.raml",
+				"afterLibraries Example API",
+				"afterInheritance Example API",
+			})
+		})
+
+		Convey("declarations added before the inheritance", func() {
+			addTrait := ProcessorFunc(func(phase Phase, root Root) error {
+				if apiDef, ok := root.(*APIDefinition); ok && phase == AfterUnmarshal {
+					apiDef.Traits = map[string]Trait{"paged": {
+						QueryParameters: map[string]NamedParameter{"page": {Type: "integer"}},
+					}}
+				}
+				return nil
+			})
+			data := []byte("#%RAML 1.0\ntitle: Paged\n/items:\n  get:\n    is: [ paged ]\n")
+			apiDef := new(APIDefinition)
+			So(ParseBytes(data, apiDef, WithProcessors(addTrait)), ShouldBeNil)
+			So(apiDef.Resources["/items"].Get.QueryParameters, ShouldContainKey, "page")
+		})
+
+		Convey("errors", func() {
+			check := ProcessorFunc(func(phase Phase, root Root) error {
+				if apiDef, ok := root.(*APIDefinition); ok && phase == AfterInheritance && apiDef.Version == "" {
+					return errors.New("the API has no version")
+				}
+				return nil
+			})
+			err := ParseBytes([]byte("#%RAML 1.0\ntitle: Unversioned\n"), new(APIDefinition), WithProcessors(check))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "the API has no version")
+		})
+
+		Convey("custom root documents", func() {
+			doc := new(titleDocument)
+			So(ParseBytes([]byte("#%RAML 1.0\ntitle: Custom\n"), doc), ShouldBeNil)
+			So(doc.Title, ShouldEqual, "Custom")
+			So(doc.phases, ShouldResemble, []Phase{AfterUnmarshal, AfterInheritance})
+			So(AfterLibraries.String(), ShouldEqual, "afterLibraries")
+		})
+	})
+}
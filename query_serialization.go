@@ -0,0 +1,156 @@
+package raml
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// QuerySerialization is the wire format of the value of a query parameter.
+// It could be set by the `(serialization)` annotation of the query parameter:
+//
+//	queryParameters:
+//	  ids:
+//	    type: string[]
+//	    (serialization): csv
+type QuerySerialization string
+
+// query parameter serializations
+const (
+	// a scalar value, e.g. `id=1`
+	QueryPlain QuerySerialization = ""
+
+	// an array as repeated keys, e.g. `ids=1&ids=2`. It is the default for the arrays.
+	QueryRepeated QuerySerialization = "repeat"
+
+	// an array as comma separated values, e.g. `ids=1,2`
+	QueryCommaSeparated QuerySerialization = "csv"
+
+	// an array as space separated values, e.g. `ids=1%202`
+	QuerySpaceSeparated QuerySerialization = "ssv"
+
+	// an array as pipe separated values, e.g. `ids=1|2`
+	QueryPipeSeparated QuerySerialization = "pipes"
+
+	// an object as keys of the parameter, e.g. `filter[name]=x&filter[age]=2`.
+	// It is the default for the objects.
+	QueryDeepObject QuerySerialization = "deepObject"
+
+	// an object as its own query parameters, e.g. `name=x&age=2`
+	QueryFlatObject QuerySerialization = "flat"
+)
+
+// separator returns the separator of the values of an array serialization
+func (s QuerySerialization) separator() (string, bool) {
+	switch s {
+	case QueryCommaSeparated:
+		return ",", true
+	case QuerySpaceSeparated:
+		return " ", true
+	case QueryPipeSeparated:
+		return "|", true
+	}
+	return "", false
+}
+
+// Serialization returns the wire format of this parameter as a query parameter:
+// the `(serialization)` annotation if any, QueryRepeated for the arrays
+// and repeated parameters, QueryDeepObject for the objects and QueryPlain otherwise.
+func (np NamedParameter) Serialization() QuerySerialization {
+	if v, ok := np.Annotation("serialization"); ok {
+		return QuerySerialization(fmt.Sprint(v))
+	}
+	switch {
+	case np.Type == arrayType || strings.HasSuffix(np.Type, "[]") || (np.Repeat != nil && *np.Repeat):
+		return QueryRepeated
+	case np.Type == "object":
+		return QueryDeepObject
+	}
+	return QueryPlain
+}
+
+// validateSerialization checks the `(serialization)` annotation of a query parameter
+func (np NamedParameter) validateSerialization() error {
+	switch s := np.Serialization(); s {
+	case QueryPlain, QueryRepeated, QueryCommaSeparated, QuerySpaceSeparated, QueryPipeSeparated,
+		QueryDeepObject, QueryFlatObject:
+		return nil
+	default:
+		return fmt.Errorf("unknown serialization %v", s)
+	}
+}
+
+// QueryValues returns the query parameters of a value of this parameter,
+// serialized as described by Serialization. The value could be a scalar,
+// a slice or a map.
+func (np NamedParameter) QueryValues(value interface{}) url.Values {
+	v := reflect.ValueOf(value)
+	serialization := np.Serialization()
+
+	params := url.Values{}
+	switch {
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		var values []string
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, fmt.Sprint(v.Index(i).Interface()))
+		}
+		if sep, ok := serialization.separator(); ok {
+			params.Set(np.Name, strings.Join(values, sep))
+		} else {
+			params[np.Name] = values
+		}
+	case v.Kind() == reflect.Map:
+		for _, key := range v.MapKeys() {
+			name := fmt.Sprint(key.Interface())
+			if serialization != QueryFlatObject {
+				name = np.Name + "[" + name + "]"
+			}
+			params.Set(name, fmt.Sprint(v.MapIndex(key).Interface()))
+		}
+	case value != nil:
+		params.Set(np.Name, fmt.Sprint(value))
+	}
+	return params
+}
+
+// QueryArray returns the values of this array parameter in the query parameters,
+// deserialized as described by Serialization
+func (np NamedParameter) QueryArray(query url.Values) []string {
+	sep, ok := np.Serialization().separator()
+	if !ok {
+		return query[np.Name]
+	}
+	var values []string
+	for _, v := range query[np.Name] {
+		values = append(values, strings.Split(v, sep)...)
+	}
+	return values
+}
+
+// QueryObject returns the properties of this object parameter in the query parameters,
+// deserialized as described by Serialization. The properties of a flat object
+// are the query parameters which are not in exclude, e.g. the other parameters of the method.
+func (np NamedParameter) QueryObject(query url.Values, exclude ...string) map[string]string {
+	excluded := map[string]bool{}
+	for _, name := range exclude {
+		excluded[name] = true
+	}
+
+	obj := map[string]string{}
+	prefix := np.Name + "["
+	for name, values := range query {
+		if len(values) == 0 {
+			continue
+		}
+		switch {
+		case np.Serialization() == QueryFlatObject:
+			if !excluded[name] {
+				obj[name] = values[0]
+			}
+		case strings.HasPrefix(name, prefix) && strings.HasSuffix(name, "]"):
+			obj[name[len(prefix):len(name)-1]] = values[0]
+		}
+	}
+	return obj
+}
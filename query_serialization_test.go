@@ -0,0 +1,58 @@
+package raml
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestQuerySerialization(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/query_serialization.raml", apiDef)
+	Convey("query parameter serialization", t, func() {
+		So(err, ShouldBeNil)
+		qps := apiDef.Resources["/orders"].Get.QueryParameters
+
+		Convey("serialization of the parameters", func() {
+			So(qps["status"].Serialization(), ShouldEqual, QueryPlain)
+			So(qps["ids"].Serialization(), ShouldEqual, QueryRepeated)
+			So(qps["tags"].Serialization(), ShouldEqual, QueryCommaSeparated)
+			So(qps["filter"].Serialization(), ShouldEqual, QueryDeepObject)
+			So(qps["sort"].Serialization(), ShouldEqual, QueryFlatObject)
+		})
+
+		Convey("encoding", func() {
+			So(qps["status"].QueryValues("open").Encode(), ShouldEqual, "status=open")
+			So(qps["ids"].QueryValues([]int{1, 2}).Encode(), ShouldEqual, "ids=1&ids=2")
+			So(qps["tags"].QueryValues([]string{"a", "b"}).Encode(), ShouldEqual, "tags=a%2Cb")
+			So(qps["filter"].QueryValues(map[string]interface{}{"name": "x", "age": 2}).Encode(),
+				ShouldEqual, "filter%5Bage%5D=2&filter%5Bname%5D=x")
+			So(qps["sort"].QueryValues(map[string]string{"date": "desc"}).Encode(), ShouldEqual, "date=desc")
+			So(qps["status"].QueryValues(nil), ShouldBeEmpty)
+		})
+
+		Convey("decoding", func() {
+			q, err := url.ParseQuery("ids=1&ids=2&tags=a,b&filter[name]=x&filter[age]=2&date=desc&status=open")
+			So(err, ShouldBeNil)
+			So(qps["ids"].QueryArray(q), ShouldResemble, []string{"1", "2"})
+			So(qps["tags"].QueryArray(q), ShouldResemble, []string{"a", "b"})
+			So(qps["filter"].QueryObject(q), ShouldResemble, map[string]string{"name": "x", "age": "2"})
+			So(qps["sort"].QueryObject(q, "ids", "tags", "filter[name]", "filter[age]", "status"),
+				ShouldResemble, map[string]string{"date": "desc"})
+		})
+
+		Convey("example request", func() {
+			r := apiDef.Resources["/orders"]
+			req := apiDef.exampleRequest(&r, r.Get)
+			So(req.resolvePath(func(p requestParam) string { return "{" + p.Name + "}" }), ShouldEqual,
+				"/orders?ids=1&ids=2&tags=a%2Cb")
+		})
+
+		Convey("unknown serialization", func() {
+			err := ParseFile("./samples/bad_query_serialization.raml", new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "GET /orders: query parameter ids: unknown serialization commas")
+		})
+	})
+}
@@ -30,9 +30,12 @@ package raml
 // This file contains tests.
 
 import (
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"os"
 	"testing"
+	"testing/fstest"
 )
 
 // TODO: Way, way more serious tests.
@@ -62,6 +65,37 @@ func TestFailedParsing(t *testing.T) {
 	}
 }
 
+func TestPostProcessErrors(t *testing.T) {
+	err := ParseFile("./samples/many_errors.raml", new(APIDefinition))
+	ramlError, ok := err.(*Error)
+	if !assert.True(t, ok, "all the errors are reported in an Error: %v", err) {
+		return
+	}
+	if assert.Len(t, ramlError.Errors, 5) {
+		assert.Contains(t, ramlError.Errors[0].Error(), "property pages")
+		assert.Contains(t, ramlError.Errors[1].Error(), "property name")
+		assert.Contains(t, ramlError.Errors[2].Error(), "can't find resource type named :unknown")
+		assert.Contains(t, ramlError.Errors[3].Error(), "can't find resource type named :other")
+		assert.Contains(t, ramlError.Errors[4].Error(), "/books: resource type collection: missing value of parameter verb")
+	}
+}
+
+func TestErrorKeepsTheErrors(t *testing.T) {
+	limitErr := &LimitError{Limit: IncludeCountLimit, Max: 4, File: "usage.md"}
+	inner := new(Error)
+	inner.add(limitErr)
+	errs := new(Error)
+	errs.add(errors.New("property pages"))
+	errs.add(inner)
+
+	var found *LimitError
+	if assert.True(t, errors.As(errs, &found)) {
+		assert.Equal(t, limitErr, found)
+	}
+	assert.True(t, errors.Is(errs, limitErr))
+	assert.Equal(t, "Error parsing RAML:\n  property pages\n  "+limitErr.Error()+"\n", errs.Error())
+}
+
 func TestParsing(t *testing.T) {
 
 	fileNames := []string{
@@ -108,3 +142,81 @@ func TestRemoteParsing(t *testing.T) {
 	asserter.Len(def.Types, 3)
 	asserter.Len(def.Types["song"].Properties, 3)
 }
+
+func TestKeepRaw(t *testing.T) {
+	asserter := assert.New(t)
+
+	def := &APIDefinition{KeepRaw: true}
+	err := ParseFile("./samples/resource_types.raml", def)
+	asserter.NoError(err)
+	asserter.NotNil(def.Raw)
+	asserter.NotEmpty(def.RawTree)
+	asserter.Equal("title", def.RawTree[0].Key)
+
+	// resolved model has the inherited description, the raw one doesn't
+	asserter.Equal("Get all Users, optionally filtered", def.Resources["/Users"].Get.Description)
+	asserter.Equal("", def.Raw.Resources["/Users"].Get.Description)
+	asserter.Equal("Create a new <<resourcePathName | !singularize | !uppercamelcase>>",
+		def.Raw.ResourceTypes["collection"].Post.Description)
+
+	// not kept by default
+	def = new(APIDefinition)
+	asserter.NoError(ParseFile("./samples/resource_types.raml", def))
+	asserter.Nil(def.Raw)
+}
+
+func TestParseBytes(t *testing.T) {
+	asserter := assert.New(t)
+
+	def := new(APIDefinition)
+	err := ParseBytes([]byte("#%RAML 1.0\ntitle: In memory\n/users:\n  get:\n    description: list\n"), def)
+	asserter.NoError(err)
+	asserter.Equal("In memory", def.Title)
+	asserter.Equal("list", def.Resources["/users"].Get.Description)
+	asserter.Equal("", def.Filename)
+
+	// not a RAML document
+	asserter.Error(ParseBytes([]byte("title: In memory\n"), new(APIDefinition)))
+}
+
+func TestParseReader(t *testing.T) {
+	asserter := assert.New(t)
+
+	f, err := os.Open("./samples/simple_with_lib.raml")
+	asserter.NoError(err)
+	defer f.Close()
+
+	def := new(APIDefinition)
+	asserter.NoError(ParseReader(f, "./samples", def))
+	asserter.Equal("Example API", def.Title)
+
+	// libraries are resolved from the working directory
+	asserter.Contains(def.Libraries, "files")
+	asserter.Equal("files.Link", def.Resources["/links"].Post.Bodies.Type)
+	asserter.Equal(IncludedFile{Path: "libraries/files.raml", Resolved: "samples/libraries/files.raml",
+		Kind: LibraryFile}, def.ListIncludedFiles()[0])
+}
+
+func TestParseFS(t *testing.T) {
+	asserter := assert.New(t)
+
+	fsys := fstest.MapFS{
+		"specs/api.raml": {Data: []byte("#%RAML 1.0\ntitle: Embedded\nuses:\n  notes: ../libs/notes.raml\n" +
+			"/notes:\n  description: !include notes.md\n")},
+		"specs/notes.md":  {Data: []byte("All the notes")},
+		"libs/notes.raml": {Data: []byte("#%RAML 1.0 Library\ntypes:\n  Note:\n    properties:\n      text: string\n")},
+	}
+	def := new(APIDefinition)
+	asserter.NoError(ParseFS(fsys, "specs/api.raml", def))
+	asserter.Equal("Embedded", def.Title)
+	asserter.Equal("All the notes", def.Resources["/notes"].Description)
+	_, ok := def.TypeByName("notes.Note")
+	asserter.True(ok)
+
+	// the files are not read from the OS file system
+	asserter.Error(ParseFS(fsys, "samples/simple_with_lib.raml", new(APIDefinition)))
+
+	def = new(APIDefinition)
+	asserter.NoError(ParseFS(os.DirFS("samples"), "included/api.raml", def))
+	asserter.Contains(def.Libraries, "files")
+}
@@ -0,0 +1,386 @@
+package raml
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// kinds of authentication of an example request
+const (
+	authNone   = ""
+	authBasic  = "basic"
+	authDigest = "digest"
+)
+
+// requestParam is a parameter of an example request.
+// If the parameter has no example value, it is a variable
+// to be provided by the user.
+type requestParam struct {
+	Name  string
+	Value string
+
+	// literal text before the variable, e.g. `Bearer `
+	Prefix string
+
+	// true if the value is not known, Value is the name of the variable
+	Variable bool
+}
+
+// exampleRequest is an example invocation of an operation,
+// built from the API definition.
+// It is rendered into curl commands, .http files, etc.
+type exampleRequest struct {
+	Method string
+
+	// base URI template, e.g. https://{host}/{version}
+	BaseURI       string
+	BaseURIParams []requestParam
+
+	// full URI template of the resource, e.g. /users/{id}
+	Path      string
+	URIParams []requestParam
+
+	Query   []requestParam
+	Headers []requestParam
+
+	// one of the auth* constants
+	Auth string
+
+	ContentType string
+	Body        string
+}
+
+// exampleRequest creates example request of a method of a resource
+func (apiDef *APIDefinition) exampleRequest(r *Resource, m *Method) exampleRequest {
+	req := exampleRequest{
+		Method:        m.Name,
+		BaseURI:       apiDef.BaseURI,
+		BaseURIParams: apiDef.baseURIParams(),
+		Path:          r.FullURI(),
+	}
+
+	for _, name := range uriTemplateParams(req.Path) {
+		req.URIParams = append(req.URIParams, paramExample(name, findURIParameter(r, name)))
+	}
+
+	// only required query parameters or the ones with example
+	for _, name := range sortedParamNames(m.QueryParameters) {
+		np := m.QueryParameters[name]
+		if !np.Required && np.exampleValue() == nil {
+			continue
+		}
+		req.Query = append(req.Query, queryExample(name, np)...)
+	}
+
+	for _, name := range sortedHeaderNames(m.Headers) {
+		np := NamedParameter(m.Headers[name])
+		if !np.Required && np.exampleValue() == nil {
+			continue
+		}
+		req.Headers = append(req.Headers, paramExample(string(name), np))
+	}
+
+	apiDef.setExampleAuth(&req, apiDef.effectiveSecuredBy(r, m))
+	req.ContentType, req.Body = apiDef.bodyExample(&m.Bodies)
+	return req
+}
+
+// findURIParameter finds URI parameter declaration in the resource or its parents
+func findURIParameter(r *Resource, name string) NamedParameter {
+	for p := r; p != nil; p = p.Parent {
+		if np, ok := p.URIParameters[name]; ok {
+			return np
+		}
+	}
+	return NamedParameter{}
+}
+
+// baseURIParams returns parameters of the base URI, version is a reserved one
+func (apiDef *APIDefinition) baseURIParams() []requestParam {
+	var params []requestParam
+	for _, name := range uriTemplateParams(apiDef.BaseURI) {
+		if name == "version" && apiDef.Version != "" {
+			params = append(params, requestParam{Name: name, Value: apiDef.Version})
+			continue
+		}
+		params = append(params, paramExample(name, apiDef.BaseURIParameters[name]))
+	}
+	return params
+}
+
+// setExampleAuth adds the parameters needed by the first applicable security scheme
+func (apiDef *APIDefinition) setExampleAuth(req *exampleRequest, securedBy []string) {
+	if len(securedBy) == 0 {
+		return
+	}
+	ss, ok := apiDef.GetSecurityScheme(securedBy[0])
+	if !ok {
+		return
+	}
+
+	switch ss.Type {
+	case "OAuth 2.0":
+		req.Headers = append(req.Headers, requestParam{
+			Name: "Authorization", Prefix: "Bearer ", Value: "access_token", Variable: true,
+		})
+	case "OAuth 1.0":
+		req.Headers = append(req.Headers, requestParam{
+			Name: "Authorization", Prefix: "OAuth ", Value: "oauth_params", Variable: true,
+		})
+	case "Basic Authentication":
+		req.Auth = authBasic
+	case "Digest Authentication":
+		req.Auth = authDigest
+	default:
+		// Pass Through and custom schemes, use the described parameters
+		for _, name := range sortedHeaderNames(ss.DescribedBy.Headers) {
+			req.Headers = append(req.Headers, requestParam{Name: string(name), Value: string(name), Variable: true})
+		}
+		for _, name := range sortedParamNames(ss.DescribedBy.QueryParameters) {
+			req.Query = append(req.Query, requestParam{Name: name, Value: name, Variable: true})
+		}
+	}
+}
+
+// bodyExample returns the media type and example of a body,
+// the Default body has the default media type of the API
+func (apiDef *APIDefinition) bodyExample(b *Bodies) (string, string) {
+	defaultType := apiDef.MediaType
+	if defaultType == "" {
+		defaultType = "application/json"
+	}
+
+	var bodies []Body
+	if b.Default != nil {
+		bodies = append(bodies, *b.Default)
+	}
+	for _, mt := range b.MediaTypes() {
+		bodies = append(bodies, b.ForMIMEType[mt])
+	}
+	contentType := func(body Body) string {
+		if body.MediaType() == "" {
+			return defaultType
+		}
+		return body.MediaType()
+	}
+
+	for _, body := range bodies {
+		if body.Example != "" {
+			return contentType(body), body.Example
+		}
+	}
+
+	// example of the body type
+	for _, body := range bodies {
+		t, ok := apiDef.TypeByName(body.TypeString())
+		if !ok {
+			continue
+		}
+		examples := t.AllExamples()
+		if len(examples) == 0 {
+			continue
+		}
+		example, err := json.MarshalIndent(jsonValue(examples[0].Value), "", "  ")
+		if err != nil {
+			continue
+		}
+		return contentType(body), string(example)
+	}
+	return "", ""
+}
+
+// paramExample creates request parameter with the example
+// or default value of the named parameter
+func paramExample(name string, np NamedParameter) requestParam {
+	if v := np.exampleValue(); v != nil {
+		return requestParam{Name: name, Value: fmt.Sprint(v)}
+	}
+	return requestParam{Name: name, Value: name, Variable: true}
+}
+
+// queryExample creates query parameters with the example or default value
+// of the named parameter, serialized as described by its Serialization
+func queryExample(name string, np NamedParameter) []requestParam {
+	v := np.exampleValue()
+	if v == nil {
+		return []requestParam{paramExample(name, np)}
+	}
+	np.Name = name
+	values := np.QueryValues(jsonValue(v))
+
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var params []requestParam
+	for _, name := range names {
+		for _, val := range values[name] {
+			params = append(params, requestParam{Name: name, Value: val})
+		}
+	}
+	return params
+}
+
+// resolveURL returns the URL of the request, variables are
+// replaced using the variable function, known values are escaped
+func (req exampleRequest) resolveURL(variable func(requestParam) string) string {
+	return strings.TrimSuffix(req.resolveBase(variable), "/") + req.resolvePath(variable)
+}
+
+// resolveBase returns the base URI of the request
+func (req exampleRequest) resolveBase(variable func(requestParam) string) string {
+	return substituteURIParams(req.BaseURI, req.BaseURIParams, escapedValue(variable, url.PathEscape))
+}
+
+// resolvePath returns the path and query string of the request
+func (req exampleRequest) resolvePath(variable func(requestParam) string) string {
+	u := substituteURIParams(req.Path, req.URIParams, escapedValue(variable, url.PathEscape))
+
+	var query []string
+	for _, q := range req.Query {
+		query = append(query, url.QueryEscape(q.Name)+"="+escapedValue(variable, url.QueryEscape)(q))
+	}
+	if len(query) > 0 {
+		u += "?" + strings.Join(query, "&")
+	}
+	return u
+}
+
+// escapedValue returns function that returns the escaped value
+// of a parameter, or the variable if the value is not known
+func escapedValue(variable func(requestParam) string, escape func(string) string) func(requestParam) string {
+	return func(p requestParam) string {
+		if p.Variable {
+			return variable(p)
+		}
+		return escape(p.Value)
+	}
+}
+
+func substituteURIParams(template string, params []requestParam, value func(requestParam) string) string {
+	for _, p := range params {
+		template = strings.Replace(template, "{"+p.Name+"}", value(p), -1)
+	}
+	return template
+}
+
+// uriTemplateParams returns the names of the parameters of an URI template
+func uriTemplateParams(template string) []string {
+	var names []string
+	for {
+		start := strings.Index(template, "{")
+		if start < 0 {
+			return names
+		}
+		end := strings.Index(template[start:], "}")
+		if end < 0 {
+			return names
+		}
+		names = appendStrNotExist(template[start+1:start+end], names)
+		template = template[start+end+1:]
+	}
+}
+
+// exampleVars collects the variables of the exported requests
+type exampleVars struct {
+	// variable names, in the order of declaration
+	vars []string
+
+	// initial values of the variables
+	values map[string]string
+}
+
+// declare declares a variable, the first declaration wins.
+// It returns the name of the variable.
+func (ev *exampleVars) declare(name, value string) string {
+	name = varName(name)
+	if _, ok := ev.values[name]; !ok {
+		ev.vars = append(ev.vars, name)
+		ev.values[name] = value
+	}
+	return name
+}
+
+// toVars declares variables for the parameters and
+// returns the parameters referencing them
+func (ev *exampleVars) toVars(params []requestParam) []requestParam {
+	var vars []requestParam
+	for _, p := range params {
+		value := p.Value
+		if p.Variable {
+			value = ""
+		}
+		vars = append(vars, requestParam{Name: p.Name, Value: ev.declare(p.Name, value), Variable: true})
+	}
+	return vars
+}
+
+func newExampleVars() *exampleVars {
+	return &exampleVars{values: map[string]string{}}
+}
+
+// varName converts a parameter name into a valid variable name
+func varName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// envName converts a parameter name into an environment variable name,
+// in upper underscore case, e.g. `USER_ID` for `userId`
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, upperUnderScoreCase(name))
+}
+
+func sortedParamNames(params map[string]NamedParameter) []string {
+	var names []string
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedHeaderNames(headers map[HTTPHeader]Header) []HTTPHeader {
+	var names []HTTPHeader
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// jsonValue converts value decoded by the YAML parser into value
+// that could be encoded as JSON
+func jsonValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, elem := range val {
+			m[fmt.Sprint(k)] = jsonValue(elem)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(val))
+		for i, elem := range val {
+			arr[i] = jsonValue(elem)
+		}
+		return arr
+	default:
+		return v
+	}
+}
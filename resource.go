@@ -2,11 +2,15 @@ package raml
 
 import (
 	"fmt"
-	"log"
 	"path"
 	"strings"
+
+	"github.com/gigforks/yaml"
 )
 
+// all supported HTTP methods, in the order they are processed
+var methodNames = []string{"GET", "POST", "PUT", "PATCH", "HEAD", "DELETE", "OPTIONS"}
+
 // A Resource is the conceptual mapping to an entity or set of entities.
 type Resource struct {
 
@@ -24,7 +28,9 @@ type Resource struct {
 	// Its value is a string and MAY be formatted using markdown.
 	Description string `yaml:"description"`
 
-	// TODO : annotationName
+	// Annotations of the resource, keyed by the annotation name
+	// in parentheses as written in the document, e.g. `(owner)`.
+	Annotations map[string]interface{} `yaml:",regexp:\\(.*\\)"`
 
 	// In a RESTful API, methods are operations that are performed on a
 	// resource. A method MUST be one of the HTTP methods defined in the
@@ -63,6 +69,78 @@ type Resource struct {
 
 	// all methods of this resource
 	Methods []*Method `yaml:"-"`
+
+	// The additional methods of this resource keyed by name, e.g. QUERY,
+	// see WithExtraMethods.
+	ExtraMethods map[string]*Method `yaml:"-"`
+
+	// Where the resource is declared in the original files.
+	Location SourceLocation `yaml:"-"`
+
+	// properties which could be additional methods
+	extensions map[string]interface{}
+}
+
+// resourceDeclaration is decoded by Resource.UnmarshalYAML
+type resourceDeclaration Resource
+
+// methodExtensions are the properties of a resource or resource type
+// which could be additional methods, the other properties are not decoded
+type methodExtensions struct {
+	Properties map[string]interface{} `yaml:",regexp:^[a-z][a-z0-9-]*\\??$"`
+}
+
+// UnmarshalYAML decodes the resource, and keeps the properties
+// which could be additional methods
+func (r *Resource) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var decl resourceDeclaration
+	err := unmarshal(&decl)
+	*r = Resource(decl)
+	if err != nil {
+		return err
+	}
+	r.extensions, err = decodeMethodExtensions(unmarshal)
+	return err
+}
+
+// decodeMethodExtensions decodes the properties which could be additional methods
+func decodeMethodExtensions(unmarshal func(interface{}) error) (map[string]interface{}, error) {
+	var ext methodExtensions
+	if err := unmarshal(&ext); err != nil {
+		return nil, err
+	}
+	for key := range ext.Properties {
+		if isMethodName(strings.TrimSuffix(key, "?")) {
+			delete(ext.Properties, key)
+		}
+	}
+	if len(ext.Properties) == 0 {
+		return nil, nil
+	}
+	return ext.Properties, nil
+}
+
+// extraMethod decodes the additional method of the given name
+// from the properties of a resource or resource type, nil if not declared
+func extraMethod(extensions map[string]interface{}, name string, optional bool) (*Method, error) {
+	key := strings.ToLower(name)
+	if optional {
+		key += "?"
+	}
+	decl, ok := extensions[key]
+	if !ok || decl == nil {
+		return nil, nil
+	}
+	data, err := yaml.Marshal(decl)
+	if err != nil {
+		return nil, err
+	}
+	m := newMethod(name)
+	if err := unmarshalYAML(data, m); err != nil {
+		return nil, fmt.Errorf("method %v: %v", name, err)
+	}
+	m.Name = name
+	return m, nil
 }
 
 // postProcess doing post processing of a resource after being constructed by the parser.
@@ -75,22 +153,41 @@ func (r *Resource) postProcess(uri string, parent *Resource, resourceTypes map[s
 	r.URI = strings.TrimSpace(uri)
 	r.Parent = parent
 
-	r.setMethods(traitsMap, apiDef)
+	// the nested resources are processed even if this one fails
+	errs := new(Error)
+	if err := r.setMethods(traitsMap, apiDef); err != nil {
+		errs.add(err)
+	} else {
+		// inherit from resource types
+		errs.add(r.inheritResourceType(resourceTypes, apiDef))
+	}
 
-	// inherit from resource types
-	if err := r.inheritResourceType(resourceTypes, apiDef); err != nil {
-		return err
+	// cascade annotations of the parent resources
+	if apiDef != nil && apiDef.CascadeAnnotations {
+		if parent != nil {
+			r.Annotations = inheritAnnotations(r.Annotations, parent.Annotations)
+		}
+		for _, m := range r.methods() {
+			m.Annotations = inheritAnnotations(m.Annotations, r.Annotations)
+		}
 	}
 
-	// process nested/child resources
-	for k := range r.Nested {
+	// the deprecation of the resource and of its parents applies to its methods
+	deprecation := r.Deprecation()
+	for _, m := range r.methods() {
+		m.resourceDeprecation = deprecation
+	}
+
+	// process nested/child resources, all of them even if some fail
+	for _, k := range sortedKeys(r.Nested) {
 		n := r.Nested[k]
-		if err := n.postProcess(k, r, resourceTypes, traitsMap, apiDef); err != nil {
-			return err
+		if n == nil { // resource without any property
+			n = &Resource{}
 		}
+		errs.add(n.postProcess(k, r, resourceTypes, traitsMap, apiDef))
 		r.Nested[k] = n
 	}
-	return nil
+	return errs.errOrNil()
 }
 
 // inherit from a resource type
@@ -100,6 +197,9 @@ func (r *Resource) inheritResourceType(resourceTypes map[string]ResourceType, ap
 	if rt == nil || err != nil {
 		return err
 	}
+	if err := checkResourceTypeParams(r.Type.Name, *rt, r.Type.Parameters); err != nil {
+		return fmt.Errorf("%v: %v", r.FullURI(), err)
+	}
 
 	// initialize dicts
 	dicts := initResourceTypeDicts(r, r.Type.Parameters)
@@ -169,28 +269,54 @@ func (r *Resource) getResourceType(resourceTypes map[string]ResourceType) (*Reso
 
 // set methods set all methods name
 // and add it to Methods slice
-func (r *Resource) setMethods(traitsMap map[string]Trait, apiDef *APIDefinition) {
-	if r.Get != nil {
-		r.Get.postProcess(r, "GET", traitsMap, apiDef)
-	}
-	if r.Post != nil {
-		r.Post.postProcess(r, "POST", traitsMap, apiDef)
-	}
-	if r.Put != nil {
-		r.Put.postProcess(r, "PUT", traitsMap, apiDef)
-	}
-	if r.Patch != nil {
-		r.Patch.postProcess(r, "PATCH", traitsMap, apiDef)
+func (r *Resource) setMethods(traitsMap map[string]Trait, apiDef *APIDefinition) error {
+	for _, name := range apiDef.extraMethods() {
+		m, err := extraMethod(r.extensions, name, false)
+		if err != nil {
+			return fmt.Errorf("%v: %v", r.FullURI(), err)
+		}
+		if m != nil {
+			r.assignMethod(m, name)
+		}
 	}
-	if r.Head != nil {
-		r.Head.postProcess(r, "HEAD", traitsMap, apiDef)
+
+	for _, name := range r.methodNames() {
+		if m := r.MethodByName(name); m != nil {
+			if err := m.postProcess(r, name, traitsMap, apiDef); err != nil {
+				return err
+			}
+		}
 	}
-	if r.Delete != nil {
-		r.Delete.postProcess(r, "DELETE", traitsMap, apiDef)
+	return nil
+}
+
+// methodNames returns the names of the methods of RAML
+// followed by the additional methods of the resource, sorted
+func (r *Resource) methodNames() []string {
+	if len(r.ExtraMethods) == 0 {
+		return methodNames
 	}
-	if r.Options != nil {
-		r.Options.postProcess(r, "OPTIONS", traitsMap, apiDef)
+	names := append([]string{}, methodNames...)
+	return append(names, sortedKeys(r.ExtraMethods)...)
+}
+
+// methods returns all non-nil methods of the resource,
+// including the ones inherited from resource type,
+// in the order of methodNames followed by the additional methods
+func (r *Resource) methods() []*Method {
+	var methods []*Method
+	for _, name := range r.methodNames() {
+		if m := r.MethodByName(name); m != nil {
+			methods = append(methods, m)
+		}
 	}
+	return methods
+}
+
+// Annotation returns the value of an annotation of this resource,
+// the name could be given with or without the parentheses, e.g. `owner`
+func (r *Resource) Annotation(name string) (interface{}, bool) {
+	return annotation(r.Annotations, name)
 }
 
 // MethodByName return resource's method by it's name
@@ -211,7 +337,7 @@ func (r *Resource) MethodByName(name string) *Method {
 	case "OPTIONS":
 		return r.Options
 	default:
-		return nil
+		return r.ExtraMethods[name]
 	}
 }
 
@@ -232,7 +358,10 @@ func (r *Resource) assignMethod(m *Method, name string) {
 	case "OPTIONS":
 		r.Options = m
 	default:
-		log.Fatalf("assignMethod fatal error, invalid method name:%v", name)
+		if r.ExtraMethods == nil {
+			r.ExtraMethods = map[string]*Method{}
+		}
+		r.ExtraMethods[name] = m
 	}
 }
 
@@ -248,22 +377,17 @@ func substituteParams(toReplace, words string, dicts map[string]interface{}) str
 		return toReplace
 	}
 
-	removeParamBracket := func(param string) string {
-		param = strings.TrimSpace(param)
-		return param[2 : len(param)-2]
-	}
-
-	// search params
-	params := dcRe.FindAllString(words, -1)
+	// search params, the submatch is the param without the brackets
+	params := dcRe.FindAllStringSubmatch(words, -1)
 
 	// substitute the params
 	for _, p := range params {
-		pVal, ok := getParamValue(removeParamBracket(p), dicts)
+		pVal, ok := getParamValue(strings.TrimSpace(p[1]), dicts)
 		if !ok {
 			// only replace if param is found
 			continue
 		}
-		words = strings.Replace(words, p, pVal, -1)
+		words = strings.Replace(words, p[0], pVal, -1)
 	}
 	return words
 }
@@ -301,14 +425,16 @@ func getParamValue(param string, dicts map[string]interface{}) (string, bool) {
 			var ok bool
 			val, ok = doInflect(val, inflector)
 			if !ok {
-				log.Fatalf("invalid inflector " + inflector)
+				// leave the param unsubstituted
+				return "", false
 			}
 		}
 	}
 	return val, true
 }
 
-// CleanURI returns URI without `/`, `\`', `{`, and `}`
+// CleanURI returns URI without `/`, `\`', `{`, and `}`,
+// see NormalizedURI for the configurable identifiers of the full URI
 func (r *Resource) CleanURI() string {
 	s := removeDoubleSlash(r.URI)
 	return strings.TrimSpace(removeDoubleChevron(s))
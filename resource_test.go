@@ -46,8 +46,8 @@ func TestResourceTypeInheritance(t *testing.T) {
 			So(r.Post, ShouldNotBeNil)
 
 			props := r.Post.Bodies.ApplicationJSON.Properties
-			So(ToProperty("name", props["name"]).Type, ShouldEqual, "string")
-			So(ToProperty("age", props["age"]).Type, ShouldEqual, "int")
+			So(props["name"].Type, ShouldEqual, "string")
+			So(props["age"].Type, ShouldEqual, "int")
 			So(r.Post.Headers["X-Chargeback"].Required, ShouldBeTrue)
 
 			mem := r.Nested["/{id}"]
@@ -101,8 +101,8 @@ func TestResourceTypeInheritance(t *testing.T) {
 			props := r.Post.Bodies.ApplicationJSON.Properties
 
 			So(props, ShouldContainKey, "name")
-			So(props, ShouldContainKey, "address?")
-			So(props, ShouldNotContainKey, "location?")
+			So(props, ShouldContainKey, "address")
+			So(props["address"].Required, ShouldBeFalse)
 			So(props, ShouldNotContainKey, "location")
 		})
 		Convey("resource types can use traits", func() {
@@ -115,3 +115,449 @@ func TestResourceTypeInheritance(t *testing.T) {
 		})
 	})
 }
+
+func TestResourceTypeMethodParams(t *testing.T) {
+	Convey("parameters of the methods of a resource type", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/resource_type_methods.raml", apiDef), ShouldBeNil)
+
+		books := apiDef.Resources["/books"]
+		So(books.Description, ShouldEqual, "The books")
+		So(books.Get.Description, ShouldEqual, "GET the books")
+		So(books.Get.DisplayName, ShouldEqual, "getBook")
+		So(books.Get.QueryParameters["sort"].Description, ShouldEqual, "sort the books to get")
+		So(books.Post.Description, ShouldEqual, "POST a book")
+		So(books.Post.DisplayName, ShouldEqual, "postBook")
+		So(books.Post.Responses["201"].Description, ShouldEqual, "the book of the post")
+
+		authors := apiDef.Resources["/authors"]
+		So(authors.Get.Description, ShouldEqual, "list the authors")
+		So(authors.Get.QueryParameters["sort"].Description, ShouldEqual, "sort the authors to get")
+		So(authors.Post.Description, ShouldEqual, "POST a author")
+
+		// not provided at the level of the resource
+		err := checkResourceTypeParams("item", ResourceType{Description: "<<methodName>>"}, nil)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "resource type item: missing value of parameter methodName")
+	})
+}
+
+func TestHeaderLookup(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/headers.raml", apiDef)
+	Convey("case-insensitive headers", t, func() {
+		So(err, ShouldBeNil)
+		m := apiDef.Resources["/items"].Get
+
+		Convey("canonical header name", func() {
+			So(HTTPHeader("x-request-id").Canonical(), ShouldEqual, HTTPHeader("X-Request-Id"))
+			So(HTTPHeader("content-TYPE").Canonical(), ShouldEqual, HTTPHeader("Content-Type"))
+		})
+
+		Convey("inherited header declared in another case", func() {
+			So(m.Headers, ShouldHaveLength, 2)
+			So(m.Headers, ShouldContainKey, HTTPHeader("X-Request-Id"))
+
+			h, ok := m.Header("x-request-id")
+			So(ok, ShouldBeTrue)
+			So(h.Required, ShouldBeTrue)
+			So(h.Description, ShouldEqual, "id of the request")
+			So(h.Example, ShouldEqual, "abc")
+		})
+
+		Convey("method header lookup", func() {
+			_, ok := m.Header("Accept")
+			So(ok, ShouldBeTrue)
+			_, ok = m.Header("Authorization")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("response header lookup", func() {
+			resp := m.Responses["200"]
+			h, ok := resp.Header("content-type")
+			So(ok, ShouldBeTrue)
+			So(h.Example, ShouldEqual, "application/json")
+		})
+
+		Convey("headers declared in different cases", func() {
+			for i := 0; i < 10; i++ {
+				apiDef := new(APIDefinition)
+				So(ParseFile("./samples/headers.raml", apiDef), ShouldBeNil)
+				get := apiDef.Resources["/collisions"].Get
+
+				// the first one by name
+				h, ok := get.Header("x-ID")
+				So(ok, ShouldBeTrue)
+				So(h.Description, ShouldEqual, "upper case")
+				So(h.Example, ShouldEqual, 42)
+				So(get.Headers["x-id"].Example, ShouldBeNil)
+
+				So(apiDef.Warnings, ShouldContain, Warning{Location: "GET /collisions",
+					Message: "header x-id is also declared as X-Id, X-Id is used"})
+			}
+		})
+	})
+}
+
+func TestResponseSelection(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/responses.raml", apiDef)
+	Convey("response selection", t, func() {
+		So(err, ShouldBeNil)
+		m := apiDef.Resources["/items"].Post
+
+		codes := func(resps []Response) []HTTPCode {
+			var codes []HTTPCode
+			for _, resp := range resps {
+				codes = append(codes, resp.HTTPCode)
+			}
+			return codes
+		}
+
+		Convey("success responses", func() {
+			So(codes(m.SuccessResponses()), ShouldResemble, []HTTPCode{"200", "201"})
+		})
+
+		Convey("error responses", func() {
+			So(codes(m.ErrorResponses()), ShouldResemble, []HTTPCode{"404", "4XX", "500"})
+		})
+
+		Convey("response for a status code", func() {
+			resp, ok := m.ResponseFor(201)
+			So(ok, ShouldBeTrue)
+			So(resp.Description, ShouldEqual, "created")
+
+			resp, ok = m.ResponseFor(409)
+			So(ok, ShouldBeTrue)
+			So(resp.Description, ShouldEqual, "client error")
+
+			resp, ok = m.ResponseFor(302)
+			So(ok, ShouldBeTrue)
+			So(resp.Description, ShouldEqual, "unexpected")
+
+			_, ok = new(Method).ResponseFor(200)
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("status codes", func() {
+			code, ok := HTTPCode("404").Int()
+			So(ok, ShouldBeTrue)
+			So(code, ShouldEqual, 404)
+			_, ok = HTTPCode("4XX").Int()
+			So(ok, ShouldBeFalse)
+
+			So(HTTPCode("4XX").IsRange(), ShouldBeTrue)
+			So(HTTPCode("5xx").IsRange(), ShouldBeTrue)
+			So(HTTPCode("404").IsRange(), ShouldBeFalse)
+			So(HTTPCode("default").IsDefault(), ShouldBeTrue)
+
+			for _, code := range []HTTPCode{"100", "599", "2XX", "default"} {
+				So(code.Validate(), ShouldBeNil)
+			}
+			for _, code := range []HTTPCode{"99", "600", "2000", "6XX", "ok", ""} {
+				So(code.Validate(), ShouldNotBeNil)
+			}
+
+			err := ParseFile("./samples/bad_response_code.raml", new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "GET /items: invalid HTTP status code: 2000")
+			So(err.Error(), ShouldContainSubstring, "trait paged: invalid HTTP status code: 4000")
+			So(err.Error(), ShouldContainSubstring, "resource type collection: POST: invalid HTTP status code: 20001")
+		})
+
+		Convey("response annotations", func() {
+			resp := m.Responses["404"]
+			So(resp.Annotations, ShouldResemble, map[string]interface{}{"(errorCode)": "ITEM_NOT_FOUND"})
+			code, ok := resp.Annotation("errorCode")
+			So(ok, ShouldBeTrue)
+			So(code, ShouldEqual, "ITEM_NOT_FOUND")
+			_, ok = resp.Annotation("(retryable)")
+			So(ok, ShouldBeFalse)
+
+			// inherited from the trait
+			resp = m.Responses["500"]
+			So(resp.Annotations, ShouldResemble, map[string]interface{}{
+				"(errorCode)": "INTERNAL",
+				"(retryable)": true,
+			})
+			So(m.Responses["201"].Annotations, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestParameterValidation(t *testing.T) {
+	Convey("trait and resource type parameters", t, func() {
+		Convey("unused trait parameter", func() {
+			err := ParseFile("./samples/bad_trait_params.raml", new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "GET /books: trait paged: parameter pageSize is not used")
+		})
+
+		Convey("missing resource type parameter", func() {
+			err := ParseFile("./samples/bad_resource_type_params.raml", new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "/books: resource type collection: missing value of parameter verb")
+		})
+
+		Convey("parameters with inflectors and reserved parameters", func() {
+			err := checkParams("trait", "paged", Trait{Description: "<<count | !singularize>> <<methodName>>"},
+				DefinitionParameters{"count": 1}, traitReservedParams)
+			So(err, ShouldBeNil)
+
+			err = checkParams("resource type", "item", ResourceType{Description: "<<methodName>>"},
+				nil, resourceTypeReservedParams)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestUnresolvedParameters(t *testing.T) {
+	apiDef := new(APIDefinition)
+	err := ParseFile("./samples/unresolved_params.raml", apiDef)
+	Convey("unresolved parameters", t, func() {
+		So(err, ShouldBeNil)
+		So(apiDef.UnresolvedParameters(), ShouldResemble, []UnresolvedParameter{
+			{URI: "/books", Path: "description", Placeholder: "<<kind>>"},
+			{URI: "/books", Method: "GET", Path: "responses.200.body.application/json.properties", Placeholder: "<<field>>"},
+			{URI: "/books", Method: "GET", Path: "responses.200.body.application/json.type", Placeholder: "<<item>>"},
+		})
+
+		Convey("resolved parameters are not reported", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/resource_types.raml", apiDef), ShouldBeNil)
+			So(apiDef.UnresolvedParameters(), ShouldBeEmpty)
+		})
+
+		Convey("field names", func() {
+			So(lowerCamel("URI"), ShouldEqual, "uri")
+			So(lowerCamel("HTTPCode"), ShouldEqual, "httpCode")
+			So(lowerCamel("DisplayName"), ShouldEqual, "displayName")
+		})
+	})
+}
+
+func TestURIParameterMismatches(t *testing.T) {
+	Convey("URI parameter mismatches", t, func() {
+		var warnings []Warning
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/uri_params.raml", apiDef, WithWarningHandler(func(w Warning) {
+			warnings = append(warnings, w)
+		})), ShouldBeNil)
+
+		mismatches := apiDef.URIParameterMismatches()
+		So(mismatches, ShouldResemble, []URIParameterMismatch{
+			{Parameter: "zone", Unused: true},
+			{Parameter: "host"},
+			{URI: "/users/{userId}", Parameter: "userdId", Unused: true},
+			{URI: "/users/{userId}", Parameter: "userId"},
+			{URI: "/users/{userId}/books/{bookId}{mediaTypeExtension}", Parameter: "bookId"},
+			{URI: "/users/{userId}/groups/{groupId}", Parameter: "itemId", Unused: true},
+		})
+		So(mismatches[0].String(), ShouldEqual, "baseUri: URI parameter zone is declared but not in the URI")
+		So(mismatches[3].String(), ShouldEqual, "/users/{userId}: URI parameter {userId} is not declared")
+
+		Convey("the unused parameters are warnings", func() {
+			So(warnings, ShouldResemble, []Warning{
+				{Message: "baseUri: URI parameter zone is declared but not in the URI"},
+				{Message: "/users/{userId}: URI parameter userdId is declared but not in the URI"},
+				{Message: "/users/{userId}/groups/{groupId}: URI parameter itemId is declared but not in the URI"},
+			})
+		})
+
+		Convey("the unused parameters are errors in strict mode", func() {
+			err := ParseFile("./samples/uri_params.raml", new(APIDefinition), WithStrictMode())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "URI parameter zone is declared but not in the URI")
+		})
+
+		Convey("the declared parameters are not reported", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/resource_types.raml", apiDef), ShouldBeNil)
+			for _, m := range apiDef.URIParameterMismatches() {
+				So(m.Unused, ShouldBeFalse)
+			}
+		})
+	})
+}
+
+func TestTraitText(t *testing.T) {
+	parse := func(mode TraitTextMerge) (*Resource, error) {
+		apiDef := &APIDefinition{TraitText: mode}
+		err := ParseFile("./samples/trait_text.raml", apiDef)
+		r := apiDef.Resources["/users"]
+		return &r, err
+	}
+
+	Convey("trait display name and description", t, func() {
+		Convey("keep the method text by default", func() {
+			r, err := parse(KeepMethodText)
+			So(err, ShouldBeNil)
+			So(r.Get.DisplayName, ShouldEqual, "list users")
+			So(r.Get.Description, ShouldEqual, "Lists the users.")
+			So(r.Post.DisplayName, ShouldEqual, "paged")
+			So(r.Post.Description, ShouldEqual, "Returns a page of the users.")
+		})
+
+		Convey("overwrite the method text", func() {
+			r, err := parse(OverwriteMethodText)
+			So(err, ShouldBeNil)
+			So(r.Get.DisplayName, ShouldEqual, "paged")
+			So(r.Get.Description, ShouldEqual, "Returns a page of the users.")
+			So(r.Post.Description, ShouldEqual, "Returns a page of the users.")
+		})
+
+		Convey("append to the method text", func() {
+			r, err := parse(AppendToMethodText)
+			So(err, ShouldBeNil)
+			So(r.Get.DisplayName, ShouldEqual, "list users paged")
+			So(r.Get.Description, ShouldEqual, "Lists the users.\n\nReturns a page of the users.")
+			So(r.Post.Description, ShouldEqual, "Returns a page of the users.")
+		})
+	})
+}
+
+func TestAnnotationCascade(t *testing.T) {
+	Convey("resource annotations", t, func() {
+		Convey("declared annotations", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/annotations.raml", apiDef), ShouldBeNil)
+			r := apiDef.Resources["/orders"]
+
+			owner, ok := r.Annotation("owner")
+			So(ok, ShouldBeTrue)
+			So(owner, ShouldEqual, "team-orders")
+			So(r.Get.Annotations, ShouldBeEmpty)
+			So(r.Post.Annotations, ShouldResemble, map[string]interface{}{"(owner)": "team-checkout"})
+			So(r.Nested["/{id}"].Annotations, ShouldResemble, map[string]interface{}{"(tier)": 2})
+		})
+
+		Convey("cascaded to nested resources and methods", func() {
+			apiDef := &APIDefinition{CascadeAnnotations: true}
+			So(ParseFile("./samples/annotations.raml", apiDef), ShouldBeNil)
+			r := apiDef.Resources["/orders"]
+
+			So(r.Get.Annotations, ShouldResemble, map[string]interface{}{"(owner)": "team-orders", "(tier)": 1})
+			So(r.Post.Annotations, ShouldResemble, map[string]interface{}{"(owner)": "team-checkout", "(tier)": 1})
+
+			item := r.Nested["/{id}"]
+			So(item.Annotations, ShouldResemble, map[string]interface{}{"(owner)": "team-orders", "(tier)": 2})
+			tier, ok := item.Get.Annotation("(tier)")
+			So(ok, ShouldBeTrue)
+			So(tier, ShouldEqual, 2)
+
+			// the parent is not modified
+			So(r.Annotations, ShouldResemble, map[string]interface{}{"(owner)": "team-orders", "(tier)": 1})
+		})
+	})
+}
+
+func TestExtraMethods(t *testing.T) {
+	Convey("additional methods", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/extra_methods.raml", apiDef), ShouldBeNil)
+		books := apiDef.Resources["/books"]
+		So(books.ExtraMethods, ShouldBeEmpty)
+		So(books.Methods, ShouldHaveLength, 1)
+
+		apiDef = new(APIDefinition)
+		So(ParseFile("./samples/extra_methods.raml", apiDef, WithExtraMethods("QUERY", "link", "purge", "get")),
+			ShouldBeNil)
+
+		Convey("declared and inherited", func() {
+			books := apiDef.Resources["/books"]
+			query := books.MethodByName("QUERY")
+			So(query, ShouldNotBeNil)
+			So(query.Name, ShouldEqual, "QUERY")
+			So(query.Description, ShouldEqual, "search the books")
+			So(query.QueryParameters, ShouldContainKey, "page")
+			So(query.Bodies.ForMIMEType, ShouldContainKey, "application/json")
+
+			// optional method of the resource type
+			So(books.MethodByName("LINK"), ShouldBeNil)
+
+			var names []string
+			for _, m := range books.Methods {
+				names = append(names, m.Name)
+			}
+			So(names, ShouldResemble, []string{"GET", "QUERY"})
+		})
+
+		Convey("optional and vendor methods", func() {
+			authors := apiDef.Resources["/authors"]
+			So(authors.ExtraMethods, ShouldHaveLength, 3)
+			link := authors.ExtraMethods["LINK"]
+			So(link.Description, ShouldEqual, "link an author")
+			So(link.Headers, ShouldContainKey, HTTPHeader("Link"))
+			So(authors.ExtraMethods["PURGE"].Description, ShouldEqual, "purge the cache of the authors")
+			So(authors.ExtraMethods["QUERY"].Description, ShouldEqual, "search the authors")
+		})
+	})
+}
+
+func TestTraitOrder(t *testing.T) {
+	Convey("trait application order", t, func() {
+		Convey("the traits of the method first", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/trait_order.raml", apiDef), ShouldBeNil)
+			get := apiDef.Resources["/items"].Get
+			So(get.AppliedTraits, ShouldResemble, []string{"paged", "secured", "audited"})
+			So(get.Description, ShouldEqual, "Paged list")
+			So(get.DisplayName, ShouldEqual, "Audited")
+			h, _ := get.Header("Authorization")
+			So(h.Description, ShouldEqual, "token of the paged trait")
+		})
+
+		Convey("a trait applied with other parameters", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/trait_order.raml", apiDef), ShouldBeNil)
+			get := apiDef.Resources["/pages"].Get
+			So(get.AppliedTraits, ShouldResemble, []string{"limited", "limited"})
+			So(get.QueryParameters["size"].Description, ShouldEqual, "at most 10")
+			So(get.QueryParameters["count"].Description, ShouldEqual, "at most 50")
+		})
+
+		Convey("the traits of the resource first", func() {
+			apiDef := &APIDefinition{TraitOrder: ResourceTraitsFirst}
+			So(ParseFile("./samples/trait_order.raml", apiDef), ShouldBeNil)
+			get := apiDef.Resources["/items"].Get
+			So(get.AppliedTraits, ShouldResemble, []string{"secured", "paged", "audited"})
+			So(get.Description, ShouldEqual, "Requires a token")
+			h, _ := get.Header("Authorization")
+			So(h.Description, ShouldEqual, "token of the secured trait")
+		})
+
+		Convey("the trait of highest precedence overwrites the text", func() {
+			apiDef := &APIDefinition{TraitText: OverwriteMethodText}
+			So(ParseFile("./samples/trait_order.raml", apiDef), ShouldBeNil)
+			So(apiDef.Resources["/orders"].Get.Description, ShouldEqual, "Paged list")
+
+			apiDef = &APIDefinition{TraitText: AppendToMethodText}
+			So(ParseFile("./samples/trait_order.raml", apiDef), ShouldBeNil)
+			So(apiDef.Resources["/orders"].Get.Description, ShouldEqual,
+				"List the orders\n\nPaged list\n\nRequires a token")
+		})
+	})
+}
+
+func TestURINormalization(t *testing.T) {
+	Convey("URI normalization", t, func() {
+		uri := "/users/{user_id}/profile-pictures"
+		So(URINormalization{}.Normalize(uri), ShouldEqual, "usersuser_idprofile-pictures")
+		So(URINormalization{Separator: "_"}.Normalize(uri), ShouldEqual, "users_user_id_profile-pictures")
+		So(URINormalization{Separator: "/", KeepParameterMarkers: true}.Normalize(uri), ShouldEqual,
+			"users/{user_id}/profile-pictures")
+		So(URINormalization{Separator: "-", Slugify: true}.Normalize(uri), ShouldEqual,
+			"users-user-id-profile-pictures")
+		So(URINormalization{Separator: ".", Slugify: true, KeepParameterMarkers: true}.Normalize("/Files/{file_Name}.json"),
+			ShouldEqual, "files.{file-name}-json")
+		So(URINormalization{Inflector: "uppercamelcase"}.Normalize(uri), ShouldEqual, "UsersUserIdProfilePictures")
+		So(URINormalization{Inflector: "!unknown"}.Normalize("/a/{b}"), ShouldEqual, "ab")
+		So(URINormalization{}.Normalize("/"), ShouldBeEmpty)
+
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/gateway.raml", apiDef), ShouldBeNil)
+		users := apiDef.Resources["/users"]
+		r := users.Nested["/{userId}"]
+		So(r.NormalizedURI(URINormalization{Separator: "_", Inflector: "lowerunderscorecase"}), ShouldEqual, "users_user_id")
+	})
+}
@@ -1,6 +1,7 @@
 package raml
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 
@@ -9,6 +10,9 @@ import (
 
 var (
 	dcRe = regexp.MustCompile(`\<<(.*?)\>>`) // double chevron regex
+
+	// type names of a type expression, possibly qualified by library name
+	typeNameRe = regexp.MustCompile(`[A-Za-z_][\w.-]*`)
 )
 
 // ResourceType defines a resource type.
@@ -44,6 +48,13 @@ type ResourceType struct {
 	// Name of the resource type
 	Name string
 
+	// The libraries the resource type is resolved through by
+	// APIDefinition.ResourceTypeByName, empty for the resource types of the root document.
+	LibraryChain LibraryChain `yaml:"-"`
+
+	// Where the resource type is declared in the original files.
+	Location SourceLocation `yaml:"-"`
+
 	// The OPTIONAL usage property of a resource type provides instructions
 	// on how and when the resource type or trait should be used.
 	// Documentation generators MUST convey this property
@@ -52,6 +63,10 @@ type ResourceType struct {
 	// neither resources nor methods allow a property named usage.
 	Usage string
 
+	// The libraries used by a resource type fragment, relative to the fragment file.
+	// They are added to the libraries of the document including the fragment.
+	Uses map[string]string `yaml:"uses"`
+
 	// Briefly describes what the resource type
 	Description string
 
@@ -100,6 +115,25 @@ type ResourceType struct {
 
 	methods         []*Method // all non-nil methods
 	optionalMethods []*Method // all non-nil optional methods
+
+	// properties which could be additional methods
+	extensions map[string]interface{}
+}
+
+// resourceTypeDeclaration is decoded by ResourceType.UnmarshalYAML
+type resourceTypeDeclaration ResourceType
+
+// UnmarshalYAML decodes the resource type, and keeps the properties
+// which could be additional methods
+func (rt *ResourceType) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var decl resourceTypeDeclaration
+	err := unmarshal(&decl)
+	*rt = ResourceType(decl)
+	if err != nil {
+		return err
+	}
+	rt.extensions, err = decodeMethodExtensions(unmarshal)
+	return err
 }
 
 // postProcess doing post processing of a resource type after being constructed
@@ -107,54 +141,78 @@ type ResourceType struct {
 // - assign all properties that can't be obtained from RAML document
 // - inherit from other resource type
 // - apply traits
-func (rt *ResourceType) postProcess(name string, traitsMap map[string]Trait, apiDef *APIDefinition) {
+func (rt *ResourceType) postProcess(name string, traitsMap map[string]Trait, apiDef *APIDefinition) error {
 	rt.Name = name
-	rt.setMethods(traitsMap, apiDef)
+	if err := rt.setMethods(traitsMap, apiDef); err != nil {
+		return fmt.Errorf("resource type %v: %v", name, err)
+	}
 	rt.setOptionalMethods()
+	if err := rt.setExtraMethods(apiDef.extraMethods(), traitsMap, apiDef); err != nil {
+		return fmt.Errorf("resource type %v: %v", name, err)
+	}
+	for _, m := range append(append([]*Method{}, rt.methods...), rt.optionalMethods...) {
+		if err := validateResponseCodes(m.Responses); err != nil {
+			return fmt.Errorf("resource type %v: %v: %v", name, m.Name, err)
+		}
+	}
 
 	// TODO : inherit from other resource type
-
-	// TODO : apply traits
+	return nil
 }
 
 // set methods set all methods name
 // and add it to methods slice
-func (rt *ResourceType) setMethods(traitsMap map[string]Trait, apiDef *APIDefinition) {
+func (rt *ResourceType) setMethods(traitsMap map[string]Trait, apiDef *APIDefinition) error {
 	if rt.Get != nil {
 		rt.Get.Name = "GET"
-		rt.Get.inheritFromTraits(nil, append(rt.Is, rt.Get.Is...), traitsMap, apiDef)
+		if err := rt.Get.inheritFromTraits(nil, rt.Is, rt.Get.Is, traitsMap, apiDef); err != nil {
+			return err
+		}
 		rt.methods = append(rt.methods, rt.Get)
 	}
 	if rt.Post != nil {
 		rt.Post.Name = "POST"
-		rt.Post.inheritFromTraits(nil, append(rt.Is, rt.Post.Is...), traitsMap, apiDef)
+		if err := rt.Post.inheritFromTraits(nil, rt.Is, rt.Post.Is, traitsMap, apiDef); err != nil {
+			return err
+		}
 		rt.methods = append(rt.methods, rt.Post)
 	}
 	if rt.Put != nil {
 		rt.Put.Name = "PUT"
-		rt.Put.inheritFromTraits(nil, append(rt.Is, rt.Put.Is...), traitsMap, apiDef)
+		if err := rt.Put.inheritFromTraits(nil, rt.Is, rt.Put.Is, traitsMap, apiDef); err != nil {
+			return err
+		}
 		rt.methods = append(rt.methods, rt.Put)
 	}
 	if rt.Patch != nil {
 		rt.Patch.Name = "PATCH"
-		rt.Patch.inheritFromTraits(nil, append(rt.Is, rt.Patch.Is...), traitsMap, apiDef)
+		if err := rt.Patch.inheritFromTraits(nil, rt.Is, rt.Patch.Is, traitsMap, apiDef); err != nil {
+			return err
+		}
 		rt.methods = append(rt.methods, rt.Patch)
 	}
 	if rt.Head != nil {
 		rt.Head.Name = "HEAD"
-		rt.Head.inheritFromTraits(nil, append(rt.Is, rt.Head.Is...), traitsMap, apiDef)
+		if err := rt.Head.inheritFromTraits(nil, rt.Is, rt.Head.Is, traitsMap, apiDef); err != nil {
+			return err
+		}
 		rt.methods = append(rt.methods, rt.Head)
 	}
 	if rt.Delete != nil {
 		rt.Delete.Name = "DELETE"
-		rt.Delete.inheritFromTraits(nil, append(rt.Is, rt.Delete.Is...), traitsMap, apiDef)
+		if err := rt.Delete.inheritFromTraits(nil, rt.Is, rt.Delete.Is, traitsMap, apiDef); err != nil {
+			return err
+		}
 		rt.methods = append(rt.methods, rt.Delete)
 	}
 	if rt.Options != nil {
 		rt.Options.Name = "OPTIONS"
-		rt.Options.inheritFromTraits(nil, append(rt.Is, rt.Options.Is...), traitsMap, apiDef)
+		if err := rt.Options.inheritFromTraits(nil, rt.Is, rt.Options.Is, traitsMap, apiDef); err != nil {
+			return err
+		}
 		rt.methods = append(rt.methods, rt.Options)
 	}
+	return nil
 }
 
 // setOptionalMethods set name of all optional methods
@@ -190,6 +248,32 @@ func (rt *ResourceType) setOptionalMethods() {
 	}
 }
 
+// setExtraMethods adds the additional methods and optional methods
+// of the given names, see WithExtraMethods
+func (rt *ResourceType) setExtraMethods(names []string, traitsMap map[string]Trait, apiDef *APIDefinition) error {
+	for _, name := range names {
+		m, err := extraMethod(rt.extensions, name, false)
+		if err != nil {
+			return err
+		}
+		if m != nil {
+			if err := m.inheritFromTraits(nil, rt.Is, m.Is, traitsMap, apiDef); err != nil {
+				return err
+			}
+			rt.methods = append(rt.methods, m)
+		}
+
+		m, err = extraMethod(rt.extensions, name, true)
+		if err != nil {
+			return err
+		}
+		if m != nil {
+			rt.optionalMethods = append(rt.optionalMethods, m)
+		}
+	}
+	return nil
+}
+
 func initResourceTypeDicts(r *Resource, dicts map[string]interface{}) map[string]interface{} {
 	if len(dicts) == 0 {
 		dicts = map[string]interface{}{}
@@ -201,31 +285,31 @@ func initResourceTypeDicts(r *Resource, dicts map[string]interface{}) map[string
 	return dicts
 }
 
-// merge name of type with resource type name.
-func mergeTypeName(name, rtName string, apiDef *APIDefinition) string {
-	if apiDef == nil {
-		log.Warning("passing nil API definition to mergeTypeName")
-		return name
-	}
-
-	// check if this resource type is in library
-	splt := strings.Split(rtName, ".")
-	if len(splt) < 2 {
+// QualifyTypeName returns the canonical name of a type referenced by a declaration
+// of a library, e.g. `files.Link` for `Link` referenced by the resource type `files.link`.
+// context is the qualified name of the resource type or trait which references the type,
+// the declarations of the API definition itself don't need to be qualified.
+// The types of a type expression are qualified one by one, e.g. `files.Link[]`.
+// Names which are not types of the library, e.g. builtin types, are kept.
+func (apiDef *APIDefinition) QualifyTypeName(name, context string) string {
+	// check if the declaration is in library
+	idx := strings.LastIndex(context, ".")
+	if idx < 0 {
 		// if not in library, we need to do nothing
 		return name
 	}
-
-	// get the library object from API definition root object
-	libName := splt[0]
-	lib, ok := apiDef.Libraries[libName]
-	if !ok {
-		return name
-	}
-
-	// type not exist in the library
-	if _, ok := lib.Types[name]; !ok {
+	if apiDef == nil {
+		log.Warning("passing nil API definition to QualifyTypeName")
 		return name
 	}
+	libName := context[:idx]
 
-	return strings.Join([]string{libName, name}, ".")
+	return typeNameRe.ReplaceAllStringFunc(name, func(typeName string) string {
+		qualified := libName + "." + typeName
+		if _, ok := apiDef.TypeByName(qualified); !ok {
+			// type not exist in the library
+			return typeName
+		}
+		return qualified
+	})
 }
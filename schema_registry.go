@@ -0,0 +1,120 @@
+package raml
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// SchemaRegistry is a central catalog of types, shared by several API
+// definitions, see WithSchemaRegistry. The types are identified by their
+// name and the version of the API definition.
+type SchemaRegistry interface {
+	// Pull returns the type of the registry, false if it has none.
+	// The type is as written in a document, e.g. decoded with yaml.Unmarshal,
+	// it is post-processed like the types declared by the API definition.
+	Pull(ctx context.Context, name, version string) (Type, bool, error)
+
+	// Push publishes a type declared by the API definition, once post-processed
+	Push(ctx context.Context, name, version string, t Type) error
+}
+
+// WithSchemaRegistry keeps the types of the API definition in sync with a registry.
+// The types referenced by the declared types or by the bodies of the methods,
+// and declared neither by the document nor by its libraries, are pulled from
+// the registry. Then the types declared by the document are pushed to the
+// registry, in dependency order, see TypesInDependencyOrder.
+// The version of the types is the version of the API definition.
+func WithSchemaRegistry(r SchemaRegistry) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.schemaRegistry = r
+	}
+}
+
+// pullTypes adds the types referenced but not declared by the document
+// which are in the registry, until all the references of the pulled types
+// are resolved too. It returns the names of the pulled types.
+func (apiDef *APIDefinition) pullTypes(ctx context.Context, r SchemaRegistry) (map[string]bool, error) {
+	pulled := map[string]bool{}
+	tried := map[string]bool{}
+	for {
+		var missing []string
+		for _, name := range apiDef.typeReferences() {
+			if _, ok := apiDef.TypeByName(name); !ok && !tried[name] {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) == 0 {
+			return pulled, nil
+		}
+		for _, name := range missing {
+			tried[name] = true
+			t, ok, err := r.Pull(ctx, name, apiDef.Version)
+			if err != nil {
+				return nil, fmt.Errorf("can't pull type %v from the schema registry: %v", name, err)
+			}
+			if !ok {
+				continue
+			}
+			if err := t.postProcess(name, apiDef); err != nil {
+				return nil, err
+			}
+			if apiDef.Types == nil {
+				apiDef.Types = map[string]Type{}
+			}
+			apiDef.Types[name] = t
+			pulled[name] = true
+		}
+	}
+}
+
+// pushTypes pushes the types declared by the document to the registry
+func (apiDef *APIDefinition) pushTypes(ctx context.Context, r SchemaRegistry, pulled map[string]bool) error {
+	for _, t := range apiDef.TypesInDependencyOrder() {
+		if pulled[t.Name] {
+			continue
+		}
+		if err := r.Push(ctx, t.Name, apiDef.Version, t); err != nil {
+			return fmt.Errorf("can't push type %v to the schema registry: %v", t.Name, err)
+		}
+	}
+	return nil
+}
+
+// typeReferences returns the names of the types referenced by the declared
+// types and by the bodies of the methods, sorted. Builtin types are not included.
+func (apiDef *APIDefinition) typeReferences() []string {
+	var refs []string
+	add := func(expr string) {
+		for _, name := range typeExprRefs(expr) {
+			refs = appendStrNotExist(name, refs)
+		}
+	}
+	addBodies := func(bodies Bodies) {
+		if bodies.Default != nil {
+			add(bodies.Default.TypeString())
+			add(itemsTypeString(bodies.Default.Items))
+		}
+		for _, mime := range sortedKeys(bodies.ForMIMEType) {
+			body := bodies.ForMIMEType[mime]
+			add(body.TypeString())
+			add(itemsTypeString(body.Items))
+		}
+	}
+
+	for _, name := range sortedKeys(apiDef.Types) {
+		for _, dep := range apiDef.Types[name].dependencies() {
+			refs = appendStrNotExist(dep, refs)
+		}
+	}
+	apiDef.walkResources(func(r *Resource) {
+		for _, m := range r.methods() {
+			addBodies(m.Bodies)
+			for _, resp := range m.Responses {
+				addBodies(resp.Bodies)
+			}
+		}
+	})
+	sort.Strings(refs)
+	return refs
+}
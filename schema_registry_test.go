@@ -0,0 +1,67 @@
+package raml
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gigforks/yaml"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// memorySchemaRegistry is a SchemaRegistry in memory, by name@version
+type memorySchemaRegistry struct {
+	types  map[string]string
+	pulls  []string
+	pushed []string
+	err    error
+}
+
+func (r *memorySchemaRegistry) Pull(ctx context.Context, name, version string) (Type, bool, error) {
+	r.pulls = append(r.pulls, name+"@"+version)
+	var t Type
+	if r.err != nil {
+		return t, false, r.err
+	}
+	doc, ok := r.types[name+"@"+version]
+	if !ok {
+		return t, false, nil
+	}
+	return t, true, yaml.Unmarshal([]byte(doc), &t)
+}
+
+func (r *memorySchemaRegistry) Push(ctx context.Context, name, version string, t Type) error {
+	r.pushed = append(r.pushed, name+"@"+version)
+	return nil
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	Convey("schema registry", t, func() {
+		registry := &memorySchemaRegistry{types: map[string]string{
+			"Address@v2": "properties:\n  street: string\n  country: Country\n",
+			"Country@v2": "type: string\nenum: [FR, US]\n",
+			"Address@v1": "properties:\n  line: string\n",
+		}}
+
+		Convey("pull the missing types and push the declared ones", func() {
+			apiDef := new(APIDefinition)
+			err := ParseFile("./samples/schema_registry.raml", apiDef, WithSchemaRegistry(registry))
+			So(err, ShouldBeNil)
+			So(registry.pulls, ShouldResemble, []string{"Address@v2", "Invoice@v2", "Country@v2"})
+			So(registry.pushed, ShouldResemble, []string{"Order@v2"})
+
+			address, ok := apiDef.TypeByName("Address")
+			So(ok, ShouldBeTrue)
+			So(address.Properties["country"].TypeString(), ShouldEqual, "Country")
+			_, ok = apiDef.TypeByName("Invoice")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("registry errors", func() {
+			registry.err = errors.New("registry unavailable")
+			err := ParseFile("./samples/schema_registry.raml", new(APIDefinition), WithSchemaRegistry(registry))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "can't pull type Address from the schema registry: registry unavailable")
+		})
+	})
+}
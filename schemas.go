@@ -0,0 +1,123 @@
+package raml
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gigforks/yaml"
+)
+
+// SchemaDeclarations are the schemas of the root `schemas` property,
+// each map has a single schema keyed by its name.
+// They are declared as a map in RAML 1.0 and as a sequence of maps in RAML 0.8.
+type SchemaDeclarations []map[string]string
+
+// UnmarshalYAML decodes the schemas declared as a map or as a sequence of maps
+func (s *SchemaDeclarations) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var seq []map[string]string
+	if err := unmarshal(&seq); err == nil {
+		*s = seq
+		return nil
+	}
+	var decl yaml.MapSlice
+	if err := unmarshal(&decl); err != nil {
+		return err
+	}
+	*s = nil
+	for _, item := range decl {
+		*s = append(*s, map[string]string{fmt.Sprint(item.Key): fmt.Sprint(item.Value)})
+	}
+	return nil
+}
+
+// schemaType returns the declared type or the schema of the given name, false if
+// there is none. A schema is returned as a type with its content as type.
+func (apiDef *APIDefinition) schemaType(name string) (*Type, bool, error) {
+	if t, ok := apiDef.TypeByName(name); ok {
+		return t, true, nil
+	}
+	for _, decl := range apiDef.Schemas {
+		content, ok := decl[name]
+		if !ok {
+			continue
+		}
+		t := &Type{Type: content}
+		if err := t.postProcess(name, apiDef); err != nil {
+			return nil, false, fmt.Errorf("schema %v: %v", name, err)
+		}
+		return t, true, nil
+	}
+	return nil, false, nil
+}
+
+// resolveBodyTypes registers the inline types of the properties of the bodies of the methods,
+// sets their ResolvedType and validates their examples
+func (apiDef *APIDefinition) resolveBodyTypes() error {
+	errs := new(Error)
+	resolve := func(location string, bodies *Bodies, owner string, path ...string) {
+		register := func(location string, body *Body) {
+			if err := body.registerInlineTypes(apiDef, owner, path...); err != nil {
+				errs.add(fmt.Errorf("%v: %v", location, err))
+			}
+		}
+		if bodies.Default != nil {
+			register(location, bodies.Default)
+			errs.add(bodies.Default.resolveType(location, apiDef))
+			if err := bodies.Default.validateExamples(apiDef.MediaType, apiDef); err != nil {
+				errs.add(fmt.Errorf("%v: %v", location, err))
+			}
+		}
+		for _, mediaType := range sortedKeys(bodies.ForMIMEType) {
+			body := bodies.ForMIMEType[mediaType]
+			register(location+" "+mediaType, &body)
+			errs.add(body.resolveType(location+" "+mediaType, apiDef))
+			if err := body.validateExamples(mediaType, apiDef); err != nil {
+				errs.add(fmt.Errorf("%v %v: %v", location, mediaType, err))
+			}
+			bodies.ForMIMEType[mediaType] = body
+		}
+		if body, ok := bodies.ForMIMEType["application/json"]; ok && bodies.ApplicationJSON != nil {
+			bodies.ApplicationJSON.Properties = body.Properties
+			bodies.ApplicationJSON.RawProperties = body.RawProperties
+			bodies.ApplicationJSON.Items = body.Items
+		}
+	}
+	apiDef.walkResources(func(r *Resource) {
+		for _, m := range r.methods() {
+			location, method := m.Name+" "+r.FullURI(), strings.ToLower(m.Name)
+			resolve(location+": body", &m.Bodies, r.FullURI(), method, "body")
+			for _, code := range sortedKeys(m.Responses) {
+				resp := m.Responses[code]
+				resolve(fmt.Sprintf("%v: response %v body", location, code), &resp.Bodies,
+					r.FullURI(), method, fmt.Sprint(code), "body")
+				m.Responses[code] = resp
+			}
+		}
+	})
+	return errs.errOrNil()
+}
+
+// resolveType sets the ResolvedType of the body if its schema or its type is a name.
+// A schema name must be declared, a type name could be a builtin type.
+func (b *Body) resolveType(location string, apiDef *APIDefinition) error {
+	name, isSchema := strings.TrimSpace(b.Schema), true
+	if name != "" {
+		apiDef.warn(location, "schema is deprecated, use type")
+	}
+	if name == "" {
+		name, isSchema = strings.TrimSpace(b.TypeString()), false
+	}
+	if refs := typeExprRefs(name); len(refs) != 1 || refs[0] != name {
+		// a builtin type, a type expression or an inline schema
+		return nil
+	}
+	t, ok, err := apiDef.schemaType(name)
+	if err != nil {
+		return fmt.Errorf("%v: %v", location, err)
+	}
+	if !ok && isSchema {
+		return fmt.Errorf("%v: schema %v is not declared", location, name)
+	}
+	b.ResolvedType = t
+	return nil
+}
@@ -14,8 +14,9 @@ type SecuritySchemeMethod struct {
 // SecurityScheme defines mechanisms to secure data access, identify
 // requests, and determine access level and data visibility.
 type SecurityScheme struct {
+	// name of the security scheme, its key in `securitySchemes`
+	// or the name of its fragment file
 	Name string
-	// TODO: Fill this during the post-processing phase
 
 	// The type attribute MAY be used to convey information about
 	// authentication flows and mechanisms to processing applications
@@ -45,3 +46,12 @@ type SecurityScheme struct {
 	// The settings attribute MAY be used to provide security scheme-specific information.
 	Settings map[string]Any `yaml:"settings"`
 }
+
+// nameSecuritySchemes sets the names of the declared security schemes,
+// including the ones included from a `#%RAML 1.0 SecurityScheme` fragment
+func nameSecuritySchemes(securitySchemes map[string]SecurityScheme) {
+	for name, ss := range securitySchemes {
+		ss.Name = name
+		securitySchemes[name] = ss
+	}
+}
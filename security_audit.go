@@ -0,0 +1,205 @@
+package raml
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// kinds of security audit findings
+const (
+	FindingUnsecured        = "unsecured"
+	FindingMissing401       = "missing-401"
+	FindingMissing403       = "missing-403"
+	FindingSecretQueryParam = "secret-query-parameter"
+)
+
+// words of the query parameter names that look like they carry a secret,
+// matched against whole words, e.g. `api_key` or `accessToken` but not `author`
+var secretParamWords = [][]string{
+	{"token"}, {"secret"}, {"password"}, {"passwd"}, {"pwd"}, {"credential"},
+	{"api", "key"}, {"apikey"}, {"private", "key"}, {"privatekey"}, {"session", "id"}, {"sessionid"},
+}
+
+// SecurityFinding is a single issue found by the security audit
+type SecurityFinding struct {
+	// full URI of the resource
+	URI string
+
+	// method name, e.g. GET
+	Method string
+
+	// one of the Finding* constants
+	Kind string
+
+	// human readable details
+	Detail string
+}
+
+// SecurityReport is the result of the security audit of an API definition
+type SecurityReport struct {
+	Findings []SecurityFinding
+}
+
+// SecurityAudit analyzes all operations of the API and reports:
+//   - operations without effective security scheme
+//   - secured operations that don't declare the 401 and 403 responses,
+//     neither directly nor via their security schemes
+//   - query parameters which look like secrets, they end up in logs and browser history
+func (apiDef *APIDefinition) SecurityAudit() SecurityReport {
+	var report SecurityReport
+
+	apiDef.walkResources(func(r *Resource) {
+		uri := r.FullURI()
+		for _, m := range r.methods() {
+			report.Findings = append(report.Findings, apiDef.auditMethod(uri, r, m)...)
+		}
+	})
+	return report
+}
+
+func (apiDef *APIDefinition) auditMethod(uri string, r *Resource, m *Method) []SecurityFinding {
+	var findings []SecurityFinding
+	add := func(kind, format string, args ...interface{}) {
+		findings = append(findings, SecurityFinding{
+			URI:    uri,
+			Method: m.Name,
+			Kind:   kind,
+			Detail: fmt.Sprintf(format, args...),
+		})
+	}
+
+	schemes := apiDef.effectiveSecuredBy(r, m)
+	if len(schemes) == 0 {
+		add(FindingUnsecured, "no security scheme applies to this operation")
+	} else {
+		responses := map[HTTPCode]bool{}
+		for code := range m.Responses {
+			responses[code] = true
+		}
+		for _, name := range schemes {
+			if ss, ok := apiDef.GetSecurityScheme(name); ok {
+				for code := range ss.DescribedBy.Responses {
+					responses[code] = true
+				}
+			}
+		}
+		if !responses["401"] {
+			add(FindingMissing401, "secured by %v but doesn't declare 401 response", schemes)
+		}
+		if !responses["403"] {
+			add(FindingMissing403, "secured by %v but doesn't declare 403 response", schemes)
+		}
+	}
+
+	var names []string
+	for name := range m.QueryParameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if isSecretParam(name) {
+			add(FindingSecretQueryParam, "query parameter '%v' looks like a secret", name)
+		}
+	}
+	return findings
+}
+
+// isSecretParam returns true if a parameter name contains the words of a secret,
+// in the singular or the plural, e.g. `X-Auth-Tokens` or `clientSecret`
+func isSecretParam(name string) bool {
+	words := nameWords(name)
+	for i := range words {
+		words[i] = strings.TrimSuffix(words[i], "s")
+	}
+	for i := range words {
+	secret:
+		for _, secret := range secretParamWords {
+			if i+len(secret) > len(words) {
+				continue
+			}
+			for j, w := range secret {
+				if words[i+j] != w {
+					continue secret
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// nameWords splits a name into its lower case words, at the characters which are not
+// letters or digits and at the camel case boundaries, e.g. `api`, `key` and `id` for `APIKey_id`
+func nameWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+			}
+			start = -1
+			continue
+		}
+		// a new word starts at an upper case letter following a lower case letter,
+		// or followed by a lower case letter after upper case letters, e.g. `Key` of `APIKey`
+		if start >= 0 && start < i && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) ||
+			unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+	return words
+}
+
+// effectiveSecuredBy returns names of the security schemes that apply to the method.
+// The method level securedBy overrides the resource level,
+// which overrides the API level.
+// `null` scheme, which means no security, is not included.
+func (apiDef *APIDefinition) effectiveSecuredBy(r *Resource, m *Method) []string {
+	securedBy := m.SecuredBy
+	if len(securedBy) == 0 {
+		securedBy = r.SecuredBy
+	}
+	if len(securedBy) == 0 {
+		securedBy = apiDef.SecuredBy
+	}
+
+	var names []string
+	for _, dc := range securedBy {
+		if dc.Name != "" && dc.Name != "null" {
+			names = append(names, dc.Name)
+		}
+	}
+	return names
+}
+
+// String returns human readable report, grouped by operation
+func (sr SecurityReport) String() string {
+	var buf bytes.Buffer
+	if len(sr.Findings) == 0 {
+		return "no security findings\n"
+	}
+
+	var last string
+	for _, f := range sr.Findings {
+		op := f.Method + " " + f.URI
+		if op != last {
+			fmt.Fprintf(&buf, "%v\n", op)
+			last = op
+		}
+		fmt.Fprintf(&buf, "    [%v] %v\n", f.Kind, f.Detail)
+	}
+	fmt.Fprintf(&buf, "%d finding(s)\n", len(sr.Findings))
+	return buf.String()
+}
@@ -0,0 +1,40 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSecurityAudit(t *testing.T) {
+	Convey("security audit", t, func() {
+		apiDef := new(APIDefinition)
+		err := ParseFile("./samples/security.raml", apiDef)
+		So(err, ShouldBeNil)
+
+		report := apiDef.SecurityAudit()
+		So(report.Findings, ShouldResemble, []SecurityFinding{
+			{URI: "/health", Method: "GET", Kind: FindingUnsecured,
+				Detail: "no security scheme applies to this operation"},
+			{URI: "/public", Method: "GET", Kind: FindingUnsecured,
+				Detail: "no security scheme applies to this operation"},
+			{URI: "/users", Method: "GET", Kind: FindingSecretQueryParam,
+				Detail: "query parameter 'api_key' looks like a secret"},
+			{URI: "/users", Method: "POST", Kind: FindingMissing403,
+				Detail: "secured by [oauth_2_0] but doesn't declare 403 response"},
+		})
+		So(report.String(), ShouldContainSubstring, "POST /users\n    [missing-403]")
+
+		Convey("secret query parameters", func() {
+			So(nameWords("APIKey_id"), ShouldResemble, []string{"api", "key", "id"})
+			for _, name := range []string{"api_key", "apiKey", "X-API-Key", "access_token", "accessToken",
+				"client-secrets", "password", "sessionId", "SESSIONID", "privateKey"} {
+				So(isSecretParam(name), ShouldBeTrue)
+			}
+			for _, name := range []string{"author", "authorId", "sessionLength", "keyword", "tokenizer",
+				"passwordless_login", "apiVersion", "session"} {
+				So(isSecretParam(name), ShouldBeFalse)
+			}
+		})
+	})
+}
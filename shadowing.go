@@ -0,0 +1,107 @@
+package raml
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ShadowedDeclaration is a type, trait or resource type declared with the same
+// unqualified name by the document and its libraries, or by several libraries.
+//
+// An unqualified type name refers to the type of the document, or to the type
+// of the library declaring it if it is declared by exactly one library.
+// An unqualified trait or resource type name only refers to the declaration
+// of the document, the ones of the libraries must be qualified.
+type ShadowedDeclaration struct {
+	// "type", "trait" or "resourceType"
+	Kind string
+
+	// unqualified name
+	Name string
+
+	// qualified names of the declarations, the one of the document first,
+	// then the ones of the libraries sorted by name, e.g. `[User files.User]`
+	Declarations []string
+
+	// qualified name of the declaration the unqualified name refers to,
+	// empty if it refers to none of them
+	Resolved string
+}
+
+// Warning explains which declaration the unqualified name refers to
+func (s ShadowedDeclaration) Warning() string {
+	declared := fmt.Sprintf("%v %v is declared as %v", s.Kind, s.Name, strings.Join(s.Declarations, ", "))
+	if s.Resolved == "" {
+		return fmt.Sprintf("%v: `%v` refers to none of them, use a qualified name", declared, s.Name)
+	}
+	return fmt.Sprintf("%v: `%v` refers to the declaration of the document", declared, s.Name)
+}
+
+// ShadowedDeclarations returns the types, traits and resource types of the libraries
+// used by the document which have the same unqualified name as a declaration of the
+// document or of another library, sorted by kind and name.
+// The libraries used by the libraries are not included, their declarations
+// can't be referenced unqualified.
+func (apiDef *APIDefinition) ShadowedDeclarations() []ShadowedDeclaration {
+	var shadowed []ShadowedDeclaration
+	add := func(kind string, rootNames map[string]bool, libNames map[string][]string) {
+		names := make([]string, 0, len(libNames))
+		for name := range libNames {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			libs := libNames[name]
+			inRoot := rootNames[name]
+			if len(libs) < 2 && !inRoot {
+				continue
+			}
+			s := ShadowedDeclaration{Kind: kind, Name: name}
+			if inRoot {
+				s.Declarations = append(s.Declarations, name)
+				s.Resolved = name
+			}
+			sort.Strings(libs)
+			for _, lib := range libs {
+				s.Declarations = append(s.Declarations, lib+"."+name)
+			}
+			shadowed = append(shadowed, s)
+		}
+	}
+
+	types, traits, rts := map[string][]string{}, map[string][]string{}, map[string][]string{}
+	for libName, lib := range apiDef.Libraries {
+		for name := range lib.Types {
+			types[name] = append(types[name], libName)
+		}
+		for name := range lib.Traits {
+			traits[name] = append(traits[name], libName)
+		}
+		for name := range lib.ResourceTypes {
+			rts[name] = append(rts[name], libName)
+		}
+	}
+	rootTypes, rootTraits, rootRTs := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for name := range apiDef.Types {
+		rootTypes[name] = true
+	}
+	for name := range apiDef.Traits {
+		rootTraits[name] = true
+	}
+	for name := range apiDef.ResourceTypes {
+		rootRTs[name] = true
+	}
+
+	add("resourceType", rootRTs, rts)
+	add("trait", rootTraits, traits)
+	add("type", rootTypes, types)
+	return shadowed
+}
+
+// warnShadowedDeclarations reports a warning for every shadowed declaration
+func (apiDef *APIDefinition) warnShadowedDeclarations() {
+	for _, s := range apiDef.ShadowedDeclarations() {
+		apiDef.warn("", "%v", s.Warning())
+	}
+}
@@ -0,0 +1,180 @@
+package raml
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SourceLocation is where a declaration is written in the original files,
+// once the included files are inlined
+type SourceLocation struct {
+	// path or URL of the document or of the included file, as resolved from
+	// the working directory, empty for a document parsed from memory
+	File string
+
+	// line of the declaration in the file, starting at 1, 0 if not known
+	Line int
+}
+
+func (l SourceLocation) String() string {
+	if l.File == "" {
+		return fmt.Sprintf("line %d", l.Line)
+	}
+	return fmt.Sprintf("%v:%d", l.File, l.Line)
+}
+
+// errorLineRe matches the line numbers of the YAML errors, e.g. `line 12:`
+var errorLineRe = regexp.MustCompile(`\bline (\d+)\b`)
+
+// sourceMap maps the preprocessed document back to the original files
+type sourceMap struct {
+	// location of every line of the preprocessed document
+	lines []SourceLocation
+
+	// line of the preprocessed document of the nodes,
+	// starting at 0, by JSON pointer
+	nodes map[string]int
+
+	// media types of the binary files included base64 encoded,
+	// by line of the preprocessed document of their tag
+	binaries map[int]string
+}
+
+// newSourceMap creates the source map of a preprocessed document, lines are the
+// locations of its lines with an empty File for the lines of the document itself
+func newSourceMap(file string, contents []byte, lines []SourceLocation) *sourceMap {
+	for i := range lines {
+		if lines[i].File == "" {
+			lines[i].File = file
+		}
+	}
+	_, src := scanSource(contents)
+	nodes := make(map[string]int, len(src.spans))
+	for pointer, span := range src.spans {
+		// the span starts with the comments preceding the node
+		line := span.start
+		for line < span.end-1 && line < len(src.lines) {
+			trimmed := strings.TrimSpace(src.lines[line])
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				break
+			}
+			line++
+		}
+		nodes[pointer] = line
+	}
+	return &sourceMap{lines: lines, nodes: nodes}
+}
+
+// location returns the location of the node of the document at the JSON pointer
+func (sm *sourceMap) location(pointer string) (SourceLocation, bool) {
+	if sm == nil {
+		return SourceLocation{}, false
+	}
+	line, ok := sm.nodes[pointer]
+	if !ok || line >= len(sm.lines) {
+		return SourceLocation{}, false
+	}
+	return sm.lines[line], true
+}
+
+// binaryMediaType returns the media type of the binary file included
+// by the node at the JSON pointer, empty if it isn't a binary file
+func (sm *sourceMap) binaryMediaType(pointer string) string {
+	if sm == nil {
+		return ""
+	}
+	line, ok := sm.nodes[pointer]
+	if !ok {
+		return ""
+	}
+	return sm.binaries[line]
+}
+
+// fixErrorLines replaces the line numbers of the preprocessed document
+// in an error message by the lines of the original files,
+// e.g. `line 3 of schemas/user.json` for an included file
+func (sm *sourceMap) fixErrorLines(msg, file string) string {
+	if sm == nil {
+		return msg
+	}
+	return errorLineRe.ReplaceAllStringFunc(msg, func(s string) string {
+		n, _ := strconv.Atoi(errorLineRe.FindStringSubmatch(s)[1])
+		if n < 1 || n > len(sm.lines) {
+			return s
+		}
+		loc := sm.lines[n-1]
+		if loc.File == file {
+			return fmt.Sprintf("line %d", loc.Line)
+		}
+		return fmt.Sprintf("line %d of %v", loc.Line, loc.File)
+	})
+}
+
+// SourceLocation returns the location of the node of the document at the
+// JSON pointer (RFC 6901), e.g. `/types/User` or `/~1users/~1{userId}/get`,
+// false if it is not declared by the document
+func (apiDef *APIDefinition) SourceLocation(pointer string) (SourceLocation, bool) {
+	return apiDef.sourceMap.location(pointer)
+}
+
+// setSourceLocations sets the location of the declarations and resources
+func (apiDef *APIDefinition) setSourceLocations() {
+	sm := apiDef.sourceMap
+	setDeclarationLocations(sm, apiDef.Types, apiDef.Traits, apiDef.ResourceTypes)
+
+	var setResource func(r *Resource, pointer string)
+	setResource = func(r *Resource, pointer string) {
+		r.Location, _ = sm.location(pointer)
+		for _, m := range r.methods() {
+			mPointer := pointer + jsonPointer([]string{strings.ToLower(m.Name)})
+			setExampleMediaTypes(sm, &m.Bodies, mPointer+"/body")
+			for code, resp := range m.Responses {
+				setExampleMediaTypes(sm, &resp.Bodies, mPointer+jsonPointer([]string{"responses", string(code), "body"}))
+				m.Responses[code] = resp
+			}
+		}
+		for key, n := range r.Nested {
+			setResource(n, pointer+jsonPointer([]string{key}))
+		}
+	}
+	for key, r := range apiDef.Resources {
+		setResource(&r, jsonPointer([]string{key}))
+		apiDef.Resources[key] = r
+	}
+}
+
+// setExampleMediaTypes sets the media type of the examples of the bodies
+// which are binary files
+func setExampleMediaTypes(sm *sourceMap, bodies *Bodies, pointer string) {
+	if bodies.Default != nil {
+		bodies.Default.ExampleMediaType = sm.binaryMediaType(pointer + "/example")
+	}
+	for mediaType, b := range bodies.ForMIMEType {
+		b.ExampleMediaType = sm.binaryMediaType(pointer + jsonPointer([]string{mediaType, "example"}))
+		bodies.ForMIMEType[mediaType] = b
+	}
+}
+
+// setSourceLocations sets the location of the declarations
+func (l *Library) setSourceLocations() {
+	setDeclarationLocations(l.sourceMap, l.Types, l.Traits, l.ResourceTypes)
+}
+
+// setDeclarationLocations sets the location of the types, traits and resource types
+func setDeclarationLocations(sm *sourceMap, types map[string]Type, traits map[string]Trait,
+	rts map[string]ResourceType) {
+	for name, t := range types {
+		t.Location, _ = sm.location(jsonPointer([]string{"types", name}))
+		types[name] = t
+	}
+	for name, t := range traits {
+		t.Location, _ = sm.location(jsonPointer([]string{"traits", name}))
+		traits[name] = t
+	}
+	for name, rt := range rts {
+		rt.Location, _ = sm.location(jsonPointer([]string{"resourceTypes", name}))
+		rts[name] = rt
+	}
+}
@@ -0,0 +1,57 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSourceMap(t *testing.T) {
+	Convey("source map", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/included/source_map.raml", apiDef), ShouldBeNil)
+		doc := "samples/included/source_map.raml"
+		types := "samples/included/types.yaml"
+
+		Convey("declarations", func() {
+			So(apiDef.Traits["paged"].Location, ShouldResemble, SourceLocation{File: doc, Line: 4})
+			So(apiDef.Types["User"].Location, ShouldResemble, SourceLocation{File: types, Line: 1})
+			So(apiDef.Types["Group"].Location, ShouldResemble, SourceLocation{File: types, Line: 5})
+			So(apiDef.Types["Group"].Location.String(), ShouldEqual, "samples/included/types.yaml:5")
+		})
+
+		Convey("resources", func() {
+			users := apiDef.Resources["/users"]
+			So(users.Location, ShouldResemble, SourceLocation{File: doc, Line: 9})
+			So(users.Nested["/{userId}"].Location, ShouldResemble, SourceLocation{File: doc, Line: 12})
+		})
+
+		Convey("nodes", func() {
+			loc, ok := apiDef.SourceLocation("/~1users/get")
+			So(ok, ShouldBeTrue)
+			So(loc, ShouldResemble, SourceLocation{File: doc, Line: 11})
+			loc, ok = apiDef.SourceLocation("/~1users/get/is")
+			So(ok, ShouldBeTrue)
+			So(loc, ShouldResemble, SourceLocation{File: "samples/included/list_users.yaml", Line: 2})
+			_, ok = apiDef.SourceLocation("/~1groups")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("libraries", func() {
+			apiDef := new(APIDefinition)
+			So(ParseFile("./samples/included/api.raml", apiDef), ShouldBeNil)
+			So(apiDef.Libraries["notes"].Types["Note"].Location, ShouldResemble,
+				SourceLocation{File: "samples/included/notes.raml", Line: 4})
+		})
+
+		Convey("errors", func() {
+			err := ParseFile("./samples/included/bad_include.raml", new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "line 4 of samples/included/bad_method.yaml:")
+
+			err = ParseBytes([]byte("#%RAML 1.0\ntitle: In memory\n/users:\n  uriParameters: []\n"), new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "line 4:")
+		})
+	})
+}
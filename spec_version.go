@@ -0,0 +1,228 @@
+package raml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gigforks/yaml"
+)
+
+// SpecVersionAnnotation is the annotation of the semantic version
+// of an API definition, e.g. `(specVersion): 1.4.0`
+const SpecVersionAnnotation = "specVersion"
+
+// SemVer is a semantic version, e.g. `1.4.0` or `2.0.0-beta.1`
+type SemVer struct {
+	Major, Minor, Patch int
+
+	// pre-release version without the `-`, e.g. `beta.1`
+	PreRelease string
+}
+
+// ParseSemVer parses a semantic version, with an optional `v` prefix.
+// The build metadata, after a `+`, is ignored.
+func ParseSemVer(s string) (SemVer, error) {
+	var v SemVer
+	str := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.Index(str, "+"); i >= 0 {
+		str = str[:i]
+	}
+	if i := strings.Index(str, "-"); i >= 0 {
+		v.PreRelease = str[i+1:]
+		str = str[:i]
+	}
+	parts := strings.Split(str, ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("%v is not a semantic version", s)
+	}
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("%v is not a semantic version", s)
+		}
+		*numbers[i] = n
+	}
+	return v, nil
+}
+
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v is lower, equal or greater than other.
+// A pre-release version is lower than its release, the pre-releases
+// are compared by their dot separated identifiers, e.g. `beta.2` < `beta.10`.
+func (v SemVer) Compare(other SemVer) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+	switch {
+	case v.PreRelease == other.PreRelease:
+		return 0
+	case v.PreRelease == "":
+		return 1
+	case other.PreRelease == "":
+		return -1
+	}
+	return comparePreReleases(v.PreRelease, other.PreRelease)
+}
+
+// comparePreReleases compares two pre-release versions identifier by identifier:
+// the numeric identifiers as numbers and lower than the alphanumeric ones, compared
+// as strings, and a version is lower than the versions it is a prefix of
+func comparePreReleases(a, b string) int {
+	ids, otherIDs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(ids) && i < len(otherIDs); i++ {
+		id, other := ids[i], otherIDs[i]
+		numeric, otherNumeric := isNumericID(id), isNumericID(other)
+		switch {
+		case numeric && otherNumeric && len(id) != len(other):
+			// without leading zeros, the longer number is the greater
+			return compareInts(len(id), len(other))
+		case numeric && !otherNumeric:
+			return -1
+		case !numeric && otherNumeric:
+			return 1
+		case id != other:
+			return strings.Compare(id, other)
+		}
+	}
+	return compareInts(len(ids), len(otherIDs))
+}
+
+// isNumericID returns true if a pre-release identifier is made of digits only
+func isNumericID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// compareInts returns -1, 0 or 1 if a is lower, equal or greater than b
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Bump returns the next version for changes of the given severity.
+// Breaking changes of a 0.y.z version bump its minor version.
+func (v SemVer) Bump(severity ChangeSeverity) SemVer {
+	switch {
+	case severity == MajorChange && v.Major > 0:
+		return SemVer{Major: v.Major + 1}
+	case severity == MajorChange || severity == MinorChange:
+		return SemVer{Major: v.Major, Minor: v.Minor + 1}
+	case severity == PatchChange:
+		return SemVer{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+	return v
+}
+
+// bumpSeverity returns the severity of the changes allowed by the bump from v to next.
+// Breaking changes are allowed by a minor bump of a 0.y.z version.
+func (v SemVer) bumpSeverity(next SemVer) ChangeSeverity {
+	switch {
+	case next.Compare(v) <= 0:
+		return 0
+	case next.Major > v.Major:
+		return MajorChange
+	case next.Minor > v.Minor && v.Major == 0:
+		return MajorChange
+	case next.Minor > v.Minor:
+		return MinorChange
+	}
+	return PatchChange
+}
+
+// SpecVersion returns the semantic version of the API definition,
+// from its SpecVersionAnnotation
+func (apiDef *APIDefinition) SpecVersion() (SemVer, error) {
+	v, ok := apiDef.Annotation(SpecVersionAnnotation)
+	if !ok {
+		return SemVer{}, fmt.Errorf("API %v has no (%v) annotation", apiDef.Title, SpecVersionAnnotation)
+	}
+	return ParseSemVer(fmt.Sprint(v))
+}
+
+// SetSpecVersion sets the SpecVersionAnnotation of the API definition.
+// If it is parsed with KeepRaw, the raw tree is updated too, so WriteRAML
+// writes the new version: after the `version` of the API if not annotated yet.
+func (apiDef *APIDefinition) SetSpecVersion(v SemVer) {
+	name := "(" + SpecVersionAnnotation + ")"
+	if apiDef.Annotations == nil {
+		apiDef.Annotations = map[string]interface{}{}
+	}
+	apiDef.Annotations[name] = v.String()
+
+	if apiDef.RawTree == nil {
+		return
+	}
+	insertAt := len(apiDef.RawTree)
+	for i, item := range apiDef.RawTree {
+		switch fmt.Sprint(item.Key) {
+		case name:
+			apiDef.RawTree[i].Value = v.String()
+			return
+		case "version":
+			insertAt = i + 1
+		}
+	}
+	tree := append(yaml.MapSlice{}, apiDef.RawTree[:insertAt]...)
+	tree = append(tree, yaml.MapItem{Key: name, Value: v.String()})
+	apiDef.RawTree = append(tree, apiDef.RawTree[insertAt:]...)
+}
+
+// CheckVersionBump compares two versions of an API definition and verifies
+// that the bump of their SpecVersion matches the most severe change, see
+// CompareAPIs: a major bump for breaking changes, at least a minor bump for
+// backward compatible changes and at least a patch bump for documentation changes.
+// It returns the changes, and an error listing the ones which need a greater bump.
+func CheckVersionBump(old, new *APIDefinition) ([]APIChange, error) {
+	oldVersion, err := old.SpecVersion()
+	if err != nil {
+		return nil, err
+	}
+	newVersion, err := new.SpecVersion()
+	if err != nil {
+		return nil, err
+	}
+	if newVersion.Compare(oldVersion) < 0 {
+		return nil, fmt.Errorf("version %v is lower than the previous version %v", newVersion, oldVersion)
+	}
+
+	changes := CompareAPIs(old, new)
+	allowed := oldVersion.bumpSeverity(newVersion)
+	var needed []string
+	for _, c := range changes {
+		if c.Severity > allowed {
+			needed = append(needed, c.String())
+		}
+	}
+	if len(needed) > 0 {
+		required := MaxSeverity(changes)
+		return changes, fmt.Errorf("version %v should be at least %v for the changes since %v:\n  %v",
+			newVersion, oldVersion.Bump(required), oldVersion, strings.Join(needed, "\n  "))
+	}
+	return changes, nil
+}
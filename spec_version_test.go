@@ -0,0 +1,85 @@
+package raml
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSpecVersion(t *testing.T) {
+	Convey("spec version", t, func() {
+		v1, v2 := new(APIDefinition), new(APIDefinition)
+		So(ParseFile("./samples/versions/v1.raml", v1), ShouldBeNil)
+		So(ParseFile("./samples/versions/v2.raml", v2), ShouldBeNil)
+
+		Convey("semantic versions", func() {
+			v, err := ParseSemVer("v2.0.1-beta.1+build.5")
+			So(err, ShouldBeNil)
+			So(v, ShouldResemble, SemVer{Major: 2, Patch: 1, PreRelease: "beta.1"})
+			So(v.String(), ShouldEqual, "2.0.1-beta.1")
+			So(v.Compare(SemVer{Major: 2, Patch: 1}), ShouldEqual, -1)
+			So(v.Compare(SemVer{Major: 1, Minor: 9}), ShouldEqual, 1)
+
+			// the pre-release identifiers in semver order
+			ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+				"1.0.0-beta.2", "1.0.0-beta.10", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0"}
+			for i := range ordered {
+				for j := range ordered {
+					a, _ := ParseSemVer(ordered[i])
+					b, _ := ParseSemVer(ordered[j])
+					So(a.Compare(b), ShouldEqual, compareInts(i, j))
+				}
+			}
+			So(v.Bump(MajorChange).String(), ShouldEqual, "3.0.0")
+			So(SemVer{Minor: 4}.Bump(MajorChange).String(), ShouldEqual, "0.5.0")
+
+			_, err = ParseSemVer("1.2")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("read and write the annotation", func() {
+			v, err := v1.SpecVersion()
+			So(err, ShouldBeNil)
+			So(v, ShouldResemble, SemVer{Major: 1, Minor: 2})
+
+			_, err = new(APIDefinition).SpecVersion()
+			So(err, ShouldNotBeNil)
+
+			apiDef := &APIDefinition{KeepRaw: true}
+			So(ParseFile("./samples/versions/v1.raml", apiDef), ShouldBeNil)
+			apiDef.SetSpecVersion(v.Bump(MinorChange))
+			var buf bytes.Buffer
+			So(apiDef.WriteRAML(&buf), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, "version: v1\n(specVersion): 1.3.0\n")
+		})
+
+		Convey("compare the versions", func() {
+			changes := CompareAPIs(v1, v2)
+			So(changes, ShouldResemble, []APIChange{
+				{MajorChange, "DELETE /books/{id}", "method removed"},
+				{PatchChange, "GET /books", "description changed"},
+				{MinorChange, "GET /books", "query parameter sort added"},
+				{MinorChange, "GET /books/{id}", "method added"},
+				{MinorChange, "type Book", "property author added"},
+			})
+			So(MaxSeverity(changes), ShouldEqual, MajorChange)
+		})
+
+		Convey("check the version bump", func() {
+			_, err := CheckVersionBump(v1, v2)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "version 1.3.0 should be at least 2.0.0")
+			So(err.Error(), ShouldContainSubstring, "DELETE /books/{id}: method removed (major)")
+
+			v2.SetSpecVersion(SemVer{Major: 2})
+			changes, err := CheckVersionBump(v1, v2)
+			So(err, ShouldBeNil)
+			So(changes, ShouldHaveLength, 5)
+
+			v2.SetSpecVersion(SemVer{Major: 1})
+			_, err = CheckVersionBump(v1, v2)
+			So(err.Error(), ShouldEqual, "version 1.0.0 is lower than the previous version 1.2.0")
+		})
+	})
+}
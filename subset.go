@@ -0,0 +1,382 @@
+package raml
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gigforks/yaml"
+)
+
+// Subset returns a copy of the API definition with the selected resources only,
+// e.g. to publish the part of an API meant for a partner. A path is the full URI
+// of a resource, e.g. `/users/{userId}`, the resource is kept with its methods and
+// its nested resources. Its parent resources are kept without their methods,
+// traits, resource type and security schemes, so the URIs don't change.
+//
+// The types, traits, resource types, security schemes and schemas are the ones
+// referenced by the selected resources and by the securedBy of the API, transitively,
+// e.g. the parent and the property types of a type. The libraries and the annotation
+// types are kept as they are.
+//
+// The methods and the declarations are shared with the API definition, they are not copied.
+// The RawTree of an API definition parsed with KeepRaw is filtered the same way,
+// so the subset could be written with WriteRAML.
+func (apiDef *APIDefinition) Subset(paths []string) (*APIDefinition, error) {
+	selected := map[string]bool{}
+	for _, p := range paths {
+		selected[normalizeSubsetPath(p)] = true
+	}
+
+	subset := *apiDef
+	subset.Resources = map[string]Resource{}
+	found := map[string]bool{}
+	for _, uri := range sortedKeys(apiDef.Resources) {
+		r := apiDef.Resources[uri]
+		if c := subsetResource(&r, nil, selected, found, false); c != nil {
+			subset.Resources[uri] = *c
+		}
+	}
+	errs := new(Error)
+	for _, p := range sortedKeys(selected) {
+		if !found[p] {
+			errs.add(fmt.Errorf("resource %v is not declared", p))
+		}
+	}
+	if err := errs.errOrNil(); err != nil {
+		return nil, err
+	}
+
+	refs := newSubsetRefs(apiDef)
+	refs.addSecuredBy(apiDef.SecuredBy)
+	refs.addParams(apiDef.BaseURIParameters)
+	subset.walkResources(refs.addResource)
+
+	subset.Types = subsetMap(apiDef.Types, refs.types)
+	subset.Traits = subsetMap(apiDef.Traits, refs.traits)
+	subset.ResourceTypes = subsetMap(apiDef.ResourceTypes, refs.resourceTypes)
+	subset.SecuritySchemes = subsetMap(apiDef.SecuritySchemes, refs.securitySchemes)
+	subset.Schemas = nil
+	for _, decl := range apiDef.Schemas {
+		if schemas := subsetMap(decl, refs.types); len(schemas) > 0 {
+			subset.Schemas = append(subset.Schemas, schemas)
+		}
+	}
+
+	if apiDef.RawTree != nil {
+		subset.RawTree = subsetRawTree(apiDef.RawTree, selected, refs)
+		text, err := yaml.Marshal(subset.RawTree)
+		if err != nil {
+			return nil, err
+		}
+		subset.Raw = new(APIDefinition)
+		if err := unmarshalYAML(text, subset.Raw); err != nil {
+			return nil, err
+		}
+	}
+	return &subset, nil
+}
+
+// normalizeSubsetPath returns the full URI of a resource as given by FullURI
+func normalizeSubsetPath(p string) string {
+	return path.Join("/", strings.TrimSpace(p))
+}
+
+// subsetResource returns a copy of a resource if it is selected, nested in a selected
+// resource or the parent of a selected resource, nil otherwise
+func subsetResource(r, parent *Resource, selected, found map[string]bool, inside bool) *Resource {
+	uri := r.FullURI()
+	if selected[uri] {
+		found[uri] = true
+		inside = true
+	}
+
+	c := *r
+	c.Parent = parent
+	c.Nested = nil
+	for _, key := range sortedResourceKeys(r.Nested) {
+		if n := subsetResource(r.Nested[key], &c, selected, found, inside); n != nil {
+			if c.Nested == nil {
+				c.Nested = map[string]*Resource{}
+			}
+			c.Nested[key] = n
+		}
+	}
+	if inside {
+		return &c
+	}
+	if c.Nested == nil {
+		return nil
+	}
+
+	// a parent of a selected resource
+	c.Get, c.Post, c.Put, c.Patch, c.Head, c.Delete, c.Options = nil, nil, nil, nil, nil, nil, nil
+	c.Methods, c.ExtraMethods = nil, nil
+	c.Is, c.Type, c.SecuredBy = nil, nil, nil
+	return &c
+}
+
+// subsetMap returns the values of a map whose keys are kept
+func subsetMap[V any](m map[string]V, keep map[string]bool) map[string]V {
+	if m == nil {
+		return nil
+	}
+	kept := map[string]V{}
+	for name, v := range m {
+		if keep[name] {
+			kept[name] = v
+		}
+	}
+	return kept
+}
+
+// subsetRefs collects the declarations referenced by the resources of a subset
+type subsetRefs struct {
+	apiDef *APIDefinition
+
+	// names of the types and schemas, traits, resource types and security schemes
+	types           map[string]bool
+	traits          map[string]bool
+	resourceTypes   map[string]bool
+	securitySchemes map[string]bool
+}
+
+func newSubsetRefs(apiDef *APIDefinition) *subsetRefs {
+	return &subsetRefs{
+		apiDef:          apiDef,
+		types:           map[string]bool{},
+		traits:          map[string]bool{},
+		resourceTypes:   map[string]bool{},
+		securitySchemes: map[string]bool{},
+	}
+}
+
+// addTypeExpr adds the types of a type expression and the types they depend on
+func (s *subsetRefs) addTypeExpr(expr string) {
+	for _, name := range typeExprRefs(expr) {
+		if s.types[name] {
+			continue
+		}
+		s.types[name] = true
+		if t, ok := s.apiDef.Types[name]; ok {
+			for _, dep := range t.dependencies() {
+				s.addTypeExpr(dep)
+			}
+		}
+	}
+}
+
+func (s *subsetRefs) addResource(r *Resource) {
+	if r.Type != nil && !s.resourceTypes[r.Type.Name] {
+		s.resourceTypes[r.Type.Name] = true
+		if rt, ok := s.apiDef.ResourceTypes[r.Type.Name]; ok {
+			s.addResourceType(rt)
+		}
+	}
+	s.addTraits(r.Is)
+	s.addSecuredBy(r.SecuredBy)
+	s.addParams(r.URIParameters)
+	for _, m := range r.methods() {
+		s.addMethod(m)
+	}
+	for _, name := range sortedKeys(r.ExtraMethods) {
+		s.addMethod(r.ExtraMethods[name])
+	}
+}
+
+func (s *subsetRefs) addResourceType(rt ResourceType) {
+	s.addTraits(rt.Is)
+	s.addParams(rt.URIParameters)
+	s.addParams(rt.BaseURIParameters)
+	for _, m := range append(append([]*Method{}, rt.methods...), rt.optionalMethods...) {
+		s.addMethod(m)
+	}
+}
+
+func (s *subsetRefs) addMethod(m *Method) {
+	s.addTraits(m.Is)
+	for _, name := range m.AppliedTraits {
+		s.addTrait(name)
+	}
+	s.addSecuredBy(m.SecuredBy)
+	s.addParams(m.QueryParameters)
+	s.addHeaders(m.Headers)
+	s.addBodies(m.Bodies)
+	s.addResponses(m.Responses)
+}
+
+func (s *subsetRefs) addTraits(is []DefinitionChoice) {
+	for _, dc := range is {
+		s.addTrait(dc.Name)
+	}
+}
+
+func (s *subsetRefs) addTrait(name string) {
+	if s.traits[name] {
+		return
+	}
+	s.traits[name] = true
+	t, ok := s.apiDef.Traits[name]
+	if !ok {
+		return
+	}
+	s.addParams(t.QueryParameters)
+	s.addHeaders(t.Headers)
+	s.addBodies(t.Bodies)
+	s.addResponses(t.Responses)
+}
+
+func (s *subsetRefs) addSecuredBy(securedBy []DefinitionChoice) {
+	for _, dc := range securedBy {
+		if dc.Name == "" || dc.Name == "null" || s.securitySchemes[dc.Name] {
+			continue
+		}
+		s.securitySchemes[dc.Name] = true
+		if ss, ok := s.apiDef.SecuritySchemes[dc.Name]; ok {
+			s.addParams(ss.DescribedBy.QueryParameters)
+			s.addParams(ss.DescribedBy.QueryString)
+			s.addHeaders(ss.DescribedBy.Headers)
+			s.addResponses(ss.DescribedBy.Responses)
+		}
+	}
+}
+
+func (s *subsetRefs) addParams(params map[string]NamedParameter) {
+	for _, np := range params {
+		s.addTypeExpr(np.Type)
+	}
+}
+
+func (s *subsetRefs) addHeaders(headers map[HTTPHeader]Header) {
+	for _, h := range headers {
+		s.addTypeExpr(h.Type)
+	}
+}
+
+func (s *subsetRefs) addResponses(responses map[HTTPCode]Response) {
+	for _, resp := range responses {
+		s.addHeaders(resp.Headers)
+		s.addBodies(resp.Bodies)
+	}
+}
+
+func (s *subsetRefs) addBodies(bodies Bodies) {
+	if bodies.Default != nil {
+		s.addBody(*bodies.Default)
+	}
+	for _, b := range bodies.ForMIMEType {
+		s.addBody(b)
+	}
+}
+
+// addBody adds the types of a body declared by name or type expression,
+// and of its inline properties
+func (s *subsetRefs) addBody(b Body) {
+	s.addTypeExpr(b.Schema)
+	if typ, ok := b.Type.(string); ok {
+		s.addTypeExpr(typ)
+	}
+	s.addTypeExpr(itemsTypeString(b.Items))
+	for _, p := range b.Properties {
+		if typ, ok := p.Type.(string); ok {
+			s.addTypeExpr(typ)
+		}
+		s.addTypeExpr(p.Items.Type)
+	}
+}
+
+// subsetRawTree returns the tree of the document with the selected resources
+// and the referenced declarations only
+func subsetRawTree(tree yaml.MapSlice, selected map[string]bool, refs *subsetRefs) yaml.MapSlice {
+	declarations := map[string]map[string]bool{
+		"types":           refs.types,
+		"schemas":         refs.types,
+		"traits":          refs.traits,
+		"resourceTypes":   refs.resourceTypes,
+		"securitySchemes": refs.securitySchemes,
+	}
+	var subset yaml.MapSlice
+	for _, item := range tree {
+		key := fmt.Sprint(item.Key)
+		switch {
+		case strings.HasPrefix(key, "/"):
+			if r, ok := subsetRawResource(item.Value, path.Join("/", key), selected, false); ok {
+				subset = append(subset, yaml.MapItem{Key: item.Key, Value: r})
+			}
+		case declarations[key] != nil:
+			if kept := subsetRawDeclarations(item.Value, declarations[key]); !isEmptyNode(kept) {
+				subset = append(subset, yaml.MapItem{Key: item.Key, Value: kept})
+			}
+		default:
+			subset = append(subset, item)
+		}
+	}
+	return subset
+}
+
+// isEmptyNode returns true if a node is an empty map or sequence
+func isEmptyNode(node interface{}) bool {
+	switch n := node.(type) {
+	case yaml.MapSlice:
+		return len(n) == 0
+	case []interface{}:
+		return len(n) == 0
+	}
+	return node == nil
+}
+
+// subsetRawDeclarations returns the declarations which are kept,
+// declared as a map or, for the schemas, a sequence of maps
+func subsetRawDeclarations(node interface{}, keep map[string]bool) interface{} {
+	switch decls := node.(type) {
+	case yaml.MapSlice:
+		var kept yaml.MapSlice
+		for _, item := range decls {
+			if keep[fmt.Sprint(item.Key)] {
+				kept = append(kept, item)
+			}
+		}
+		return kept
+	case []interface{}:
+		var kept []interface{}
+		for _, decl := range decls {
+			if m, ok := subsetRawDeclarations(decl, keep).(yaml.MapSlice); ok && len(m) > 0 {
+				kept = append(kept, m)
+			}
+		}
+		return kept
+	}
+	return node
+}
+
+// subsetRawResource returns the tree of a resource, see subsetResource
+func subsetRawResource(node interface{}, uri string, selected map[string]bool, inside bool) (interface{}, bool) {
+	inside = inside || selected[uri]
+	r, ok := node.(yaml.MapSlice)
+	if !ok {
+		return node, inside
+	}
+
+	var kept yaml.MapSlice
+	hasNested := false
+	for _, item := range r {
+		key := fmt.Sprint(item.Key)
+		switch {
+		case strings.HasPrefix(key, "/"):
+			if n, ok := subsetRawResource(item.Value, path.Join(uri, key), selected, inside); ok {
+				kept = append(kept, yaml.MapItem{Key: item.Key, Value: n})
+				hasNested = true
+			}
+		case inside || isAnnotationKey(key) || ancestorKeys[key]:
+			kept = append(kept, item)
+		}
+	}
+	return kept, inside || hasNested
+}
+
+// ancestorKeys are the keys of a resource kept for the parents of the selected resources
+var ancestorKeys = map[string]bool{
+	"displayName":   true,
+	"description":   true,
+	"uriParameters": true,
+}
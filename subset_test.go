@@ -0,0 +1,73 @@
+package raml
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSubset(t *testing.T) {
+	apiDef := &APIDefinition{KeepRaw: true}
+	err := ParseFile("./samples/subset/api.raml", apiDef)
+	Convey("subset of the resources", t, func() {
+		So(err, ShouldBeNil)
+
+		Convey("a nested resource and its parents", func() {
+			subset, err := apiDef.Subset([]string{"/users/{userId}"})
+			So(err, ShouldBeNil)
+			So(subset.Resources, ShouldHaveLength, 1)
+
+			users := subset.Resources["/users"]
+			So(users.Description, ShouldEqual, "all the users")
+			So(users.Get, ShouldBeNil)
+			So(users.Type, ShouldBeNil)
+			So(users.Nested, ShouldHaveLength, 1)
+			user := users.Nested["/{userId}"]
+			So(user.FullURI(), ShouldEqual, "/users/{userId}")
+			So(user.Get, ShouldNotBeNil)
+			So(user.Nested, ShouldContainKey, "/invoices")
+
+			So(sortedKeys(subset.Types), ShouldResemble, []string{"Address", "Admin", "ApiKey", "Error", "Invoice", "User"})
+			So(sortedKeys(subset.Traits), ShouldResemble, []string{"failing"})
+			So(subset.ResourceTypes, ShouldBeEmpty)
+			So(sortedKeys(subset.SecuritySchemes), ShouldResemble, []string{"apiKey", "oauth"})
+		})
+
+		Convey("the API definition is not changed", func() {
+			_, err := apiDef.Subset([]string{"/invoices"})
+			So(err, ShouldBeNil)
+			So(apiDef.Resources, ShouldHaveLength, 2)
+			So(apiDef.Resources["/users"].Get, ShouldNotBeNil)
+			So(apiDef.Types, ShouldHaveLength, 6)
+		})
+
+		Convey("resource types and their traits", func() {
+			subset, err := apiDef.Subset([]string{"/users/"})
+			So(err, ShouldBeNil)
+			So(sortedKeys(subset.ResourceTypes), ShouldResemble, []string{"collection"})
+			So(subset.Traits, ShouldContainKey, "paged")
+		})
+
+		Convey("the raw tree is filtered", func() {
+			subset, err := apiDef.Subset([]string{"/users/{userId}/invoices"})
+			So(err, ShouldBeNil)
+			So(subset.Raw.Resources, ShouldContainKey, "/users")
+			So(subset.Raw.Types, ShouldHaveLength, 2)
+
+			var buf bytes.Buffer
+			So(subset.WriteRAML(&buf), ShouldBeNil)
+			expected, err := ioutil.ReadFile("./samples/subset/partner.raml")
+			So(err, ShouldBeNil)
+			So(buf.String(), ShouldEqual, string(expected))
+			So(ParseBytes(buf.Bytes(), new(APIDefinition), WithUnknownKeyErrors()), ShouldBeNil)
+		})
+
+		Convey("unknown resource", func() {
+			_, err := apiDef.Subset([]string{"/users/{userId}", "/groups"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "resource /groups is not declared")
+		})
+	})
+}
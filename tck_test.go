@@ -0,0 +1,161 @@
+package raml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// The RAML test compatibility kit (https://github.com/raml-org/raml-tck)
+// is not vendored in this repository.
+// To run the conformance tests, clone it and point RAML_TCK_DIR to the
+// directory containing the RAML 1.0 tests, e.g.:
+//
+//	RAML_TCK_DIR=../raml-tck/tests/raml-1.0 go test -run TestTCK -v
+//
+// The per feature area report is logged and optionally written to
+// the file specified by RAML_TCK_REPORT.
+
+// tckArea is the result of all tck tests of a feature area
+type tckArea struct {
+	Name   string
+	Passed int
+	Failed []string
+}
+
+// tckResult is the expected result of a tck test,
+// as written in the '<name>-tck.json' file
+type tckResult struct {
+	Errors []interface{} `json:"errors"`
+}
+
+func TestTCK(t *testing.T) {
+	dir := os.Getenv("RAML_TCK_DIR")
+	if dir == "" {
+		t.Skip("RAML_TCK_DIR is not set")
+	}
+
+	areas, err := runTCK(dir)
+	if err != nil {
+		t.Fatalf("failed to run tck: %v", err)
+	}
+
+	report := tckReport(areas)
+	t.Log("\n" + report)
+
+	if reportFile := os.Getenv("RAML_TCK_REPORT"); reportFile != "" {
+		if err := ioutil.WriteFile(reportFile, []byte(report), 0644); err != nil {
+			t.Fatalf("failed to write tck report: %v", err)
+		}
+	}
+}
+
+// runTCK parses all root RAML documents in the tck directory
+// and groups the results by feature area.
+// The feature area is the first directory below the tck directory.
+func runTCK(dir string) ([]*tckArea, error) {
+	areas := map[string]*tckArea{}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".raml" {
+			return nil
+		}
+
+		// only root documents are tested, fragments are included by them
+		isRoot, err := isRootRAML(path)
+		if err != nil || !isRoot {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		areaName := strings.Split(filepath.ToSlash(rel), "/")[0]
+		area, ok := areas[areaName]
+		if !ok {
+			area = &tckArea{Name: areaName}
+			areas[areaName] = area
+		}
+
+		if passTCK(path) {
+			area.Passed++
+		} else {
+			area.Failed = append(area.Failed, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*tckArea
+	for _, area := range areas {
+		results = append(results, area)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results, nil
+}
+
+// passTCK returns true if the parser result of the file
+// matches the tck expectation
+func passTCK(path string) bool {
+	err := ParseFile(path, new(APIDefinition))
+	return (err != nil) == tckExpectInvalid(path)
+}
+
+// tckExpectInvalid returns true if the tck expects
+// the RAML file to be rejected
+func tckExpectInvalid(path string) bool {
+	tckFile := strings.TrimSuffix(path, ".raml") + "-tck.json"
+	b, err := ioutil.ReadFile(tckFile)
+	if err != nil {
+		return strings.Contains(strings.ToLower(filepath.Base(path)), "invalid")
+	}
+
+	var res tckResult
+	if err := json.Unmarshal(b, &res); err != nil {
+		return false
+	}
+	return len(res.Errors) > 0
+}
+
+// isRootRAML returns true if the file is a RAML 1.0 root document
+func isRootRAML(path string) (bool, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	firstLine := string(bytes.SplitN(b, []byte("\n"), 2)[0])
+	return strings.TrimSpace(firstLine) == "#%RAML 1.0", nil
+}
+
+// tckReport creates human readable report of the tck results
+func tckReport(areas []*tckArea) string {
+	var buf bytes.Buffer
+	var passed, total int
+
+	for _, area := range areas {
+		areaTotal := area.Passed + len(area.Failed)
+		passed += area.Passed
+		total += areaTotal
+
+		fmt.Fprintf(&buf, "%-40s %4d/%-4d\n", area.Name, area.Passed, areaTotal)
+		for _, f := range area.Failed {
+			fmt.Fprintf(&buf, "    FAIL %s\n", f)
+		}
+	}
+	fmt.Fprintf(&buf, "%-40s %4d/%-4d\n", "TOTAL", passed, total)
+	return buf.String()
+}
@@ -1,6 +1,8 @@
 package raml
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -11,10 +13,24 @@ import (
 type Trait struct {
 	Name string
 
+	// The libraries the trait is resolved through by APIDefinition.TraitByName,
+	// empty for the traits of the root document.
+	LibraryChain LibraryChain `yaml:"-"`
+
+	// Where the trait is declared in the original files.
+	Location SourceLocation `yaml:"-"`
+
 	// The usage property of a resource type or trait is used to describe how
 	// the resource type or trait should be used
 	Usage string
 
+	// The libraries used by a trait fragment, relative to the fragment file.
+	// They are added to the libraries of the document including the fragment.
+	Uses map[string]string `yaml:"uses"`
+
+	// An alternate, human-friendly name of the method the trait is applied to
+	DisplayName string `yaml:"displayName"`
+
 	// Briefly describes what the method does to the resource
 	Description string
 
@@ -50,8 +66,85 @@ type Trait struct {
 	OptionalQueryParameters map[string]NamedParameter `yaml:"queryParameters?"`
 }
 
-func (t *Trait) postProcess(name string) {
+// TraitTextMerge controls how the display name and description of a trait
+// are merged into the methods the trait is applied to
+type TraitTextMerge int
+
+const (
+	// KeepMethodText keeps the text of the method, the text of the trait
+	// is only used by the methods without it. It is the default.
+	KeepMethodText TraitTextMerge = iota
+
+	// OverwriteMethodText replaces the text of the method by the text of the trait
+	OverwriteMethodText
+
+	// AppendToMethodText appends the text of the trait to the text of the method
+	AppendToMethodText
+)
+
+// merge merges the text of a trait into the text of a method,
+// sep separates the texts when the trait text is appended
+func (mode TraitTextMerge) merge(methodText, traitText, sep string, dicts map[string]interface{}) string {
+	switch {
+	case traitText == "":
+		return methodText
+	case mode == OverwriteMethodText:
+		return substituteParams("", traitText, dicts)
+	case mode == AppendToMethodText && methodText != "":
+		return methodText + sep + substituteParams("", traitText, dicts)
+	}
+	return substituteParams(methodText, traitText, dicts)
+}
+
+// TraitOrder controls the precedence of the traits applied to a method by the method
+// itself and by its resource. The properties of the method have the highest precedence,
+// then a trait has precedence over the traits after it. A trait applied twice with the same
+// parameters is applied once, at its highest precedence. The traits of the resource type have the lowest precedence.
+type TraitOrder int
+
+const (
+	// MethodTraitsFirst gives precedence to the traits of the method over the traits
+	// of the resource, as the most specific declaration. Each list keeps its order.
+	// It is the default.
+	MethodTraitsFirst TraitOrder = iota
+
+	// ResourceTraitsFirst gives precedence to the traits of the resource over
+	// the traits of the method, as the previous versions of the parser did.
+	ResourceTraitsFirst
+)
+
+// apply returns the traits in the order they are applied, from the highest precedence
+func (order TraitOrder) apply(resourceIs, methodIs []DefinitionChoice) []DefinitionChoice {
+	first, second := methodIs, resourceIs
+	if order == ResourceTraitsFirst {
+		first, second = resourceIs, methodIs
+	}
+	var traits []DefinitionChoice
+	for _, t := range append(append([]DefinitionChoice{}, first...), second...) {
+		if !isApplied(traits, t) {
+			traits = append(traits, t)
+		}
+	}
+	return traits
+}
+
+// isApplied checks if a trait is in the applied traits with the same parameters,
+// e.g. `paged: { size: 10 }` and `paged: { size: 50 }` are both applied
+func isApplied(applied []DefinitionChoice, t DefinitionChoice) bool {
+	for _, a := range applied {
+		if a.Name == t.Name && reflect.DeepEqual(a.Parameters, t.Parameters) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Trait) postProcess(name string) error {
 	t.Name = name
+	if err := validateResponseCodes(t.Responses, t.OptionalResponses); err != nil {
+		return fmt.Errorf("trait %v: %v", name, err)
+	}
+	return nil
 }
 
 // init trait dicts
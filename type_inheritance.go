@@ -0,0 +1,357 @@
+package raml
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gigforks/yaml"
+)
+
+// Ancestors returns the resolved parent types of this type.
+// The parents of a library type are first looked for in its library, see parentType.
+// The parents of a type with multiple inheritance are walked in declaration order,
+// each parent followed by its own ancestors, and a type inherited through several
+// paths is only returned once.
+// Parents which are not types of the API definition, e.g. builtin types,
+// are not returned.
+func (t Type) Ancestors(apiDef *APIDefinition) []Type {
+	var ancestors []Type
+	visited := map[string]bool{t.Name: true}
+
+	var walk func(t Type)
+	walk = func(t Type) {
+		for _, name := range t.Parents() {
+			name = strings.TrimSpace(name)
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+			parent, ok := apiDef.parentType(t, name)
+			if !ok {
+				continue
+			}
+			ancestors = append(ancestors, *parent)
+			walk(*parent)
+		}
+	}
+	walk(t)
+	return ancestors
+}
+
+// parentType returns a parent of a type by the name written in its declaration.
+// A library type is declared with the names of its library, e.g. `Entity` or
+// `common.Id` for the types of the library or of the libraries it uses,
+// so they are first qualified by its LibraryChain, then looked up by TypeByName.
+func (apiDef *APIDefinition) parentType(t Type, name string) (*Type, bool) {
+	name = strings.TrimSpace(name)
+	if len(t.LibraryChain) > 0 {
+		var qualified []string
+		for _, lib := range t.LibraryChain {
+			qualified = append(qualified, lib.Name)
+		}
+		if parent, ok := apiDef.TypeByName(strings.Join(append(qualified, name), ".")); ok {
+			return parent, true
+		}
+	}
+	return apiDef.TypeByName(name)
+}
+
+// Resolve returns the effective type of this type, once its inheritance is flattened,
+// for the code generators and the validators which don't walk the parents themselves:
+//   - Type is the builtin type or the type expression it extends, e.g. `object`, `string` or `User[]`
+//   - Properties are the ones of AllProperties, RawProperties too
+//   - the facets which are not declared are the ones of the nearest ancestor declaring them,
+//     in the order of Ancestors, e.g. the pattern of a string or the discriminator of an object
+//   - the annotations and the values of the custom facets are merged, the ones of the type
+//     override the inherited ones, and Facets are the ones of AllFacets
+//   - the examples are the ones of the type, or of the nearest ancestor if it has none
+//
+// The name, display name, description, discriminator value and location
+// are the ones of the type. A type declared by a JSON schema is returned as is.
+func (t Type) Resolve(apiDef *APIDefinition) Type {
+	if t.IsJSONType() {
+		return t
+	}
+	ancestors := t.Ancestors(apiDef)
+	resolved := t
+	resolved._apiDef = apiDef
+	resolved.Type = baseType(apiDef, t, ancestors)
+
+	resolved.Properties = nil
+	resolved.propertyOrder = nil
+	for _, p := range t.AllProperties(apiDef) {
+		if resolved.Properties == nil {
+			resolved.Properties = map[string]Property{}
+		}
+		p._type = nil
+		resolved.Properties[p.Name] = p
+		resolved.propertyOrder = append(resolved.propertyOrder, p.Name)
+	}
+	resolved.RawProperties = copyMap(t.RawProperties)
+	resolved.Annotations = copyMap(t.Annotations)
+	resolved.Facets = t.AllFacets(apiDef)
+	if len(resolved.Facets) == 0 {
+		resolved.Facets = nil
+	}
+	resolved.FacetValues = copyMap(t.FacetValues)
+	hasExamples := t.Example != nil || len(t.Examples) > 0
+
+	for _, parent := range ancestors {
+		for name, p := range parent.RawProperties {
+			if _, ok := resolved.RawProperties[name]; !ok && resolved.Properties[name].Name != "" {
+				if resolved.RawProperties == nil {
+					resolved.RawProperties = map[string]interface{}{}
+				}
+				resolved.RawProperties[name] = p
+			}
+		}
+		for name, v := range parent.Annotations {
+			if _, ok := resolved.Annotations[name]; !ok {
+				if resolved.Annotations == nil {
+					resolved.Annotations = map[string]interface{}{}
+				}
+				resolved.Annotations[name] = v
+			}
+		}
+		for name, v := range parent.FacetValues {
+			if _, ok := resolved.FacetValues[name]; !ok {
+				if resolved.FacetValues == nil {
+					resolved.FacetValues = map[string]interface{}{}
+				}
+				resolved.FacetValues[name] = v
+			}
+		}
+		if !hasExamples && (parent.Example != nil || len(parent.Examples) > 0) {
+			resolved.Example, resolved.Examples = parent.Example, parent.Examples
+			hasExamples = true
+		}
+		resolved.inheritFacets(parent)
+	}
+	return resolved
+}
+
+// inheritFacets sets the facets of a resolved type which are not declared,
+// to the ones of a parent
+func (t *Type) inheritFacets(parent Type) {
+	if t.Default == nil {
+		t.Default = parent.Default
+	}
+	if t.Schema == nil {
+		t.Schema = parent.Schema
+	}
+	if t.MinProperties == 0 {
+		t.MinProperties = parent.MinProperties
+	}
+	if t.MaxProperties == 0 {
+		t.MaxProperties = parent.MaxProperties
+	}
+	if t.AdditionalProperties == "" {
+		t.AdditionalProperties = parent.AdditionalProperties
+	}
+	if t.Discriminator == "" {
+		t.Discriminator = parent.Discriminator
+	}
+	if t.Items == nil {
+		t.Items = parent.Items
+	}
+	if t.MinItems == 0 {
+		t.MinItems = parent.MinItems
+	}
+	if t.MaxItems == 0 {
+		t.MaxItems = parent.MaxItems
+	}
+	t.UniqueItems = t.UniqueItems || parent.UniqueItems
+	if t.Enum == nil {
+		t.Enum = parent.Enum
+	}
+	if t.Pattern == "" {
+		t.Pattern = parent.Pattern
+	}
+	if t.MinLength == 0 {
+		t.MinLength = parent.MinLength
+	}
+	if t.MaxLength == 0 {
+		t.MaxLength = parent.MaxLength
+	}
+	if t.Minimum == 0 {
+		t.Minimum = parent.Minimum
+	}
+	if t.Maximum == 0 {
+		t.Maximum = parent.Maximum
+	}
+	if t.Format == "" {
+		t.Format = parent.Format
+	}
+	if t.MultipleOf == 0 {
+		t.MultipleOf = parent.MultipleOf
+	}
+	if t.FileTypes == "" {
+		t.FileTypes = parent.FileTypes
+	}
+	if t.XML == nil {
+		t.XML = parent.XML
+	}
+}
+
+// baseType returns the builtin type or type expression a type extends: the one of the first
+// of the type and its ancestors which doesn't extend a declared type,
+// `object` for the types with properties and no type, `string` otherwise
+func baseType(apiDef *APIDefinition, t Type, ancestors []Type) interface{} {
+	for _, x := range append([]Type{t}, ancestors...) {
+		extends := false
+		for _, name := range x.Parents() {
+			if _, ok := apiDef.parentType(x, name); ok {
+				extends = true
+				break
+			}
+		}
+		if extends {
+			continue
+		}
+		if x.Type != nil && x.TypeString() != "" {
+			return x.Type
+		}
+		break
+	}
+	if len(t.RawProperties) > 0 || len(t.Properties) > 0 || len(ancestors) > 0 {
+		return "object"
+	}
+	return "string"
+}
+
+// copyMap returns a copy of a map, nil if it is nil
+func copyMap[V any](m map[string]V) map[string]V {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// typeDeclaration is decoded by Type.UnmarshalYAML
+type typeDeclaration Type
+
+// UnmarshalYAML decodes the type, records the declaration order of its properties
+// and parses them. A type could be declared by its type expression or schema only,
+// e.g. `Users: User[]` or an included JSON schema.
+func (t *Type) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var expr string
+	if err := unmarshal(&expr); err == nil {
+		*t = Type{Type: expr}
+		return nil
+	}
+
+	var decl typeDeclaration
+	if err := unmarshal(&decl); err != nil {
+		return err
+	}
+	*t = Type(decl)
+
+	var order struct {
+		Properties yaml.MapSlice `yaml:"properties"`
+	}
+	if err := unmarshal(&order); err == nil {
+		for _, item := range order.Properties {
+			t.propertyOrder = append(t.propertyOrder, strings.TrimSuffix(fmt.Sprint(item.Key), "?"))
+		}
+	}
+	t.parseProperties()
+	t.Facets = parseProperties(t.RawFacets)
+
+	var keys map[string]interface{}
+	if err := unmarshal(&keys); err == nil {
+		t.FacetValues = facetValues(keys, typeKeys)
+	}
+	return nil
+}
+
+// AllProperties returns own and inherited properties of this type.
+// Inherited properties come first, in the order of the parents,
+// followed by the own properties in declaration order.
+// A property redeclared by a type overrides the inherited one,
+// at the position of the inherited property.
+func (t Type) AllProperties(apiDef *APIDefinition) []Property {
+	return t.allProperties(apiDef, map[string]bool{})
+}
+
+func (t Type) allProperties(apiDef *APIDefinition, visited map[string]bool) []Property {
+	visited[t.Name] = true
+
+	var props []Property
+	index := map[string]int{}
+	add := func(p Property) {
+		if i, ok := index[p.Name]; ok {
+			props[i] = p
+			return
+		}
+		index[p.Name] = len(props)
+		props = append(props, p)
+	}
+
+	for _, name := range t.Parents() {
+		name = strings.TrimSpace(name)
+		if visited[name] {
+			continue
+		}
+		if parent, ok := apiDef.parentType(t, name); ok {
+			for _, p := range parent.allProperties(apiDef, visited) {
+				add(p)
+			}
+		}
+	}
+	for _, name := range t.propertyNames() {
+		add(t.GetProperty(name))
+	}
+	return props
+}
+
+// propertyNames returns names of the own properties, in declaration order.
+// The order of the properties of types synthesized from inline
+// declarations is not known, they are sorted by name.
+func (t Type) propertyNames() []string {
+	var names []string
+	for name := range t.Properties {
+		names = append(names, name)
+	}
+	return declarationOrder(t.propertyOrder, names)
+}
+
+// RequiredProperties returns the required properties of this type,
+// including the inherited ones, in the order of AllProperties.
+// A property is optional if its name has the `?` suffix
+// or its `required` facet is false.
+func (t Type) RequiredProperties() []Property {
+	return t.filterProperties(true)
+}
+
+// OptionalProperties returns the optional properties of this type,
+// including the inherited ones, in the order of AllProperties.
+func (t Type) OptionalProperties() []Property {
+	return t.filterProperties(false)
+}
+
+func (t Type) filterProperties(required bool) []Property {
+	var props []Property
+	for _, p := range t.mergedProperties() {
+		if p.Required == required {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// mergedProperties returns own and inherited properties if the type
+// is post processed as part of an API definition, only the own properties otherwise
+func (t Type) mergedProperties() []Property {
+	if t._apiDef != nil {
+		return t.AllProperties(t._apiDef)
+	}
+	var props []Property
+	for _, name := range t.propertyNames() {
+		props = append(props, t.GetProperty(name))
+	}
+	return props
+}
@@ -0,0 +1,94 @@
+package raml
+
+import (
+	"sort"
+	"strings"
+)
+
+// TypesInDependencyOrder returns the types of this document sorted by reference:
+// the parents, property types and item types of a type come before the type.
+// Types which don't depend on each other are sorted by name, so the order is deterministic.
+// Recursive types are allowed by RAML, a cycle is broken at the type
+// of the cycle which comes first by name.
+func (apiDef *APIDefinition) TypesInDependencyOrder() []Type {
+	names := make([]string, 0, len(apiDef.Types))
+	for name := range apiDef.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		sorted  []Type
+		visited = map[string]bool{}
+		visit   func(name string)
+	)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		t := apiDef.Types[name]
+		for _, dep := range t.dependencies() {
+			if _, ok := apiDef.Types[dep]; ok {
+				visit(dep)
+			}
+		}
+		sorted = append(sorted, t)
+	}
+	for _, name := range names {
+		visit(name)
+	}
+	return sorted
+}
+
+// dependencies returns names of the types referenced by this type,
+// sorted by name. Builtin types are not included.
+func (t Type) dependencies() []string {
+	var deps []string
+	add := func(expr string) {
+		for _, name := range typeExprRefs(expr) {
+			if name != t.Name {
+				deps = appendStrNotExist(name, deps)
+			}
+		}
+	}
+
+	if !t.IsJSONType() {
+		add(t.TypeString())
+	}
+	add(itemsTypeString(t.Items))
+	for _, prop := range t.Properties {
+		prop._type = &t
+		add(prop.TypeString())
+		if prop.Items.Type != "" {
+			add(prop.Items.Type)
+		}
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// typeExprRefs returns the names of the types referenced by a type expression,
+// e.g. `(Cat | Dog)[]` or `[Animal, Pet]`, builtin types are not included.
+func typeExprRefs(expr string) []string {
+	var refs []string
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "{") || strings.HasPrefix(expr, "<") {
+		// JSON or XML schema
+		return nil
+	}
+	fields := strings.FieldsFunc(expr, func(r rune) bool {
+		return strings.ContainsRune("[](),|? ", r)
+	})
+	for _, name := range fields {
+		if _, ok := scalarTypes[name]; ok {
+			continue
+		}
+		switch name {
+		case "object", "array", "any", "nil", "union":
+			continue
+		}
+		refs = appendStrNotExist(name, refs)
+	}
+	return refs
+}
@@ -0,0 +1,163 @@
+package raml
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gigforks/yaml"
+)
+
+// TypeNamingStrategy names a type synthesized from an inline type declaration,
+// from its owner, the name of the declaring type or the full URI of the resource,
+// and the path of the declaration in the owner, e.g. `Action` and `recurring`
+// for the property `recurring` of the type `Action`
+type TypeNamingStrategy func(owner string, path ...string) string
+
+// ConcatTypeNames concatenates the owner and the path as they are written,
+// e.g. `Actionrecurring`. It is the default.
+func ConcatTypeNames(owner string, path ...string) string {
+	return owner + strings.Join(path, "")
+}
+
+// CamelCaseTypeNames joins the words of the owner and of the path in upper camel case,
+// e.g. `ActionRecurring`, or `UsersUserIdGetBody` for the owner `/users/{userId}` and the
+// path `get` and `body`, instead of `/users/{userId}getbody` with ConcatTypeNames
+func CamelCaseTypeNames(owner string, path ...string) string {
+	var b strings.Builder
+	for _, s := range append([]string{owner}, path...) {
+		words := strings.FieldsFunc(s, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, w := range words {
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	return b.String()
+}
+
+// TypeCollisionPolicy controls what RegisterType does when
+// a type with the name of the synthesized type is already declared
+type TypeCollisionPolicy int
+
+const (
+	// ReuseExistingType uses the declared type instead of the synthesized one,
+	// with a warning, as the types could be unrelated. It is the default.
+	ReuseExistingType TypeCollisionPolicy = iota
+
+	// SuffixTypeName suffixes the name of the synthesized type by the first number
+	// from 2 which makes it unique, e.g. `ActionRecurring2`
+	SuffixTypeName
+
+	// FailOnTypeCollision reports the collision as an error
+	FailOnTypeCollision
+)
+
+// RegisterType adds a type synthesized from an inline type declaration,
+// named by the TypeNaming strategy from its owner and its path, see TypeNamingStrategy,
+// e.g. `UsersUserIdGetBody` for `/users/{userId}`, `get` and `body` if TypeNaming is
+// CamelCaseTypeNames. The type is post-processed like the declared types. It returns
+// the name of the type, which is the declared type on a collision with ReuseExistingType,
+// reported as a warning of the API definition.
+// The types of the inline properties of the declared types are registered the same way.
+func (apiDef *APIDefinition) RegisterType(t Type, owner string, path ...string) (string, error) {
+	naming := apiDef.TypeNaming
+	if naming == nil {
+		naming = ConcatTypeNames
+	}
+	name := naming(owner, path...)
+	if name == "" {
+		return "", fmt.Errorf("can't name the type of %v", strings.Join(append([]string{owner}, path...), " "))
+	}
+
+	if _, exist := apiDef.Types[name]; exist {
+		switch apiDef.TypeCollision {
+		case ReuseExistingType:
+			apiDef.warn(strings.Join(append([]string{owner}, path...), " "),
+				"type %v is already declared, it is used instead of the inline type", name)
+			return name, nil
+		case FailOnTypeCollision:
+			return "", fmt.Errorf("can't register the type %v, a type is already declared with this name", name)
+		case SuffixTypeName:
+			base := name
+			for i := 2; exist; i++ {
+				name = fmt.Sprintf("%v%d", base, i)
+				_, exist = apiDef.Types[name]
+			}
+		}
+	}
+
+	if apiDef.Types == nil {
+		apiDef.Types = map[string]Type{}
+	}
+	t.Name = name
+	apiDef.Types[name] = t
+	err := t.postProcess(name, apiDef)
+	apiDef.Types[name] = t
+	return name, err
+}
+
+// registerInlineTypes registers the types declared inline by a property declaration,
+// the type of the property when it has its own properties and the type of the items of
+// an array property, nested inline types included. The types keep all the facets of their
+// declaration. It returns a copy of the declaration referring to the registered types by name.
+func registerInlineTypes(decl map[interface{}]interface{}, apiDef *APIDefinition,
+	owner string, path ...string) (map[interface{}]interface{}, error) {
+	if props, ok := decl["properties"].(map[interface{}]interface{}); ok {
+		name, err := registerInlineType(decl, props, apiDef, owner, path...)
+		if err != nil {
+			return decl, err
+		}
+		decl = copyDeclaration(decl, "properties")
+		decl["type"] = name
+		return decl, nil
+	}
+
+	items, ok := decl["items"].(map[interface{}]interface{})
+	if !ok || decl["type"] != "array" {
+		return decl, nil
+	}
+	itemsDecl, err := registerInlineTypes(items, apiDef, owner, append(path, "Item")...)
+	if err != nil {
+		return decl, err
+	}
+	decl = copyDeclaration(decl, "")
+	decl["items"] = itemsDecl
+	return decl, nil
+}
+
+// registerInlineType registers the type of an inline type declaration with properties
+func registerInlineType(decl, props map[interface{}]interface{}, apiDef *APIDefinition,
+	owner string, path ...string) (string, error) {
+	data, err := yaml.Marshal(copyDeclaration(decl, "properties"))
+	if err != nil {
+		return "", err
+	}
+	var t Type
+	if err := unmarshalYAML(data, &t); err != nil {
+		return "", err
+	}
+	t.RawProperties = rawProperties(props)
+	return apiDef.RegisterType(t, owner, path...)
+}
+
+// copyDeclaration copies an inline declaration without the given key, so the declarations
+// shared by the traits and resource types are not modified
+func copyDeclaration(decl map[interface{}]interface{}, without string) map[interface{}]interface{} {
+	c := make(map[interface{}]interface{}, len(decl))
+	for k, v := range decl {
+		if k != without {
+			c[k] = v
+		}
+	}
+	return c
+}
+
+// rawProperties converts the properties of an inline type declaration
+func rawProperties(props map[interface{}]interface{}) map[string]interface{} {
+	raw := make(map[string]interface{}, len(props))
+	for k, p := range props {
+		raw[fmt.Sprint(k)] = p
+	}
+	return raw
+}
@@ -0,0 +1,64 @@
+package raml
+
+import (
+	"strings"
+)
+
+// GetType gets a type by its name, declared in this document or in the libraries it uses,
+// directly or through other libraries, e.g. `Foo`, `lib.Foo` or `libA.libB.Bar`.
+// It is TypeByName, see there how the names are resolved.
+func (apiDef *APIDefinition) GetType(name string) (*Type, bool) {
+	return apiDef.TypeByName(name)
+}
+
+// AllTypes returns the types of the API definition and of its libraries, transitively.
+// The types of the libraries are keyed by their qualified name, e.g. `libA.libB.Bar`,
+// and have their LibraryChain, so every key resolves with GetType to its type.
+// A library used through several chains has its types under every chain.
+func (apiDef *APIDefinition) AllTypes() map[string]Type {
+	all := make(map[string]Type, len(apiDef.Types))
+	for name, t := range apiDef.Types {
+		all[name] = t
+	}
+	addLibraryTypes(all, apiDef.Libraries, nil)
+	return all
+}
+
+// addLibraryTypes adds the types of the libraries and of their libraries,
+// qualified by the chain of libraries to them. A library already in the chain is skipped.
+func addLibraryTypes(all map[string]Type, libraries map[string]*Library, chain LibraryChain) {
+	for _, libName := range sortedKeys(libraries) {
+		l := libraries[libName]
+		if l == nil || chain.contains(l.Filename) {
+			continue
+		}
+		libChain := append(append(LibraryChain{}, chain...), LibraryRef{Name: libName, Filename: l.Filename})
+		for name := range l.Types {
+			t, _ := libraryType(l, name)
+			t.LibraryChain = libChain
+			all[t.QualifiedName()] = *t
+		}
+		addLibraryTypes(all, l.Libraries, libChain)
+	}
+}
+
+// contains checks if a library file is in the chain
+func (c LibraryChain) contains(filename string) bool {
+	for _, lib := range c {
+		if filename != "" && lib.Filename == filename {
+			return true
+		}
+	}
+	return false
+}
+
+// QualifiedName returns the name of the type qualified by its LibraryChain,
+// e.g. `libA.libB.Bar`, the name itself for the types of the root document.
+// The qualified name resolves to the type with GetType.
+func (t Type) QualifiedName() string {
+	parts := make([]string, 0, len(t.LibraryChain)+1)
+	for _, lib := range t.LibraryChain {
+		parts = append(parts, lib.Name)
+	}
+	return strings.Join(append(parts, t.Name), ".")
+}
@@ -0,0 +1,44 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTypeRegistry(t *testing.T) {
+	Convey("type registry", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/simple_with_lib.raml", apiDef), ShouldBeNil)
+
+		Convey("lookup across the libraries", func() {
+			link, ok := apiDef.GetType("files.Link")
+			So(ok, ShouldBeTrue)
+			So(link.QualifiedName(), ShouldEqual, "files.Link")
+
+			file, ok := apiDef.GetType("files.file-type.File")
+			So(ok, ShouldBeTrue)
+			So(file.Name, ShouldEqual, "File")
+			So(file.QualifiedName(), ShouldEqual, "files.file-type.File")
+
+			_, ok = apiDef.GetType("file-type.File")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("all the types by qualified name", func() {
+			all := apiDef.AllTypes()
+			So(all, ShouldContainKey, "files.Link")
+			So(all, ShouldContainKey, "files.file-type.File")
+			So(all["files.file-type.File"].LibraryChain.String(), ShouldEqual,
+				"root → files (libraries/files.raml) → file-type (libraries/file-type.raml)")
+			for name, t := range apiDef.Types {
+				So(all[name].Name, ShouldEqual, t.Name)
+			}
+			for name, t := range all {
+				found, ok := apiDef.GetType(name)
+				So(ok, ShouldBeTrue)
+				So(found.QualifiedName(), ShouldEqual, t.QualifiedName())
+			}
+		})
+	})
+}
@@ -0,0 +1,71 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTypeResolve(t *testing.T) {
+	Convey("effective types", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/type_resolve/api.raml", apiDef, WithUnknownKeyErrors()), ShouldBeNil)
+
+		names := func(props []Property) []string {
+			var names []string
+			for _, p := range props {
+				names = append(names, p.Name)
+			}
+			return names
+		}
+
+		Convey("scalar facets", func() {
+			code := apiDef.Types["ShortCode"].Resolve(apiDef)
+			So(code.Name, ShouldEqual, "ShortCode")
+			So(code.Type, ShouldEqual, "string")
+			So(code.Pattern, ShouldEqual, "^[A-Z]+$")
+			So(code.MaxLength, ShouldEqual, 4)
+			So(code.Description, ShouldEqual, "a code of 4 letters at most")
+			So(code.Annotations, ShouldResemble, map[string]interface{}{"(owner)": "catalog", "(audited)": true})
+			So(code.Parents(), ShouldBeEmpty)
+		})
+
+		Convey("multiple inheritance and library parents", func() {
+			product := apiDef.Types["Product"].Resolve(apiDef)
+			So(product.Type, ShouldEqual, "object")
+			So(names(product.AllProperties(apiDef)), ShouldResemble, []string{"id", "name", "created", "kind", "code"})
+			So(*product.GetProperty("name").MinLength, ShouldEqual, 3)
+			So(product.RawProperties, ShouldContainKey, "id")
+			So(product.RequiredProperties(), ShouldHaveLength, 5)
+
+			// the examples of the nearest ancestor
+			So(product.AllExamples(), ShouldHaveLength, 1)
+			So(product.Example, ShouldResemble, map[interface{}]interface{}{"id": "e1"})
+		})
+
+		Convey("the parents of a library type are the ones of its library", func() {
+			named, ok := apiDef.TypeByName("lib.Named")
+			So(ok, ShouldBeTrue)
+			So(names(named.Resolve(apiDef).AllProperties(apiDef)), ShouldResemble, []string{"id", "name"})
+			ancestors := named.Ancestors(apiDef)
+			So(ancestors, ShouldHaveLength, 1)
+			So(ancestors[0].Properties, ShouldContainKey, "id")
+		})
+
+		Convey("discriminator", func() {
+			book := apiDef.Types["Book"].Resolve(apiDef)
+			So(book.Discriminator, ShouldEqual, "kind")
+			So(book.DiscriminatorValue, ShouldEqual, "book")
+			So(apiDef.Types["Product"].Resolve(apiDef).DiscriminatorValue, ShouldEqual, "")
+		})
+
+		Convey("type expressions and the types without parents", func() {
+			So(apiDef.Types["Books"].Resolve(apiDef).Type, ShouldEqual, "Book[]")
+			timestamped := apiDef.Types["Timestamped"]
+			So(timestamped.Resolve(apiDef).Type, ShouldEqual, "object")
+			So(timestamped.Resolve(apiDef).Properties, ShouldResemble, map[string]Property{
+				"created": timestamped.Properties["created"],
+			})
+		})
+	})
+}
@@ -35,8 +35,11 @@ package raml
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"net/textproto"
+	"strconv"
 	"strings"
+
+	"github.com/gigforks/yaml"
 )
 
 const (
@@ -78,9 +81,63 @@ type Any interface{}
 // HTTPCode defines an HTTP status code, for extra clarity
 type HTTPCode string // e.g. 200
 
+// Int returns the status code as an integer,
+// false for a range of codes, `default` or an invalid code
+func (c HTTPCode) Int() (int, bool) {
+	code, err := strconv.Atoi(string(c))
+	if err != nil || code < 100 || code > 599 {
+		return 0, false
+	}
+	return code, true
+}
+
+// IsRange returns true if the status code is a range of codes, e.g. `4XX`
+func (c HTTPCode) IsRange() bool {
+	_, ok := c.class()
+	return ok && strings.ToUpper(string(c[1:])) == "XX"
+}
+
+// IsDefault returns true if the status code is `default`,
+// the response for the codes without their own response
+func (c HTTPCode) IsDefault() bool {
+	return c == "default"
+}
+
+// Validate checks that the status code is between 100 and 599,
+// a range of codes or `default`
+func (c HTTPCode) Validate() error {
+	if _, ok := c.Int(); ok || c.IsRange() || c.IsDefault() {
+		return nil
+	}
+	return fmt.Errorf("invalid HTTP status code: %v", c)
+}
+
+// class returns the class of the status code, e.g. 4 for `404` and `4XX`
+func (c HTTPCode) class() (int, bool) {
+	s := strings.ToUpper(string(c))
+	if len(s) != 3 || s[0] < '1' || s[0] > '5' {
+		return 0, false
+	}
+	switch {
+	case s[1:] == "XX":
+	case '0' <= s[1] && s[1] <= '9' && '0' <= s[2] && s[2] <= '9':
+	default:
+		return 0, false
+	}
+	return int(s[0] - '0'), true
+}
+
 // HTTPHeader defines an HTTP header
 type HTTPHeader string // e.g. Content-Length
 
+// Canonical returns the canonical format of the header name,
+// e.g. `Content-Type` for `content-type`.
+// HTTP header names are case-insensitive, headers with the same
+// canonical name are the same header.
+func (h HTTPHeader) Canonical() HTTPHeader {
+	return HTTPHeader(textproto.CanonicalMIMEHeaderKey(string(h)))
+}
+
 // Header used in Methods and other types
 type Header NamedParameter
 
@@ -94,8 +151,51 @@ type Documentation struct {
 // A ResourceType/Trait/SecurityScheme choice contains the name of a
 // ResourceType/Trait/SecurityScheme as well as the parameters used to create
 // an instance of it.
+// The values are scalars, []interface{} or map[string]interface{}.
 type DefinitionParameters map[string]interface{}
 
+// Get returns the value of a parameter
+func (dp DefinitionParameters) Get(name string) (interface{}, bool) {
+	v, ok := dp[name]
+	return v, ok
+}
+
+// String returns the value of a scalar parameter as a string,
+// false if the parameter is missing or not a scalar
+func (dp DefinitionParameters) String(name string) (string, bool) {
+	v, ok := dp[name]
+	if !ok || v == nil {
+		return "", false
+	}
+	switch v.(type) {
+	case []interface{}, map[string]interface{}:
+		return "", false
+	}
+	return fmt.Sprint(v), true
+}
+
+// Strings returns the values of a sequence parameter as strings, e.g. the
+// `scopes` of an OAuth security scheme. A scalar parameter is a single value.
+func (dp DefinitionParameters) Strings(name string) []string {
+	switch v := dp[name].(type) {
+	case nil, map[string]interface{}:
+		return nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// Names returns the sorted names of the parameters
+func (dp DefinitionParameters) Names() []string {
+	return sortedKeys(dp)
+}
+
 // DefinitionChoice defines a definition with it's parameters
 type DefinitionChoice struct {
 	Name string
@@ -105,33 +205,102 @@ type DefinitionChoice struct {
 	// UNLESS the parameter corresponds to a reserved parameter name, in which
 	// case its value is provided by the processing application.
 	// Same goes for security schemes.
+	// It is empty, not nil, for a choice without parameters.
 	Parameters DefinitionParameters
 }
 
-// UnmarshalYAML unmarshals a node which MIGHT be a simple string or a
-// map[string]DefinitionParameters
-func (dc *DefinitionChoice) UnmarshalYAML(unmarshaler func(interface{}) error) error {
+// Param returns the value of a parameter of the choice
+func (dc DefinitionChoice) Param(name string) (interface{}, bool) {
+	return dc.Parameters.Get(name)
+}
 
-	simpleDefinition := new(string)
-	parameterizedDefinition := make(map[string]DefinitionParameters)
+// Scopes returns the `scopes` parameter of a security scheme choice,
+// e.g. `[ ADMINISTRATOR ]` for `oauth_2_0: { scopes: [ ADMINISTRATOR ] }`
+func (dc DefinitionChoice) Scopes() []string {
+	return dc.Parameters.Strings("scopes")
+}
 
-	var err error
+// UnmarshalYAML unmarshals a node which MIGHT be a simple string, `null`,
+// a `name: {params}` mapping or a `name: [ {params}, ... ]` mapping
+// whose parameter mappings are merged
+func (dc *DefinitionChoice) UnmarshalYAML(unmarshaler func(interface{}) error) error {
+	var simpleDefinition *string
+	if err := unmarshaler(&simpleDefinition); err == nil {
+		dc.Name = ""
+		if simpleDefinition != nil {
+			dc.Name = *simpleDefinition
+		}
+		dc.Parameters = DefinitionParameters{}
+		return nil
+	}
 
-	// Unmarshal into a string
-	if err = unmarshaler(simpleDefinition); err == nil {
-		dc.Name = *simpleDefinition
-		dc.Parameters = nil
-	} else if err = unmarshaler(parameterizedDefinition); err == nil {
-		// Didn't work? Now unmarshal into a map
-		for choice, params := range parameterizedDefinition {
-			dc.Name = choice
-			dc.Parameters = params
+	parameterizedDefinition := yaml.MapSlice{}
+	if err := unmarshaler(&parameterizedDefinition); err != nil {
+		return err
+	}
+	if len(parameterizedDefinition) == 0 {
+		return fmt.Errorf("a definition choice needs a name")
+	}
+	if len(parameterizedDefinition) > 1 {
+		var names []string
+		for _, choice := range parameterizedDefinition {
+			names = append(names, fmt.Sprint(choice.Key))
 		}
+		return fmt.Errorf("a definition choice has one name, got %v", strings.Join(names, ", "))
 	}
+	choice := parameterizedDefinition[0]
+	dc.Name = fmt.Sprint(choice.Key)
+	params, err := definitionParameters(choice.Value)
+	if err != nil {
+		return fmt.Errorf("%v: %v", dc.Name, err)
+	}
+	dc.Parameters = params
+	return nil
+}
 
-	// Still didn't work? Panic
+// definitionParameters converts the parameters of a definition choice,
+// a mapping, a sequence of mappings or null
+func definitionParameters(v interface{}) (DefinitionParameters, error) {
+	params := DefinitionParameters{}
+	switch v := v.(type) {
+	case nil:
+	case yaml.MapSlice:
+		for _, item := range v {
+			params[fmt.Sprint(item.Key)] = plainValue(item.Value)
+		}
+	case []interface{}:
+		for _, item := range v {
+			m, ok := item.(yaml.MapSlice)
+			if !ok {
+				return nil, fmt.Errorf("parameters must be mappings, got %v", item)
+			}
+			for _, p := range m {
+				params[fmt.Sprint(p.Key)] = plainValue(p.Value)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("parameters must be a mapping, got %v", v)
+	}
+	return params, nil
+}
 
-	return err
+// plainValue converts the mappings of a YAML value to map[string]interface{}
+func plainValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		m := make(map[string]interface{}, len(v))
+		for _, item := range v {
+			m[fmt.Sprint(item.Key)] = plainValue(item.Value)
+		}
+		return m
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, item := range v {
+			values[i] = plainValue(item)
+		}
+		return values
+	}
+	return v
 }
 
 // HasProperties is interface of all objects that
@@ -148,6 +317,10 @@ type Property struct {
 	Enum        interface{} `yaml:"enum"`
 	Description string      `yaml:"description"`
 
+	// The default value of the property, with its YAML type, e.g. `default: 42` is an int.
+	// nil if not declared
+	Default interface{} `yaml:"default"`
+
 	// string
 	Pattern   *string
 	MinLength *int
@@ -165,9 +338,15 @@ type Property struct {
 	UniqueItems bool
 	Items       Items
 
+	// serialization in XML, nil if not declared
+	XML *XML
+
 	// Capnp extension
 	CapnpType string
 
+	// values of the facets declared by the type of the property, e.g. `maxAmount: 1000`
+	FacetValues map[string]interface{}
+
 	_type *Type // pointer to Type of this Property
 }
 
@@ -180,14 +359,14 @@ func ToProperty(name string, p interface{}) Property {
 
 func toProperty(name string, p interface{}) Property {
 	// convert number(int/float) to float
-	toFloat64 := func(number interface{}) float64 {
+	toFloat64 := func(number interface{}) (float64, bool) {
 		switch v := number.(type) {
 		case int:
-			return float64(v)
+			return float64(v), true
 		case float64:
-			return v
+			return v, true
 		default:
-			return v.(float64)
+			return 0, false
 		}
 	}
 	// convert from map of interface to property
@@ -197,51 +376,73 @@ func toProperty(name string, p interface{}) Property {
 		for k, v := range val {
 			switch k {
 			case "type":
-				if p.Format == nil { // if not nil, we already override it
-					p.Type = v.(string)
+				// if not nil, we already override it, except by a datetime format
+				if p.Format == nil || isDateTimeFormat(*p.Format) {
+					p.Type = interfaceToString(v)
 				}
 			case "format":
-				p.Format = new(string)
-				*p.Format = v.(string)
-				p.Type = *p.Format
+				if f, ok := v.(string); ok {
+					p.Format = &f
+					if !isDateTimeFormat(f) {
+						p.Type = f
+					}
+				}
 			case "required":
-				p.Required = v.(bool)
+				if r, ok := v.(bool); ok {
+					p.Required = r
+				}
 			case "enum":
 				p.Enum = v
 			case "description":
-				p.Description = v.(string)
+				if d, ok := v.(string); ok {
+					p.Description = d
+				}
+			case "default":
+				p.Default = v
 			case "minLength":
-				p.MinLength = new(int)
-				*p.MinLength = v.(int)
+				if i, ok := v.(int); ok {
+					p.MinLength = &i
+				}
 			case "maxLength":
-				p.MaxLength = new(int)
-				*p.MaxLength = v.(int)
+				if i, ok := v.(int); ok {
+					p.MaxLength = &i
+				}
 			case "pattern":
-				p.Pattern = new(string)
-				*p.Pattern = v.(string)
+				if pat, ok := v.(string); ok {
+					p.Pattern = &pat
+				}
 			case "minimum":
-				p.Minimum = new(float64)
-				*p.Minimum = toFloat64(v)
+				if f, ok := toFloat64(v); ok {
+					p.Minimum = &f
+				}
 			case "maximum":
-				p.Maximum = new(float64)
-				*p.Maximum = toFloat64(v)
+				if f, ok := toFloat64(v); ok {
+					p.Maximum = &f
+				}
 			case "multipleOf":
-				p.MultipleOf = new(float64)
-				*p.MultipleOf = toFloat64(v)
+				if f, ok := toFloat64(v); ok {
+					p.MultipleOf = &f
+				}
 			case "minItems":
-				p.MinItems = new(int)
-				*p.MinItems = v.(int)
+				if i, ok := v.(int); ok {
+					p.MinItems = &i
+				}
 			case "maxItems":
-				p.MaxItems = new(int)
-				*p.MaxItems = v.(int)
+				if i, ok := v.(int); ok {
+					p.MaxItems = &i
+				}
 			case "uniqueItems":
-				p.UniqueItems = v.(bool)
+				if u, ok := v.(bool); ok {
+					p.UniqueItems = u
+				}
 			case "items":
 				p.Items = newItems(v)
 			case "capnpType":
-				p.CapnpType = v.(string)
-			case "properties":
-				log.Fatalf("Properties field of '%v' should already be deleted. Seems there are unsupported inline type", name)
+				if c, ok := v.(string); ok {
+					p.CapnpType = c
+				}
+			case "xml":
+				p.XML = newXML(v)
 			}
 		}
 		return p
@@ -253,6 +454,7 @@ func toProperty(name string, p interface{}) Property {
 		prop.Type = p.(string)
 	case map[interface{}]interface{}:
 		prop = mapToProperty(p.(map[interface{}]interface{}))
+		prop.FacetValues = propertyFacetValues(p.(map[interface{}]interface{}))
 	case Property:
 		prop = p.(Property)
 	}
@@ -294,9 +496,9 @@ func (p Property) IsEnum() bool {
 }
 
 // IsBidimensiArray returns true if
-// this property is a bidimensional array
+// this property is an array of at least two dimensions, see ArrayDepth
 func (p Property) IsBidimensiArray() bool {
-	return strings.HasSuffix(p.TypeString(), "[][]")
+	return p.ArrayDepth() >= 2
 }
 
 // IsArray returns true if it is an array
@@ -304,9 +506,17 @@ func (p Property) IsArray() bool {
 	return p.Type == arrayType || strings.HasSuffix(p.TypeString(), "[]")
 }
 
-// IsUnion returns true if a property is a union
+// IsUnion returns true if a property is a union of several types,
+// the nil member of a nullable type is not counted, e.g. `string | nil` is not a union
 func (p Property) IsUnion() bool {
-	return strings.Index(p.TypeString(), "|") > 0
+	_, ok := p.Union()
+	return ok
+}
+
+// Union returns the members of the union type of this property, without nil
+func (p Property) Union() ([]string, bool) {
+	members, _ := nonNilMembers(p.TypeString())
+	return members, len(members) > 1
 }
 
 // BidimensiArrayType returns type of the bidimensional array
@@ -326,6 +536,14 @@ func (p Property) ArrayType() string {
 type Type struct {
 	Name string
 
+	// The libraries the type is resolved through by APIDefinition.TypeByName,
+	// empty for the types of the root document.
+	LibraryChain LibraryChain `yaml:"-" json:"-"`
+
+	// Where the type is declared in the original files,
+	// unknown for the inline types.
+	Location SourceLocation `yaml:"-" json:"-"`
+
 	// A default value for a type
 	Default interface{} `yaml:"default"`
 
@@ -367,13 +585,29 @@ type Type struct {
 	// Its value is a string and MAY be formatted using markdown.
 	Description string `yaml:"description" json:"description"`
 
-	// TODO : annotation names
+	// Annotations of the type, keyed by the annotation name
+	// in parentheses as written in the document, e.g. `(deprecated)`.
+	Annotations map[string]interface{} `yaml:",regexp:\\(.*\\)" json:"-"`
+
+	// The facets declared by this type, keyed by name without the `?` suffix,
+	// e.g. `maxAmount: number`. Their values are given by the subtypes, see FacetValues.
+	Facets map[string]Property `yaml:"-" json:"facets,omitempty"`
+
+	// The facets as written in the document.
+	RawFacets map[string]interface{} `yaml:"facets" json:"-"`
 
-	// TODO : facets
+	// The values of the facets declared by the ancestors of this type, e.g. `maxAmount: 1000`,
+	// the keys of the declaration which are not builtin facets.
+	FacetValues map[string]interface{} `yaml:"-" json:"facetValues,omitempty"`
 
-	// The properties that instances of this type may or must have.
+	// The properties that instances of this type may or must have,
+	// keyed by the property name without the `?` suffix.
+	// They are parsed from RawProperties after post-processing.
+	Properties map[string]Property `yaml:"-" json:"properties"`
+
+	// The properties as written in the document.
 	// we use `interface{}` as property type to support syntactic sugar & shortcut
-	Properties map[string]interface{} `yaml:"properties" json:"properties"`
+	RawProperties map[string]interface{} `yaml:"properties" json:"-"`
 
 	// -------- Below facets are available for object type --------------//
 
@@ -450,22 +684,55 @@ type Type struct {
 	// A list of valid content-type strings for the file. The file type */* MUST be a valid value.
 	FileTypes string `yaml:"fileTypes" json:"fileTypes"`
 
+	// ---------- facets for XML --------------------------------//
+	// The serialization of the instances of this type in XML, nil if not declared.
+	XML *XML `yaml:"xml" json:"xml,omitempty"`
+
 	_apiDef *APIDefinition
+
+	// names of the properties, in declaration order
+	propertyOrder []string
+}
+
+// Annotation returns the value of an annotation of this type,
+// the name could be given with or without the parentheses, e.g. `deprecated`
+func (t Type) Annotation(name string) (interface{}, bool) {
+	return annotation(t.Annotations, name)
 }
 
 // GetProperty returns property with given name
 func (t *Type) GetProperty(name string) Property {
-	propInterface, ok := t.Properties[name]
+	prop, ok := t.Properties[name]
 	if !ok {
-		panic(fmt.Errorf("property %v not exist", name))
+		// not parsed yet
+		propInterface, ok := t.RawProperties[name]
+		if !ok {
+			panic(fmt.Errorf("property %v not exist", name))
+		}
+		prop = toProperty(name, propInterface)
 	}
-
-	prop := toProperty(name, propInterface)
 	prop._type = t
 
 	return prop
 }
 
+// parseProperties parses the raw properties
+func (t *Type) parseProperties() {
+	t.Properties = parseProperties(t.RawProperties)
+}
+
+func parseProperties(raw map[string]interface{}) map[string]Property {
+	if raw == nil {
+		return nil
+	}
+	props := make(map[string]Property, len(raw))
+	for name, p := range raw {
+		prop := toProperty(name, p)
+		props[prop.Name] = prop
+	}
+	return props
+}
+
 // IsBuiltin if a type is an RAML builtin type.
 func (t Type) IsBuiltin() bool {
 	_, ok := scalarTypes[t.TypeString()]
@@ -578,18 +845,21 @@ func (t Type) IsArray() bool {
 // ArrayType returns type of the array
 func (t Type) ArrayType() string {
 	if t.TypeString() == "array" {
-		return interfaceToString(t.Items)
+		return itemsTypeString(t.Items)
 	}
 	return strings.TrimSuffix(t.TypeString(), "[]")
 }
 
+// ItemsDeclaration returns the items of this array type as a type declaration,
+// with their facets and examples, nil if the type declares no items
+func (t Type) ItemsDeclaration() *Type {
+	return itemsDeclaration(t.Items)
+}
+
 // IsBidimensiArray returns true
-// if it is a bidimensional array
+// if it is an array of at least two dimensions, see ArrayDepth
 func (t Type) IsBidimensiArray() bool {
-	if t.IsJSONType() {
-		return false
-	}
-	return strings.HasSuffix(t.TypeString(), "[][]")
+	return t.ArrayDepth() >= 2
 }
 
 // BidimensiArrayType returns type
@@ -606,23 +876,22 @@ func (t Type) IsEnum() bool {
 
 // IsUnion checks if a type is Union type
 // see http://docs.raml.org/specs/1.0/#raml-10-spec-union-types
+// The nil member of a nullable type is not counted, e.g. `string | nil` is not a union.
 func (t Type) IsUnion() bool {
-	if t.IsJSONType() {
-		return false
-	}
-	return strings.Index(t.TypeString(), "|") > 0
+	_, ok := t.Union()
+	return ok
 }
 
-// Union returns union type of this type
+// Union returns the members of the union type of this type, without nil
 func (t Type) Union() ([]string, bool) {
-	if !t.IsUnion() {
+	if t.IsJSONType() {
 		return nil, false
 	}
-	var tips []string
-	for _, ut := range strings.Split(t.TypeString(), "|") {
-		tips = append(tips, strings.TrimSpace(ut))
+	members, _ := nonNilMembers(t.TypeString())
+	if len(members) < 2 {
+		return nil, false
 	}
-	return tips, true
+	return members, true
 }
 
 // IsAlias returns true if this Type is
@@ -644,12 +913,16 @@ func (t *Type) postProcess(name string, apiDef *APIDefinition) error {
 	}
 
 	// process type in properties
-	for name := range t.Properties {
+	errs := new(Error)
+	for name := range t.RawProperties {
 		t.parseOptionalProperty(name)
-		t.createTypeFromPropProperty(name, apiDef)
-		t.createTypeFromPropItems(name, apiDef)
 	}
-	return nil
+	for name := range t.RawProperties {
+		errs.add(t.createTypeFromPropDeclaration(name, apiDef))
+	}
+	errs.add(t.createTypeFromItems(apiDef))
+	t.parseProperties()
+	return errs.errOrNil()
 }
 
 // parse property with `?` suffix as optional property
@@ -659,11 +932,11 @@ func (t *Type) parseOptionalProperty(name string) {
 	}
 	newName := strings.TrimSuffix(name, "?")
 
-	p := t.Properties[name]
+	p := t.RawProperties[name]
 
 	// we will modify both property name
 	// and content, so we delete it
-	delete(t.Properties, name)
+	delete(t.RawProperties, name)
 
 	switch p.(type) {
 	case string:
@@ -672,129 +945,80 @@ func (t *Type) parseOptionalProperty(name string) {
 		newProp := map[interface{}]interface{}{}
 		newProp["type"] = p
 		newProp["required"] = false
-		t.Properties[newName] = newProp
+		t.RawProperties[newName] = newProp
 
 	case map[interface{}]interface{}:
 		// already in map style property
 		propMap := p.(map[interface{}]interface{})
 		propMap["required"] = false
-		t.Properties[newName] = propMap
+		t.RawProperties[newName] = propMap
 	default:
-		log.Fatalf("unexpeced property type: %v", p)
+		// empty or unexpected property value,
+		// fallback to the default type
+		t.RawProperties[newName] = map[interface{}]interface{}{
+			"required": false,
+		}
 	}
 }
 
-// create type from item with inline type definition
-func (t *Type) createTypeFromPropItems(name string, apiDef *APIDefinition) {
-	p := t.Properties[name]
-
-	// propMap is this properties as map
-	propMap, ok := p.(map[interface{}]interface{})
-	if !ok {
-		return
-	}
-
-	// only process the array
-	tip, ok := propMap["type"]
-	if !ok || tip != "array" {
-		return
-	}
-
-	// only process if it has 'items' field
-	itemsIf, ok := propMap["items"]
-	if !ok {
-		return
-	}
-
-	// check it's validity
-	items, ok := itemsIf.(map[interface{}]interface{})
-	if !ok {
-		return
+// create type from the inline type declaration of the items of an array type
+func (t *Type) createTypeFromItems(apiDef *APIDefinition) error {
+	if _, ok := t.Items.(map[interface{}]interface{}); !ok {
+		return nil
 	}
-
-	// to define new type, it needs to have 'properties' field
-	props, ok := items["properties"].(map[interface{}]interface{})
-	if !ok { // doesn't define new type, no problem, we can simply return
-		return
-	}
-	newName := t.Name + name + "Item"
-	created := apiDef.createType(newName, tip, props)
-
-	delete(items, "properties")
-	items["type"] = newName
-	propMap["items"] = items
-
-	t.Properties[name] = propMap
-
-	if created {
-		createdType := apiDef.Types[newName]
-		createdType.postProcess(newName, apiDef)
+	decl, err := registerInlineTypes(map[interface{}]interface{}{"type": t.Type, "items": t.Items}, apiDef, t.Name)
+	if err != nil {
+		return fmt.Errorf("type %v: items: %v", t.Name, err)
 	}
+	t.Items = decl["items"]
+	return nil
 }
 
-// create type from property's property
-func (t *Type) createTypeFromPropProperty(name string, apiDef *APIDefinition) {
-	p := t.Properties[name]
-	// only process map[interface]interface{}
-	propMap, ok := p.(map[interface{}]interface{})
-	if !ok {
-		return
-	}
-
-	// only process if it has 'properties' field
-	propsIf, ok := propMap["properties"]
+// create types from the inline type declaration of a property
+// or of the items of an array property
+func (t *Type) createTypeFromPropDeclaration(name string, apiDef *APIDefinition) error {
+	propMap, ok := t.RawProperties[name].(map[interface{}]interface{})
 	if !ok {
-		return
+		return nil
 	}
-
-	// check validity of the properties
-	props, ok := propsIf.(map[interface{}]interface{})
-	if !ok {
-		panic("inline properties expect properties in type:map[string]interface{}")
+	decl, err := registerInlineTypes(propMap, apiDef, t.Name, name)
+	if err != nil {
+		return fmt.Errorf("type %v: property %v: %v", t.Name, name, err)
 	}
-
-	newName := t.Name + name
-	created := apiDef.createType(newName, propMap["type"], props)
-
-	propMap["type"] = newName
-
-	// delete the 'properties' field
-	delete(propMap, "properties")
-
-	t.Properties[name] = propMap
-
-	// post process the created type
-	if created {
-		createdType := apiDef.Types[newName]
-		createdType.postProcess(newName, apiDef)
-	}
-
+	t.RawProperties[name] = decl
+	return nil
 }
+
 func (t *Type) postProcessJSONSchema() error {
 	var jt JSONSchema
 
 	if err := json.Unmarshal([]byte(t.TypeString()), &jt); err != nil {
-		fmt.Println("failed to marshal json")
-		return err
+		return fmt.Errorf("type %v: invalid JSON schema: %v", t.Name, err)
 	}
 	jt.PostUnmarshal()
 
 	// assign the properties in JSON to Type object
-	if t.Properties == nil {
-		t.Properties = map[string]interface{}{}
+	if t.RawProperties == nil {
+		t.RawProperties = map[string]interface{}{}
 	}
 	for name, prop := range jt.Properties {
-		t.Properties[name] = prop.toRAMLProperty()
+		t.RawProperties[name] = prop.toRAMLProperty()
 	}
 
 	t.Type = "object"
+	t.parseProperties()
 	return nil
 }
 
-// BodiesProperty defines a Body's property
+// BodiesProperty defines the properties of the `application/json` body,
+// see Bodies.ApplicationJSON
 type BodiesProperty struct {
+	// The properties of the body, keyed by the property name without the `?` suffix.
+	Properties map[string]Property
+
+	// The properties as written in the document.
 	// we use `interface{}` as property type to support syntactic sugar & shortcut
-	Properties map[string]interface{} `yaml:"properties"`
+	RawProperties map[string]interface{}
 
 	Type interface{}
 
@@ -806,54 +1030,56 @@ func (bp BodiesProperty) TypeString() string {
 	return interfaceToString(bp.Type)
 }
 
+// ItemsDeclaration returns the items of an array body as a type declaration,
+// nil if the body declares no items
+func (bp BodiesProperty) ItemsDeclaration() *Type {
+	return itemsDeclaration(bp.Items)
+}
+
 // GetProperty gets property with given name
 // from a bodies
 func (bp BodiesProperty) GetProperty(name string) Property {
-	p, ok := bp.Properties[name]
+	if p, ok := bp.Properties[name]; ok {
+		return p
+	}
+	// not parsed yet
+	p, ok := bp.RawProperties[name]
 	if !ok {
 		panic(fmt.Errorf("can't find property name %v", name))
 	}
 	return toProperty(name, p)
 }
 
-// - normalize inline array definition
-// - TODO : handle inlined type definition as part of
-//	 https://github.com/Jumpscale/go-raml/issues/96
-func (bp *BodiesProperty) postProcess() {
-	bp.normalizeArray()
-}
-
-// change this form
+// normalizeArray changes this form
 // type: array
 // items:
 //   type: something
 //
 // to this form
 // type: something[]
-func (bp *BodiesProperty) normalizeArray() {
+func normalizeArray(typ, items interface{}) (interface{}, interface{}) {
 	// `type` and `items` can't be nil
-	if bp.Type == nil || bp.Items == nil {
-		return
+	if typ == nil || items == nil {
+		return typ, items
 	}
 
 	// make sure `type` value = 'array'
-	typeStr, ok := bp.Type.(string)
-	if !ok && typeStr != arrayType {
-		return
+	typeStr, ok := typ.(string)
+	if !ok || typeStr != arrayType {
+		return typ, items
 	}
 
 	// check items value
-	switch item := bp.Items.(type) {
+	switch item := items.(type) {
 	case string:
-		bp.Type = item + "[]"
-		bp.Items = nil
+		return item + "[]", nil
 	case map[interface{}]interface{}:
 		tip, ok := item["type"].(string)
 		if !ok {
-			return
+			return typ, items
 		}
-		bp.Type = tip + "[]"
 		delete(item, "type")
-		bp.Items = item
+		return tip + "[]", item
 	}
+	return typ, items
 }
@@ -2,7 +2,9 @@ package raml
 
 import (
 	"testing"
+	"time"
 
+	"github.com/gigforks/yaml"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -29,14 +31,8 @@ func TestTypeInType(t *testing.T) {
 		period := ar.GetProperty("period")
 		So(period.TypeString(), ShouldEqual, "integer")
 
-		// Also must work via .ToProperty
-		var prop Property
-		for k, p := range action.Properties {
-			if k == "recurring" {
-				prop = ToProperty(k, p)
-				break
-			}
-		}
+		// Also must work via the parsed properties
+		prop := action.Properties["recurring"]
 		So(prop.TypeString(), ShouldEqual, "Actionrecurring")
 
 		// test for the recursive type
@@ -64,5 +60,320 @@ func TestTypeInType(t *testing.T) {
 		coinTipesPlain := action.GetProperty("coinTipesPlain")
 		So(coinTipesPlain.Type, ShouldEqual, "array")
 		So(coinTipesPlain.Items.Type, ShouldEqual, "string")
+
+		// synthesized types could be looked up by name
+		synthesized, ok := apiDef.TypeByName("ActioncoininputsItem")
+		So(ok, ShouldBeTrue)
+		So(synthesized.Name, ShouldEqual, "ActioncoininputsItem")
+	})
+}
+
+func TestTypeExamples(t *testing.T) {
+	Convey("Type examples", t, func() {
+		apiDef := new(APIDefinition)
+		err := ParseFile("./samples/examples.raml", apiDef)
+		So(err, ShouldBeNil)
+
+		Convey("structured examples", func() {
+			examples := apiDef.Types["User"].AllExamples()
+			So(examples, ShouldHaveLength, 3)
+
+			So(examples[0].Name, ShouldEqual, "john")
+			So(examples[0].Strict, ShouldBeTrue)
+
+			So(examples[1].Name, ShouldEqual, "notStrict")
+			So(examples[1].Strict, ShouldBeFalse)
+
+			So(examples[2].Name, ShouldEqual, "strictJane")
+			So(examples[2].DisplayName, ShouldEqual, "Jane")
+			So(examples[2].Description, ShouldEqual, "a user without age")
+			So(examples[2].Strict, ShouldBeTrue)
+
+			count := apiDef.Types["Count"].AllExamples()
+			So(count, ShouldHaveLength, 1)
+			So(count[0].Value, ShouldEqual, 5)
+		})
+
+		Convey("parameter examples", func() {
+			qps := apiDef.Resources["/users"].Get.QueryParameters
+
+			So(qps["limit"].Example, ShouldEqual, 42)
+			So(qps["page"].Example, ShouldEqual, 1)
+
+			examples := qps["sort"].AllExamples()
+			So(examples, ShouldHaveLength, 2)
+			So(examples[0].Name, ShouldEqual, "byAge")
+			So(examples[0].DisplayName, ShouldEqual, "By age")
+			So(examples[0].Value, ShouldEqual, "-age")
+			So(examples[1].Name, ShouldEqual, "byName")
+			So(examples[1].Value, ShouldEqual, "name")
+		})
+
+		Convey("invalid strict example", func() {
+			err := ParseFile("./samples/bad_example.raml", new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "property name")
+		})
+	})
+}
+
+func TestInvalidJSONSchemaType(t *testing.T) {
+	Convey("invalid JSON schema type", t, func() {
+		data := []byte("#%RAML 1.0\ntitle: API\ntypes:\n  User: '{ \"type\": }'\n")
+		err := ParseBytes(data, new(APIDefinition))
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "type User: invalid JSON schema: ")
+	})
+}
+
+func TestTypesInDependencyOrder(t *testing.T) {
+	Convey("types in dependency order", t, func() {
+		apiDef := new(APIDefinition)
+		err := ParseFile("./samples/type_order.raml", apiDef)
+		So(err, ShouldBeNil)
+
+		var names []string
+		for _, t := range apiDef.TypesInDependencyOrder() {
+			names = append(names, t.Name)
+		}
+		So(names, ShouldResemble, []string{
+			"B", "A", // cycle
+			"Living", "Named", "Animal", "Cat", "Dog",
+			"Node", "Person", "Zoo",
+		})
+	})
+}
+
+func TestTypeAncestors(t *testing.T) {
+	Convey("type ancestors", t, func() {
+		apiDef := new(APIDefinition)
+		err := ParseFile("./samples/type_order.raml", apiDef)
+		So(err, ShouldBeNil)
+
+		names := func(types []Type) []string {
+			var names []string
+			for _, t := range types {
+				names = append(names, t.Name)
+			}
+			return names
+		}
+
+		So(names(apiDef.Types["Cat"].Ancestors(apiDef)), ShouldResemble, []string{"Animal", "Named", "Living"})
+		So(names(apiDef.Types["Person"].Ancestors(apiDef)), ShouldResemble, []string{"Named"})
+		So(apiDef.Types["Named"].Ancestors(apiDef), ShouldBeEmpty)
+	})
+}
+
+func TestTypeAllProperties(t *testing.T) {
+	Convey("own and inherited properties", t, func() {
+		apiDef := new(APIDefinition)
+		err := ParseFile("./samples/type_order.raml", apiDef)
+		So(err, ShouldBeNil)
+
+		names := func(props []Property) []string {
+			var names []string
+			for _, p := range props {
+				names = append(names, p.Name)
+			}
+			return names
+		}
+
+		Convey("declaration order", func() {
+			props := apiDef.Types["Named"].AllProperties(apiDef)
+			So(names(props), ShouldResemble, []string{"name", "alias"})
+			So(props[1].Required, ShouldBeFalse)
+		})
+
+		Convey("inherited through multiple inheritance", func() {
+			props := apiDef.Types["Cat"].AllProperties(apiDef)
+			So(names(props), ShouldResemble, []string{"name", "alias", "meows"})
+		})
+
+		Convey("override", func() {
+			props := apiDef.Types["Person"].AllProperties(apiDef)
+			So(names(props), ShouldResemble, []string{"name", "alias", "friend", "pet"})
+			So(*props[0].MinLength, ShouldEqual, 1)
+		})
+
+		Convey("required and optional properties", func() {
+			cat := apiDef.Types["Cat"]
+			So(names(cat.RequiredProperties()), ShouldResemble, []string{"name", "meows"})
+			So(names(cat.OptionalProperties()), ShouldResemble, []string{"alias"})
+
+			// not part of an API definition, only the own properties
+			So(names(Type{Properties: cat.Properties}.RequiredProperties()), ShouldResemble, []string{"meows"})
+		})
+	})
+}
+
+func TestDateTimeExamples(t *testing.T) {
+	Convey("date and time examples", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/dates.raml", apiDef), ShouldBeNil)
+		event := apiDef.Types["Event"]
+		example := event.AllExamples()[0].Value.(map[interface{}]interface{})
+
+		Convey("typed values", func() {
+			day, err := event.GetProperty("day").ParseTime(example["day"].(string))
+			So(err, ShouldBeNil)
+			So(day, ShouldEqual, time.Date(2015, 5, 23, 0, 0, 0, 0, time.UTC))
+
+			modified := event.GetProperty("modified")
+			So(modified.TypeString(), ShouldEqual, "datetime")
+			So(*modified.Format, ShouldEqual, DateTimeRFC2616)
+			at, err := modified.ParseTime(example["modified"].(string))
+			So(err, ShouldBeNil)
+			So(at, ShouldEqual, time.Date(2016, 2, 28, 16, 41, 41, 0, time.UTC))
+
+			created, err := event.GetProperty("created").ParseTime(example["created"].(string))
+			So(err, ShouldBeNil)
+			So(created, ShouldEqual, time.Date(2016, 2, 28, 16, 41, 41, 90000000, time.UTC))
+
+			lm := apiDef.Types["LastModified"]
+			at, err = lm.ParseTime(lm.Example.(string))
+			So(err, ShouldBeNil)
+			So(at.Year(), ShouldEqual, 2016)
+		})
+
+		Convey("values in the declared format", func() {
+			at := time.Date(2016, 2, 28, 17, 41, 41, 90000000, time.FixedZone("CET", 3600))
+			for _, c := range []struct{ typ, format, value string }{
+				{"date-only", "", "2016-02-28"},
+				{"time-only", "", "17:41:41.09"},
+				{"datetime-only", "", "2016-02-28T17:41:41.09"},
+				{"datetime", "", "2016-02-28T17:41:41.09+01:00"},
+				{"datetime", "rfc2616", "Sun, 28 Feb 2016 16:41:41 GMT"},
+			} {
+				s, err := FormatDateTime(c.typ, c.format, at)
+				So(err, ShouldBeNil)
+				So(s, ShouldEqual, c.value)
+			}
+			_, err := FormatDateTime("string", "", at)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("invalid examples", func() {
+			err := ParseFile("./samples/bad_date_example.raml", new(APIDefinition))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring,
+				"property modified: 2016-02-28T16:41:41.090Z is not a valid datetime in rfc2616 format")
+		})
+	})
+}
+
+func TestRegisterType(t *testing.T) {
+	Convey("register types", t, func() {
+		So(ConcatTypeNames("Action", "coininputs", "Item"), ShouldEqual, "ActioncoininputsItem")
+		So(CamelCaseTypeNames("Action", "recurring"), ShouldEqual, "ActionRecurring")
+		So(CamelCaseTypeNames("/users/{userId}", "get", "body"), ShouldEqual, "UsersUserIdGetBody")
+		So(ConcatTypeNames("/users/{userId}", "get", "body"), ShouldEqual, "/users/{userId}getbody")
+
+		Convey("inline types", func() {
+			apiDef := &APIDefinition{TypeNaming: CamelCaseTypeNames}
+			So(ParseFile("./samples/types.raml", apiDef), ShouldBeNil)
+			So(apiDef.Types, ShouldContainKey, "ActionRecurring")
+			So(apiDef.Types, ShouldContainKey, "ActionRecurringCombo")
+			So(apiDef.Types, ShouldContainKey, "ActionCoininputsItem")
+			action := apiDef.Types["Action"]
+			So(action.GetProperty("recurring").TypeString(), ShouldEqual, "ActionRecurring")
+		})
+
+		Convey("collisions", func() {
+			user := Type{Type: "object", RawProperties: map[string]interface{}{"name": "string"}}
+			apiDef := &APIDefinition{TypeNaming: CamelCaseTypeNames}
+			name, err := apiDef.RegisterType(user, "/users/{userId}", "get", "body")
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "UsersUserIdGetBody")
+			So(apiDef.Types[name].Properties, ShouldContainKey, "name")
+
+			// reused, with a warning
+			So(apiDef.Warnings, ShouldBeEmpty)
+			name, err = apiDef.RegisterType(Type{Type: "string"}, "/users/{userId}", "get", "body")
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "UsersUserIdGetBody")
+			So(apiDef.Types[name].TypeString(), ShouldEqual, "object")
+			So(apiDef.Warnings, ShouldResemble, []Warning{{Location: "/users/{userId} get body",
+				Message: "type UsersUserIdGetBody is already declared, it is used instead of the inline type"}})
+
+			apiDef.TypeCollision = SuffixTypeName
+			name, err = apiDef.RegisterType(Type{Type: "string"}, "/users/{userId}", "get", "body")
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "UsersUserIdGetBody2")
+			So(apiDef.Types[name].TypeString(), ShouldEqual, "string")
+
+			apiDef.TypeCollision = FailOnTypeCollision
+			_, err = apiDef.RegisterType(Type{Type: "string"}, "/users/{userId}", "get", "body")
+			So(err, ShouldNotBeNil)
+
+			// in the inline types of the document
+			apiDef = &APIDefinition{TypeCollision: FailOnTypeCollision}
+			data := []byte("#%RAML 1.0\ntitle: Collision\ntypes:\n  Userprofile: string\n" +
+				"  User:\n    properties:\n      profile:\n        properties:\n          bio: string\n")
+			err = ParseBytes(data, apiDef)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "type User: property profile: can't register the type Userprofile")
+		})
+	})
+}
+
+func TestDefinitionChoice(t *testing.T) {
+	Convey("definition choices", t, func() {
+		var choices []DefinitionChoice
+		So(yaml.Unmarshal([]byte(`
+- basic
+- null
+- paged: { maxPages: 10, fields: [ id, name ] }
+- oauth_2_0:
+    - scopes: [ ADMINISTRATOR, USER ]
+    - realm: { name: admin }
+- searchable:
+`), &choices), ShouldBeNil)
+		So(choices, ShouldHaveLength, 5)
+
+		Convey("the scalar forms have no parameters", func() {
+			So(choices[0].Name, ShouldEqual, "basic")
+			So(choices[0].Parameters, ShouldResemble, DefinitionParameters{})
+			So(choices[1].Name, ShouldBeEmpty)
+			So(choices[4].Name, ShouldEqual, "searchable")
+			So(choices[4].Parameters, ShouldResemble, DefinitionParameters{})
+		})
+
+		Convey("the parameters are typed", func() {
+			paged := choices[2]
+			So(paged.Name, ShouldEqual, "paged")
+			So(paged.Parameters.Names(), ShouldResemble, []string{"fields", "maxPages"})
+			v, ok := paged.Param("maxPages")
+			So(ok, ShouldBeTrue)
+			So(v, ShouldEqual, 10)
+			s, ok := paged.Parameters.String("maxPages")
+			So(ok, ShouldBeTrue)
+			So(s, ShouldEqual, "10")
+			_, ok = paged.Parameters.String("fields")
+			So(ok, ShouldBeFalse)
+			So(paged.Parameters.Strings("fields"), ShouldResemble, []string{"id", "name"})
+			So(paged.Parameters.Strings("maxPages"), ShouldResemble, []string{"10"})
+			So(paged.Parameters.Strings("missing"), ShouldBeNil)
+		})
+
+		Convey("the sequences of parameters are merged", func() {
+			oauth := choices[3]
+			So(oauth.Name, ShouldEqual, "oauth_2_0")
+			So(oauth.Scopes(), ShouldResemble, []string{"ADMINISTRATOR", "USER"})
+			realm, _ := oauth.Param("realm")
+			So(realm, ShouldResemble, map[string]interface{}{"name": "admin"})
+		})
+
+		Convey("the parameters must be mappings", func() {
+			var dc DefinitionChoice
+			So(yaml.Unmarshal([]byte(`paged: 10`), &dc), ShouldNotBeNil)
+			So(yaml.Unmarshal([]byte(`paged: [ 10 ]`), &dc), ShouldNotBeNil)
+		})
+
+		Convey("one choice by mapping", func() {
+			var dc DefinitionChoice
+			err := yaml.Unmarshal([]byte(`{ paged: { size: 10 }, searchable: { field: name } }`), &dc)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "a definition choice has one name, got paged, searchable")
+		})
 	})
 }
@@ -0,0 +1,276 @@
+package raml
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gigforks/yaml"
+)
+
+// WithUnknownKeyErrors reports the unknown keys of the document and of its
+// libraries as errors, e.g. a misspelled `queryParamters`, instead of
+// ignoring them. The annotations, e.g. `(owner)`, and the keys with
+// `<<parameter>>` placeholders of the traits and resource types are allowed.
+func WithUnknownKeyErrors() ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.unknownKeys = true
+	}
+}
+
+// WithRootExtensions keeps the unknown keys of the root of the API definition,
+// e.g. the `x-generation-settings` of a vendor tool, in APIDefinition.Extensions
+// instead of ignoring them. They are not reported by WithUnknownKeyErrors.
+func WithRootExtensions() ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.rootExtensions = true
+	}
+}
+
+// declarationTypes are the types decoded by the UnmarshalYAML of a type,
+// their keys are checked instead
+var declarationTypes = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(Resource{}):       reflect.TypeOf(resourceDeclaration{}),
+	reflect.TypeOf(ResourceType{}):   reflect.TypeOf(resourceTypeDeclaration{}),
+	reflect.TypeOf(Body{}):           reflect.TypeOf(bodyDeclaration{}),
+	reflect.TypeOf(Type{}):           reflect.TypeOf(typeDeclaration{}),
+	reflect.TypeOf(AnnotationType{}): reflect.TypeOf(annotationTypeDeclaration{}),
+}
+
+// facetTypes are the types whose keys could also be the facets of a type,
+// e.g. the `enum` of a parameter
+var facetTypes = map[reflect.Type]bool{
+	reflect.TypeOf(NamedParameter{}): true,
+	reflect.TypeOf(Header{}):         true,
+}
+
+var (
+	methodType           = reflect.TypeOf(Method{})
+	bodiesType           = reflect.TypeOf(Bodies{})
+	bodyType             = reflect.TypeOf(Body{})
+	definitionChoiceType = reflect.TypeOf(DefinitionChoice{})
+	typeDeclarationType  = reflect.TypeOf(typeDeclaration{})
+)
+
+// keyChecker checks the keys of a document decoded as yaml.MapSlice
+// against the fields of the structs it is decoded into
+type keyChecker struct {
+	// names of the additional methods, lower case
+	extraMethods map[string]bool
+
+	// the unknown root keys are extensions, see WithRootExtensions
+	rootExtensions bool
+
+	errs *Error
+}
+
+// checkUnknownKeys returns the unknown keys of a document as an Error, nil if none
+func checkUnknownKeys(contents []byte, root interface{}, cfg *parseConfig) error {
+	var tree yaml.MapSlice
+	if err := unmarshalYAML(contents, &tree); err != nil {
+		return err
+	}
+	c := &keyChecker{extraMethods: map[string]bool{}, errs: new(Error)}
+	_, c.rootExtensions = root.(*APIDefinition)
+	c.rootExtensions = c.rootExtensions && cfg.rootExtensions
+	for _, name := range cfg.extraMethods {
+		c.extraMethods[strings.ToLower(name)] = true
+	}
+	if f, ok := root.(fragmentRoot); ok {
+		root = f.fragmentValue()
+	}
+	c.check(tree, reflect.TypeOf(root), "")
+	return c.errs.errOrNil()
+}
+
+// check checks the keys of a node decoded into a value of type t
+func (c *keyChecker) check(node interface{}, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case definitionChoiceType:
+		// the parameters of the traits and resource types are free
+		return
+	case bodiesType:
+		c.checkBodies(node, path)
+		return
+	}
+	if decl, ok := declarationTypes[t]; ok {
+		c.checkStruct(node, []reflect.Type{decl}, path, t == reflect.TypeOf(Resource{}) || t == reflect.TypeOf(ResourceType{}))
+		return
+	}
+	if facetTypes[t] {
+		c.checkStruct(node, []reflect.Type{t, reflect.TypeOf(typeDeclaration{})}, path, false)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		c.checkStruct(node, []reflect.Type{t}, path, false)
+	case reflect.Map:
+		if m, ok := node.(yaml.MapSlice); ok {
+			for _, item := range m {
+				c.check(item.Value, t.Elem(), childPath(path, fmt.Sprint(item.Key)))
+			}
+		}
+	case reflect.Slice:
+		if seq, ok := node.([]interface{}); ok {
+			for i, item := range seq {
+				c.check(item, t.Elem(), fmt.Sprintf("%v/%v", path, i))
+			}
+		}
+	}
+}
+
+// checkBodies checks the bodies, keyed by media type or declared without media type
+func (c *keyChecker) checkBodies(node interface{}, path string) {
+	m, ok := node.(yaml.MapSlice)
+	if !ok {
+		return
+	}
+	for _, item := range m {
+		if strings.Contains(fmt.Sprint(item.Key), "/") {
+			c.check(m, reflect.MapOf(reflect.TypeOf(""), bodyType), path)
+			return
+		}
+	}
+	c.check(m, bodyType, path)
+}
+
+// checkStruct checks the keys of a mapping decoded into the structs, the fields of the
+// first struct take precedence. The additional methods are allowed if withMethods is true.
+func (c *keyChecker) checkStruct(node interface{}, types []reflect.Type, path string, withMethods bool) {
+	m, ok := node.(yaml.MapSlice)
+	if !ok {
+		return
+	}
+	fields, patterns, patternTypes := structKeys(types...)
+
+	for _, item := range m {
+		key := fmt.Sprint(item.Key)
+		itemPath := childPath(path, key)
+		name := strings.TrimSuffix(key, "?")
+		if ft, ok := fields[name]; ok {
+			c.check(item.Value, ft, itemPath)
+			continue
+		}
+		if withMethods && c.extraMethods[name] {
+			c.check(item.Value, methodType, itemPath)
+			continue
+		}
+		if isAnnotationKey(name) || strings.Contains(name, "<<") {
+			continue
+		}
+		matched := false
+		for i, re := range patterns {
+			if re.MatchString(key) {
+				c.check(item.Value, patternTypes[i], itemPath)
+				matched = true
+				break
+			}
+		}
+		// the other keys of a type declaration are the values of its facets,
+		// they are checked against the facets of its ancestors
+		if !matched && !(path == "" && c.rootExtensions) && types[0] != typeDeclarationType {
+			c.errs.add(fmt.Errorf("unknown key %v at %v", key, displayPath(path)))
+		}
+	}
+}
+
+// structKeys returns the keys of the fields of the structs, the fields of the first
+// struct take precedence, and the patterns of the keys of the regexp fields
+func structKeys(types ...reflect.Type) (map[string]reflect.Type, []*regexp.Regexp, []reflect.Type) {
+	fields := map[string]reflect.Type{}
+	var patterns []*regexp.Regexp
+	var patternTypes []reflect.Type
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			tag := f.Tag.Get("yaml")
+			if tag == "-" {
+				continue
+			}
+			parts := strings.Split(tag, ",")
+			name := parts[0]
+			for _, flag := range parts[1:] {
+				switch {
+				case flag == "inline":
+					addFields(f.Type)
+					name = "-"
+				case strings.HasPrefix(flag, "regexp:"):
+					if re, err := regexp.Compile(strings.TrimPrefix(flag, "regexp:")); err == nil {
+						patterns = append(patterns, re)
+						patternTypes = append(patternTypes, f.Type.Elem())
+					}
+					name = "-"
+				}
+			}
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			if _, ok := fields[name]; !ok {
+				fields[name] = f.Type
+			}
+		}
+	}
+	for _, t := range types {
+		addFields(t)
+	}
+	return fields, patterns, patternTypes
+}
+
+// rootExtensions returns the root keys of an API definition which are not decoded,
+// e.g. `x-generation-settings`, with their values
+func rootExtensions(tree yaml.MapSlice) map[string]interface{} {
+	fields, patterns, _ := structKeys(reflect.TypeOf(APIDefinition{}))
+	var extensions map[string]interface{}
+	for _, item := range tree {
+		key := fmt.Sprint(item.Key)
+		if _, ok := fields[key]; ok || isAnnotationKey(key) {
+			continue
+		}
+		matched := false
+		for _, re := range patterns {
+			matched = matched || re.MatchString(key)
+		}
+		if matched {
+			continue
+		}
+		if extensions == nil {
+			extensions = map[string]interface{}{}
+		}
+		extensions[key] = plainValue(item.Value)
+	}
+	return extensions
+}
+
+// isAnnotationKey returns true if the key is an annotation, e.g. `(owner)`
+func isAnnotationKey(key string) bool {
+	return strings.HasPrefix(key, "(") && strings.HasSuffix(key, ")")
+}
+
+// childPath returns the path of a child node, the keys of the resources
+// are appended as is, e.g. `/users/{id}/get`
+func childPath(path, key string) string {
+	if strings.HasPrefix(key, "/") {
+		return path + key
+	}
+	return path + "/" + key
+}
+
+// displayPath returns the path of a node, `/` for the root of the document
+func displayPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,351 @@
+package raml
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gigforks/yaml"
+)
+
+const raml08Header = "#%RAML 0.8"
+
+var (
+	// `!include` tag, it is kept as is by the upgrade
+	includeTagRe = regexp.MustCompile(`(:\s+|-\s+)!include\s+(\S.*?)\s*$`)
+)
+
+// includeRef is an `!include` of the upgraded document
+type includeRef string
+
+// UpgradeRAML08 converts a RAML 0.8 document into a RAML 1.0 document:
+//   - schemas are converted to types, with the schema as their type,
+//     and the `schema` of the bodies to `type`
+//   - form parameters are converted to properties of the body
+//   - repeatable named parameters are converted to arrays
+//   - the sequences of traits, resource types, security schemes and schemas
+//     are converted to maps
+//   - the optional query parameters, headers and form parameters are
+//     explicitly declared optional, they are required by default in RAML 1.0
+//   - `date` named parameters are converted to `datetime` with rfc2616 format
+//
+// Included files are not upgraded, the `!include` references are kept as is.
+func UpgradeRAML08(contents []byte) ([]byte, error) {
+	parts := bytes.SplitN(contents, []byte("\n"), 2)
+	if strings.TrimSpace(string(parts[0])) != raml08Header {
+		return nil, errors.New("input is not a RAML 0.8 document. Make sure it starts with " + raml08Header)
+	}
+	var body []byte
+	if len(parts) == 2 {
+		body = parts[1]
+	}
+
+	body = quoteIncludes(body)
+	var tree yaml.MapSlice
+	if err := unmarshalYAML(body, &tree); err != nil {
+		return nil, fmt.Errorf("error parsing RAML 0.8 document (Error: %s)", err.Error())
+	}
+	tree = upgradeRoot(toIncludeRefs(tree).(yaml.MapSlice))
+
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	e := &emitter{
+		w:        bw,
+		comments: parseComments(body),
+		src: &documentSource{
+			header:       ramlHeader,
+			lineEnding:   "\n",
+			finalNewline: true,
+		},
+	}
+	if err := e.emitDocument(tree); err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// quoteIncludes turns the `!include` tags into strings,
+// so they are not lost by the YAML decoder
+func quoteIncludes(body []byte) []byte {
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := includeTagRe.ReplaceAllStringFunc(scanner.Text(), func(s string) string {
+			m := includeTagRe.FindStringSubmatch(s)
+			return m[1] + `"!include ` + strings.Replace(m[2], `"`, `\"`, -1) + `"`
+		})
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// toIncludeRefs converts the quoted `!include` tags back into includeRef
+func toIncludeRefs(v interface{}) interface{} {
+	switch val := v.(type) {
+	case yaml.MapSlice:
+		for i := range val {
+			val[i].Value = toIncludeRefs(val[i].Value)
+		}
+	case []interface{}:
+		for i := range val {
+			val[i] = toIncludeRefs(val[i])
+		}
+	case string:
+		if strings.HasPrefix(val, "!include ") {
+			return includeRef(strings.TrimPrefix(val, "!include "))
+		}
+	}
+	return v
+}
+
+func upgradeRoot(root yaml.MapSlice) yaml.MapSlice {
+	var out yaml.MapSlice
+	for _, item := range root {
+		key := fmt.Sprint(item.Key)
+		switch {
+		case key == "schemas":
+			out = append(out, yaml.MapItem{Key: "types", Value: upgradeEach(seqToMap(item.Value), upgradeSchema08)})
+		case key == "traits":
+			out = append(out, yaml.MapItem{Key: key, Value: upgradeEach(seqToMap(item.Value), upgradeMethod08)})
+		case key == "resourceTypes":
+			out = append(out, yaml.MapItem{Key: key, Value: upgradeEach(seqToMap(item.Value), upgradeResource08)})
+		case key == "securitySchemes":
+			out = append(out, yaml.MapItem{Key: key, Value: upgradeEach(seqToMap(item.Value), upgradeSecurityScheme08)})
+		case key == "baseUriParameters":
+			out = append(out, yaml.MapItem{Key: key, Value: upgradeParams08(item.Value, false)})
+		case strings.HasPrefix(key, "/"):
+			out = append(out, yaml.MapItem{Key: item.Key, Value: upgradeResource08(item.Value)})
+		default:
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// upgradeSchema08 converts a schema into a type declaration with the schema as its type
+func upgradeSchema08(v interface{}) interface{} {
+	switch v.(type) {
+	case string, includeRef:
+		return yaml.MapSlice{{Key: "type", Value: v}}
+	}
+	return v
+}
+
+func upgradeSecurityScheme08(v interface{}) interface{} {
+	return upgradeMap(v, func(key string, value interface{}) (string, interface{}) {
+		if key == "describedBy" {
+			return key, upgradeMethod08(value)
+		}
+		return key, value
+	})
+}
+
+func upgradeResource08(v interface{}) interface{} {
+	return upgradeMap(v, func(key string, value interface{}) (string, interface{}) {
+		switch name := strings.TrimSuffix(key, "?"); {
+		case strings.HasPrefix(key, "/"):
+			return key, upgradeResource08(value)
+		case name == "uriParameters" || name == "baseUriParameters":
+			return key, upgradeParams08(value, false)
+		case isMethodName(name):
+			return key, upgradeMethod08(value)
+		}
+		return key, value
+	})
+}
+
+func upgradeMethod08(v interface{}) interface{} {
+	return upgradeMap(v, func(key string, value interface{}) (string, interface{}) {
+		switch strings.TrimSuffix(key, "?") {
+		case "queryParameters", "headers":
+			return key, upgradeParams08(value, true)
+		case "baseUriParameters":
+			return key, upgradeParams08(value, false)
+		case "body":
+			return key, upgradeBodies08(value)
+		case "responses":
+			return key, upgradeEach(value, upgradeResponse08)
+		}
+		return key, value
+	})
+}
+
+func upgradeResponse08(v interface{}) interface{} {
+	return upgradeMap(v, func(key string, value interface{}) (string, interface{}) {
+		switch strings.TrimSuffix(key, "?") {
+		case "headers":
+			return key, upgradeParams08(value, true)
+		case "body":
+			return key, upgradeBodies08(value)
+		}
+		return key, value
+	})
+}
+
+// upgradeBodies08 upgrades a body, with or without the media types
+func upgradeBodies08(v interface{}) interface{} {
+	m, ok := v.(yaml.MapSlice)
+	if !ok {
+		return v
+	}
+	for _, item := range m {
+		switch fmt.Sprint(item.Key) {
+		case "schema", "formParameters", "example":
+			// body without media types
+			return upgradeBody08(m)
+		}
+	}
+	return upgradeEach(m, upgradeBody08)
+}
+
+func upgradeBody08(v interface{}) interface{} {
+	return upgradeMap(v, func(key string, value interface{}) (string, interface{}) {
+		switch key {
+		case "schema":
+			return "type", value
+		case "formParameters":
+			return "properties", upgradeParams08(value, true)
+		}
+		return key, value
+	})
+}
+
+// upgradeParams08 upgrades the named parameters, optional is true
+// if the parameters are optional by default in RAML 0.8
+func upgradeParams08(v interface{}, optional bool) interface{} {
+	return upgradeEach(v, func(pv interface{}) interface{} {
+		param, ok := pv.(yaml.MapSlice)
+		if !ok {
+			if pv != nil {
+				// e.g. multiple types, not upgraded
+				return pv
+			}
+			param = yaml.MapSlice{}
+		}
+		return upgradeParam08(param, optional)
+	})
+}
+
+// facets of a repeatable named parameter that apply to its items
+var itemFacets = map[string]bool{
+	"type": true, "enum": true, "pattern": true,
+	"minLength": true, "maxLength": true, "minimum": true, "maximum": true,
+}
+
+func upgradeParam08(param yaml.MapSlice, optional bool) yaml.MapSlice {
+	var out, items yaml.MapSlice
+	repeat, hasRequired, isDate := false, false, false
+	for _, item := range param {
+		key := fmt.Sprint(item.Key)
+		switch {
+		case key == "repeat":
+			repeat, _ = item.Value.(bool)
+			continue
+		case key == "required":
+			hasRequired = true
+		case key == "type" && item.Value == "date":
+			item.Value, isDate = "datetime", true
+		}
+		out = append(out, item)
+	}
+	if isDate && !hasKey(param, "format") {
+		out = append(out, yaml.MapItem{Key: "format", Value: "rfc2616"})
+	}
+	if optional && !hasRequired {
+		out = append(out, yaml.MapItem{Key: "required", Value: false})
+	}
+	if !repeat {
+		return out
+	}
+
+	// repeatable parameter is an array of its type
+	var arr yaml.MapSlice
+	for _, item := range out {
+		key := fmt.Sprint(item.Key)
+		switch {
+		case itemFacets[key] || key == "format":
+			items = append(items, item)
+		case key == "example" || key == "default":
+			if _, ok := item.Value.([]interface{}); !ok {
+				item.Value = []interface{}{item.Value}
+			}
+			arr = append(arr, item)
+		default:
+			arr = append(arr, item)
+		}
+	}
+	var itemsValue interface{} = "string"
+	if len(items) == 1 && fmt.Sprint(items[0].Key) == "type" {
+		itemsValue = items[0].Value
+	} else if len(items) > 0 {
+		itemsValue = items
+	}
+	return append(yaml.MapSlice{{Key: "type", Value: "array"}, {Key: "items", Value: itemsValue}}, arr...)
+}
+
+// seqToMap converts a sequence of single key maps, which is
+// how RAML 0.8 declares the traits, resource types, etc., into a map
+func seqToMap(v interface{}) interface{} {
+	seq, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	var out yaml.MapSlice
+	for _, elem := range seq {
+		if m, ok := elem.(yaml.MapSlice); ok {
+			out = append(out, m...)
+		}
+	}
+	return out
+}
+
+// upgradeEach upgrades all values of a map
+func upgradeEach(v interface{}, fn func(interface{}) interface{}) interface{} {
+	return upgradeMap(v, func(key string, value interface{}) (string, interface{}) {
+		return key, fn(value)
+	})
+}
+
+// upgradeMap upgrades the items of a map, fn returns the new key and value
+func upgradeMap(v interface{}, fn func(key string, value interface{}) (string, interface{})) interface{} {
+	m, ok := v.(yaml.MapSlice)
+	if !ok {
+		return v
+	}
+	out := make(yaml.MapSlice, 0, len(m))
+	for _, item := range m {
+		key, value := fn(fmt.Sprint(item.Key), item.Value)
+		if key == fmt.Sprint(item.Key) {
+			// keep the key type, e.g. integer response codes
+			out = append(out, yaml.MapItem{Key: item.Key, Value: value})
+			continue
+		}
+		out = append(out, yaml.MapItem{Key: key, Value: value})
+	}
+	return out
+}
+
+func hasKey(m yaml.MapSlice, key string) bool {
+	for _, item := range m {
+		if fmt.Sprint(item.Key) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func isMethodName(name string) bool {
+	for _, m := range methodNames {
+		if strings.EqualFold(m, name) {
+			return true
+		}
+	}
+	return false
+}
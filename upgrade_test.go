@@ -0,0 +1,38 @@
+package raml
+
+import (
+	"io/ioutil"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUpgradeRAML08(t *testing.T) {
+	Convey("upgrade RAML 0.8 document", t, func() {
+		contents, err := ioutil.ReadFile("./samples/raml08/api.raml")
+		So(err, ShouldBeNil)
+
+		upgraded, err := UpgradeRAML08(contents)
+		So(err, ShouldBeNil)
+
+		expected, err := ioutil.ReadFile("./samples/raml08/api_upgraded.raml")
+		So(err, ShouldBeNil)
+		So(string(upgraded), ShouldEqual, string(expected))
+
+		Convey("upgraded document is valid RAML 1.0", func() {
+			apiDef := new(APIDefinition)
+			_, err := parseBytes(upgraded, "./samples/raml08", "api.raml", apiDef, &parseConfig{})
+			So(err, ShouldBeNil)
+			So(apiDef.Types["user"].Type, ShouldEqual, "object")
+
+			tag := apiDef.Resources["/users"].Get.QueryParameters["tag"]
+			So(tag.Type, ShouldEqual, "array")
+			So(tag.Example, ShouldResemble, []interface{}{"admin"})
+		})
+
+		Convey("not a RAML 0.8 document", func() {
+			_, err := UpgradeRAML08([]byte("#%RAML 1.0\ntitle: x\n"))
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
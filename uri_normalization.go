@@ -0,0 +1,72 @@
+package raml
+
+import (
+	"strings"
+	"unicode"
+)
+
+// URINormalization describes the identifier derived from a URI by Normalize,
+// e.g. for the names generated by the code generators.
+// The zero value removes the slashes and the parameter markers,
+// e.g. `usersuserId` for `/users/{userId}`.
+type URINormalization struct {
+	// Separator joins the segments of the URI, e.g. `_` for `users_userId`,
+	// empty to concatenate them
+	Separator string
+
+	// KeepParameterMarkers keeps the braces of the URI parameters, e.g. `users_{userId}`
+	KeepParameterMarkers bool
+
+	// Slugify lower cases the segments and replaces their characters
+	// other than the letters and the digits by `-`, e.g. `user-id` for `{user_id}`
+	Slugify bool
+
+	// Inflector is the name of an inflector applied to every segment, e.g. `uppercamelcase`
+	// for `UsersUserId`, see RegisterInflector. An unknown inflector is ignored.
+	Inflector string
+}
+
+// Normalize returns the identifier of a URI, its segments are normalized then
+// joined by the separator, the empty segments are skipped
+func (n URINormalization) Normalize(uri string) string {
+	var segments []string
+	for _, s := range strings.Split(uri, "/") {
+		if !n.KeepParameterMarkers {
+			s = strings.NewReplacer("{", "", "}", "").Replace(s)
+		}
+		if n.Slugify {
+			s = slugify(s, n.KeepParameterMarkers)
+		}
+		if n.Inflector != "" {
+			s, _ = doInflect(s, "!"+strings.TrimPrefix(n.Inflector, "!"))
+		}
+		if s = strings.TrimSpace(s); s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return strings.Join(segments, n.Separator)
+}
+
+// NormalizedURI returns the identifier of the full URI of this resource, see URINormalization
+func (r *Resource) NormalizedURI(n URINormalization) string {
+	return n.Normalize(r.FullURI())
+}
+
+// slugify lower cases a segment and replaces the runs of characters other than
+// the letters and the digits by `-`, the braces are kept if keepBraces is true
+func slugify(s string, keepBraces bool) string {
+	var b strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(s) {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) || (keepBraces && (c == '{' || c == '}')) {
+			if dash && b.Len() > 0 && !strings.HasSuffix(b.String(), "{") {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(c)
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
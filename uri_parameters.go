@@ -0,0 +1,112 @@
+package raml
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// uriPlaceholderRe matches the URI parameters of a URI, e.g. `{userId}`
+var uriPlaceholderRe = regexp.MustCompile(`{([^{}]+)}`)
+
+// URIParameterMismatch is a URI parameter declared in `uriParameters` or
+// `baseUriParameters` without a placeholder in the URI, or a placeholder
+// of the URI without a declaration
+type URIParameterMismatch struct {
+	// full URI of the resource, empty for the base URI
+	URI string
+
+	// name of the parameter, e.g. `userId`
+	Parameter string
+
+	// true if the parameter is declared but not in the URI,
+	// false if it is in the URI but not declared
+	Unused bool
+}
+
+func (m URIParameterMismatch) String() string {
+	location := m.URI
+	if location == "" {
+		location = "baseUri"
+	}
+	if m.Unused {
+		return fmt.Sprintf("%v: URI parameter %v is declared but not in the URI", location, m.Parameter)
+	}
+	return fmt.Sprintf("%v: URI parameter {%v} is not declared", location, m.Parameter)
+}
+
+// URIParameterMismatches returns the URI parameters which are declared but not in the URI
+// of their resource or in the base URI, and the placeholders of the URIs which are not
+// declared by their resource, the parent resources or `baseUriParameters`.
+// The URI parameters of the resource types are taken into account.
+// The parameters `version` of the base URI and `mediaTypeExtension` don't need a declaration.
+func (apiDef *APIDefinition) URIParameterMismatches() []URIParameterMismatch {
+	var mismatches []URIParameterMismatch
+
+	// base URI
+	basePlaceholders := uriPlaceholders(apiDef.BaseURI)
+	for _, name := range sortedKeys(apiDef.BaseURIParameters) {
+		if !basePlaceholders[name] {
+			mismatches = append(mismatches, URIParameterMismatch{Parameter: name, Unused: true})
+		}
+	}
+	for _, name := range sortedKeys(basePlaceholders) {
+		if _, ok := apiDef.BaseURIParameters[name]; !ok && name != "version" {
+			mismatches = append(mismatches, URIParameterMismatch{Parameter: name})
+		}
+	}
+
+	apiDef.walkResources(func(r *Resource) {
+		uri := r.FullURI()
+
+		// a resource could describe the parameters of its parents
+		placeholders := uriPlaceholders(uri)
+		for _, name := range sortedKeys(r.URIParameters) {
+			if !placeholders[name] {
+				mismatches = append(mismatches, URIParameterMismatch{URI: uri, Parameter: name, Unused: true})
+			}
+		}
+		for _, name := range sortedKeys(uriPlaceholders(r.URI)) {
+			if name == "mediaTypeExtension" || r.declaresURIParameter(name) {
+				continue
+			}
+			mismatches = append(mismatches, URIParameterMismatch{URI: uri, Parameter: name})
+		}
+	})
+
+	sort.SliceStable(mismatches, func(i, j int) bool {
+		return mismatches[i].URI < mismatches[j].URI
+	})
+	return mismatches
+}
+
+// declaresURIParameter returns true if the resource or one of its parents declares the parameter
+func (r *Resource) declaresURIParameter(name string) bool {
+	for ; r != nil; r = r.Parent {
+		if _, ok := r.URIParameters[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// uriPlaceholders returns the names of the URI parameters of a URI
+func uriPlaceholders(uri string) map[string]bool {
+	names := map[string]bool{}
+	for _, m := range uriPlaceholderRe.FindAllStringSubmatch(uri, -1) {
+		names[strings.TrimSpace(m[1])] = true
+	}
+	return names
+}
+
+// warnUnusedURIParameters reports a warning for every declared URI parameter
+// which is not in its URI. The undeclared placeholders are implicit string
+// parameters, they are only reported by URIParameterMismatches.
+func (apiDef *APIDefinition) warnUnusedURIParameters() {
+	for _, m := range apiDef.URIParameterMismatches() {
+		if m.Unused {
+			apiDef.warn("", "%v", m)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package raml
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedDocument is a remote document kept by an URLCache
+type CachedDocument struct {
+	Content []byte
+
+	// validators of the document, sent in the If-None-Match
+	// and If-Modified-Since headers to revalidate it
+	ETag         string
+	LastModified string
+
+	// the document is used without revalidation until Expires,
+	// as told by the Cache-Control max-age or Expires headers
+	Expires time.Time
+}
+
+// URLCache keeps the remote documents, included files and libraries
+// between parsings, see WithURLCache.
+// Its methods could be called concurrently.
+type URLCache interface {
+	// Get returns the document of the URL, false if not in the cache
+	Get(url string) (CachedDocument, bool)
+
+	// Set keeps the document of the URL
+	Set(url string, doc CachedDocument) error
+}
+
+// URLCacheOption configures the URL caches of NewMemoryURLCache and NewDirURLCache
+type URLCacheOption func(*urlCacheClock)
+
+// WithCacheClock sets the clock the freshness of the cached documents is checked with,
+// time.Now by default
+func WithCacheClock(now func() time.Time) URLCacheOption {
+	return func(c *urlCacheClock) {
+		c.clock = now
+	}
+}
+
+// urlCacheClock is the clock of an URL cache
+type urlCacheClock struct {
+	clock func() time.Time
+}
+
+func newURLCacheClock(opts []URLCacheOption) urlCacheClock {
+	c := urlCacheClock{clock: time.Now}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+func (c urlCacheClock) now() time.Time {
+	return c.clock()
+}
+
+// cacheNow returns the current time of the clock of a cache,
+// time.Now for the caches without clock
+func cacheNow(cache URLCache) time.Time {
+	if c, ok := cache.(interface{ now() time.Time }); ok {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// WithURLCache reads the remote documents, included files and libraries through
+// the cache: a fresh document is not requested again, a stale one is revalidated
+// with its ETag or Last-Modified date, and used if the server can't be reached.
+func WithURLCache(c URLCache) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.urlCache = c
+	}
+}
+
+// memoryURLCache is an URLCache in memory
+type memoryURLCache struct {
+	urlCacheClock
+
+	mu   sync.Mutex
+	docs map[string]CachedDocument
+}
+
+// NewMemoryURLCache returns an URLCache which keeps the documents in memory
+func NewMemoryURLCache(opts ...URLCacheOption) URLCache {
+	return &memoryURLCache{urlCacheClock: newURLCacheClock(opts), docs: map[string]CachedDocument{}}
+}
+
+func (c *memoryURLCache) Get(url string) (CachedDocument, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	doc, ok := c.docs[url]
+	return doc, ok
+}
+
+func (c *memoryURLCache) Set(url string, doc CachedDocument) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.docs[url] = doc
+	return nil
+}
+
+// dirURLCache is an URLCache in a directory, a file per URL
+type dirURLCache struct {
+	urlCacheClock
+
+	dir string
+}
+
+// NewDirURLCache returns an URLCache which keeps the documents in files of dir,
+// e.g. a directory cached between CI runs. The directory is created if needed.
+func NewDirURLCache(dir string, opts ...URLCacheOption) URLCache {
+	return dirURLCache{urlCacheClock: newURLCacheClock(opts), dir: dir}
+}
+
+// fileName returns the name of the file of the URL
+func (c dirURLCache) fileName(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c dirURLCache) Get(url string) (CachedDocument, bool) {
+	var doc CachedDocument
+	data, err := ioutil.ReadFile(c.fileName(url))
+	if err != nil {
+		return doc, false
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return doc, false
+	}
+	return doc, true
+}
+
+func (c dirURLCache) Set(url string, doc CachedDocument) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	// write then rename, so a concurrent Get never reads a partial file
+	tmp, err := ioutil.TempFile(c.dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.fileName(url))
+}
+
+// readCachedURL reads a remote document through the cache
+func readCachedURL(address string, cfg *parseConfig) ([]byte, error) {
+	client, cache := cfg.client(), cfg.urlCache
+	cached, found := cache.Get(address)
+	if found && cacheNow(cache).Before(cached.Expires) {
+		return cached.Content, nil
+	}
+
+	ctx, cancel := cfg.fetchContext()
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// unless the parsing is aborted, a server which can't be reached in time
+		// is like a server which can't be reached
+		if found && cfg.context().Err() == nil {
+			cfg.warn(address, "using the cached document: %v", err)
+			return cached.Content, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && found:
+		cached.Expires = cacheExpires(resp.Header, cacheNow(cache))
+		if err := cache.Set(address, cached); err != nil {
+			cfg.warn(address, "can't cache the document: %v", err)
+		}
+		return cached.Content, nil
+	case resp.StatusCode >= 500 && found:
+		cfg.warn(address, "using the cached document: %v %v", resp.StatusCode, http.StatusText(resp.StatusCode))
+		return cached.Content, nil
+	}
+	if err := checkStatus(address, resp); err != nil {
+		return nil, err
+	}
+
+	content, err := cfg.readLimited(address, resp.Body, resp.ContentLength)
+	if err != nil {
+		return nil, err
+	}
+	if !hasCacheDirective(resp.Header, "no-store") {
+		doc := CachedDocument{
+			Content:      content,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Expires:      cacheExpires(resp.Header, cacheNow(cache)),
+		}
+		if err := cache.Set(address, doc); err != nil {
+			cfg.warn(address, "can't cache the document: %v", err)
+		}
+	}
+	return content, nil
+}
+
+// cacheExpires returns until when a response received at now is fresh,
+// from its Cache-Control max-age or Expires headers. The zero time if it
+// must be revalidated.
+func cacheExpires(h http.Header, now time.Time) time.Time {
+	if hasCacheDirective(h, "no-cache") {
+		return time.Time{}
+	}
+	for _, directive := range strings.Split(h.Get("Cache-Control"), ",") {
+		directive = strings.TrimSpace(directive)
+		if !strings.HasPrefix(directive, "max-age=") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil {
+			return now.Add(time.Duration(seconds) * time.Second)
+		}
+	}
+	if expires, err := http.ParseTime(h.Get("Expires")); err == nil {
+		return expires
+	}
+	return time.Time{}
+}
+
+// hasCacheDirective returns true if the Cache-Control header has the directive
+func hasCacheDirective(h http.Header, name string) bool {
+	for _, directive := range strings.Split(h.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), name) {
+			return true
+		}
+	}
+	return false
+}
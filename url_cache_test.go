@@ -0,0 +1,101 @@
+package raml
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestURLCache(t *testing.T) {
+	Convey("remote documents cache", t, func() {
+		docs := map[string]string{
+			"/api.raml":   "#%RAML 1.0\ntitle: Remote\nuses:\n  notes: notes.raml\n",
+			"/notes.raml": "#%RAML 1.0 Library\ntypes:\n  Note:\n    properties:\n      text: string\n",
+		}
+		cacheControl := "no-cache"
+		offline := false
+		var requested []string
+		client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if offline {
+				return nil, errors.New("network is unreachable")
+			}
+			status := http.StatusOK
+			if req.Header.Get("If-None-Match") == `"v1"` {
+				status = http.StatusNotModified
+			}
+			requested = append(requested, req.URL.Path+" "+http.StatusText(status))
+			return &http.Response{
+				StatusCode: status,
+				Body:       ioutil.NopCloser(strings.NewReader(docs[req.URL.Path])),
+				Header:     http.Header{"Etag": {`"v1"`}, "Cache-Control": {cacheControl}},
+				Request:    req,
+			}, nil
+		})}
+
+		parse := func(cache URLCache) {
+			apiDef := new(APIDefinition)
+			So(ParseFile("http://raml.test/api.raml", apiDef, WithHTTPClient(client), WithURLCache(cache)),
+				ShouldBeNil)
+			_, ok := apiDef.TypeByName("notes.Note")
+			So(ok, ShouldBeTrue)
+		}
+
+		Convey("revalidation", func() {
+			cache := NewMemoryURLCache()
+			parse(cache)
+			So(requested, ShouldResemble, []string{"/api.raml OK", "/notes.raml OK"})
+
+			requested = nil
+			parse(cache)
+			So(requested, ShouldResemble, []string{"/api.raml Not Modified", "/notes.raml Not Modified"})
+
+			// stale documents are used if the server can't be reached
+			offline = true
+			parse(cache)
+		})
+
+		Convey("fresh documents", func() {
+			cacheControl = "public, max-age=60"
+			clock := time.Now()
+			cache := NewMemoryURLCache(WithCacheClock(func() time.Time { return clock }))
+			parse(cache)
+			So(requested, ShouldHaveLength, 2)
+
+			requested = nil
+			parse(cache)
+			So(requested, ShouldBeEmpty)
+
+			clock = clock.Add(time.Minute)
+			parse(cache)
+			So(requested, ShouldHaveLength, 2)
+		})
+
+		Convey("documents which must not be stored", func() {
+			cacheControl = "no-store"
+			cache := NewMemoryURLCache()
+			parse(cache)
+			_, found := cache.Get("http://raml.test/api.raml")
+			So(found, ShouldBeFalse)
+		})
+
+		Convey("directory", func() {
+			dir := t.TempDir()
+			parse(NewDirURLCache(dir))
+
+			// another process
+			requested = nil
+			parse(NewDirURLCache(dir))
+			So(requested, ShouldResemble, []string{"/api.raml Not Modified", "/notes.raml Not Modified"})
+
+			doc, found := NewDirURLCache(dir).Get("http://raml.test/notes.raml")
+			So(found, ShouldBeTrue)
+			So(string(doc.Content), ShouldEqual, docs["/notes.raml"])
+			So(doc.ETag, ShouldEqual, `"v1"`)
+		})
+	})
+}
@@ -0,0 +1,74 @@
+package raml
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Warning is a problem of a document which doesn't fail the parsing,
+// e.g. an unknown trait or the deprecated `schemas`
+type Warning struct {
+	// where the problem is, e.g. `GET /users: body`, an included file
+	// or the URL of a remote document, empty for the whole document
+	Location string
+
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Location == "" {
+		return w.Message
+	}
+	return w.Location + ": " + w.Message
+}
+
+// WithWarningHandler calls h for every warning of the document, of its included
+// files and of its libraries, as they are found. The warnings are also in
+// APIDefinition.Warnings, and they are not logged anymore.
+func WithWarningHandler(h func(Warning)) ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.warningHandler = h
+	}
+}
+
+// warningList collects the warnings of a document, of its included
+// files and of its libraries
+type warningList struct {
+	warnings []Warning
+}
+
+// warn reports a warning to the handler, or logs it if there is none,
+// and collects it
+func (cfg *parseConfig) warn(location, format string, args ...interface{}) {
+	w := Warning{Location: location, Message: fmt.Sprintf(format, args...)}
+	if cfg == nil {
+		log.Warning(w.String())
+		return
+	}
+	if cfg.warningHandler != nil {
+		cfg.warningHandler(w)
+	} else {
+		log.Warning(w.String())
+	}
+	if cfg.warnings != nil {
+		cfg.warnings.warnings = append(cfg.warnings.warnings, w)
+	}
+}
+
+// warn reports a warning of the API definition, see parseConfig.warn
+func (apiDef *APIDefinition) warn(location, format string, args ...interface{}) {
+	if apiDef == nil {
+		(*parseConfig)(nil).warn(location, format, args...)
+		return
+	}
+	cfg := apiDef.cfg
+	cfg.warn(location, format, args...)
+	// the warnings found once the document is parsed, e.g. by RegisterType,
+	// or of an API definition which is not parsed are added too
+	if cfg != nil && cfg.warnings != nil {
+		apiDef.Warnings = cfg.warnings.warnings
+	} else {
+		apiDef.Warnings = append(apiDef.Warnings, Warning{Location: location, Message: fmt.Sprintf(format, args...)})
+	}
+}
@@ -0,0 +1,106 @@
+package raml
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Workspace is a directory of RAML documents parsed together, see ParseWorkspace
+type Workspace struct {
+	// directory of the documents
+	Dir string
+
+	// the root API definition and its path, relative to Dir
+	API     *APIDefinition
+	APIFile string
+
+	// the libraries of the directory, by path relative to Dir. They are parsed once,
+	// the libraries of the API definition and of the other libraries share their declarations.
+	Libraries map[string]*Library
+
+	// the headers of all the RAML documents of the directory, by path relative to Dir,
+	// e.g. the trait fragments, the overlays and the extensions
+	Documents map[string]DocumentHeader
+}
+
+// ParseWorkspace parses a directory of RAML documents, e.g. an API project with its
+// libraries and fragments in subdirectories. The documents are the `.raml` files of the
+// directory and of its subdirectories, the kind of a document is given by its header.
+//
+// The root API definition is the only document of the directory with the header of an
+// API definition, `#%RAML 1.0`, the overlays and extensions are not parsed. Every library
+// is parsed once, whether it is used by the API definition, by other libraries or by
+// nobody, so the libraries of the directory are validated even when they are not used yet.
+// The other fragments are included by the documents as they are.
+func ParseWorkspace(dir string, opts ...ParseOption) (*Workspace, error) {
+	ws := &Workspace{
+		Dir:       dir,
+		Libraries: map[string]*Library{},
+		Documents: map[string]DocumentHeader{},
+	}
+	var roots []string
+	readCfg := newParseConfig(opts)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(p), ".raml") {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		contents, err := readFileContents(dir, rel, readCfg)
+		if err != nil {
+			return err
+		}
+		header, err := ParseHeader(contents)
+		if err != nil {
+			// not a RAML document, e.g. an included YAML file
+			return nil
+		}
+		ws.Documents[rel] = header
+		if header.Version == "1.0" && header.Kind == "" {
+			roots = append(roots, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(roots) {
+	case 0:
+		return nil, fmt.Errorf("%v: no API definition, a document starting with #%%RAML 1.0", dir)
+	case 1:
+	default:
+		sort.Strings(roots)
+		return nil, fmt.Errorf("%v: several API definitions: %v", dir, strings.Join(roots, ", "))
+	}
+
+	cfg := newParseConfig(opts)
+	errs := new(Error)
+	ws.APIFile = roots[0]
+	ws.API = new(APIDefinition)
+	if _, err := parseFile(dir, ws.APIFile, ws.API, cfg); err != nil {
+		errs.add(fmt.Errorf("%v: %v", ws.APIFile, cfg.limitError(err)))
+	}
+
+	// the libraries used by the API definition are already parsed,
+	// the other ones are parsed on their own
+	cfg.chain = nil
+	for _, rel := range sortedKeys(ws.Documents) {
+		if ws.Documents[rel].Kind != LibraryFragment {
+			continue
+		}
+		lib := &Library{Filename: rel}
+		if _, err := parseLibrary(dir, rel, lib, cfg); err != nil {
+			errs.add(fmt.Errorf("%v: %v", rel, cfg.limitError(err)))
+			continue
+		}
+		ws.Libraries[rel] = lib
+	}
+	return ws, errs.errOrNil()
+}
@@ -0,0 +1,55 @@
+package raml
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseWorkspace(t *testing.T) {
+	Convey("workspace", t, func() {
+		ws, err := ParseWorkspace("./samples/workspace", WithUnknownKeyErrors())
+		So(err, ShouldBeNil)
+
+		Convey("the root API definition", func() {
+			So(ws.APIFile, ShouldEqual, "api.raml")
+			So(ws.API.Title, ShouldEqual, "Workspace")
+			So(ws.API.Resources["/users"].Get.QueryParameters, ShouldContainKey, "page")
+		})
+
+		Convey("the documents by kind", func() {
+			So(ws.Documents, ShouldResemble, map[string]DocumentHeader{
+				"api.raml":              {Version: "1.0"},
+				"libraries/common.raml": {Version: "1.0", Kind: LibraryFragment},
+				"libraries/drafts.raml": {Version: "1.0", Kind: LibraryFragment},
+				"libraries/users.raml":  {Version: "1.0", Kind: LibraryFragment},
+				"traits/paged.raml":     {Version: "1.0", Kind: TraitFragment},
+			})
+		})
+
+		Convey("every library is parsed once", func() {
+			So(ws.Libraries, ShouldHaveLength, 3)
+			So(ws.Libraries["libraries/drafts.raml"].Types, ShouldContainKey, "Draft")
+
+			common := reflect.ValueOf(ws.Libraries["libraries/common.raml"].Types).Pointer()
+			So(reflect.ValueOf(ws.API.Libraries["common"].Types).Pointer(), ShouldEqual, common)
+			So(reflect.ValueOf(ws.API.Libraries["users"].Libraries["common"].Types).Pointer(), ShouldEqual, common)
+			So(reflect.ValueOf(ws.Libraries["libraries/drafts.raml"].Libraries["common"].Types).Pointer(), ShouldEqual, common)
+
+			// named as used by each document
+			So(ws.API.Libraries["common"].Filename, ShouldEqual, "libraries/common.raml")
+			So(ws.API.Libraries["users"].Libraries["common"].Filename, ShouldEqual, "common.raml")
+		})
+
+		Convey("a workspace has one API definition", func() {
+			_, err := ParseWorkspace("./samples/fragments")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "several API definitions: api.raml, uses.raml")
+
+			_, err = ParseWorkspace("./samples/workspace/libraries")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "no API definition")
+		})
+	})
+}
@@ -0,0 +1,341 @@
+package raml
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gigforks/yaml"
+)
+
+const (
+	ramlHeader = "#%RAML 1.0"
+	indentSize = 2
+)
+
+// WriteRAML writes the raw tree of the API definition back as RAML document.
+// The API definition must be parsed with KeepRaw set to true.
+//
+// WriteRAML works in round-trip mode:
+//   - keys are written in the order of the RawTree
+//   - comments of the original document are re-emitted
+//     on the node they were attached to
+//   - nodes that are not modified are written exactly as
+//     they were written in the original document, including
+//     the scalar and collection styles, indentation and blank lines.
+//
+// So writing an unmodified parsed document reproduces the input byte-for-byte,
+// except that included files are written inline.
+func (apiDef *APIDefinition) WriteRAML(w io.Writer) error {
+	if apiDef.RawTree == nil {
+		return errors.New("API definition has no raw tree, parse it with KeepRaw enabled")
+	}
+	bw := bufio.NewWriter(w)
+	e := &emitter{
+		w:        bw,
+		comments: apiDef.Comments,
+		src:      apiDef.source,
+	}
+	if e.src == nil {
+		e.src = &documentSource{
+			header:       ramlHeader,
+			lineEnding:   "\n",
+			finalNewline: true,
+		}
+	}
+	if err := e.emitDocument(apiDef.RawTree); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// emitter emits a YAML tree decoded as yaml.MapSlice
+type emitter struct {
+	w        *bufio.Writer
+	comments map[string]NodeComments
+	src      *documentSource
+	err      error
+
+	// pending line ending, so we could omit the last one
+	eol bool
+}
+
+func (e *emitter) emitDocument(tree yaml.MapSlice) error {
+	e.write(e.src.header)
+	e.newline()
+
+	if e.src.tree != nil && reflect.DeepEqual(tree, e.src.tree) {
+		// not modified
+		e.writeLines(e.src.lines)
+	} else {
+		e.emitMap(tree, 0, nil, "")
+		if e.src.tree != nil {
+			e.writeLines(e.src.lines[e.src.footStart:])
+		} else {
+			for _, c := range e.comments[""].Foot {
+				e.write(c)
+				e.newline()
+			}
+		}
+	}
+
+	// the last line ending is only written if
+	// the original document has it
+	if e.eol && e.src.finalNewline {
+		e.write()
+	}
+	return e.err
+}
+
+func (e *emitter) write(strs ...string) {
+	if e.eol {
+		e.eol = false
+		e.write(e.src.lineEnding)
+	}
+	for _, s := range strs {
+		if e.err != nil {
+			return
+		}
+		_, e.err = e.w.WriteString(s)
+	}
+}
+
+// newline ends current line
+func (e *emitter) newline() {
+	if e.eol {
+		e.write()
+	}
+	e.eol = true
+}
+
+// writeLines writes lines of the original document
+func (e *emitter) writeLines(lines []string) {
+	for _, l := range lines {
+		e.write(l)
+		e.newline()
+	}
+}
+
+// writeHead writes head comments of a node
+func (e *emitter) writeHead(path string, col int) {
+	for _, c := range e.comments[path].Head {
+		e.write(strings.Repeat(" ", col), c)
+		e.newline()
+	}
+}
+
+// lineComment returns line comment of a node, prefixed by a space
+func (e *emitter) lineComment(path string) string {
+	return commentSuffix(e.comments[path].Line)
+}
+
+// verbatim writes the node exactly as in the original document
+// if it is not modified. It returns false if the node needs to be emitted.
+func (e *emitter) verbatim(pointer string, path []string, v interface{}) bool {
+	span, ok := e.src.spans[pointer]
+	if !ok || span.inline {
+		return false
+	}
+	orig, ok := lookupTree(e.src.tree, path)
+	if !ok || !reflect.DeepEqual(orig, v) {
+		return false
+	}
+	e.writeLines(e.src.lines[span.start:span.end])
+	return true
+}
+
+// childCol returns column of the children of the node,
+// it follows the indentation of the original document if possible.
+func (e *emitter) childCol(path []string, v interface{}, col int) int {
+	var first string
+	switch val := v.(type) {
+	case yaml.MapSlice:
+		first = fmt.Sprint(val[0].Key)
+	case []interface{}:
+		first = "0"
+	}
+	childPath := append(append([]string{}, path...), first)
+	if span, ok := e.src.spans[jsonPointer(childPath)]; ok && !span.inline {
+		return span.col
+	}
+	if len(path) == 0 {
+		return 0
+	}
+	return col + indentSize
+}
+
+// emitMap emits a mapping.
+// If firstPrefix is not empty, the first key is written after it
+// instead of an indentation, it is used by the sequence items.
+func (e *emitter) emitMap(m yaml.MapSlice, col int, path []string, firstPrefix string) {
+	for i, item := range m {
+		key := fmt.Sprint(item.Key)
+		itemPath := append(append([]string{}, path...), key)
+		pointer := jsonPointer(itemPath)
+
+		prefix := strings.Repeat(" ", col)
+		if i == 0 && firstPrefix != "" {
+			// first key of a sequence item is on the same line as the dash
+			prefix = firstPrefix
+			e.writeHead(pointer, col-indentSize)
+		} else {
+			if e.verbatim(pointer, itemPath, item.Value) {
+				continue
+			}
+			e.writeHead(pointer, col)
+		}
+
+		e.write(prefix, formatScalar(item.Key), ":")
+		e.emitValue(item.Value, col, itemPath, pointer)
+	}
+}
+
+// emitValue emits the value of a mapping item or sequence item,
+// the key or the dash is already written.
+func (e *emitter) emitValue(v interface{}, col int, path []string, pointer string) {
+	switch val := v.(type) {
+	case yaml.MapSlice:
+		if len(val) == 0 {
+			e.write(" {}", e.lineComment(pointer))
+			e.newline()
+			return
+		}
+		e.write(e.lineComment(pointer))
+		e.newline()
+		e.emitMap(val, e.childCol(path, val, col), path, "")
+	case map[interface{}]interface{}:
+		e.emitValue(toMapSlice(val), col, path, pointer)
+	case []interface{}:
+		if len(val) == 0 {
+			e.write(" []", e.lineComment(pointer))
+			e.newline()
+			return
+		}
+		e.write(e.lineComment(pointer))
+		e.newline()
+		e.emitSeq(val, e.childCol(path, val, col), path)
+	case nil:
+		e.write(e.lineComment(pointer))
+		e.newline()
+	case includeRef:
+		e.write(" !include ", string(val), e.lineComment(pointer))
+		e.newline()
+	default:
+		s, ok := val.(string)
+		if ok && strings.Contains(s, "\n") {
+			e.write(" ", blockIndicator(s), e.lineComment(pointer))
+			e.newline()
+			e.writeBlock(s, col+indentSize)
+			return
+		}
+		e.write(" ", formatScalar(val), e.lineComment(pointer))
+		e.newline()
+	}
+}
+
+func (e *emitter) emitSeq(seq []interface{}, col int, path []string) {
+	for i, item := range seq {
+		itemPath := append(append([]string{}, path...), strconv.Itoa(i))
+		pointer := jsonPointer(itemPath)
+		if e.verbatim(pointer, itemPath, item) {
+			continue
+		}
+		e.writeHead(pointer, col)
+
+		prefix := strings.Repeat(" ", col) + "-"
+		switch val := item.(type) {
+		case yaml.MapSlice:
+			if len(val) > 0 {
+				e.emitMap(val, col+indentSize, itemPath, prefix+" ")
+				continue
+			}
+		case map[interface{}]interface{}:
+			if len(val) > 0 {
+				e.emitMap(toMapSlice(val), col+indentSize, itemPath, prefix+" ")
+				continue
+			}
+		}
+		e.write(prefix)
+		e.emitValue(item, col, itemPath, pointer)
+	}
+}
+
+// writeBlock writes the lines of a multi line string
+func (e *emitter) writeBlock(s string, col int) {
+	prefix := strings.Repeat(" ", col)
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		if line != "" {
+			e.write(prefix, line)
+		}
+		e.newline()
+	}
+}
+
+// blockIndicator returns the literal block scalar indicator
+// that keeps the trailing line breaks of s
+func blockIndicator(s string) string {
+	switch {
+	case strings.HasSuffix(s, "\n\n"):
+		return "|+"
+	case strings.HasSuffix(s, "\n"):
+		return "|"
+	default:
+		return "|-"
+	}
+}
+
+// formatScalar formats a scalar value in the form
+// that would be decoded back to the same value
+func formatScalar(v interface{}) string {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(string(b), "\n")
+}
+
+// toMapSlice converts a map to yaml.MapSlice with sorted keys
+func toMapSlice(m map[interface{}]interface{}) yaml.MapSlice {
+	var ms yaml.MapSlice
+	for k, v := range m {
+		ms = append(ms, yaml.MapItem{Key: k, Value: v})
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		return fmt.Sprint(ms[i].Key) < fmt.Sprint(ms[j].Key)
+	})
+	return ms
+}
+
+// lookupTree returns the node of the tree at the given path
+func lookupTree(tree interface{}, path []string) (interface{}, bool) {
+	node := tree
+	for _, elem := range path {
+		switch val := node.(type) {
+		case yaml.MapSlice:
+			found := false
+			for _, item := range val {
+				if fmt.Sprint(item.Key) == elem {
+					node, found = item.Value, true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		case []interface{}:
+			idx, err := strconv.Atoi(elem)
+			if err != nil || idx < 0 || idx >= len(val) {
+				return nil, false
+			}
+			node = val[idx]
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
@@ -0,0 +1,72 @@
+package raml
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteRAML(t *testing.T) {
+	Convey("write RAML with comments", t, func() {
+		apiDef := &APIDefinition{KeepRaw: true}
+		err := ParseFile("./samples/comments.raml", apiDef)
+		So(err, ShouldBeNil)
+
+		Convey("comments are captured", func() {
+			So(apiDef.Comments["/title"].Head, ShouldResemble, []string{"# head of title"})
+			So(apiDef.Comments["/title"].Line, ShouldEqual, "# the title")
+			So(apiDef.Comments["/types/User/properties/tags/1"].Line, ShouldEqual, "# second")
+			So(apiDef.Comments["/~1users/get/queryParameters/page/type"].Line, ShouldEqual, "# page number")
+			So(apiDef.Comments[""].Foot, ShouldResemble, []string{"# the end"})
+		})
+
+		Convey("comments are re-emitted", func() {
+			var buf bytes.Buffer
+			So(apiDef.WriteRAML(&buf), ShouldBeNil)
+
+			orig, err := ioutil.ReadFile("./samples/comments.raml")
+			So(err, ShouldBeNil)
+			So(buf.String(), ShouldEqual, string(orig))
+		})
+
+		Convey("modified node is re-emitted, others are kept as is", func() {
+			apiDef.RawTree[1].Value = "v2"
+
+			var buf bytes.Buffer
+			So(apiDef.WriteRAML(&buf), ShouldBeNil)
+
+			orig, err := ioutil.ReadFile("./samples/comments.raml")
+			So(err, ShouldBeNil)
+			So(buf.String(), ShouldEqual, strings.Replace(string(orig), "version: v1", "version: v2", 1))
+		})
+
+		Convey("API definition without raw tree", func() {
+			var buf bytes.Buffer
+			So(new(APIDefinition).WriteRAML(&buf), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	Convey("unmodified documents are written byte-for-byte", t, func() {
+		files, err := filepath.Glob("./samples/*.raml")
+		So(err, ShouldBeNil)
+
+		for _, file := range files {
+			apiDef := &APIDefinition{KeepRaw: true}
+			if err := ParseFile(file, apiDef); err != nil {
+				continue // invalid samples
+			}
+			var buf bytes.Buffer
+			So(apiDef.WriteRAML(&buf), ShouldBeNil)
+
+			orig, err := ioutil.ReadFile(file)
+			So(err, ShouldBeNil)
+			So(buf.String(), ShouldEqual, string(orig))
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package raml
+
+import "fmt"
+
+// XML is the `xml` facet of a type or a property, how its instances
+// are serialized in the application/xml bodies
+type XML struct {
+	// serialize the value as an attribute of the element of the parent, not as an element.
+	// Only for the scalar types.
+	Attribute bool `yaml:"attribute" json:"attribute,omitempty"`
+
+	// wrap the items of an array in an element named after the array
+	Wrapped bool `yaml:"wrapped" json:"wrapped,omitempty"`
+
+	// name of the element or attribute, the name of the type or property by default
+	Name string `yaml:"name" json:"name,omitempty"`
+
+	// namespace and prefix of the element or attribute
+	Namespace string `yaml:"namespace" json:"namespace,omitempty"`
+	Prefix    string `yaml:"prefix" json:"prefix,omitempty"`
+}
+
+// newXML creates the xml facet of a property from its declaration
+func newXML(v interface{}) *XML {
+	decl, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil
+	}
+	x := &XML{}
+	for k, v := range decl {
+		switch fmt.Sprint(k) {
+		case "attribute":
+			x.Attribute, _ = v.(bool)
+		case "wrapped":
+			x.Wrapped, _ = v.(bool)
+		case "name":
+			x.Name = fmt.Sprint(v)
+		case "namespace":
+			x.Namespace = fmt.Sprint(v)
+		case "prefix":
+			x.Prefix = fmt.Sprint(v)
+		}
+	}
+	return x
+}
+
+// IsAttribute returns true if the value is serialized as an attribute, false if x is nil
+func (x *XML) IsAttribute() bool {
+	return x != nil && x.Attribute
+}
+
+// IsWrapped returns true if the items of an array are wrapped, false if x is nil
+func (x *XML) IsWrapped() bool {
+	return x != nil && x.Wrapped
+}
+
+// QualifiedName returns the name of the element or attribute, with its prefix,
+// e.g. `ns:user`. name is the name of the type or property, used if x has no name.
+func (x *XML) QualifiedName(name string) string {
+	if x == nil {
+		return name
+	}
+	if x.Name != "" {
+		name = x.Name
+	}
+	if x.Prefix != "" {
+		return x.Prefix + ":" + name
+	}
+	return name
+}
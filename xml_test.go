@@ -0,0 +1,47 @@
+package raml
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestXMLFacet(t *testing.T) {
+	Convey("xml facet", t, func() {
+		apiDef := new(APIDefinition)
+		So(ParseFile("./samples/xml.raml", apiDef, WithUnknownKeyErrors()), ShouldBeNil)
+		user := apiDef.Types["User"]
+
+		Convey("types", func() {
+			So(*user.XML, ShouldResemble, XML{Name: "user", Namespace: "http://example.com/users", Prefix: "u"})
+			So(user.XML.QualifiedName(user.Name), ShouldEqual, "u:user")
+
+			admin := apiDef.Types["Admin"]
+			So(admin.XML, ShouldBeNil)
+			So(admin.XML.QualifiedName(admin.Name), ShouldEqual, "Admin")
+			So(admin.Resolve(apiDef).XML.Namespace, ShouldEqual, "http://example.com/users")
+		})
+
+		Convey("properties", func() {
+			id := user.GetProperty("id")
+			So(id.XML.IsAttribute(), ShouldBeTrue)
+			So(id.XML.QualifiedName(id.Name), ShouldEqual, "id")
+
+			roles := user.GetProperty("roles")
+			So(roles.XML.IsWrapped(), ShouldBeTrue)
+			So(roles.XML.IsAttribute(), ShouldBeFalse)
+			So(roles.XML.QualifiedName(roles.Name), ShouldEqual, "role")
+
+			name := user.GetProperty("name")
+			So(name.XML, ShouldBeNil)
+			So(name.XML.IsAttribute(), ShouldBeFalse)
+		})
+
+		Convey("the keys of the facet are checked", func() {
+			doc := "#%RAML 1.0\ntitle: API\ntypes:\n  User:\n    xml:\n      atribute: true\n"
+			err := ParseBytes([]byte(doc), new(APIDefinition), WithUnknownKeyErrors())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "unknown key atribute at /types/User/xml")
+		})
+	})
+}